@@ -1,14 +1,16 @@
 package main
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 
 	"github.com/erikbos/gatekeeper/cmd/authserver/oauth"
 	"github.com/erikbos/gatekeeper/cmd/authserver/policy"
 	"github.com/erikbos/gatekeeper/pkg/config"
+	"github.com/erikbos/gatekeeper/pkg/db/backend"
 	"github.com/erikbos/gatekeeper/pkg/db/cache"
-	"github.com/erikbos/gatekeeper/pkg/db/cassandra"
 	"github.com/erikbos/gatekeeper/pkg/shared"
 	"github.com/erikbos/gatekeeper/pkg/webadmin"
 )
@@ -23,18 +25,24 @@ const (
 	defaultCacheSize           = 100 * 1024 * 1024
 	defaultCacheTTL            = 180
 	defaultCacheNegativeTTL    = 5
+	defaultCacheInvalidation   = 1
 	defaultOrganization        = "default"
+	defaultJWKSRefreshInterval = time.Hour
+	defaultHMACClockSkew       = 30 * time.Second
+	defaultHMACNonceCacheSize  = 10 * 1024 * 1024
 )
 
 // AuthServerConfig contains our startup configuration data
 type AuthServerConfig struct {
-	Logger    shared.Logger            // log configuration of application
-	WebAdmin  webadmin.Config          // Admin web interface configuration
-	EnvoyAuth envoyAuthConfig          // Authserver configuration
-	OAuth     oauth.Config             // OAuth configuration
-	Database  cassandra.DatabaseConfig // Database configuration
-	Cache     cache.Config             // Cache configuration
-	Geoip     policy.Geoip             // Geoip lookup configuration
+	Logger    shared.Logger   // log configuration of application
+	WebAdmin  webadmin.Config // Admin web interface configuration
+	EnvoyAuth envoyAuthConfig // Authserver configuration
+	OAuth     oauth.Config    // OAuth configuration
+	Database  backend.Config  // Database configuration
+	Cache     cache.Config    // Cache configuration
+	Geoip     policy.Geoip    // Geoip lookup configuration
+	JWT       policy.JWT      // JWT validation configuration
+	HMAC      policy.HMAC     // HMAC request signature validation configuration
 }
 
 func loadConfiguration(filename string) (*AuthServerConfig, error) {
@@ -58,9 +66,17 @@ func loadConfiguration(filename string) (*AuthServerConfig, error) {
 			Listen: defaultOAuthListen,
 		},
 		Cache: cache.Config{
-			Size:        defaultCacheSize,
-			TTL:         defaultCacheTTL,
-			NegativeTTL: defaultCacheNegativeTTL,
+			Size:                 defaultCacheSize,
+			TTL:                  defaultCacheTTL,
+			NegativeTTL:          defaultCacheNegativeTTL,
+			InvalidationInterval: defaultCacheInvalidation,
+		},
+		JWT: policy.JWT{
+			RefreshInterval: defaultJWKSRefreshInterval,
+		},
+		HMAC: policy.HMAC{
+			ClockSkew:      defaultHMACClockSkew,
+			NonceCacheSize: defaultHMACNonceCacheSize,
 		},
 	}
 
@@ -86,6 +102,7 @@ func (config *AuthServerConfig) String() string {
 	// We must remove db password from configuration struct before showing
 	redactedConfig := config
 	redactedConfig.Database.Password = "[redacted]"
+	redactedConfig.Database.SQL.DSN = "[redacted]"
 
 	configAsYAML, err := yaml.Marshal(redactedConfig)
 	if err != nil {
@@ -57,12 +57,21 @@ func (s *server) Check(ctx context.Context,
 	timer := s.metrics.NewTimerAuthLatency()
 	defer timer.ObserveDuration()
 
+	return s.check(extauthzRequest, nil)
+}
+
+// check authenticates & authorizes a HTTP request, in case trace is set all steps are recorded
+func (s *server) check(extauthzRequest *envoy_service_auth_v3.CheckRequest,
+	trace *checkTrace) (*envoy_service_auth_v3.CheckResponse, error) {
+
 	request, err := request.DecodeAuthRequest(extauthzRequest)
 	if err != nil {
 		s.metrics.IncConnectionInfoFailure()
+		trace.addStep(traceStepDecode, err.Error())
 		return s.rejectRequest(http.StatusServiceUnavailable, nil, nil, err.Error())
 	}
 	request.Timestamp = shared.GetCurrentTimeMilliseconds()
+	trace.setRequest(request)
 
 	s.logger.Debug("extauthz",
 		zap.String("path", request.HTTPRequest.Path),
@@ -72,27 +81,35 @@ func (s *server) Check(ctx context.Context,
 	request.Listener, request.Organization, err = s.vhosts.Lookup(request.HTTPRequest.Host, request.Port)
 	if err != nil {
 		s.metrics.IncAuthenticationRejected(request)
+		trace.addStep(traceStepVhostLookup, err.Error())
 		return s.rejectRequest(http.StatusServiceUnavailable, nil, nil, "Unknown vhost/port")
 	}
+	trace.addStep(traceStepVhostLookup, fmt.Sprintf("vhost %s port %d uses listener %s of organization %s",
+		request.HTTPRequest.Host, request.Port, request.Listener.Name, request.Organization.Name))
 
-	policyConfig := policy.NewChainConfig(s.db, s.oauth, s.geoip, s.metrics, s.logger)
+	policyConfig := policy.NewChainConfig(s.db, s.oauth, s.geoip, s.jwt, s.hmac, s.expressions, s.metrics, s.logger)
+	if trace != nil {
+		policyConfig.EnableTrace()
+	}
 
 	// Evaluate policies, if any, assigned to listener
 	vhostPolicyOut := &policy.ChainOutcome{}
-	if request.Listener != nil && request.Listener.Policies != "" {
+	if request.Listener != nil && len(request.Listener.Policies) != 0 {
 		vhostPolicyOut = policy.NewChain(request,
 			policy.PolicyScopeVhost, policyConfig).Evaluate()
 
 		s.logger.Debug("vhostPolicyOutcome", zap.Reflect("debug", vhostPolicyOut))
+		trace.addPolicies(vhostPolicyOut.Trace)
 	}
 
 	// Evaluate policies assigned, if any, that are assigned to requested apiproduct
 	APIProductPolicyOut := &policy.ChainOutcome{}
-	if request.APIProduct != nil && request.APIProduct.Policies != "" {
+	if request.APIProduct != nil && len(request.APIProduct.Policies) != 0 {
 		APIProductPolicyOut = policy.NewChain(request,
 			policy.PolicyScopeAPIProduct, policyConfig).Evaluate()
 
 		s.logger.Debug("APIProductPolicyOutcome", zap.Reflect("debug", APIProductPolicyOut))
+		trace.addPolicies(APIProductPolicyOut.Trace)
 	}
 
 	// We reject request in case both vhost & apiproduct policy did not authenticate request
@@ -100,6 +117,7 @@ func (s *server) Check(ctx context.Context,
 		(APIProductPolicyOut != nil && !APIProductPolicyOut.Authenticated) {
 
 		s.metrics.IncAuthenticationRejected(request)
+		trace.addStep(traceStepDecision, "request rejected: "+vhostPolicyOut.DeniedMessage)
 
 		return s.rejectRequest(vhostPolicyOut.DeniedStatusCode,
 			mergeMapsStringString(vhostPolicyOut.UpstreamHeaders,
@@ -111,16 +129,19 @@ func (s *server) Check(ctx context.Context,
 
 	// Allow request
 	s.metrics.IncAuthenticationAccepted(request)
+	trace.addStep(traceStepDecision, "request allowed")
 
 	return s.allowRequest(
 		mergeMapsStringString(vhostPolicyOut.UpstreamHeaders,
 			APIProductPolicyOut.UpstreamHeaders),
+		append(vhostPolicyOut.UpstreamHeadersToRemove,
+			APIProductPolicyOut.UpstreamHeadersToRemove...),
 		mergeMapsStringString(vhostPolicyOut.UpstreamDynamicMetadata,
 			APIProductPolicyOut.UpstreamDynamicMetadata))
 }
 
 // allowRequest answers Envoyproxy to authorizates request to go upstream
-func (s *server) allowRequest(headers, metadata map[string]string) (
+func (s *server) allowRequest(headers map[string]string, headersToRemove []string, metadata map[string]string) (
 	*envoy_service_auth_v3.CheckResponse, error) {
 
 	response := &envoy_service_auth_v3.CheckResponse{
@@ -129,7 +150,8 @@ func (s *server) allowRequest(headers, metadata map[string]string) (
 		},
 		HttpResponse: &envoy_service_auth_v3.CheckResponse_OkResponse{
 			OkResponse: &envoy_service_auth_v3.OkHttpResponse{
-				Headers: buildHeadersList(headers),
+				Headers:         buildHeadersList(headers),
+				HeadersToRemove: headersToRemove,
 			},
 		},
 		DynamicMetadata: buildDynamicMetadataList(metadata),
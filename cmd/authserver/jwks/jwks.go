@@ -0,0 +1,167 @@
+// Package jwks encodes and decodes JSON Web Key Sets as specified by RFC 7517
+package jwks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Set is a set of keys
+type Set struct {
+	Keys []Key `json:"keys"`
+}
+
+// Key is a single RSA, EC or symmetric key
+type Key struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	K   string `json:"k,omitempty"`
+}
+
+// Supported key types
+const (
+	KeyTypeRSA       = "RSA"
+	KeyTypeEC        = "EC"
+	KeyTypeSymmetric = "oct"
+	curveP256        = "P-256"
+	// Key use for signatures
+	UseSignature = "sig"
+)
+
+// NewKey returns signature verification key for a RSA or EC P-256 public key,
+// its key id is set to the key's thumbprint
+func NewKey(publicKey interface{}, alg string) (Key, error) {
+
+	var key Key
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		key = Key{
+			Kty: KeyTypeRSA,
+			N:   encodeInt(k.N, 0),
+			E:   encodeInt(big.NewInt(int64(k.E)), 0),
+		}
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return Key{}, errors.New("unsupported curve")
+		}
+		// Coordinates must be full length as per RFC 7518, section 6.2.1.2
+		key = Key{
+			Kty: KeyTypeEC,
+			Crv: curveP256,
+			X:   encodeInt(k.X, 32),
+			Y:   encodeInt(k.Y, 32),
+		}
+	default:
+		return Key{}, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	key.Use = UseSignature
+	key.Alg = alg
+	key.Kid = key.Thumbprint()
+	return key, nil
+}
+
+// Thumbprint returns SHA-256 thumbprint of key as specified by RFC 7638
+func (k Key) Thumbprint() string {
+
+	// Required members only, in lexicographic order
+	var members interface{}
+	switch k.Kty {
+	case KeyTypeRSA:
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.Kty, k.N}
+	case KeyTypeEC:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{k.Crv, k.Kty, k.X, k.Y}
+	case KeyTypeSymmetric:
+		members = struct {
+			K   string `json:"k"`
+			Kty string `json:"kty"`
+		}{k.K, k.Kty}
+	default:
+		return ""
+	}
+	encoded, _ := json.Marshal(members)
+	hash := sha256.Sum256(encoded)
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// PublicKey returns key material of key, this is a *rsa.PublicKey,
+// *ecdsa.PublicKey or []byte in case of a symmetric key
+func (k Key) PublicKey() (interface{}, error) {
+
+	switch k.Kty {
+	case KeyTypeRSA:
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case KeyTypeEC:
+		if k.Crv != curveP256 {
+			return nil, fmt.Errorf("unsupported curve '%s'", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("point not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	case KeyTypeSymmetric:
+		return base64.RawURLEncoding.DecodeString(k.K)
+	}
+	return nil, fmt.Errorf("unsupported key type '%s'", k.Kty)
+}
+
+// encodeInt base64url encodes a big-endian integer, zero padded to size bytes
+func encodeInt(i *big.Int, size int) string {
+
+	b := i.Bytes()
+	if len(b) < size {
+		b = i.FillBytes(make([]byte, size))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeInt decodes a base64url encoded big-endian integer
+func decodeInt(s string) (*big.Int, error) {
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("missing key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
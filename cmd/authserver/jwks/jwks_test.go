@@ -0,0 +1,50 @@
+package jwks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Thumbprint(t *testing.T) {
+
+	// Example from RFC 7638, section 3.1
+	key := Key{
+		Kty: KeyTypeRSA,
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+		Alg: "RS256",
+		Kid: "2011-04-29",
+	}
+	require.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", key.Thumbprint())
+
+	// Decoding and encoding again should result in same key
+	publicKey, err := key.PublicKey()
+	require.NoError(t, err)
+	encoded, err := NewKey(publicKey, "RS256")
+	require.NoError(t, err)
+	require.Equal(t, key.N, encoded.N)
+	require.Equal(t, key.E, encoded.E)
+	require.Equal(t, key.Thumbprint(), encoded.Kid)
+}
+
+func Test_NewKey_EC(t *testing.T) {
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	key, err := NewKey(&privateKey.PublicKey, "ES256")
+	require.NoError(t, err)
+	require.Len(t, key.X, 43)
+	require.Len(t, key.Y, 43)
+
+	publicKey, err := key.PublicKey()
+	require.NoError(t, err)
+	require.True(t, privateKey.PublicKey.Equal(publicKey))
+
+	_, err = NewKey(privateKey, "ES256")
+	require.Error(t, err)
+}
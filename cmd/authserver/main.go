@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
 	"time"
 
 	"go.uber.org/zap"
@@ -11,8 +12,8 @@ import (
 	"github.com/erikbos/gatekeeper/cmd/authserver/oauth"
 	"github.com/erikbos/gatekeeper/cmd/authserver/policy"
 	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/db/backend"
 	"github.com/erikbos/gatekeeper/pkg/db/cache"
-	"github.com/erikbos/gatekeeper/pkg/db/cassandra"
 	"github.com/erikbos/gatekeeper/pkg/shared"
 	"github.com/erikbos/gatekeeper/pkg/webadmin"
 )
@@ -23,21 +24,25 @@ var (
 )
 
 type server struct {
-	config     *AuthServerConfig
-	webadmin   *webadmin.Webadmin
-	db         *db.Database
-	dbentities *db.EntityCache
-	vhosts     *vhostMapping
-	oauth      *oauth.Server
-	geoip      *policy.Geoip
-	metrics    *metrics.Metrics
-	logger     *zap.Logger
+	config      *AuthServerConfig
+	webadmin    *webadmin.Webadmin
+	db          *db.Database
+	dbentities  *db.EntityCache
+	vhosts      *vhostMapping
+	oauth       *oauth.Server
+	geoip       *policy.Geoip
+	jwt         *policy.JWTValidator
+	hmac        *policy.HMACValidator
+	expressions *policy.ExpressionCache
+	metrics     *metrics.Metrics
+	logger      *zap.Logger
 }
 
 func main() {
 	const applicationName = "authserver"
 
 	filename := flag.String("config", "authserver-config.yaml", "Configuration filename")
+	migrate := flag.String("migrate", "", "Run database schema migration command (up / status) and exit")
 	flag.Parse()
 
 	var a server
@@ -55,10 +60,17 @@ func main() {
 		zap.String("version", version),
 		zap.String("buildtime", buildTime))
 
+	if *migrate != "" {
+		if err := backend.Migrate(a.config.Database, *migrate, 1, a.logger); err != nil {
+			a.logger.Fatal("Database schema migration failed", zap.Error(err))
+		}
+		os.Exit(0)
+	}
+
 	a.metrics = metrics.New(applicationName)
 	a.metrics.RegisterWithPrometheus()
 
-	database, err := cassandra.New(a.config.Database, applicationName, a.logger, a.config.Database.InitKeyspaces, 1)
+	database, err := backend.New(a.config.Database, applicationName, a.logger, a.config.Database.InitKeyspaces, 1)
 	if err != nil {
 		a.logger.Fatal("Database connect failed", zap.Error(err))
 	}
@@ -76,6 +88,17 @@ func main() {
 		}
 	}
 
+	if a.config.JWT.JWKSFile != "" || a.config.JWT.JWKSURL != "" {
+		a.jwt, err = policy.NewJWTValidator(a.config.JWT, a.logger)
+		if err != nil {
+			a.logger.Fatal("JWKS load failed", zap.Error(err))
+		}
+		go a.jwt.Start()
+	}
+
+	a.hmac = policy.NewHMACValidator(a.config.HMAC)
+	a.expressions = policy.NewExpressionCache()
+
 	go startWebAdmin(&a, applicationName)
 
 	// Start continously loading of virtual host, routes & cluster data
@@ -110,6 +133,7 @@ func startWebAdmin(s *server, applicationName string) {
 	s.webadmin.Router.GET(webadmin.ReadinessCheckPath, webadmin.ReadinessProbe)
 	s.webadmin.Router.GET(webadmin.MetricsPath, s.metrics.GinHandler())
 	s.webadmin.Router.GET(webadmin.ConfigDumpPath, webadmin.ShowStartupConfiguration(s.config))
+	s.webadmin.Router.POST(traceRequestPath, newRequestTracer(s, applicationName).TraceRequest)
 
 	s.webadmin.Start()
 }
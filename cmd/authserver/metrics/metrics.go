@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -17,6 +19,7 @@ type Metrics struct {
 	configLoads                   *prometheus.CounterVec
 	connectInfoFailures           prometheus.Counter
 	UnknownAPIkey                 *prometheus.CounterVec
+	InsufficientScope             *prometheus.CounterVec
 	PolicyHits                    *prometheus.CounterVec
 	PolicyMisses                  *prometheus.CounterVec
 	CountryHits                   *prometheus.CounterVec
@@ -45,13 +48,28 @@ func (m *Metrics) GinHandler() gin.HandlerFunc {
 // RegisterWithPrometheus registers our operational metrics
 func (m *Metrics) RegisterWithPrometheus() {
 
+	m.registerWith(prometheus.DefaultRegisterer)
+}
+
+// NewUnexported returns metrics which are not exported to Prometheus, to be able
+// to evaluate requests without changing operational metrics
+func NewUnexported(applicationName string) *Metrics {
+
+	m := New(applicationName)
+	m.registerWith(prometheus.NewRegistry())
+	return m
+}
+
+// registerWith creates our operational metrics and registers them with registerer
+func (m *Metrics) registerWith(registerer prometheus.Registerer) {
+
 	m.authAccepted = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: m.applicationName,
 			Name:      "requests_accepted_total",
 			Help:      "Total number of authentication requests accepted.",
 		}, []string{"hostname", "protocol", "method", "apiproduct"})
-	prometheus.MustRegister(m.authAccepted)
+	registerer.MustRegister(m.authAccepted)
 
 	m.authRejected = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -59,7 +77,7 @@ func (m *Metrics) RegisterWithPrometheus() {
 			Name:      "requests_rejected_total",
 			Help:      "Total number of authentication requests rejected.",
 		}, []string{"hostname", "protocol", "method", "apiproduct"})
-	prometheus.MustRegister(m.authRejected)
+	registerer.MustRegister(m.authRejected)
 
 	m.authLatency = prometheus.NewSummary(
 		prometheus.SummaryOpts{
@@ -70,7 +88,7 @@ func (m *Metrics) RegisterWithPrometheus() {
 				0.5: 0.05, 0.9: 0.01, 0.99: 0.001, 0.999: 0.0001,
 			},
 		})
-	prometheus.MustRegister(m.authLatency)
+	registerer.MustRegister(m.authLatency)
 
 	m.configLoads = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -78,7 +96,7 @@ func (m *Metrics) RegisterWithPrometheus() {
 			Name:      "config_table_loads_total",
 			Help:      "Total sum of listener/route/cluster table loads.",
 		}, []string{"resource"})
-	prometheus.MustRegister(m.configLoads)
+	registerer.MustRegister(m.configLoads)
 
 	m.connectInfoFailures = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -86,7 +104,7 @@ func (m *Metrics) RegisterWithPrometheus() {
 			Name:      "connection_info_failures_total",
 			Help:      "Total number of connection info failures.",
 		})
-	prometheus.MustRegister(m.connectInfoFailures)
+	registerer.MustRegister(m.connectInfoFailures)
 
 	m.UnknownAPIkey = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -94,23 +112,31 @@ func (m *Metrics) RegisterWithPrometheus() {
 			Name:      "requests_unknown_apikey_total",
 			Help:      "Total number of requests with an unknown apikey.",
 		}, []string{"hostname", "protocol", "method"})
-	prometheus.MustRegister(m.UnknownAPIkey)
+	registerer.MustRegister(m.UnknownAPIkey)
+
+	m.InsufficientScope = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: m.applicationName,
+			Name:      "requests_insufficient_scope_total",
+			Help:      "Total number of requests with a token lacking a scope required by apiproduct.",
+		}, []string{"hostname", "protocol", "method", "apiproduct"})
+	registerer.MustRegister(m.InsufficientScope)
 
 	m.PolicyHits = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: m.applicationName,
 			Name:      "policy_hits_total",
 			Help:      "Total number of policy hits.",
-		}, []string{"scope", "policy"})
-	prometheus.MustRegister(m.PolicyHits)
+		}, []string{"scope", "policy", "shadow"})
+	registerer.MustRegister(m.PolicyHits)
 
 	m.PolicyMisses = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: m.applicationName,
 			Name:      "policy_unknown_total",
 			Help:      "Total number of unknown policy hits.",
-		}, []string{"scope", "policy"})
-	prometheus.MustRegister(m.PolicyMisses)
+		}, []string{"scope", "policy", "shadow"})
+	registerer.MustRegister(m.PolicyMisses)
 
 	m.CountryHits = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -118,7 +144,7 @@ func (m *Metrics) RegisterWithPrometheus() {
 			Name:      "requests_per_country_total",
 			Help:      "Total number of requests per country.",
 		}, []string{"country"})
-	prometheus.MustRegister(m.CountryHits)
+	registerer.MustRegister(m.CountryHits)
 
 	m.OAuthClientStoreHits = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -126,7 +152,7 @@ func (m *Metrics) RegisterWithPrometheus() {
 			Name:      "oauth_clientstore_hits_total",
 			Help:      "Number of OAuth client store hits.",
 		})
-	prometheus.MustRegister(m.OAuthClientStoreHits)
+	registerer.MustRegister(m.OAuthClientStoreHits)
 
 	m.OAuthClientStoreMisses = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -134,7 +160,7 @@ func (m *Metrics) RegisterWithPrometheus() {
 			Name:      "oauth_clientstore_misses_total",
 			Help:      "Number of OAuth client store misses.",
 		})
-	prometheus.MustRegister(m.OAuthClientStoreMisses)
+	registerer.MustRegister(m.OAuthClientStoreMisses)
 
 	m.OAuthTokenStoreIssueSuccesses = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -142,7 +168,7 @@ func (m *Metrics) RegisterWithPrometheus() {
 			Name:      "oauth_tokenstore_issue_successes_total",
 			Help:      "Number of OAuth succesful token store issue requests.",
 		})
-	prometheus.MustRegister(m.OAuthTokenStoreIssueSuccesses)
+	registerer.MustRegister(m.OAuthTokenStoreIssueSuccesses)
 
 	m.OAuthTokenStoreIssueFailures = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -150,7 +176,7 @@ func (m *Metrics) RegisterWithPrometheus() {
 			Name:      "oauth_tokenstore_issue_failures_total",
 			Help:      "Number of OAuth token store issue failures.",
 		})
-	prometheus.MustRegister(m.OAuthTokenStoreIssueFailures)
+	registerer.MustRegister(m.OAuthTokenStoreIssueFailures)
 
 	m.OAuthTokenStoreLookupHits = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -158,7 +184,7 @@ func (m *Metrics) RegisterWithPrometheus() {
 			Name:      "oauth_tokenstore_lookup_hits_total",
 			Help:      "Number of OAuth token store lookup hits.",
 		}, []string{"method"})
-	prometheus.MustRegister(m.OAuthTokenStoreLookupHits)
+	registerer.MustRegister(m.OAuthTokenStoreLookupHits)
 
 	m.OAuthTokenStoreLookupMisses = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -166,7 +192,7 @@ func (m *Metrics) RegisterWithPrometheus() {
 			Name:      "oauth_tokenstore_lookup_misses_total",
 			Help:      "Number of OAuth token store lookup misses.",
 		}, []string{"method"})
-	prometheus.MustRegister(m.OAuthTokenStoreLookupMisses)
+	registerer.MustRegister(m.OAuthTokenStoreLookupMisses)
 }
 
 // IncAuthenticationAccepted counts requests that are accepted
@@ -237,16 +263,32 @@ func (m *Metrics) IncDatabaseFetchFailure(r *request.Request) {
 		r.HTTPRequest.Method).Inc()
 }
 
-// IncPolicyHits increases policy hit metric
-func (m *Metrics) IncPolicyHits(scope, name string) {
+// IncInsufficientScope increases insufficient scope metric
+func (m *Metrics) IncInsufficientScope(r *request.Request) {
+
+	var product string
+
+	if r.APIProduct != nil {
+		product = r.APIProduct.Name
+	}
+
+	m.InsufficientScope.WithLabelValues(
+		r.HTTPRequest.Host,
+		r.HTTPRequest.Protocol,
+		r.HTTPRequest.Method,
+		product).Inc()
+}
+
+// IncPolicyHits increases policy hit metric, shadow indicates policy evaluated in shadow mode
+func (m *Metrics) IncPolicyHits(scope, name string, shadow bool) {
 
-	m.PolicyHits.WithLabelValues(scope, name).Inc()
+	m.PolicyHits.WithLabelValues(scope, name, strconv.FormatBool(shadow)).Inc()
 }
 
-// IncPolicyMisses increases policy miss metric
-func (m *Metrics) IncPolicyMisses(scope, name string) {
+// IncPolicyMisses increases policy miss metric, shadow indicates policy evaluated in shadow mode
+func (m *Metrics) IncPolicyMisses(scope, name string, shadow bool) {
 
-	m.PolicyMisses.WithLabelValues(scope, name).Inc()
+	m.PolicyMisses.WithLabelValues(scope, name, strconv.FormatBool(shadow)).Inc()
 }
 
 // IncCountryHits increases country hit metric
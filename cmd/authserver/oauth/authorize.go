@@ -0,0 +1,89 @@
+package oauth
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	oautherrors "github.com/go-oauth2/oauth2/v4/errors"
+)
+
+// UserAuthorizationHandler authenticates the user of an authorization request and
+// obtains its consent. It returns the id of the user on success. In case it returns
+// an empty user id without error the request has been handled, e.g. by redirecting
+// the user to a login page.
+type UserAuthorizationHandler func(w http.ResponseWriter, r *http.Request) (userID string, err error)
+
+// SetUserAuthorizationHandler sets the handler to authenticate users during
+// authorization requests, replacing the default header based handler
+func (oauth *Server) SetUserAuthorizationHandler(handler UserAuthorizationHandler) {
+
+	oauth.userAuthorization = handler
+}
+
+// handleAuthorizeRequest handles a request for an authorization code
+func (oauth *Server) handleAuthorizeRequest(c *gin.Context) {
+
+	// Errors are returned to client by redirecting to redirect_uri, so we
+	// have to make sure it is valid before handing request to oauth server
+	if err := c.Request.ParseForm(); err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	client, err := oauth.oauthserver.Manager.GetClient(c.Request.Context(), c.Request.Form.Get("client_id"))
+	if err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, oautherrors.ErrInvalidClient)
+		return
+	}
+	redirectURI := c.Request.Form.Get("redirect_uri")
+	if err := validateRedirectURI(client.GetDomain(), redirectURI); err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	if redirectURI == "" {
+		c.Request.Form.Set("redirect_uri", client.GetDomain())
+	}
+
+	if err := oauth.oauthserver.HandleAuthorizeRequest(c.Writer, c.Request); err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+}
+
+// validateRedirectURI checks whether redirect uri matches callback url of developer app
+func validateRedirectURI(callbackURL, redirectURI string) error {
+
+	if callbackURL == "" {
+		return oautherrors.ErrInvalidRedirectURI
+	}
+	// No redirect uri provided means callback url of app will be used
+	if redirectURI == "" || redirectURI == callbackURL {
+		return nil
+	}
+	return oautherrors.ErrInvalidRedirectURI
+}
+
+// userAuthorizationFromHeader authenticates the user by a header set by an
+// authenticating proxy, if not present the user is redirected to login.
+// The header is not verified: the proxy must remove it from client requests.
+func (oauth *Server) userAuthorizationFromHeader(w http.ResponseWriter, r *http.Request) (string, error) {
+
+	if oauth.config.UserIDHeader != "" {
+		if userID := r.Header.Get(oauth.config.UserIDHeader); userID != "" {
+			return userID, nil
+		}
+	}
+	if oauth.config.LoginURL == "" {
+		return "", oautherrors.ErrAccessDenied
+	}
+	loginURL, err := url.Parse(oauth.config.LoginURL)
+	if err != nil {
+		return "", err
+	}
+	query := loginURL.Query()
+	query.Set("return_to", r.URL.RequestURI())
+	loginURL.RawQuery = query.Encode()
+
+	http.Redirect(w, r, loginURL.String(), http.StatusFound)
+	return "", nil
+}
@@ -0,0 +1,210 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/metrics"
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/db/memory"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	testCallbackURL  = "https://app.example.com/callback"
+	testCodeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+func newTestServer(t *testing.T) *Server {
+
+	database, err := memory.New(t.Name(), zap.NewNop())
+	require.NoError(t, err)
+	require.Nil(t, database.Organization.Update(&types.Organization{Name: "default"}, db.Precondition{}))
+	require.Nil(t, database.APIProduct.Update("default", &types.APIProduct{
+		Name:   "orders",
+		Scopes: []string{"read", "write"},
+	}, db.Precondition{}))
+	require.Nil(t, database.APIProduct.Update("default", &types.APIProduct{
+		Name:   "payments",
+		Scopes: []string{"pay"},
+	}, db.Precondition{}))
+	require.Nil(t, database.Developer.Update("default", &types.Developer{
+		DeveloperID: "dev1",
+		Email:       "joe@example.com",
+		Status:      "active",
+	}, db.Precondition{}))
+	require.Nil(t, database.DeveloperApp.Update("default", &types.DeveloperApp{
+		AppID:       "app1",
+		DeveloperID: "dev1",
+		Name:        "app",
+		CallbackURL: testCallbackURL,
+	}, db.Precondition{}))
+	require.Nil(t, database.Key.UpdateByKey("default", &types.Key{
+		ConsumerKey:    "client",
+		ConsumerSecret: "secret",
+		AppID:          "app1",
+		Status:         "approved",
+		APIProducts: types.KeyAPIProductStatuses{
+			{Apiproduct: "orders", Status: "approved"},
+			{Apiproduct: "payments", Status: "revoked"},
+		},
+	}))
+
+	m := metrics.New(t.Name())
+	m.RegisterWithPrometheus()
+
+	oauth := New(Config{
+		TokenIssuePath: "/oauth2/token",
+		AuthorizePath:  "/oauth2/authorize",
+		RevokePath:     "/oauth2/revoke",
+		IntrospectPath: "/oauth2/introspect",
+		LoginURL:       "https://login.example.com/",
+		UserIDHeader:   "X-User",
+	}, database, m, zap.NewNop())
+	require.NoError(t, oauth.setup())
+	return oauth
+}
+
+func authorize(oauth *Server, query url.Values, user string) *httptest.ResponseRecorder {
+
+	request := httptest.NewRequest(http.MethodGet, "/oauth2/authorize?"+query.Encode(), nil)
+	if user != "" {
+		request.Header.Set("X-User", user)
+	}
+	response := httptest.NewRecorder()
+	oauth.router.ServeHTTP(response, request)
+	return response
+}
+
+func postForm(oauth *Server, path string, form url.Values) *httptest.ResponseRecorder {
+
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	response := httptest.NewRecorder()
+	oauth.router.ServeHTTP(response, request)
+	return response
+}
+
+func requestToken(oauth *Server, form url.Values) (*httptest.ResponseRecorder, map[string]interface{}) {
+
+	form.Set("client_id", "client")
+	form.Set("client_secret", "secret")
+	response := postForm(oauth, "/oauth2/token", form)
+
+	var body map[string]interface{}
+	_ = json.Unmarshal(response.Body.Bytes(), &body)
+	return response, body
+}
+
+func Test_AuthorizationCodeGrant(t *testing.T) {
+
+	oauth := newTestServer(t)
+
+	challenge := sha256.Sum256([]byte(testCodeVerifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"client"},
+		"redirect_uri":          {testCallbackURL},
+		"state":                 {"xyz"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	// Redirect uri not matching callback url of app is rejected without redirecting
+	badRedirect := url.Values{}
+	for k, v := range query {
+		badRedirect[k] = v
+	}
+	badRedirect.Set("redirect_uri", "https://evil.example.com/")
+	require.Equal(t, http.StatusBadRequest, authorize(oauth, badRedirect, "joe").Code)
+
+	// PKCE is required
+	noChallenge := url.Values{}
+	for k, v := range query {
+		noChallenge[k] = v
+	}
+	noChallenge.Del("code_challenge")
+	require.Equal(t, http.StatusBadRequest, authorize(oauth, noChallenge, "joe").Code)
+
+	// Unauthenticated user is sent to login
+	response := authorize(oauth, query, "")
+	require.Equal(t, http.StatusFound, response.Code)
+	location, err := url.Parse(response.Header().Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "login.example.com", location.Host)
+	require.True(t, strings.HasPrefix(location.Query().Get("return_to"), "/oauth2/authorize?"))
+
+	response = authorize(oauth, query, "joe")
+	require.Equal(t, http.StatusFound, response.Code)
+	location, err = url.Parse(response.Header().Get("Location"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(location.String(), testCallbackURL))
+	require.Equal(t, "xyz", location.Query().Get("state"))
+	code := location.Query().Get("code")
+	require.NotEmpty(t, code)
+
+	exchange := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {testCallbackURL},
+	}
+
+	// Code can only be exchanged with matching code verifier
+	exchange.Set("code_verifier", strings.Repeat("a", 43))
+	response, _ = requestToken(oauth, exchange)
+	require.NotEqual(t, http.StatusOK, response.Code)
+
+	// Wrong verifier has consumed the code, get a new one
+	response = authorize(oauth, query, "joe")
+	location, err = url.Parse(response.Header().Get("Location"))
+	require.NoError(t, err)
+	exchange.Set("code", location.Query().Get("code"))
+	exchange.Set("code_verifier", testCodeVerifier)
+	response, token := requestToken(oauth, exchange)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.NotEmpty(t, token["access_token"])
+	refreshToken, _ := token["refresh_token"].(string)
+	require.NotEmpty(t, refreshToken)
+
+	// Code cannot be used twice
+	response, _ = requestToken(oauth, exchange)
+	require.NotEqual(t, http.StatusOK, response.Code)
+
+	tokenInfo, err := oauth.LoadAccessToken(context.Background(), token["access_token"].(string))
+	require.NoError(t, err)
+	require.Equal(t, "joe", tokenInfo.GetUserID())
+
+	// Refresh token gets new token pair, and cannot be used again
+	refresh := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+	response, refreshed := requestToken(oauth, refresh)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.NotEqual(t, token["access_token"], refreshed["access_token"])
+	require.NotEqual(t, refreshToken, refreshed["refresh_token"])
+
+	response, _ = requestToken(oauth, refresh)
+	require.NotEqual(t, http.StatusOK, response.Code)
+
+	// Revoking refresh token revokes its access token as well
+	response = postForm(oauth, "/oauth2/revoke", url.Values{
+		"client_id":       {"client"},
+		"client_secret":   {"secret"},
+		"token":           {refreshed["refresh_token"].(string)},
+		"token_type_hint": {"refresh_token"},
+	})
+	require.Equal(t, http.StatusOK, response.Code)
+	_, err = oauth.LoadAccessToken(context.Background(), refreshed["access_token"].(string))
+	require.Error(t, err)
+}
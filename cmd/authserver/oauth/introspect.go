@@ -0,0 +1,141 @@
+package oauth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-oauth2/oauth2/v4"
+	oautherrors "github.com/go-oauth2/oauth2/v4/errors"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/pkg/shared"
+)
+
+// introspectionAnswer is the response to an introspection request (RFC 7662, section 2.2)
+type introspectionAnswer struct {
+	Active         bool     `json:"active"`
+	Scope          string   `json:"scope,omitempty"`
+	ClientID       string   `json:"client_id,omitempty"`
+	Username       string   `json:"username,omitempty"`
+	TokenType      string   `json:"token_type,omitempty"`
+	ExpiresAt      int64    `json:"exp,omitempty"`
+	IssuedAt       int64    `json:"iat,omitempty"`
+	Subject        string   `json:"sub,omitempty"`
+	DeveloperEmail string   `json:"developer_email,omitempty"`
+	AppName        string   `json:"app_name,omitempty"`
+	APIProducts    []string `json:"apiproducts,omitempty"`
+}
+
+// handleIntrospectRequest handles a POST request of a resource server to get the
+// state and details of an access or refresh token (RFC 7662)
+func (oauth *Server) handleIntrospectRequest(c *gin.Context) {
+
+	clientID, ok := oauth.authenticateClient(c.Request)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": oautherrors.ErrInvalidClient.Error()})
+		return
+	}
+	token := c.Request.PostFormValue("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": oautherrors.ErrInvalidRequest.Error()})
+		return
+	}
+
+	inactive := introspectionAnswer{Active: false}
+
+	// Unknown, expired and revoked tokens are not active
+	tokenInfo, tokenType := oauth.lookupToken(c.Request.Context(), token, c.Request.PostFormValue("token_type_hint"))
+	if tokenInfo == nil {
+		c.JSON(http.StatusOK, inactive)
+		return
+	}
+	// Clients can only introspect their own tokens, unless allowed to introspect all
+	if tokenInfo.GetClientID() != clientID && !oauth.isIntrospectionClient(clientID) {
+		c.JSON(http.StatusOK, inactive)
+		return
+	}
+	answer, err := oauth.introspectToken(tokenInfo, tokenType)
+	if err != nil {
+		oauth.logger.Debug("Token not active", zap.String("clientid", tokenInfo.GetClientID()), zap.Error(err))
+		c.JSON(http.StatusOK, inactive)
+		return
+	}
+	c.JSON(http.StatusOK, answer)
+}
+
+// introspectToken builds introspection answer of a token, its key, developer app
+// and developer need to be valid for the token to be active
+func (oauth *Server) introspectToken(tokenInfo oauth2.TokenInfo, tokenType string) (*introspectionAnswer, error) {
+
+	clientID := tokenInfo.GetClientID()
+	key, keyErr := oauth.db.Key.GetByKey(nil, &clientID)
+	if keyErr != nil {
+		return nil, keyErr
+	}
+	organizationName, developerApp, err := findDeveloperApp(oauth.db, key.AppID)
+	if err != nil {
+		return nil, err
+	}
+	developer, developerErr := oauth.db.Developer.GetByID(organizationName, developerApp.DeveloperID)
+	if developerErr != nil {
+		return nil, developerErr
+	}
+	if !developer.IsActive() {
+		return nil, errors.New("developer not active")
+	}
+	if developerApp.IsRevoked() {
+		return nil, errors.New("developer app revoked")
+	}
+	if !key.IsApproved() {
+		return nil, errors.New("unapproved apikey")
+	}
+	if key.IsExpired(shared.GetCurrentTimeMilliseconds()) {
+		return nil, errors.New("expired apikey")
+	}
+
+	answer := &introspectionAnswer{
+		Active:         true,
+		Scope:          tokenInfo.GetScope(),
+		ClientID:       clientID,
+		Username:       tokenInfo.GetUserID(),
+		TokenType:      oauth.oauthserver.Config.TokenType,
+		Subject:        tokenInfo.GetUserID(),
+		DeveloperEmail: developer.Email,
+		AppName:        developerApp.Name,
+	}
+	// Client credentials tokens do not have a user, the client is the subject
+	if answer.Subject == "" {
+		answer.Subject = clientID
+	}
+	var createdAt time.Time
+	var expiresIn time.Duration
+	if tokenType == tokenTypeHintRefreshToken {
+		createdAt, expiresIn = tokenInfo.GetRefreshCreateAt(), tokenInfo.GetRefreshExpiresIn()
+	} else {
+		createdAt, expiresIn = tokenInfo.GetAccessCreateAt(), tokenInfo.GetAccessExpiresIn()
+	}
+	answer.IssuedAt = createdAt.UTC().Unix()
+	if expiresIn > 0 {
+		answer.ExpiresAt = createdAt.Add(expiresIn).UTC().Unix()
+	}
+	for _, apiproduct := range key.APIProducts {
+		if apiproduct.IsApproved() {
+			answer.APIProducts = append(answer.APIProducts, apiproduct.Apiproduct)
+		}
+	}
+	return answer, nil
+}
+
+// isIntrospectionClient returns true in case client is allowed to introspect
+// tokens of all clients
+func (oauth *Server) isIntrospectionClient(clientID string) bool {
+
+	for _, introspectionClient := range oauth.config.IntrospectionClients {
+		if introspectionClient == clientID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package oauth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func Test_IntrospectToken(t *testing.T) {
+
+	oauth := newTestServer(t)
+	require.Nil(t, oauth.db.Key.UpdateByKey("default", &types.Key{
+		ConsumerKey:    "other",
+		ConsumerSecret: "othersecret",
+		AppID:          "app1",
+		Status:         "approved",
+	}))
+
+	response, token := requestToken(oauth, url.Values{"grant_type": {"client_credentials"}})
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	accessToken := token["access_token"].(string)
+
+	introspect := func(clientID, clientSecret string, form url.Values) (int, map[string]interface{}) {
+		form.Set("client_id", clientID)
+		form.Set("client_secret", clientSecret)
+		response := postForm(oauth, "/oauth2/introspect", form)
+
+		var body map[string]interface{}
+		_ = json.Unmarshal(response.Body.Bytes(), &body)
+		return response.Code, body
+	}
+
+	status, _ := introspect("client", "wrong", url.Values{"token": {accessToken}})
+	require.Equal(t, http.StatusUnauthorized, status)
+	status, _ = introspect("client", "secret", url.Values{})
+	require.Equal(t, http.StatusBadRequest, status)
+
+	status, answer := introspect("client", "secret", url.Values{"token": {"unknown"}})
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, map[string]interface{}{"active": false}, answer)
+
+	status, answer = introspect("client", "secret", url.Values{"token": {accessToken}})
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, true, answer["active"])
+	require.Equal(t, "client", answer["client_id"])
+	require.Equal(t, "client", answer["sub"])
+	require.Equal(t, "Bearer", answer["token_type"])
+	require.Equal(t, "joe@example.com", answer["developer_email"])
+	require.Equal(t, "app", answer["app_name"])
+	require.Equal(t, []interface{}{"orders"}, answer["apiproducts"])
+	require.Greater(t, answer["exp"], answer["iat"])
+
+	// Token of other client is only visible to introspection clients
+	_, answer = introspect("other", "othersecret", url.Values{"token": {accessToken}})
+	require.Equal(t, false, answer["active"])
+	oauth.config.IntrospectionClients = []string{"other"}
+	_, answer = introspect("other", "othersecret", url.Values{"token": {accessToken}})
+	require.Equal(t, true, answer["active"])
+
+	// Token of revoked key is not active
+	clientID := "client"
+	key, err := oauth.db.Key.GetByKey(nil, &clientID)
+	require.Nil(t, err)
+	key.Revoke()
+	require.Nil(t, oauth.db.Key.UpdateByKey("default", key))
+	_, answer = introspect("other", "othersecret", url.Values{"token": {accessToken}})
+	require.Equal(t, false, answer["active"])
+}
@@ -0,0 +1,260 @@
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-oauth2/oauth2/v4"
+	"github.com/go-oauth2/oauth2/v4/models"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/jwks"
+)
+
+// Supported formats of issued access tokens
+const (
+	AccessTokenFormatOpaque = "opaque"
+	AccessTokenFormatJWT    = "jwt"
+)
+
+// JWKSPath is the path of endpoint publishing the public keys of JWT access tokens
+const JWKSPath = "/.well-known/jwks.json"
+
+// JWTConfig contains configuration of JWT access tokens
+type JWTConfig struct {
+	SigningKeyFile string   // PEM file with RSA or EC P-256 private key to sign tokens with
+	PublicKeyFiles []string // PEM files with public keys of tokens still accepted (e.g. previous signing key)
+	Issuer         string   // Issuer (iss claim) of tokens
+}
+
+// jwtAccessTokens issues and validates self-contained JWT access tokens
+type jwtAccessTokens struct {
+	issuer     string
+	method     jwt.SigningMethod
+	kid        string
+	signingKey interface{}
+	publicKeys map[string]interface{}
+	keySet     jwks.Set
+}
+
+// newJWTAccessTokens loads signing key and additional public keys
+func newJWTAccessTokens(config JWTConfig) (*jwtAccessTokens, error) {
+
+	if config.SigningKeyFile == "" {
+		return nil, errors.New("jwt access tokens require signingkeyfile to be configured")
+	}
+	signingKey, err := loadPrivateKey(config.SigningKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	j := &jwtAccessTokens{
+		issuer:     config.Issuer,
+		signingKey: signingKey,
+		publicKeys: make(map[string]interface{}),
+	}
+	var publicKey interface{}
+	switch k := signingKey.(type) {
+	case *rsa.PrivateKey:
+		j.method, publicKey = jwt.SigningMethodRS256, &k.PublicKey
+	case *ecdsa.PrivateKey:
+		j.method, publicKey = jwt.SigningMethodES256, &k.PublicKey
+	}
+	if j.kid, err = j.addPublicKey(publicKey); err != nil {
+		return nil, err
+	}
+	for _, filename := range config.PublicKeyFiles {
+		publicKey, err := loadPublicKey(filename)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := j.addPublicKey(publicKey); err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// addPublicKey adds a key to the set of keys accepted and published, returns its key id
+func (j *jwtAccessTokens) addPublicKey(publicKey interface{}) (string, error) {
+
+	alg := jwt.SigningMethodRS256.Alg()
+	if _, ok := publicKey.(*ecdsa.PublicKey); ok {
+		alg = jwt.SigningMethodES256.Alg()
+	}
+	key, err := jwks.NewKey(publicKey, alg)
+	if err != nil {
+		return "", err
+	}
+	if _, exists := j.publicKeys[key.Kid]; !exists {
+		j.publicKeys[key.Kid] = publicKey
+		j.keySet.Keys = append(j.keySet.Keys, key)
+	}
+	return key.Kid, nil
+}
+
+// Token generates a signed access token and an opaque refresh token, implements oauth2.AccessGenerate
+func (j *jwtAccessTokens) Token(ctx context.Context, data *oauth2.GenerateBasic,
+	isGenRefresh bool) (string, string, error) {
+
+	createdAt := data.TokenInfo.GetAccessCreateAt()
+	claims := jwt.MapClaims{
+		"client_id": data.Client.GetID(),
+		"sub":       data.Client.GetID(),
+		"iat":       createdAt.Unix(),
+		"exp":       createdAt.Add(data.TokenInfo.GetAccessExpiresIn()).Unix(),
+		"jti":       uuid.New().String(),
+	}
+	if data.UserID != "" {
+		claims["sub"] = data.UserID
+	}
+	if j.issuer != "" {
+		claims["iss"] = j.issuer
+	}
+	if scope := data.TokenInfo.GetScope(); scope != "" {
+		claims["scope"] = scope
+	}
+	token := jwt.NewWithClaims(j.method, claims)
+	token.Header["kid"] = j.kid
+
+	access, err := token.SignedString(j.signingKey)
+	if err != nil {
+		return "", "", err
+	}
+	refresh := ""
+	if isGenRefresh {
+		refresh = base64.RawURLEncoding.EncodeToString([]byte(uuid.New().String()))
+	}
+	return access, refresh, nil
+}
+
+// Validate verifies signature, expiry and issuer of an access token, returns its details
+func (j *jwtAccessTokens) Validate(accessToken string) (oauth2.TokenInfo, error) {
+
+	parser := jwt.Parser{
+		ValidMethods: []string{
+			jwt.SigningMethodRS256.Alg(),
+			jwt.SigningMethodES256.Alg(),
+		},
+	}
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(accessToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if publicKey, ok := j.publicKeys[kid]; ok {
+			return publicKey, nil
+		}
+		return nil, errors.New("token signed with unknown key")
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.New("token is expired")
+	}
+	if j.issuer != "" && !claims.VerifyIssuer(j.issuer, true) {
+		return nil, errors.New("token has incorrect issuer")
+	}
+
+	clientID, _ := claims["client_id"].(string)
+	if clientID == "" {
+		return nil, errors.New("token has no client_id")
+	}
+	token := &models.Token{
+		ClientID: clientID,
+		Access:   accessToken,
+	}
+	if subject, _ := claims["sub"].(string); subject != clientID {
+		token.UserID = subject
+	}
+	token.Scope, _ = claims["scope"].(string)
+	if issuedAt, ok := claims["iat"].(float64); ok {
+		token.AccessCreateAt = time.Unix(int64(issuedAt), 0)
+	}
+	if expiresAt, ok := claims["exp"].(float64); ok {
+		token.AccessExpiresIn = time.Unix(int64(expiresAt), 0).Sub(token.AccessCreateAt)
+	}
+	return token, nil
+}
+
+// handleJWKS returns the public keys to verify JWT access tokens with
+func (oauth *Server) handleJWKS(c *gin.Context) {
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.IndentedJSON(http.StatusOK, oauth.jwt.keySet)
+}
+
+// isJWT returns true if token looks like a JWT
+func isJWT(token string) bool {
+
+	return strings.Count(token, ".") == 2
+}
+
+// loadPrivateKey reads PEM encoded RSA or EC P-256 private key
+func loadPrivateKey(filename string) (interface{}, error) {
+
+	block, err := readPEM(filename)
+	if err != nil {
+		return nil, err
+	}
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse private key '%s': %w", filename, err)
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve == elliptic.P256() {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("private key '%s' is not a RSA or EC P-256 key", filename)
+}
+
+// loadPublicKey reads PEM encoded RSA or EC P-256 public key
+func loadPublicKey(filename string) (interface{}, error) {
+
+	block, err := readPEM(filename)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse public key '%s': %w", filename, err)
+	}
+	return key, nil
+}
+
+// readPEM returns first PEM block of a file
+func readPEM(filename string) (*pem.Block, error) {
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in '%s'", filename)
+	}
+	return block, nil
+}
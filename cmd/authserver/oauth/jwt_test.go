@@ -0,0 +1,114 @@
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-oauth2/oauth2/v4"
+	"github.com/go-oauth2/oauth2/v4/models"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+
+	filename := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(filename,
+		pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+	return filename
+}
+
+func issueToken(t *testing.T, j *jwtAccessTokens, createdAt time.Time) string {
+
+	access, refresh, err := j.Token(context.Background(), &oauth2.GenerateBasic{
+		Client: &models.Client{ID: "apikey"},
+		TokenInfo: &models.Token{
+			Scope:           "read write",
+			AccessCreateAt:  createdAt,
+			AccessExpiresIn: time.Hour,
+		},
+	}, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, refresh)
+	return access
+}
+
+func Test_jwtAccessTokens(t *testing.T) {
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+
+	j, err := newJWTAccessTokens(JWTConfig{
+		SigningKeyFile: writePEM(t, "EC PRIVATE KEY", der),
+		Issuer:         "https://gatekeeper",
+	})
+	require.NoError(t, err)
+	require.Len(t, j.keySet.Keys, 1)
+	require.Equal(t, "ES256", j.keySet.Keys[0].Alg)
+
+	now := time.Now().Truncate(time.Second)
+	access := issueToken(t, j, now)
+	require.True(t, isJWT(access))
+
+	tokenInfo, err := j.Validate(access)
+	require.NoError(t, err)
+	require.Equal(t, "apikey", tokenInfo.GetClientID())
+	require.Equal(t, "", tokenInfo.GetUserID())
+	require.Equal(t, "read write", tokenInfo.GetScope())
+	require.Equal(t, now, tokenInfo.GetAccessCreateAt())
+	require.Equal(t, time.Hour, tokenInfo.GetAccessExpiresIn())
+
+	_, err = j.Validate(issueToken(t, j, now.Add(-2*time.Hour)))
+	require.Error(t, err, "expired")
+
+	_, err = j.Validate(access[:len(access)-4] + "AAAA")
+	require.Error(t, err, "bad signature")
+
+	// A token of another issuer signed with same key is not accepted
+	other := *j
+	other.issuer = "https://other"
+	_, err = j.Validate(issueToken(t, &other, now))
+	require.Error(t, err, "issuer")
+}
+
+func Test_jwtAccessTokens_KeyRotation(t *testing.T) {
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	previous, err := newJWTAccessTokens(JWTConfig{
+		SigningKeyFile: writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(oldKey)),
+	})
+	require.NoError(t, err)
+	oldToken := issueToken(t, previous, time.Now())
+
+	der, err := x509.MarshalPKIXPublicKey(&oldKey.PublicKey)
+	require.NoError(t, err)
+	newDer, err := x509.MarshalPKCS8PrivateKey(newKey)
+	require.NoError(t, err)
+	current, err := newJWTAccessTokens(JWTConfig{
+		SigningKeyFile: writePEM(t, "PRIVATE KEY", newDer),
+		PublicKeyFiles: []string{writePEM(t, "PUBLIC KEY", der)},
+	})
+	require.NoError(t, err)
+
+	// Both keys are published, tokens signed by previous key remain valid
+	require.Len(t, current.keySet.Keys, 2)
+	require.Equal(t, current.kid, current.keySet.Keys[0].Kid)
+	_, err = current.Validate(oldToken)
+	require.NoError(t, err)
+	_, err = current.Validate(issueToken(t, current, time.Now()))
+	require.NoError(t, err)
+}
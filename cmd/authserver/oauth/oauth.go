@@ -26,8 +26,20 @@ type Config struct {
 		certFile string // TLS certifcate file
 		keyFile  string // TLS certifcate key file
 	}
-	TokenIssuePath string // Path to request access tokens (e.g. "/oauth2/token")
-	TokenInfoPath  string // Path to request info about token (e.g. "/oauth2/info")
+	TokenIssuePath    string    // Path to request access tokens (e.g. "/oauth2/token")
+	TokenInfoPath     string    // Path to request info about token (e.g. "/oauth2/info")
+	AccessTokenFormat string    // Format of issued access tokens: "opaque" (default) or "jwt"
+	JWT               JWTConfig // JWT access token configuration
+	AuthorizePath     string    // Path to request authorization code (e.g. "/oauth2/authorize"), optional
+	RevokePath        string    // Path to revoke tokens (e.g. "/oauth2/revoke"), optional
+	IntrospectPath    string    // Path to introspect tokens (e.g. "/oauth2/introspect"), optional
+	LoginURL          string    // URL to redirect user to for login and consent
+	// Header with id of user authenticated by login proxy. Its value is trusted
+	// as is: the listener in front of authserver must strip or overwrite this
+	// header on every request so clients cannot set it themselves.
+	UserIDHeader string
+	// Consumer keys allowed to introspect tokens of all clients
+	IntrospectionClients []string
 }
 
 // Server is an oauth server instance
@@ -36,8 +48,11 @@ type Server struct {
 	router      *gin.Engine
 	db          *db.Database
 	oauthserver *server.Server
-	logger      *zap.Logger
-	metrics     *metrics.Metrics
+	jwt         *jwtAccessTokens
+	// userAuthorization authenticates user and obtains consent during authorization requests
+	userAuthorization UserAuthorizationHandler
+	logger            *zap.Logger
+	metrics           *metrics.Metrics
 }
 
 // New returns a new oauth server instance
@@ -59,23 +74,11 @@ func (oauth *Server) Start(applicationName string) error {
 	if oauth.config.Listen == "" {
 		return nil
 	}
-	if oauth.config.TokenIssuePath == "" {
-		return errors.New("oauth TokenIssuePath needs to be configured")
-	}
 
 	oauth.logger = shared.NewLogger("oauth", &oauth.config.Logger)
 
-	oauth.prepareOAuthInstance()
-
-	gin.SetMode(gin.ReleaseMode)
-	oauth.router = gin.New()
-	oauth.router.Use(webadmin.LogHTTPRequest(oauth.logger))
-	oauth.router.Use(webadmin.SetRequestID())
-
-	oauth.router.POST(oauth.config.TokenIssuePath, oauth.handleTokenIssueRequest)
-	// TokenInfo is an optional endpoint
-	if oauth.config.TokenInfoPath != "" {
-		oauth.router.GET(oauth.config.TokenInfoPath, oauth.handleTokenInfo)
+	if err := oauth.setup(); err != nil {
+		return err
 	}
 
 	oauth.logger.Info("OAuth2 listening on " + oauth.config.Listen)
@@ -96,6 +99,56 @@ func (oauth *Server) Start(applicationName string) error {
 	return err
 }
 
+// setup checks configuration and builds OAuth server instance and its router
+func (oauth *Server) setup() error {
+
+	if oauth.config.TokenIssuePath == "" {
+		return errors.New("oauth TokenIssuePath needs to be configured")
+	}
+
+	switch oauth.config.AccessTokenFormat {
+	case "", AccessTokenFormatOpaque:
+	case AccessTokenFormatJWT:
+		var err error
+		if oauth.jwt, err = newJWTAccessTokens(oauth.config.JWT); err != nil {
+			return err
+		}
+	default:
+		return errors.New("oauth AccessTokenFormat must be opaque or jwt")
+	}
+
+	oauth.prepareOAuthInstance()
+
+	gin.SetMode(gin.ReleaseMode)
+	oauth.router = gin.New()
+	oauth.router.Use(webadmin.LogHTTPRequest(oauth.logger))
+	oauth.router.Use(webadmin.SetRequestID())
+
+	oauth.router.POST(oauth.config.TokenIssuePath, oauth.handleTokenIssueRequest)
+	// TokenInfo is an optional endpoint
+	if oauth.config.TokenInfoPath != "" {
+		oauth.router.GET(oauth.config.TokenInfoPath, oauth.handleTokenInfo)
+	}
+	// Authorization code grant is optional
+	if oauth.config.AuthorizePath != "" {
+		oauth.router.GET(oauth.config.AuthorizePath, oauth.handleAuthorizeRequest)
+		oauth.router.POST(oauth.config.AuthorizePath, oauth.handleAuthorizeRequest)
+	}
+	// Token revocation is optional
+	if oauth.config.RevokePath != "" {
+		oauth.router.POST(oauth.config.RevokePath, oauth.handleRevokeRequest)
+	}
+	// Token introspection is optional
+	if oauth.config.IntrospectPath != "" {
+		oauth.router.POST(oauth.config.IntrospectPath, oauth.handleIntrospectRequest)
+	}
+	// Public keys of JWT access tokens
+	if oauth.jwt != nil {
+		oauth.router.GET(JWKSPath, oauth.handleJWKS)
+	}
+	return nil
+}
+
 // prepareOAuthInstance build OAuth server instance with client and token storage backends
 func (oauth *Server) prepareOAuthInstance() {
 
@@ -107,29 +160,72 @@ func (oauth *Server) prepareOAuthInstance() {
 	// Set client id engine for client ids
 	manager.MapClientStorage(NewClientTokenStore(oauth.db, oauth.metrics, oauth.logger))
 
+	// Issue signed JWT access tokens instead of random strings
+	if oauth.jwt != nil {
+		manager.MapAccessGenerate(oauth.jwt)
+	}
+
 	// Set default token ttl
 	manager.SetClientTokenCfg(&manage.Config{AccessTokenExp: 1 * time.Hour})
 
+	// Refresh tokens cannot outlive the token row in database
+	manager.SetAuthorizeCodeTokenCfg(&manage.Config{
+		AccessTokenExp:    1 * time.Hour,
+		RefreshTokenExp:   24 * time.Hour,
+		IsGenerateRefresh: true,
+	})
+
+	// Redirect uri must match callback url of developer app
+	manager.SetValidateURIHandler(validateRedirectURI)
+
 	config := &server.Config{
 		TokenType: "Bearer",
 		// We do not allow retrieving token using HTTP GET method
 		AllowGetAccessRequest: false,
 		AllowedResponseTypes: []oauth2.ResponseType{
-			oauth2.Token,
+			oauth2.Code,
 		},
 		AllowedGrantTypes: []oauth2.GrantType{
 			oauth2.ClientCredentials,
+			oauth2.AuthorizationCode,
+			oauth2.Refreshing,
+		},
+		// Authorization code requests must use PKCE (RFC 7636)
+		ForcePKCE: true,
+		AllowedCodeChallengeMethods: []oauth2.CodeChallengeMethod{
+			oauth2.CodeChallengeS256,
 		},
 	}
 	oauth.oauthserver = server.NewServer(config, manager)
 
 	// Setup extracting POSTed clientId/Secret
 	oauth.oauthserver.SetClientInfoHandler(server.ClientFormHandler)
+
+	// Granted scopes are limited to scopes of apiproducts of key
+	oauth.oauthserver.SetClientScopeHandler(oauth.clientScopeHandler)
+	oauth.oauthserver.SetRefreshingScopeHandler(oauth.refreshingScopeHandler)
+
+	// Setup login and consent of user during authorization requests
+	if oauth.userAuthorization == nil {
+		oauth.userAuthorization = oauth.userAuthorizationFromHeader
+	}
+	oauth.oauthserver.SetUserAuthorizationHandler(server.UserAuthorizationHandler(oauth.userAuthorization))
 }
 
 // LoadAccessToken returns the details of token
 func (oauth *Server) LoadAccessToken(ctx context.Context, accessToken string) (oauth2.TokenInfo, error) {
 
+	if oauth.jwt != nil && isJWT(accessToken) {
+		tokenInfo, err := oauth.jwt.Validate(accessToken)
+		if err != nil {
+			return nil, err
+		}
+		// A revoked JWT access token is no longer in database
+		if _, err := oauth.oauthserver.Manager.LoadAccessToken(ctx, accessToken); err != nil {
+			return nil, err
+		}
+		return tokenInfo, nil
+	}
 	return oauth.oauthserver.Manager.LoadAccessToken(ctx, accessToken)
 }
 
@@ -155,7 +251,12 @@ type tokenInfoAnswer struct {
 // handleTokenInfo shows information about temporary token (RFC7662)
 func (oauth *Server) handleTokenInfo(c *gin.Context) {
 
-	tokenInfo, err := oauth.oauthserver.ValidationBearerToken(c.Request)
+	accessToken, ok := oauth.oauthserver.BearerAuth(c.Request)
+	if !ok {
+		_ = c.AbortWithError(http.StatusUnauthorized, errors.New("no access token provided"))
+		return
+	}
+	tokenInfo, err := oauth.LoadAccessToken(c.Request.Context(), accessToken)
 	if err != nil {
 		_ = c.AbortWithError(http.StatusUnauthorized, err)
 		return
@@ -3,6 +3,7 @@ package oauth
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/go-oauth2/oauth2/v4"
 	"github.com/go-oauth2/oauth2/v4/models"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/erikbos/gatekeeper/cmd/authserver/metrics"
 	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
 // ClientTokenStore is our interface to our OAuth token database.
@@ -38,14 +40,50 @@ func (clientstore *ClientTokenStore) GetByID(ctx context.Context, id string) (oa
 	}
 	clientstore.logger.Debug("GetByID", zap.String("id", id))
 
+	// Keys of client certificates can only be used with the certificate itself
+	if types.IsClientCertificateConsumerKey(id) {
+		clientstore.metrics.IncOAuthClientStoreMisses()
+		return nil, fmt.Errorf("key '%s' can only be used with client certificate", id)
+	}
+
 	key, err := clientstore.db.Key.GetByKey(nil, &id)
 	if err != nil {
 		clientstore.metrics.IncOAuthClientStoreMisses()
 		return nil, err
 	}
-	clientstore.metrics.IncOAuthClientStoreHits()
-	return &models.Client{
+	// Revoked keys cannot obtain new tokens
+	if !key.IsApproved() {
+		clientstore.metrics.IncOAuthClientStoreMisses()
+		return nil, fmt.Errorf("key '%s' is not approved", id)
+	}
+	client := &models.Client{
 		ID:     key.ConsumerKey,
 		Secret: key.ConsumerSecret,
-	}, nil
+	}
+	if _, developerApp, err := findDeveloperApp(clientstore.db, key.AppID); err == nil {
+		if developerApp.IsRevoked() {
+			clientstore.metrics.IncOAuthClientStoreMisses()
+			return nil, fmt.Errorf("developer app of key '%s' is revoked", id)
+		}
+		// Callback url of developer app is the only allowed redirect uri of authorization requests
+		client.Domain = developerApp.CallbackURL
+	}
+	clientstore.metrics.IncOAuthClientStoreHits()
+	return client, nil
+}
+
+// findDeveloperApp retrieves developer app by id, as a key does not have
+// an organization we look it up in all organizations
+func findDeveloperApp(database *db.Database, appID string) (string, *types.DeveloperApp, error) {
+
+	organizations, err := database.Organization.GetAll()
+	if err != nil {
+		return "", nil, err
+	}
+	for _, organization := range organizations {
+		if developerApp, err := database.DeveloperApp.GetByID(organization.Name, appID); err == nil {
+			return organization.Name, developerApp, nil
+		}
+	}
+	return "", nil, fmt.Errorf("cannot find developer app id '%s'", appID)
 }
@@ -0,0 +1,36 @@
+package oauth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func Test_ClientTokenStore_GetByID(t *testing.T) {
+
+	oauth := newTestServer(t)
+	clientStore := NewClientTokenStore(oauth.db, oauth.metrics, zap.NewNop())
+
+	client, err := clientStore.GetByID(context.Background(), "client")
+	require.NoError(t, err)
+	require.Equal(t, "secret", client.GetSecret())
+	require.Equal(t, testCallbackURL, client.GetDomain())
+
+	_, err = clientStore.GetByID(context.Background(), "unknown")
+	require.Error(t, err)
+
+	// Key of client certificate cannot obtain tokens
+	consumerKey := types.KeyClientCertificateNamePrefix + "partner.example.com"
+	require.Nil(t, oauth.db.Key.UpdateByKey("default", &types.Key{
+		ConsumerKey:    consumerKey,
+		ConsumerSecret: "secret",
+		AppID:          "app1",
+		Status:         "approved",
+	}))
+	_, err = clientStore.GetByID(context.Background(), consumerKey)
+	require.Error(t, err)
+}
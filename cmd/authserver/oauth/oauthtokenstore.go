@@ -3,6 +3,7 @@ package oauth
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/go-oauth2/oauth2/v4"
@@ -39,19 +40,24 @@ func (tokenstore *TokenStore) Create(ctx context.Context, info oauth2.TokenInfo)
 	tokenstore.logger.Debug("Create", zap.String("token", info.GetAccess()))
 	token := types.OAuthAccessToken{
 		// FIXME do we need all fields
-		ClientID:         info.GetClientID(),
-		UserID:           info.GetUserID(),
-		RedirectURI:      info.GetRedirectURI(),
-		Scope:            info.GetScope(),
-		Code:             info.GetCode(),
-		CodeCreatedAt:    shared.TimeMillisecondsToInt64(info.GetCodeCreateAt()),
-		CodeExpiresIn:    int64(info.GetCodeExpiresIn().Milliseconds()),
-		Access:           info.GetAccess(),
-		AccessCreatedAt:  shared.TimeMillisecondsToInt64(info.GetAccessCreateAt()),
-		AccessExpiresIn:  int64(info.GetAccessExpiresIn().Milliseconds()),
-		Refresh:          info.GetRefresh(),
-		RefreshCreatedAt: shared.TimeMillisecondsToInt64(info.GetRefreshCreateAt()),
-		RefreshExpiresIn: int64(info.GetRefreshExpiresIn().Milliseconds()),
+		ClientID:            info.GetClientID(),
+		UserID:              info.GetUserID(),
+		RedirectURI:         info.GetRedirectURI(),
+		Scope:               info.GetScope(),
+		Code:                info.GetCode(),
+		CodeCreatedAt:       shared.TimeMillisecondsToInt64(info.GetCodeCreateAt()),
+		CodeExpiresIn:       int64(info.GetCodeExpiresIn().Milliseconds()),
+		Access:              info.GetAccess(),
+		AccessCreatedAt:     shared.TimeMillisecondsToInt64(info.GetAccessCreateAt()),
+		AccessExpiresIn:     int64(info.GetAccessExpiresIn().Milliseconds()),
+		Refresh:             info.GetRefresh(),
+		RefreshCreatedAt:    shared.TimeMillisecondsToInt64(info.GetRefreshCreateAt()),
+		RefreshExpiresIn:    int64(info.GetRefreshExpiresIn().Milliseconds()),
+		CodeChallenge:       info.GetCodeChallenge(),
+		CodeChallengeMethod: info.GetCodeChallengeMethod().String(),
+	}
+	if token.Access == "" && token.Code != "" {
+		token.Access = types.OAuthAuthorizationCodePrefix + token.Code
 	}
 	if err := tokenstore.db.OAuth.OAuthAccessTokenCreate(&token); err != nil {
 		tokenstore.metrics.IncOAuthTokenStoreIssueFailures()
@@ -67,7 +73,7 @@ func (tokenstore *TokenStore) GetByAccess(ctx context.Context, access string) (o
 	const method string = "access"
 
 	tokenstore.logger.Debug("GetByAccess", zap.String(method, access))
-	if access == "" {
+	if access == "" || strings.HasPrefix(access, types.OAuthAuthorizationCodePrefix) {
 		tokenstore.metrics.IncOAuthTokenStoreLookupMisses(method)
 		return nil, errors.New("empty token provided")
 	}
@@ -120,21 +126,27 @@ func (tokenstore *TokenStore) GetByRefresh(ctx context.Context, refresh string)
 
 func toOAuthTokenStore(token *types.OAuthAccessToken) (oauth2.TokenInfo, error) {
 
+	// Authorization code does not have an access token yet
+	if token.IsAuthorizationCode() {
+		token.Access = ""
+	}
 	return &models.Token{
 		// FIXME do we need all fields
-		ClientID:         token.ClientID,
-		UserID:           token.UserID,
-		RedirectURI:      token.RedirectURI,
-		Scope:            token.Scope,
-		Code:             token.Code,
-		CodeCreateAt:     time.Unix(0, token.CodeCreatedAt*int64(time.Millisecond)),
-		CodeExpiresIn:    time.Duration(token.CodeExpiresIn) * time.Millisecond,
-		Access:           token.Access,
-		AccessCreateAt:   time.Unix(0, token.AccessCreatedAt*int64(time.Millisecond)),
-		AccessExpiresIn:  time.Duration(token.AccessExpiresIn) * time.Millisecond,
-		Refresh:          token.Refresh,
-		RefreshCreateAt:  time.Unix(0, token.RefreshCreatedAt*int64(time.Millisecond)),
-		RefreshExpiresIn: time.Duration(token.RefreshExpiresIn) * time.Millisecond,
+		ClientID:            token.ClientID,
+		UserID:              token.UserID,
+		RedirectURI:         token.RedirectURI,
+		Scope:               token.Scope,
+		Code:                token.Code,
+		CodeCreateAt:        time.Unix(0, token.CodeCreatedAt*int64(time.Millisecond)),
+		CodeExpiresIn:       time.Duration(token.CodeExpiresIn) * time.Millisecond,
+		Access:              token.Access,
+		AccessCreateAt:      time.Unix(0, token.AccessCreatedAt*int64(time.Millisecond)),
+		AccessExpiresIn:     time.Duration(token.AccessExpiresIn) * time.Millisecond,
+		Refresh:             token.Refresh,
+		RefreshCreateAt:     time.Unix(0, token.RefreshCreatedAt*int64(time.Millisecond)),
+		RefreshExpiresIn:    time.Duration(token.RefreshExpiresIn) * time.Millisecond,
+		CodeChallenge:       token.CodeChallenge,
+		CodeChallengeMethod: token.CodeChallengeMethod,
 	}, nil
 }
 
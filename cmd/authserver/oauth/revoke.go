@@ -0,0 +1,127 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-oauth2/oauth2/v4"
+	oautherrors "github.com/go-oauth2/oauth2/v4/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// Token type hints of a revocation or introspection request (RFC 7009, section 2.1)
+const (
+	tokenTypeHintAccessToken  = "access_token"
+	tokenTypeHintRefreshToken = "refresh_token"
+)
+
+// handleRevokeRequest handles a POST request to revoke an access or refresh token (RFC 7009)
+func (oauth *Server) handleRevokeRequest(c *gin.Context) {
+
+	clientID, ok := oauth.authenticateClient(c.Request)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": oautherrors.ErrInvalidClient.Error()})
+		return
+	}
+	token := c.Request.PostFormValue("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": oautherrors.ErrInvalidRequest.Error()})
+		return
+	}
+
+	tokenInfo, _ := oauth.lookupToken(c.Request.Context(), token, c.Request.PostFormValue("token_type_hint"))
+	// An invalid or unknown token does not result in an error
+	if tokenInfo == nil {
+		c.Status(http.StatusOK)
+		return
+	}
+	// Clients can only revoke their own tokens
+	if tokenInfo.GetClientID() != clientID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": oautherrors.ErrUnauthorizedClient.Error()})
+		return
+	}
+
+	// Access token is primary key of a stored token, removing it
+	// revokes both access and refresh token
+	access := tokenInfo.GetAccess()
+	if access == "" {
+		access = token
+	}
+	if err := oauth.oauthserver.Manager.RemoveAccessToken(c.Request.Context(), access); err != nil {
+		oauth.logger.Warn("Cannot revoke token", zap.String("clientid", clientID), zap.Error(err))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": oautherrors.ErrTemporarilyUnavailable.Error()})
+		return
+	}
+	oauth.recordRevocation(clientID)
+	c.Status(http.StatusOK)
+}
+
+// recordRevocation writes revocation of a token of client to changelog,
+// so other instances remove the token from their cache
+func (oauth *Server) recordRevocation(clientID string) {
+
+	if oauth.db.ChangeLog == nil {
+		return
+	}
+	change := &types.Change{
+		ID:         uuid.New().String(),
+		Timestamp:  shared.GetCurrentTimeMilliseconds(),
+		Operation:  "delete",
+		EntityType: types.TypeOAuthName,
+		EntityID:   clientID,
+	}
+	if err := oauth.db.ChangeLog.Write(change); err != nil {
+		oauth.logger.Error("Cannot write changelog", zap.String("clientid", clientID), zap.Error(err))
+	}
+}
+
+// lookupToken retrieves a valid access or refresh token from database, the hint
+// determines which type of token to look for first. JWT access tokens are looked
+// up as well, so a revoked JWT access token is not found.
+func (oauth *Server) lookupToken(ctx context.Context, token, hint string) (oauth2.TokenInfo, string) {
+
+	lookups := []struct {
+		tokenType string
+		load      func(context.Context, string) (oauth2.TokenInfo, error)
+	}{
+		{tokenTypeHintAccessToken, oauth.oauthserver.Manager.LoadAccessToken},
+		{tokenTypeHintRefreshToken, oauth.oauthserver.Manager.LoadRefreshToken},
+	}
+	if hint == tokenTypeHintRefreshToken {
+		lookups[0], lookups[1] = lookups[1], lookups[0]
+	}
+	for _, lookup := range lookups {
+		if tokenInfo, err := lookup.load(ctx, token); err == nil && tokenInfo != nil {
+			return tokenInfo, lookup.tokenType
+		}
+	}
+	return nil, ""
+}
+
+// authenticateClient validates the client credentials of a request, provided
+// using HTTP basic authentication or as form parameters
+func (oauth *Server) authenticateClient(r *http.Request) (string, bool) {
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostFormValue("client_id")
+		clientSecret = r.PostFormValue("client_secret")
+	}
+	if clientID == "" {
+		return "", false
+	}
+	client, err := oauth.oauthserver.Manager.GetClient(r.Context(), clientID)
+	if err != nil {
+		return "", false
+	}
+	if subtle.ConstantTimeCompare([]byte(client.GetSecret()), []byte(clientSecret)) != 1 {
+		return "", false
+	}
+	return clientID, true
+}
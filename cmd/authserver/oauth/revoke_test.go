@@ -0,0 +1,101 @@
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func Test_RevokeToken(t *testing.T) {
+
+	oauth := newTestServer(t)
+	require.Nil(t, oauth.db.Key.UpdateByKey("default", &types.Key{
+		ConsumerKey:    "other",
+		ConsumerSecret: "othersecret",
+		AppID:          "app1",
+		Status:         "approved",
+	}))
+
+	response, token := requestToken(oauth, url.Values{"grant_type": {"client_credentials"}})
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	accessToken := token["access_token"].(string)
+
+	revoke := func(clientID, clientSecret string, form url.Values) int {
+		form.Set("client_id", clientID)
+		form.Set("client_secret", clientSecret)
+		return postForm(oauth, "/oauth2/revoke", form).Code
+	}
+
+	require.Equal(t, http.StatusUnauthorized,
+		revoke("client", "wrong", url.Values{"token": {accessToken}}))
+	require.Equal(t, http.StatusBadRequest,
+		revoke("client", "secret", url.Values{}))
+	require.Equal(t, http.StatusBadRequest,
+		revoke("other", "othersecret", url.Values{"token": {accessToken}}), "token of other client")
+
+	// Incorrect hint falls back to lookup as access token
+	require.Equal(t, http.StatusOK,
+		revoke("client", "secret", url.Values{"token": {accessToken}, "token_type_hint": {"refresh_token"}}))
+	_, err := oauth.LoadAccessToken(context.Background(), accessToken)
+	require.Error(t, err)
+
+	// Revocation is written to changelog so other instances drop token from cache
+	changes, err := oauth.db.ChangeLog.GetSince(0)
+	require.Nil(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, types.TypeOAuthName, changes[0].EntityType)
+	require.Equal(t, "client", changes[0].EntityID)
+
+	// Revoking unknown or already revoked token succeeds
+	require.Equal(t, http.StatusOK,
+		revoke("client", "secret", url.Values{"token": {accessToken}}))
+
+	// Revoked key cannot obtain new tokens
+	require.Nil(t, oauth.db.Key.UpdateByKey("default", &types.Key{
+		ConsumerKey:    "client",
+		ConsumerSecret: "secret",
+		AppID:          "app1",
+		Status:         "revoked",
+	}))
+	response, _ = requestToken(oauth, url.Values{"grant_type": {"client_credentials"}})
+	require.NotEqual(t, http.StatusOK, response.Code)
+}
+
+func Test_RevokeJWTToken(t *testing.T) {
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+
+	oauth := newTestServer(t)
+	oauth.config.AccessTokenFormat = AccessTokenFormatJWT
+	oauth.config.JWT = JWTConfig{SigningKeyFile: writePEM(t, "EC PRIVATE KEY", der)}
+	require.NoError(t, oauth.setup())
+
+	response, token := requestToken(oauth, url.Values{"grant_type": {"client_credentials"}})
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	accessToken := token["access_token"].(string)
+	require.True(t, isJWT(accessToken))
+
+	tokenInfo, err := oauth.LoadAccessToken(context.Background(), accessToken)
+	require.NoError(t, err)
+	require.Equal(t, "client", tokenInfo.GetClientID())
+
+	// Revoked token is rejected even though its signature is still valid
+	require.Equal(t, http.StatusOK, postForm(oauth, "/oauth2/revoke", url.Values{
+		"client_id": {"client"}, "client_secret": {"secret"}, "token": {accessToken}}).Code)
+	_, err = oauth.jwt.Validate(accessToken)
+	require.NoError(t, err)
+	_, err = oauth.LoadAccessToken(context.Background(), accessToken)
+	require.Error(t, err)
+}
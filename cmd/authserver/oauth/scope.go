@@ -0,0 +1,92 @@
+package oauth
+
+import (
+	"strings"
+
+	"github.com/go-oauth2/oauth2/v4"
+	"go.uber.org/zap"
+)
+
+// clientScopeHandler restricts the scope of a token request to the scopes of the
+// approved apiproducts of the key. In case no scope is requested all of them are granted.
+func (oauth *Server) clientScopeHandler(tgr *oauth2.TokenGenerateRequest) (bool, error) {
+
+	allowedScopes, err := oauth.apiProductScopes(tgr.ClientID)
+	if err != nil {
+		return false, err
+	}
+	scope, allowed := intersectScopes(tgr.Scope, allowedScopes)
+	if !allowed {
+		oauth.logger.Debug("Requested scope not allowed",
+			zap.String("clientid", tgr.ClientID), zap.String("scope", tgr.Scope))
+		return false, nil
+	}
+	tgr.Scope = scope
+	return true, nil
+}
+
+// refreshingScopeHandler only allows a refresh request to narrow down the scope
+// of the original token (RFC 6749, section 6)
+func (oauth *Server) refreshingScopeHandler(tgr *oauth2.TokenGenerateRequest, oldScope string) (bool, error) {
+
+	_, allowed := intersectScopes(tgr.Scope, strings.Fields(oldScope))
+	return allowed, nil
+}
+
+// apiProductScopes returns all scopes of the approved apiproducts of a key
+func (oauth *Server) apiProductScopes(clientID string) ([]string, error) {
+
+	key, err := oauth.db.Key.GetByKey(nil, &clientID)
+	if err != nil {
+		return nil, err
+	}
+	organizationName, _, appErr := findDeveloperApp(oauth.db, key.AppID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	var scopes []string
+	for _, apiproduct := range key.APIProducts {
+		if !apiproduct.IsApproved() {
+			continue
+		}
+		apiproductDetails, err := oauth.db.APIProduct.Get(organizationName, apiproduct.Apiproduct)
+		if err != nil {
+			// Key has an apiproduct we cannot find, it does not grant any scopes
+			continue
+		}
+		for _, scope := range apiproductDetails.Scopes {
+			if !containsScope(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes, nil
+}
+
+// intersectScopes returns the requested scopes that are allowed, or all allowed scopes in
+// case none were requested. It returns false when scopes were requested but none is allowed.
+func intersectScopes(requestedScope string, allowedScopes []string) (string, bool) {
+
+	requested := strings.Fields(requestedScope)
+	if len(requested) == 0 {
+		return strings.Join(allowedScopes, " "), true
+	}
+	var granted []string
+	for _, scope := range requested {
+		if containsScope(allowedScopes, scope) && !containsScope(granted, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	return strings.Join(granted, " "), len(granted) != 0
+}
+
+// containsScope returns true in case scope is part of scopes
+func containsScope(scopes []string, scope string) bool {
+
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
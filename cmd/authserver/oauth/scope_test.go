@@ -0,0 +1,55 @@
+package oauth
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_intersectScopes(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		requested string
+		allowed   []string
+		granted   string
+		ok        bool
+	}{
+		{"no scope requested", "", []string{"read", "write"}, "read write", true},
+		{"subset", "write", []string{"read", "write"}, "write", true},
+		{"partially allowed", "read admin read", []string{"read", "write"}, "read", true},
+		{"none allowed", "admin", []string{"read", "write"}, "", false},
+		{"no allowed scopes", "read", nil, "", false},
+		{"nothing requested nor allowed", "", nil, "", true},
+	}
+	for _, test := range tests {
+		granted, ok := intersectScopes(test.requested, test.allowed)
+		require.Equal(t, test.granted, granted, test.name)
+		require.Equal(t, test.ok, ok, test.name)
+	}
+}
+
+func Test_TokenScope(t *testing.T) {
+
+	oauth := newTestServer(t)
+
+	// Only scopes of approved apiproducts of key are granted
+	response, token := requestToken(oauth, url.Values{"grant_type": {"client_credentials"}})
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.Equal(t, "read write", token["scope"])
+
+	response, token = requestToken(oauth, url.Values{
+		"grant_type": {"client_credentials"},
+		"scope":      {"write pay"},
+	})
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.Equal(t, "write", token["scope"])
+
+	response, _ = requestToken(oauth, url.Values{
+		"grant_type": {"client_credentials"},
+		"scope":      {"pay"},
+	})
+	require.NotEqual(t, http.StatusOK, response.Code)
+}
@@ -2,6 +2,7 @@ package policy
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"go.uber.org/zap"
@@ -22,7 +23,8 @@ func (p *Policy) CheckProductEntitlement(request *request.Request) error {
 		return err
 	}
 	var err error
-	request.APIProduct, err = p.IsPathAllowed(request.Organization.Name, request.URL.Path, request.Key)
+	request.APIProduct, err = p.IsPathAllowed(request.Organization.Name,
+		request.HTTPRequest.Method, request.URL.Path, request.Key)
 	return err
 }
 
@@ -77,16 +79,43 @@ func (p *Policy) checkDevAndKeyValidity(request *request.Request) error {
 	return nil
 }
 
+// checkScopes checks whether the token of a request has all scopes required by
+// the matched apiproduct
+func (p *Policy) checkScopes(request *request.Request) error {
+
+	if request == nil || request.APIProduct == nil {
+		return errors.New("no request details available")
+	}
+	for _, requiredScope := range request.APIProduct.Scopes {
+		if !hasScope(request.Scopes, requiredScope) {
+			return fmt.Errorf("insufficient scope, token lacks scope '%s'", requiredScope)
+		}
+	}
+	return nil
+}
+
+// hasScope returns true in case scope is one of scopes
+func hasScope(scopes []string, scope string) bool {
+
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // IsPathAllowed checks whether paths is allowed by apikey,
-// this means the apikey needs to contain a product that matchs the request path
+// this means the apikey needs to contain a product that matchs the request method and path
 func (p *Policy) IsPathAllowed(
-	organizationName, requestPath string, key *types.Key) (*types.APIProduct, error) {
+	organizationName, requestMethod, requestPath string, key *types.Key) (*types.APIProduct, error) {
 
 	// Does this apikey have any products assigned?
 	if len(key.APIProducts) == 0 {
 		return nil, errors.New("no active products for apikey")
 	}
 
+	methodNotAllowed := false
 	// Iterate over this key's apiproducts
 	for _, apiproduct := range key.APIProducts {
 		if apiproduct.IsApproved() {
@@ -96,17 +125,28 @@ func (p *Policy) IsPathAllowed(
 				// FIXME increase "unknown product in apikey" counter (not an error state)
 			} else {
 				// Iterate over all paths of apiproduct and try to match with path of request
-				for _, productPath := range apiproductDetails.APIResources {
+				for _, productResource := range apiproductDetails.APIResources {
 					p.config.logger.Debug("IsRequestPathAllowed",
-						zap.String("productpath", productPath),
+						zap.String("productpath", productResource),
+						zap.String("requestmethod", requestMethod),
 						zap.String("requestpath", requestPath))
 
-					if ok, _ := doublestar.Match(productPath, requestPath); ok {
+					resource, err := types.ParseAPIResource(productResource)
+					if err != nil {
+						continue
+					}
+					if resource.IsRequestAllowed(requestMethod, requestPath) {
 						return apiproductDetails, nil
 					}
+					if ok, _ := doublestar.Match(resource.Path, requestPath); ok {
+						methodNotAllowed = true
+					}
 				}
 			}
 		}
 	}
+	if methodNotAllowed {
+		return nil, errors.New("not authorized for requested method")
+	}
 	return nil, errors.New("not authorized for requested path")
 }
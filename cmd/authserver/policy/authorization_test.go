@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/metrics"
+	"github.com/erikbos/gatekeeper/cmd/authserver/request"
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/db/memory"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func newTestPolicy(t *testing.T) *Policy {
+
+	database, err := memory.New(t.Name(), zap.NewNop())
+	require.NoError(t, err)
+	require.Nil(t, database.APIProduct.Update("default", &types.APIProduct{
+		Name:         "orders",
+		APIResources: []string{"/orders/**"},
+	}, db.Precondition{}))
+	require.Nil(t, database.Developer.Update("default", &types.Developer{
+		DeveloperID: "dev1",
+		Email:       "joe@example.com",
+		Status:      "active",
+	}, db.Precondition{}))
+	require.Nil(t, database.DeveloperApp.Update("default", &types.DeveloperApp{
+		AppID:       "app1",
+		DeveloperID: "dev1",
+		Name:        "app",
+	}, db.Precondition{}))
+	require.Nil(t, database.Key.UpdateByKey("default", &types.Key{
+		ConsumerKey:    "partner",
+		ConsumerSecret: "secret",
+		AppID:          "app1",
+		Status:         "approved",
+		APIProducts: types.KeyAPIProductStatuses{
+			{Apiproduct: "orders", Status: "approved"},
+		},
+	}))
+
+	m := metrics.New(t.Name())
+	m.RegisterWithPrometheus()
+
+	hmacValidator := NewHMACValidator(HMAC{ClockSkew: time.Minute})
+	return NewPolicy(NewChainConfig(database, nil, nil, nil, hmacValidator, NewExpressionCache(), m, zap.NewNop()))
+}
+
+func Test_IsPathAllowed(t *testing.T) {
+
+	database, err := memory.New(t.Name(), zap.NewNop())
+	require.NoError(t, err)
+	require.Nil(t, database.APIProduct.Update("default", &types.APIProduct{
+		Name:         "orders_read",
+		APIResources: []string{"GET,HEAD /orders/**"},
+	}, db.Precondition{}))
+	require.Nil(t, database.APIProduct.Update("default", &types.APIProduct{
+		Name:         "customers",
+		APIResources: []string{"/customers/**"},
+	}, db.Precondition{}))
+
+	p := NewPolicy(NewChainConfig(database, nil, nil, nil, nil, nil, nil, zap.NewNop()))
+	key := &types.Key{
+		APIProducts: types.KeyAPIProductStatuses{
+			{Apiproduct: "orders_read", Status: "approved"},
+			{Apiproduct: "customers", Status: "approved"},
+		},
+	}
+
+	product, err := p.IsPathAllowed("default", "GET", "/orders/42", key)
+	require.NoError(t, err)
+	require.Equal(t, "orders_read", product.Name)
+
+	_, err = p.IsPathAllowed("default", "DELETE", "/orders/42", key)
+	require.EqualError(t, err, "not authorized for requested method")
+
+	product, err = p.IsPathAllowed("default", "DELETE", "/customers/42", key)
+	require.NoError(t, err)
+	require.Equal(t, "customers", product.Name)
+
+	_, err = p.IsPathAllowed("default", "GET", "/payments", key)
+	require.EqualError(t, err, "not authorized for requested path")
+}
+
+func Test_checkScopes(t *testing.T) {
+
+	p := NewPolicy(nil)
+	r := &request.Request{
+		APIProduct: &types.APIProduct{Name: "orders", Scopes: []string{"read", "write"}},
+		Scopes:     []string{"read", "write", "admin"},
+	}
+	require.NoError(t, p.checkScopes(r))
+
+	r.Scopes = []string{"read"}
+	require.EqualError(t, p.checkScopes(r), "insufficient scope, token lacks scope 'write'")
+
+	// Apiproduct without scopes does not require any
+	r.APIProduct.Scopes = nil
+	r.Scopes = nil
+	require.NoError(t, p.checkScopes(r))
+}
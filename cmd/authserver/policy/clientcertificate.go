@@ -0,0 +1,98 @@
+package policy
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/request"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// checkClientCertificate maps client certificate of a mutual TLS connection to a key,
+// loads dev app, dev details, and check whether path is allowed
+func (p *Policy) checkClientCertificate(request *request.Request) *Response {
+
+	if request.ClientCertificate == nil {
+		// Not a problem: apparently this request was not meant to be authenticated using a certificate
+		return nil
+	}
+
+	consumerKey, err := p.lookupClientCertificateKey(request)
+	if err != nil {
+		p.config.logger.Debug("Client certificate not allowed",
+			zap.String("subject", request.ClientCertificate.Subject.String()), zap.Error(err))
+
+		p.config.metrics.IncUnknownAPIKey(request)
+
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusForbidden,
+			DeniedMessage:    fmt.Sprint(err),
+		}
+	}
+	request.ConsumerKey = &consumerKey
+
+	err = p.CheckProductEntitlement(request)
+	if err != nil {
+		p.config.logger.Debug("CheckProductEntitlement() not allowed",
+			zap.String("path", request.URL.Path), zap.String("reason", err.Error()))
+
+		p.config.metrics.IncUnknownAPIKey(request)
+
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusForbidden,
+			DeniedMessage:    fmt.Sprint(err),
+		}
+	}
+
+	metadata := buildMetadata(request)
+	metadata[metadataAuthMethod] = metadataAuthMethodValueCert
+
+	// Signal that we have authenticated this request
+	return &Response{
+		Authenticated: true,
+		Metadata:      metadata,
+	}
+}
+
+// lookupClientCertificateKey returns consumer key of key matching client certificate
+func (p *Policy) lookupClientCertificateKey(request *request.Request) (string, error) {
+
+	for _, consumerKey := range clientCertificateConsumerKeys(request.ClientCertificate) {
+		consumerKey := consumerKey
+		if _, err := p.config.db.Key.GetByKey(&request.Organization.Name, &consumerKey); err == nil {
+			return consumerKey, nil
+		}
+	}
+	return "", errors.New("no key for client certificate")
+}
+
+// clientCertificateConsumerKeys returns consumer keys which can identify a certificate,
+// in order of preference: fingerprint, subject alternative names and subject
+func clientCertificateConsumerKeys(certificate *x509.Certificate) []string {
+
+	fingerprint := sha256.Sum256(certificate.Raw)
+	consumerKeys := []string{
+		types.KeyClientCertificateFingerprintPrefix + hex.EncodeToString(fingerprint[:]),
+	}
+	for _, uri := range certificate.URIs {
+		consumerKeys = append(consumerKeys, types.KeyClientCertificateNamePrefix+uri.String())
+	}
+	for _, name := range certificate.DNSNames {
+		consumerKeys = append(consumerKeys, types.KeyClientCertificateNamePrefix+name)
+	}
+	for _, email := range certificate.EmailAddresses {
+		consumerKeys = append(consumerKeys, types.KeyClientCertificateNamePrefix+email)
+	}
+	if subject := certificate.Subject.String(); subject != "" {
+		consumerKeys = append(consumerKeys, types.KeyClientCertificateNamePrefix+subject)
+	}
+	return consumerKeys
+}
@@ -0,0 +1,110 @@
+package policy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	envoy_service_auth_v3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/request"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func newTestCertificate(t *testing.T, commonName string, uris ...string) *x509.Certificate {
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, uri := range uris {
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+		template.URIs = append(template.URIs, u)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return certificate
+}
+
+func newClientCertificateRequest(certificate *x509.Certificate) *request.Request {
+
+	return &request.Request{
+		HTTPRequest:       &envoy_service_auth_v3.AttributeContext_HttpRequest{Method: http.MethodGet},
+		URL:               &url.URL{Path: "/orders/42"},
+		Organization:      &types.Organization{Name: "default"},
+		ClientCertificate: certificate,
+	}
+}
+
+func Test_checkClientCertificate(t *testing.T) {
+
+	p := newTestPolicy(t)
+
+	byFingerprint := newTestCertificate(t, "partner-a")
+	fingerprint := sha256.Sum256(byFingerprint.Raw)
+	fingerprintKey := types.KeyClientCertificateFingerprintPrefix + hex.EncodeToString(fingerprint[:])
+	bySAN := newTestCertificate(t, "partner-b", "spiffe://example.com/partner-b")
+	sanKey := types.KeyClientCertificateNamePrefix + "spiffe://example.com/partner-b"
+	for _, consumerKey := range []string{fingerprintKey, sanKey} {
+		require.Nil(t, p.config.db.Key.UpdateByKey("default", &types.Key{
+			ConsumerKey: consumerKey,
+			AppID:       "app1",
+			Status:      "approved",
+			APIProducts: types.KeyAPIProductStatuses{
+				{Apiproduct: "orders", Status: "approved"},
+			},
+		}))
+	}
+
+	response := p.checkClientCertificate(newClientCertificateRequest(byFingerprint))
+	require.NotNil(t, response)
+	require.True(t, response.Authenticated, response.DeniedMessage)
+	require.Equal(t, metadataAuthMethodValueCert, response.Metadata[metadataAuthMethod])
+	require.Equal(t, fingerprintKey, response.Metadata[metadataAuthAPIKey])
+
+	response = p.checkClientCertificate(newClientCertificateRequest(bySAN))
+	require.True(t, response.Authenticated, response.DeniedMessage)
+	require.Equal(t, sanKey, response.Metadata[metadataAuthAPIKey])
+
+	// Certificate without matching key
+	response = p.checkClientCertificate(newClientCertificateRequest(newTestCertificate(t, "unknown")))
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusForbidden, response.DeniedStatusCode)
+
+	// Path not covered by apiproducts of key
+	r := newClientCertificateRequest(byFingerprint)
+	r.URL.Path = "/payments"
+	response = p.checkClientCertificate(r)
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusForbidden, response.DeniedStatusCode)
+
+	// Request without client certificate is not for this policy
+	require.Nil(t, p.checkClientCertificate(newClientCertificateRequest(nil)))
+
+	// Certificate consumer key cannot be presented as apikey
+	r = newClientCertificateRequest(nil)
+	r.URL.RawQuery = "apikey=" + url.QueryEscape(fingerprintKey)
+	r.QueryParameters = r.URL.Query()
+	response = p.checkAPIKey(r)
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusBadRequest, response.DeniedStatusCode)
+}
@@ -0,0 +1,239 @@
+package policy
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/request"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// ExpressionCache holds compiled policy expressions of each listener and apiproduct
+type ExpressionCache struct {
+	mutex   sync.RWMutex
+	entries map[string]*expressionCacheEntry
+}
+
+// expressionCacheEntry holds compiled expressions of one listener or apiproduct
+type expressionCacheEntry struct {
+	// Last modified timestamp of listener or apiproduct at time of compilation
+	lastModifiedAt int64
+	programs       map[string]cel.Program
+}
+
+// Actions of checkExpression policy
+const (
+	expressionActionDeny  = "deny"
+	expressionActionAllow = "allow"
+)
+
+// NewExpressionCache returns a cache for compiled policy expressions
+func NewExpressionCache() *ExpressionCache {
+
+	return &ExpressionCache{
+		entries: make(map[string]*expressionCacheEntry),
+	}
+}
+
+// Get returns compiled expression of a listener or apiproduct, compiled expressions
+// are discarded as soon as last modified timestamp of listener or apiproduct changes
+func (c *ExpressionCache) Get(owner string, lastModifiedAt int64, expression string) (cel.Program, error) {
+
+	if program, found := c.lookup(owner, lastModifiedAt, expression); found {
+		return program, nil
+	}
+	// Compile without holding lock so other expressions can be evaluated meanwhile
+	program, err := types.CompilePolicyExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, found := c.entries[owner]
+	if !found || entry.lastModifiedAt != lastModifiedAt {
+		entry = &expressionCacheEntry{
+			lastModifiedAt: lastModifiedAt,
+			programs:       make(map[string]cel.Program),
+		}
+		c.entries[owner] = entry
+	}
+	entry.programs[expression] = program
+	return program, nil
+}
+
+// lookup returns compiled expression in case it has been compiled for
+// current version of listener or apiproduct
+func (c *ExpressionCache) lookup(owner string, lastModifiedAt int64, expression string) (cel.Program, bool) {
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, found := c.entries[owner]
+	if !found || entry.lastModifiedAt != lastModifiedAt {
+		return nil, false
+	}
+	program, found := entry.programs[expression]
+	return program, found
+}
+
+// checkExpression rejects requests based upon outcome of a CEL expression
+func (p *Policy) checkExpression(request *request.Request) *Response {
+
+	expression := p.Parameters.GetAsString("expression", "")
+	action := p.Parameters.GetAsString("action", expressionActionDeny)
+	if expression == "" {
+		return nil
+	}
+
+	matched, err := p.evaluateExpression(request, expression)
+	if err != nil {
+		p.config.logger.Warn("Cannot evaluate policy expression",
+			zap.String("expression", expression), zap.Error(err))
+		return p.expressionDenied()
+	}
+	// allow only lets requests matching expression pass, deny rejects them
+	if (action == expressionActionAllow && !matched) ||
+		(action != expressionActionAllow && matched) {
+		return p.expressionDenied()
+	}
+	return nil
+}
+
+// evaluateExpression returns outcome of expression evaluated against request
+func (p *Policy) evaluateExpression(request *request.Request, expression string) (bool, error) {
+
+	owner, lastModifiedAt := p.expressionOwner(request)
+	var program cel.Program
+	var err error
+	if p.config != nil && p.config.expressions != nil {
+		program, err = p.config.expressions.Get(owner, lastModifiedAt, expression)
+	} else {
+		program, err = types.CompilePolicyExpression(expression)
+	}
+	if err != nil {
+		return false, err
+	}
+	out, _, err := program.Eval(p.expressionVariables(request))
+	if err != nil {
+		return false, err
+	}
+	matched, ok := out.Value().(bool)
+	return matched && ok, nil
+}
+
+// expressionOwner returns name and last modified timestamp of listener or
+// apiproduct which has the policy chain this policy is evaluated in
+func (p *Policy) expressionOwner(request *request.Request) (string, int64) {
+
+	// Apiproduct names are only unique within an organization
+	if p.scope == PolicyScopeAPIProduct && request.APIProduct != nil {
+		organizationName := ""
+		if request.Organization != nil {
+			organizationName = request.Organization.Name
+		}
+		return PolicyScopeAPIProduct + "/" + organizationName + "/" + request.APIProduct.Name,
+			request.APIProduct.LastModifiedAt
+	}
+	if request.Listener != nil {
+		return PolicyScopeVhost + "/" + request.Listener.Name, request.Listener.LastModifiedAt
+	}
+	return "", 0
+}
+
+// expressionVariables returns the variables of a request which can be used in an expression
+func (p *Policy) expressionVariables(request *request.Request) map[string]interface{} {
+
+	variables := map[string]interface{}{
+		types.PolicyExpressionRequestMethod:        "",
+		types.PolicyExpressionRequestHost:          "",
+		types.PolicyExpressionRequestPath:          "",
+		types.PolicyExpressionRequestHeaders:       map[string]string{},
+		types.PolicyExpressionRequestQuery:         map[string]string{},
+		types.PolicyExpressionRequestIP:            "",
+		types.PolicyExpressionRequestCountry:       "",
+		types.PolicyExpressionDeveloperID:          "",
+		types.PolicyExpressionDeveloperEmail:       "",
+		types.PolicyExpressionDeveloperStatus:      "",
+		types.PolicyExpressionDeveloperAttributes:  map[string]string{},
+		types.PolicyExpressionAppID:                "",
+		types.PolicyExpressionAppName:              "",
+		types.PolicyExpressionAppStatus:            "",
+		types.PolicyExpressionAppAttributes:        map[string]string{},
+		types.PolicyExpressionKeyStatus:            "",
+		types.PolicyExpressionKeyAttributes:        map[string]string{},
+		types.PolicyExpressionAPIProductName:       "",
+		types.PolicyExpressionAPIProductAttributes: map[string]string{},
+	}
+	if request.HTTPRequest != nil {
+		variables[types.PolicyExpressionRequestMethod] = request.HTTPRequest.Method
+		variables[types.PolicyExpressionRequestHost] = request.HTTPRequest.Host
+		if request.HTTPRequest.Headers != nil {
+			variables[types.PolicyExpressionRequestHeaders] = request.HTTPRequest.Headers
+		}
+	}
+	if request.URL != nil {
+		variables[types.PolicyExpressionRequestPath] = request.URL.Path
+	}
+	query := make(map[string]string, len(request.QueryParameters))
+	for name := range request.QueryParameters {
+		query[name] = request.QueryParameters.Get(name)
+	}
+	variables[types.PolicyExpressionRequestQuery] = query
+	if request.IP != nil {
+		variables[types.PolicyExpressionRequestIP] = request.IP.String()
+		if p.config != nil && p.config.geo != nil {
+			variables[types.PolicyExpressionRequestCountry], _ = p.config.geo.GetCountryAndState(request.IP)
+		}
+	}
+	if request.Developer != nil {
+		variables[types.PolicyExpressionDeveloperID] = request.Developer.DeveloperID
+		variables[types.PolicyExpressionDeveloperEmail] = request.Developer.Email
+		variables[types.PolicyExpressionDeveloperStatus] = request.Developer.Status
+		variables[types.PolicyExpressionDeveloperAttributes] = attributesToMap(request.Developer.Attributes)
+	}
+	if request.DeveloperApp != nil {
+		variables[types.PolicyExpressionAppID] = request.DeveloperApp.AppID
+		variables[types.PolicyExpressionAppName] = request.DeveloperApp.Name
+		variables[types.PolicyExpressionAppStatus] = request.DeveloperApp.Status
+		variables[types.PolicyExpressionAppAttributes] = attributesToMap(request.DeveloperApp.Attributes)
+	}
+	if request.Key != nil {
+		variables[types.PolicyExpressionKeyStatus] = request.Key.Status
+		variables[types.PolicyExpressionKeyAttributes] = attributesToMap(request.Key.Attributes)
+	}
+	if request.APIProduct != nil {
+		variables[types.PolicyExpressionAPIProductName] = request.APIProduct.Name
+		variables[types.PolicyExpressionAPIProductAttributes] = attributesToMap(request.APIProduct.Attributes)
+	}
+	return variables
+}
+
+// expressionDenied returns response rejecting request with configured status code and message
+func (p *Policy) expressionDenied() *Response {
+
+	// Status code is validated when policy is stored, but Envoy requires an error status code
+	statusCode := int(p.Parameters.GetAsUInt32("statusCode", http.StatusForbidden))
+	if statusCode < http.StatusBadRequest || statusCode > 599 {
+		statusCode = http.StatusForbidden
+	}
+	return &Response{
+		Denied:           true,
+		DeniedStatusCode: statusCode,
+		DeniedMessage:    p.Parameters.GetAsString("message", "Blocked by expression"),
+	}
+}
+
+// attributesToMap returns attributes as map of name and value
+func attributesToMap(attributes types.Attributes) map[string]string {
+
+	m := make(map[string]string, len(attributes))
+	for _, attribute := range attributes {
+		m[attribute.Name] = attribute.Value
+	}
+	return m
+}
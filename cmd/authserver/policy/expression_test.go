@@ -0,0 +1,188 @@
+package policy
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/request"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func Test_checkExpression(t *testing.T) {
+
+	const adminRule = `request.method == "POST" && request.path.startsWith("/admin/") &&
+		!(has(developer.attributes.tier) && developer.attributes.tier == "gold")`
+
+	tests := []struct {
+		name               string
+		parameters         types.Attributes
+		method             string
+		path               string
+		developer          *types.Developer
+		expectedDenied     bool
+		expectedStatusCode int
+		expectedMessage    string
+	}{
+		{
+			name:       "no match",
+			parameters: types.Attributes{{Name: "expression", Value: adminRule}},
+			method:     http.MethodGet,
+			path:       "/admin/users",
+		},
+		{
+			name:               "match without developer",
+			parameters:         types.Attributes{{Name: "expression", Value: adminRule}},
+			method:             http.MethodPost,
+			path:               "/admin/users",
+			expectedDenied:     true,
+			expectedStatusCode: http.StatusForbidden,
+			expectedMessage:    "Blocked by expression",
+		},
+		{
+			name:       "developer with gold tier",
+			parameters: types.Attributes{{Name: "expression", Value: adminRule}},
+			method:     http.MethodPost,
+			path:       "/admin/users",
+			developer: &types.Developer{
+				Attributes: types.Attributes{{Name: "tier", Value: "gold"}},
+			},
+		},
+		{
+			name: "custom status code and message",
+			parameters: types.Attributes{
+				{Name: "expression", Value: adminRule},
+				{Name: "statusCode", Value: "451"},
+				{Name: "message", Value: "Gold tier required"},
+			},
+			method: http.MethodPost,
+			path:   "/admin/users",
+			developer: &types.Developer{
+				Attributes: types.Attributes{{Name: "tier", Value: "silver"}},
+			},
+			expectedDenied:     true,
+			expectedStatusCode: 451,
+			expectedMessage:    "Gold tier required",
+		},
+		{
+			name: "invalid status code",
+			parameters: types.Attributes{
+				{Name: "expression", Value: adminRule},
+				{Name: "statusCode", Value: "0"},
+			},
+			method:             http.MethodPost,
+			path:               "/admin/users",
+			expectedDenied:     true,
+			expectedStatusCode: http.StatusForbidden,
+			expectedMessage:    "Blocked by expression",
+		},
+		{
+			name: "allow matching requests",
+			parameters: types.Attributes{
+				{Name: "expression", Value: `request.headers["x-tenant"] == "acme"`},
+				{Name: "action", Value: "allow"},
+			},
+			method:             http.MethodGet,
+			path:               "/orders/42",
+			expectedDenied:     true,
+			expectedStatusCode: http.StatusForbidden,
+			expectedMessage:    "Blocked by expression",
+		},
+		{
+			name: "evaluation error",
+			parameters: types.Attributes{
+				{Name: "expression", Value: `developer.attributes["tier"] == "gold"`},
+			},
+			method:             http.MethodGet,
+			path:               "/orders/42",
+			expectedDenied:     true,
+			expectedStatusCode: http.StatusForbidden,
+			expectedMessage:    "Blocked by expression",
+		},
+	}
+	p := newTestPolicy(t)
+	for _, test := range tests {
+		p.Parameters = test.parameters
+		r := newAPIKeyRequest("", nil, "")
+		r.HTTPRequest.Method = test.method
+		r.URL.Path = test.path
+		r.Developer = test.developer
+
+		response := p.checkExpression(r)
+		if !test.expectedDenied {
+			require.Nil(t, response, test.name)
+			continue
+		}
+		require.NotNil(t, response, test.name)
+		require.True(t, response.Denied, test.name)
+		require.Equal(t, test.expectedStatusCode, response.DeniedStatusCode, test.name)
+		require.Equal(t, test.expectedMessage, response.DeniedMessage, test.name)
+	}
+}
+
+func Test_ExpressionCache(t *testing.T) {
+
+	cache := NewExpressionCache()
+
+	program, err := cache.Get("listener/default", 1, `request.method == "GET"`)
+	require.NoError(t, err)
+	cached, err := cache.Get("listener/default", 1, `request.method == "GET"`)
+	require.NoError(t, err)
+	require.Equal(t, program, cached)
+
+	// Compiled expressions are discarded once listener has been modified
+	_, err = cache.Get("listener/default", 2, `request.method == "POST"`)
+	require.NoError(t, err)
+	require.Len(t, cache.entries["listener/default"].programs, 1)
+
+	_, err = cache.Get("listener/default", 2, `request.method ==`)
+	require.Error(t, err)
+}
+
+func Test_expressionOwner(t *testing.T) {
+
+	p := &Policy{scope: PolicyScopeAPIProduct}
+	r := &request.Request{
+		Listener:     &types.Listener{Name: "public", LastModifiedAt: 1},
+		APIProduct:   &types.APIProduct{Name: "orders", LastModifiedAt: 2},
+		Organization: &types.Organization{Name: "default"},
+	}
+	owner, lastModifiedAt := p.expressionOwner(r)
+	require.Equal(t, "apiproduct/default/orders", owner)
+	require.Equal(t, int64(2), lastModifiedAt)
+
+	// Apiproducts with same name in different organizations do not share compiled expressions
+	r.Organization = &types.Organization{Name: "other"}
+	owner, _ = p.expressionOwner(r)
+	require.Equal(t, "apiproduct/other/orders", owner)
+
+	p.scope = PolicyScopeVhost
+	owner, lastModifiedAt = p.expressionOwner(r)
+	require.Equal(t, "listener/public", owner)
+	require.Equal(t, int64(1), lastModifiedAt)
+}
+
+func Test_ChainEvaluateExpression(t *testing.T) {
+
+	config := newTestPolicy(t).config
+
+	r := newAPIKeyRequest("", map[string]string{"x-api-key": "partner"}, "")
+	r.Listener.Policies = types.Policies{
+		{Name: "checkAPIKey", Parameters: types.Attributes{{Name: "header", Value: "x-api-key"}}},
+		{Name: "checkExpression", Parameters: types.Attributes{
+			{Name: "expression", Value: `app.name == "app" && apiproduct.name == "orders"`},
+			{Name: "action", Value: "allow"},
+		}},
+	}
+	outcome := NewChain(r, PolicyScopeVhost, config).Evaluate()
+	require.True(t, outcome.Authenticated, outcome.DeniedMessage)
+	require.NotEqual(t, "Blocked by expression", outcome.DeniedMessage)
+
+	// Expression is evaluated against entities looked up by checkAPIKey
+	r.Listener.Policies[1].Parameters[0].Value = `app.name == "other"`
+	r.Listener.LastModifiedAt++
+	outcome = NewChain(r, PolicyScopeVhost, config).Evaluate()
+	require.True(t, outcome.Denied)
+	require.Equal(t, "Blocked by expression", outcome.DeniedMessage)
+}
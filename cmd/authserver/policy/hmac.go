@@ -0,0 +1,147 @@
+package policy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coocood/freecache"
+	envoy_service_auth_v3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+)
+
+// HMAC holds configuration of HMAC request signature validation
+type HMAC struct {
+	ClockSkew      time.Duration // Allowed difference between timestamp of signature and current time
+	NonceCacheSize int           // Size in bytes of cache holding nonces of recent requests
+}
+
+// Header holding signature of a signed request
+const hmacSignatureHeader = "x-signature"
+
+// HMACValidator validates HMAC request signatures
+type HMACValidator struct {
+	config HMAC
+	// nonces holds the nonces of recently signed requests to detect replays, as it
+	// is not shared between authserver instances replays are only detected per instance
+	nonces *freecache.Cache
+}
+
+// hmacSignature holds the parameters of a signature header
+type hmacSignature struct {
+	keyID     string
+	timestamp int64
+	nonce     string
+	headers   []string
+	signature []byte
+}
+
+// NewHMACValidator returns a HMAC request signature validator
+func NewHMACValidator(config HMAC) *HMACValidator {
+
+	return &HMACValidator{
+		config: config,
+		nonces: freecache.NewCache(config.NonceCacheSize),
+	}
+}
+
+// parseSignatureHeader parses a signature header, e.g.
+// keyId="abc",timestamp="1620000000",nonce="1f2e",headers="host content-type",signature="<base64>"
+func parseSignatureHeader(value string) (*hmacSignature, error) {
+
+	params := make(map[string]string)
+	for _, param := range strings.Split(value, ",") {
+		name, paramValue, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found {
+			return nil, fmt.Errorf("cannot parse signature parameter '%s'", param)
+		}
+		params[name] = strings.Trim(paramValue, `"`)
+	}
+
+	s := &hmacSignature{
+		keyID:   params["keyId"],
+		nonce:   params["nonce"],
+		headers: strings.Fields(strings.ToLower(params["headers"])),
+	}
+	if s.keyID == "" || s.nonce == "" {
+		return nil, errors.New("signature requires keyId and nonce")
+	}
+	var err error
+	if s.timestamp, err = strconv.ParseInt(params["timestamp"], 10, 64); err != nil {
+		return nil, errors.New("signature requires timestamp in seconds since epoch")
+	}
+	if s.signature, err = base64.StdEncoding.DecodeString(params["signature"]); err != nil || len(s.signature) == 0 {
+		return nil, errors.New("signature requires base64 encoded signature")
+	}
+	return s, nil
+}
+
+// checkTimestamp checks whether signature was created within allowed clock skew
+func (h *HMACValidator) checkTimestamp(s *hmacSignature, now time.Time) error {
+
+	skew := time.Unix(s.timestamp, 0).Sub(now)
+	if skew > h.config.ClockSkew || skew < -h.config.ClockSkew {
+		return errors.New("signature timestamp outside of allowed clock skew")
+	}
+	return nil
+}
+
+// checkNonce checks that nonce was not used before by key within clock skew period
+func (h *HMACValidator) checkNonce(s *hmacSignature) error {
+
+	// Requests older than clock skew are rejected anyway, so we only need to
+	// remember nonces for twice that period
+	expire := int(2 * h.config.ClockSkew.Seconds())
+	previous, err := h.nonces.GetOrSet([]byte(s.keyID+"\x00"+s.nonce), []byte{1}, expire)
+	if err != nil {
+		return err
+	}
+	if previous != nil {
+		return errors.New("signature nonce already used")
+	}
+	return nil
+}
+
+// verify checks whether signature of request was computed using secret
+func (s *hmacSignature) verify(httpRequest *envoy_service_auth_v3.AttributeContext_HttpRequest, secret string) error {
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(s.stringToSign(httpRequest)))
+	if !hmac.Equal(mac.Sum(nil), s.signature) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// stringToSign returns the canonical representation of a request covered by signature:
+// method, path, timestamp, nonce, each signed header and hex encoded sha256 digest of body
+// all separated by newlines
+func (s *hmacSignature) stringToSign(httpRequest *envoy_service_auth_v3.AttributeContext_HttpRequest) string {
+
+	var b strings.Builder
+	b.WriteString(httpRequest.Method + "\n")
+	b.WriteString(httpRequest.Path + "\n")
+	b.WriteString(strconv.FormatInt(s.timestamp, 10) + "\n")
+	b.WriteString(s.nonce + "\n")
+	for _, header := range s.headers {
+		value := httpRequest.Headers[header]
+		// Envoy provides host as :authority pseudo header
+		if header == "host" {
+			value = httpRequest.Host
+		}
+		b.WriteString(header + ":" + strings.TrimSpace(value) + "\n")
+	}
+	// Body is only available in case listener has ExtAuthzRequestBodySize set
+	body := httpRequest.RawBody
+	if len(body) == 0 {
+		body = []byte(httpRequest.Body)
+	}
+	digest := sha256.Sum256(body)
+	b.WriteString(hex.EncodeToString(digest[:]))
+	return b.String()
+}
@@ -0,0 +1,162 @@
+package policy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	envoy_service_auth_v3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/request"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func newSignedRequest(now time.Time, secret, nonce, body string) *request.Request {
+
+	httpRequest := &envoy_service_auth_v3.AttributeContext_HttpRequest{
+		Method: http.MethodPost,
+		Path:   "/orders/42?expand=true",
+		Host:   "api.example.com",
+		Headers: map[string]string{
+			"content-type": "application/json",
+		},
+		Body: body,
+	}
+	signature := &hmacSignature{
+		keyID:     "partner",
+		timestamp: now.Unix(),
+		nonce:     nonce,
+		headers:   []string{"host", "content-type"},
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signature.stringToSign(httpRequest)))
+	httpRequest.Headers[hmacSignatureHeader] = fmt.Sprintf(
+		`keyId="partner", timestamp="%d", nonce="%s", headers="host content-type", signature="%s"`,
+		now.Unix(), nonce, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	u, _ := url.ParseRequestURI(httpRequest.Path)
+	return &request.Request{
+		Timestamp:    now.UnixMilli(),
+		HTTPRequest:  httpRequest,
+		URL:          u,
+		Organization: &types.Organization{Name: "default"},
+	}
+}
+
+func Test_checkHMACSignature(t *testing.T) {
+
+	p := newTestPolicy(t)
+	now := time.Now()
+
+	response := p.checkHMACSignature(newSignedRequest(now, "secret", "n1", `{"qty":1}`))
+	require.NotNil(t, response)
+	require.True(t, response.Authenticated, response.DeniedMessage)
+	require.Equal(t, metadataAuthMethodValueHMAC, response.Metadata[metadataAuthMethod])
+	require.Equal(t, "partner", response.Metadata[metadataAuthAPIKey])
+
+	// Same nonce cannot be used twice
+	response = p.checkHMACSignature(newSignedRequest(now, "secret", "n1", `{"qty":1}`))
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusUnauthorized, response.DeniedStatusCode)
+
+	// Signature computed with wrong secret
+	response = p.checkHMACSignature(newSignedRequest(now, "wrong", "n2", `{"qty":1}`))
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusUnauthorized, response.DeniedStatusCode)
+
+	// Body has been tampered with
+	r := newSignedRequest(now, "secret", "n3", `{"qty":1}`)
+	r.HTTPRequest.Body = `{"qty":1000}`
+	response = p.checkHMACSignature(r)
+	require.True(t, response.Denied)
+	require.Equal(t, "signature mismatch", response.DeniedMessage)
+
+	// Signed header has been tampered with
+	r = newSignedRequest(now, "secret", "n4", "")
+	r.HTTPRequest.Headers["content-type"] = "text/plain"
+	require.True(t, p.checkHMACSignature(r).Denied)
+
+	// Timestamp outside clock skew
+	r = newSignedRequest(now.Add(-2*time.Minute), "secret", "n5", "")
+	r.Timestamp = now.UnixMilli()
+	response = p.checkHMACSignature(r)
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusUnauthorized, response.DeniedStatusCode)
+
+	// Malformed signature header
+	r = newSignedRequest(now, "secret", "n6", "")
+	r.HTTPRequest.Headers[hmacSignatureHeader] = `keyId="partner"`
+	response = p.checkHMACSignature(r)
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusBadRequest, response.DeniedStatusCode)
+
+	// Request without signature is not for this policy
+	r = newSignedRequest(now, "secret", "n7", "")
+	delete(r.HTTPRequest.Headers, hmacSignatureHeader)
+	require.Nil(t, p.checkHMACSignature(r))
+}
+
+func Test_checkHMACSignatureBeforeEntitlement(t *testing.T) {
+
+	p := newTestPolicy(t)
+	now := time.Now()
+
+	// Key without any apiproduct
+	require.Nil(t, p.config.db.Key.UpdateByKey("default", &types.Key{
+		ConsumerKey:    "partner",
+		ConsumerSecret: "secret",
+		AppID:          "app1",
+		Status:         "approved",
+	}))
+
+	// Entitlement of key is not revealed to requests with invalid signature
+	r := newSignedRequest(now, "wrong", "n1", "")
+	response := p.checkHMACSignature(r)
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusUnauthorized, response.DeniedStatusCode)
+	require.Nil(t, r.Key)
+	require.Nil(t, r.ConsumerKey)
+
+	// Validly signed request is checked for entitlement
+	response = p.checkHMACSignature(newSignedRequest(now, "secret", "n2", ""))
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusForbidden, response.DeniedStatusCode)
+
+	// Unknown key cannot be distinguished from invalid signature
+	require.Nil(t, p.config.db.Key.DeleteByKey("default", "partner"))
+	response = p.checkHMACSignature(newSignedRequest(now, "secret", "n3", ""))
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusUnauthorized, response.DeniedStatusCode)
+	require.Equal(t, "signature mismatch", response.DeniedMessage)
+}
+
+func Test_checkHMACSignatureClientCertificateKey(t *testing.T) {
+
+	p := newTestPolicy(t)
+
+	// Key of client certificate cannot be used to sign requests
+	consumerKey := types.KeyClientCertificateNamePrefix + "partner.example.com"
+	require.Nil(t, p.config.db.Key.UpdateByKey("default", &types.Key{
+		ConsumerKey:    consumerKey,
+		ConsumerSecret: "secret",
+		AppID:          "app1",
+		Status:         "approved",
+		APIProducts: types.KeyAPIProductStatuses{
+			{Apiproduct: "orders", Status: "approved"},
+		},
+	}))
+	r := newSignedRequest(time.Now(), "secret", "n1", "")
+	r.HTTPRequest.Headers[hmacSignatureHeader] = strings.Replace(
+		r.HTTPRequest.Headers[hmacSignatureHeader], `keyId="partner"`, `keyId="`+consumerKey+`"`, 1)
+	response := p.checkHMACSignature(r)
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusBadRequest, response.DeniedStatusCode)
+	require.Nil(t, r.ConsumerKey)
+}
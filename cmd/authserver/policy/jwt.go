@@ -0,0 +1,318 @@
+package policy
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/jwks"
+)
+
+// JWT holds configuration of JWT bearer token validation
+type JWT struct {
+	JWKSFile        string        // Filename of JWKS with keys to validate tokens
+	JWKSURL         string        // URL of JWKS with keys to validate tokens
+	RefreshInterval time.Duration // Interval between reloads of JWKS
+	Issuer          string        // Required issuer (iss claim) of tokens, optional
+	Audience        string        // Required audience (aud claim) of tokens, optional
+	ClockSkew       time.Duration // Allowed clock difference when checking exp and nbf
+	Claims          []string      // Claims to set as dynamic metadata
+}
+
+const (
+	// Minimum time between two loads of JWKS triggered by an unknown key id
+	jwksMinReloadInterval = 30 * time.Second
+	// Maximum duration of fetching JWKS from URL
+	jwksFetchTimeout = 10 * time.Second
+)
+
+// Signing algorithms we accept
+var jwtValidMethods = []string{
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodES256.Alg(),
+	jwt.SigningMethodHS256.Alg(),
+}
+
+// JWTValidator validates JWT tokens using keys from a JWKS
+type JWTValidator struct {
+	config            JWT
+	keys              map[string]verificationKey
+	lastLoad          time.Time
+	minReloadInterval time.Duration
+	mutex             sync.RWMutex
+	client            *http.Client
+	logger            *zap.Logger
+}
+
+// verificationKey is a key to verify token signatures with
+type verificationKey struct {
+	alg string
+	key interface{}
+}
+
+// NewJWTValidator returns a JWT validator with keys loaded from configured JWKS
+func NewJWTValidator(config JWT, logger *zap.Logger) (*JWTValidator, error) {
+
+	if config.JWKSFile == "" && config.JWKSURL == "" {
+		return nil, errors.New("jwt requires jwksfile or jwksurl to be configured")
+	}
+	j := &JWTValidator{
+		config:            config,
+		minReloadInterval: jwksMinReloadInterval,
+		client:            &http.Client{Timeout: jwksFetchTimeout},
+		logger:            logger.With(zap.String("system", "jwt")),
+	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Start continuously reloads the JWKS to pick up rotated keys
+func (j *JWTValidator) Start() {
+
+	if j.config.RefreshInterval <= 0 {
+		return
+	}
+	for range time.NewTicker(j.config.RefreshInterval).C {
+		if err := j.load(); err != nil {
+			j.logger.Warn("Cannot reload jwks", zap.Error(err))
+		}
+	}
+}
+
+// load retrieves and parses JWKS from file or url
+func (j *JWTValidator) load() error {
+
+	j.mutex.Lock()
+	j.lastLoad = time.Now()
+	j.mutex.Unlock()
+
+	var data []byte
+	var err error
+	if j.config.JWKSFile != "" {
+		data, err = os.ReadFile(j.config.JWKSFile)
+	} else {
+		data, err = j.fetch(j.config.JWKSURL)
+	}
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	j.mutex.Lock()
+	j.keys = keys
+	j.mutex.Unlock()
+
+	j.logger.Debug("Loaded jwks", zap.Int("keys", len(keys)))
+	return nil
+}
+
+// fetch retrieves JWKS from url
+func (j *JWTValidator) fetch(url string) ([]byte, error) {
+
+	response, err := j.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot fetch jwks, status code %d", response.StatusCode)
+	}
+	return io.ReadAll(response.Body)
+}
+
+// Validate checks signature and exp, nbf, iss and aud claims of a token, returns claims of token
+func (j *JWTValidator) Validate(tokenString string, now time.Time) (jwt.MapClaims, error) {
+
+	parser := jwt.Parser{
+		ValidMethods: jwtValidMethods,
+		// We check claims ourselves as we need to allow for clock skew
+		SkipClaimsValidation: true,
+	}
+	claims := jwt.MapClaims{}
+	if _, err := parser.ParseWithClaims(tokenString, claims, j.getKey); err != nil {
+		// Unwrap error of key lookup
+		if validationError, ok := err.(*jwt.ValidationError); ok && validationError.Inner != nil {
+			return nil, validationError.Inner
+		}
+		return nil, err
+	}
+
+	skew := int64(j.config.ClockSkew.Seconds())
+	if !claims.VerifyExpiresAt(now.Unix()-skew, true) {
+		return nil, errors.New("token is expired")
+	}
+	if !claims.VerifyNotBefore(now.Unix()+skew, false) {
+		return nil, errors.New("token is not valid yet")
+	}
+	if j.config.Issuer != "" && !claims.VerifyIssuer(j.config.Issuer, true) {
+		return nil, errors.New("token has incorrect issuer")
+	}
+	if j.config.Audience != "" && !claims.VerifyAudience(j.config.Audience, true) {
+		return nil, errors.New("token has incorrect audience")
+	}
+	return claims, nil
+}
+
+// getKey returns the key to verify signature of token with
+func (j *JWTValidator) getKey(token *jwt.Token) (interface{}, error) {
+
+	kid, _ := token.Header["kid"].(string)
+
+	key, found := j.lookupKey(kid)
+	if !found && j.reloadAllowed() {
+		// Unknown key id might be caused by key rotation
+		if err := j.load(); err != nil {
+			j.logger.Warn("Cannot reload jwks", zap.Error(err))
+		}
+		key, found = j.lookupKey(kid)
+	}
+	if !found {
+		return nil, errors.New("token signed with unknown key")
+	}
+
+	// Make sure type of key matches with signing algorithm of token
+	if key.alg != "" && key.alg != token.Method.Alg() {
+		return nil, errors.New("token signing algorithm does not match key")
+	}
+	var ok bool
+	switch token.Method.(type) {
+	case *jwt.SigningMethodRSA:
+		_, ok = key.key.(*rsa.PublicKey)
+	case *jwt.SigningMethodECDSA:
+		_, ok = key.key.(*ecdsa.PublicKey)
+	case *jwt.SigningMethodHMAC:
+		_, ok = key.key.([]byte)
+	}
+	if !ok {
+		return nil, errors.New("token signing algorithm does not match key")
+	}
+	return key.key, nil
+}
+
+// lookupKey finds key by key id, a token without key id can only be verified
+// in case the JWKS has a single key
+func (j *JWTValidator) lookupKey(kid string) (verificationKey, bool) {
+
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	if key, ok := j.keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	return verificationKey{}, false
+}
+
+// reloadAllowed returns true if JWKS has not been loaded recently
+func (j *JWTValidator) reloadAllowed() bool {
+
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	return time.Since(j.lastLoad) > j.minReloadInterval
+}
+
+// parseJWKS parses a JWKS, returns keys indexed by key id
+func parseJWKS(data []byte) (map[string]verificationKey, error) {
+
+	var keySet jwks.Set
+	if err := json.Unmarshal(data, &keySet); err != nil {
+		return nil, fmt.Errorf("cannot parse jwks: %w", err)
+	}
+	keys := make(map[string]verificationKey, len(keySet.Keys))
+	for _, key := range keySet.Keys {
+		// Skip keys not meant for signatures
+		if key.Use != "" && key.Use != jwks.UseSignature {
+			continue
+		}
+		publicKey, err := key.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse jwks key '%s': %w", key.Kid, err)
+		}
+		keys[key.Kid] = verificationKey{alg: key.Alg, key: publicKey}
+	}
+	return keys, nil
+}
+
+// jwtClientID returns client id of token, which is the gatekeeper apikey
+func jwtClientID(claims jwt.MapClaims) string {
+
+	if clientID, ok := claims["client_id"].(string); ok && clientID != "" {
+		return clientID
+	}
+	azp, _ := claims["azp"].(string)
+	return azp
+}
+
+// jwtScopes returns scopes of token, provided as space separated string or as list
+func jwtScopes(claims jwt.MapClaims) []string {
+
+	for _, name := range []string{"scope", "scp"} {
+		switch v := claims[name].(type) {
+		case string:
+			return strings.Fields(v)
+		case []interface{}:
+			scopes := make([]string, 0, len(v))
+			for _, element := range v {
+				if scope, ok := element.(string); ok {
+					scopes = append(scopes, scope)
+				}
+			}
+			return scopes
+		}
+	}
+	return nil
+}
+
+// jwtClaimsMetadata returns the requested claims of a token as dynamic metadata
+func jwtClaimsMetadata(claims jwt.MapClaims, names []string) map[string]string {
+
+	m := make(map[string]string, len(names))
+	for _, name := range names {
+		value, ok := claims[name]
+		if !ok {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			m[metadataJWTClaimPrefix+name] = v
+		case float64:
+			m[metadataJWTClaimPrefix+name] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			m[metadataJWTClaimPrefix+name] = strconv.FormatBool(v)
+		case []interface{}:
+			values := make([]string, 0, len(v))
+			for _, element := range v {
+				values = append(values, fmt.Sprint(element))
+			}
+			m[metadataJWTClaimPrefix+name] = strings.Join(values, ",")
+		default:
+			if encoded, err := json.Marshal(v); err == nil {
+				m[metadataJWTClaimPrefix+name] = string(encoded)
+			}
+		}
+	}
+	return m
+}
@@ -0,0 +1,228 @@
+package policy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/jwks"
+	"github.com/erikbos/gatekeeper/cmd/authserver/request"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func publicJWK(t *testing.T, kid string, publicKey interface{}) jwks.Key {
+
+	key, err := jwks.NewKey(publicKey, "")
+	require.NoError(t, err)
+	key.Kid = kid
+	return key
+}
+
+func signJWT(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func Test_JWTValidator_Validate(t *testing.T) {
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	keySet := jwks.Set{Keys: []jwks.Key{
+		publicJWK(t, "rsa", &rsaKey.PublicKey),
+		publicJWK(t, "ec", &ecKey.PublicKey),
+		{Kty: "oct", Kid: "hmac", Alg: "HS256", K: base64.RawURLEncoding.EncodeToString(secret)},
+	}}
+	data, err := json.Marshal(keySet)
+	require.NoError(t, err)
+	filename := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(filename, data, 0600))
+
+	validator, err := NewJWTValidator(JWT{
+		JWKSFile:  filename,
+		Issuer:    "https://issuer",
+		Audience:  "api",
+		ClockSkew: 10 * time.Second,
+	}, zap.NewNop())
+	require.NoError(t, err)
+
+	now := time.Unix(1600000000, 0)
+	claims := func(modify func(jwt.MapClaims)) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss":       "https://issuer",
+			"aud":       []string{"other", "api"},
+			"exp":       now.Add(time.Minute).Unix(),
+			"nbf":       now.Unix(),
+			"client_id": "apikey",
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"rs256", signJWT(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims(nil)), true},
+		{"es256", signJWT(t, jwt.SigningMethodES256, "ec", ecKey, claims(nil)), true},
+		{"hs256", signJWT(t, jwt.SigningMethodHS256, "hmac", secret, claims(nil)), true},
+		{"within clock skew", signJWT(t, jwt.SigningMethodRS256, "rsa", rsaKey,
+			claims(func(c jwt.MapClaims) { c["exp"] = now.Add(-5 * time.Second).Unix() })), true},
+		{"expired", signJWT(t, jwt.SigningMethodRS256, "rsa", rsaKey,
+			claims(func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Minute).Unix() })), false},
+		{"no expiry", signJWT(t, jwt.SigningMethodRS256, "rsa", rsaKey,
+			claims(func(c jwt.MapClaims) { delete(c, "exp") })), false},
+		{"not yet valid", signJWT(t, jwt.SigningMethodRS256, "rsa", rsaKey,
+			claims(func(c jwt.MapClaims) { c["nbf"] = now.Add(time.Minute).Unix() })), false},
+		{"wrong issuer", signJWT(t, jwt.SigningMethodRS256, "rsa", rsaKey,
+			claims(func(c jwt.MapClaims) { c["iss"] = "https://other" })), false},
+		{"wrong audience", signJWT(t, jwt.SigningMethodRS256, "rsa", rsaKey,
+			claims(func(c jwt.MapClaims) { c["aud"] = "other" })), false},
+		{"unknown key", signJWT(t, jwt.SigningMethodRS256, "unknown", rsaKey, claims(nil)), false},
+		{"no key id", signJWT(t, jwt.SigningMethodRS256, "", rsaKey, claims(nil)), false},
+		{"algorithm does not match key", signJWT(t, jwt.SigningMethodHS256, "rsa",
+			[]byte("secret"), claims(nil)), false},
+		{"unsupported algorithm", signJWT(t, jwt.SigningMethodHS512, "hmac", secret, claims(nil)), false},
+		{"bad signature", signJWT(t, jwt.SigningMethodHS256, "hmac", []byte("secret"), claims(nil)), false},
+	}
+	for _, test := range tests {
+		result, err := validator.Validate(test.token, now)
+		if test.valid {
+			require.NoError(t, err, test.name)
+			require.Equal(t, "apikey", jwtClientID(result), test.name)
+		} else {
+			require.Error(t, err, test.name)
+		}
+	}
+}
+
+func Test_JWTValidator_KeyRotation(t *testing.T) {
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var rotated int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keySet := jwks.Set{Keys: []jwks.Key{publicJWK(t, "old", &oldKey.PublicKey)}}
+		if atomic.LoadInt32(&rotated) == 1 {
+			keySet.Keys = append(keySet.Keys, publicJWK(t, "new", &newKey.PublicKey))
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(keySet))
+	}))
+	defer server.Close()
+
+	validator, err := NewJWTValidator(JWT{JWKSURL: server.URL}, zap.NewNop())
+	require.NoError(t, err)
+	validator.minReloadInterval = 0
+
+	now := time.Now()
+	token := signJWT(t, jwt.SigningMethodRS256, "new", newKey,
+		jwt.MapClaims{"azp": "apikey", "exp": now.Add(time.Minute).Unix()})
+
+	_, err = validator.Validate(token, now)
+	require.Error(t, err)
+
+	// Unknown key id should trigger reload of JWKS
+	atomic.StoreInt32(&rotated, 1)
+	claims, err := validator.Validate(token, now)
+	require.NoError(t, err)
+	require.Equal(t, "apikey", jwtClientID(claims))
+}
+
+func Test_jwtClaimsMetadata(t *testing.T) {
+
+	claims := jwt.MapClaims{
+		"sub":   "joe",
+		"scope": []interface{}{"read", "write"},
+		"exp":   float64(1600000000),
+		"admin": false,
+		"org":   map[string]interface{}{"name": "acme"},
+	}
+	require.Equal(t, map[string]string{
+		"jwt.sub":   "joe",
+		"jwt.scope": "read,write",
+		"jwt.exp":   "1600000000",
+		"jwt.admin": "false",
+		"jwt.org":   `{"name":"acme"}`,
+	}, jwtClaimsMetadata(claims, []string{"sub", "scope", "exp", "admin", "org", "missing"}))
+}
+
+func Test_jwtScopes(t *testing.T) {
+
+	require.Equal(t, []string{"read", "write"}, jwtScopes(jwt.MapClaims{"scope": "read write"}))
+	require.Equal(t, []string{"read"}, jwtScopes(jwt.MapClaims{"scp": []interface{}{"read"}}))
+	require.Nil(t, jwtScopes(jwt.MapClaims{"sub": "joe"}))
+}
+
+func Test_checkJWTClientCertificateKey(t *testing.T) {
+
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	data, err := json.Marshal(jwks.Set{Keys: []jwks.Key{
+		{Kty: "oct", Kid: "hmac", Alg: "HS256", K: base64.RawURLEncoding.EncodeToString(secret)},
+	}})
+	require.NoError(t, err)
+	filename := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(filename, data, 0600))
+
+	p := newTestPolicy(t)
+	p.config.jwt, err = NewJWTValidator(JWT{JWKSFile: filename}, zap.NewNop())
+	require.NoError(t, err)
+
+	now := time.Now()
+	token := func(clientID string) *request.Request {
+		r := newAPIKeyRequest("", map[string]string{
+			"authorization": "Bearer " + signJWT(t, jwt.SigningMethodHS256, "hmac", secret, jwt.MapClaims{
+				"exp":       now.Add(time.Minute).Unix(),
+				"client_id": clientID,
+			}),
+		}, "")
+		r.Timestamp = now.UnixMilli()
+		return r
+	}
+
+	response := p.checkJWT(token("partner"))
+	require.True(t, response.Authenticated, response.DeniedMessage)
+
+	// Key of client certificate cannot be used as client id of token
+	require.Nil(t, p.config.db.Key.UpdateByKey("default", &types.Key{
+		ConsumerKey: types.KeyClientCertificateNamePrefix + "partner.example.com",
+		AppID:       "app1",
+		Status:      "approved",
+		APIProducts: types.KeyAPIProductStatuses{
+			{Apiproduct: "orders", Status: "approved"},
+		},
+	}))
+	r := token(types.KeyClientCertificateNamePrefix + "partner.example.com")
+	response = p.checkJWT(r)
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusForbidden, response.DeniedStatusCode)
+	require.Nil(t, r.Key)
+}
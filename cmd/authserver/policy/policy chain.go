@@ -1,24 +1,36 @@
 package policy
 
 import (
+	"context"
 	"net/http"
-	"strings"
 
+	"github.com/go-oauth2/oauth2/v4"
 	"go.uber.org/zap"
 
 	"github.com/erikbos/gatekeeper/cmd/authserver/metrics"
 	"github.com/erikbos/gatekeeper/cmd/authserver/oauth"
 	"github.com/erikbos/gatekeeper/cmd/authserver/request"
 	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
 // ChainConfig hold chain policy configuration
 type ChainConfig struct {
-	db      *db.Database
-	oauth   *oauth.Server
-	geo     *Geoip
-	metrics *metrics.Metrics
-	logger  *zap.Logger
+	db          *db.Database
+	oauth       accessTokenLoader
+	geo         *Geoip
+	jwt         *JWTValidator
+	hmac        *HMACValidator
+	expressions *ExpressionCache
+	metrics     *metrics.Metrics
+	logger      *zap.Logger
+	// Record every policy evaluation in chain outcome
+	trace bool
+}
+
+// accessTokenLoader retrieves details of an OAuth access token
+type accessTokenLoader interface {
+	LoadAccessToken(ctx context.Context, accessToken string) (oauth2.TokenInfo, error)
 }
 
 // Chain holds the input to evaluating a series of policies
@@ -34,17 +46,27 @@ type Chain struct {
 
 // NewChainConfig returns a ChainConfig object holding policy configuration
 func NewChainConfig(db *db.Database, oauth *oauth.Server, geo *Geoip,
+	jwt *JWTValidator, hmac *HMACValidator, expressions *ExpressionCache,
 	metrics *metrics.Metrics, logger *zap.Logger) *ChainConfig {
 
 	return &ChainConfig{
-		db:      db,
-		oauth:   oauth,
-		geo:     geo,
-		metrics: metrics,
-		logger:  logger,
+		db:          db,
+		oauth:       oauth,
+		geo:         geo,
+		jwt:         jwt,
+		hmac:        hmac,
+		expressions: expressions,
+		metrics:     metrics,
+		logger:      logger,
 	}
 }
 
+// EnableTrace records every policy evaluation as part of chain outcome
+func (c *ChainConfig) EnableTrace() {
+
+	c.trace = true
+}
+
 // NewChain returns a new Chain object
 func NewChain(r *request.Request, scope string, config *ChainConfig) *Chain {
 
@@ -55,10 +77,9 @@ func NewChain(r *request.Request, scope string, config *ChainConfig) *Chain {
 	}
 }
 
-//
 const (
-	PolicyScopeVhost      = "listener"
-	PolicyScopeAPIProduct = "apiproduct"
+	PolicyScopeVhost      = types.PolicyScopeListener
+	PolicyScopeAPIProduct = types.PolicyScopeAPIProduct
 )
 
 // ChainOutcome holds the output of a policy chain evaluation
@@ -73,8 +94,43 @@ type ChainOutcome struct {
 	DeniedMessage string
 	// Additional HTTP headers to set when forwarding to upstream
 	UpstreamHeaders map[string]string
+	// HTTP headers to remove when forwarding to upstream
+	UpstreamHeadersToRemove []string
 	// Dynamic metadata to set when forwarding to subsequent envoyproxy filter
 	UpstreamDynamicMetadata map[string]string
+	// Evaluation of each policy, only recorded in case trace is enabled
+	Trace []PolicyTrace
+}
+
+// clone returns a copy of outcome which can be modified without affecting the original outcome
+func (o *ChainOutcome) clone() *ChainOutcome {
+
+	clone := *o
+	clone.UpstreamHeaders = make(map[string]string, len(o.UpstreamHeaders))
+	for key, value := range o.UpstreamHeaders {
+		clone.UpstreamHeaders[key] = value
+	}
+	clone.UpstreamHeadersToRemove = append([]string(nil), o.UpstreamHeadersToRemove...)
+	clone.UpstreamDynamicMetadata = make(map[string]string, len(o.UpstreamDynamicMetadata))
+	for key, value := range o.UpstreamDynamicMetadata {
+		clone.UpstreamDynamicMetadata[key] = value
+	}
+	clone.Trace = append([]PolicyTrace(nil), o.Trace...)
+	return &clone
+}
+
+// PolicyTrace holds the evaluation of one policy of a policy chain
+type PolicyTrace struct {
+	// Policy chain: listener or apiproduct
+	Scope string
+	// Name of policy
+	Policy string
+	// Policy evaluated in shadow mode
+	Shadow bool
+	// Reason policy was not evaluated, empty in case it was evaluated
+	Skipped string
+	// Response of policy, nil in case policy did not apply
+	Response *Response
 }
 
 // Evaluate invokes all policy functions one by one, to:
@@ -105,31 +161,62 @@ func (p Chain) Evaluate() *ChainOutcome {
 
 	p.config.logger.Debug("Evaluating policy chain",
 		zap.String("scope", p.scope),
-		zap.String("policies", policies))
+		zap.String("policies", policies.String()))
 
-	for _, policyName := range strings.Split(policies, ",") {
+	for _, policyEntry := range policies {
 
-		trimmedPolicyName := strings.TrimSpace(policyName)
+		// Skip policy in case it cannot be used in this chain or request does not meet its condition
+		if !p.isAllowedInScope(policyEntry) {
+			p.config.metrics.IncPolicyMisses(p.scope, policyEntry.Name, policyEntry.Shadow)
+			p.addTrace(&policyChainResult, policyEntry, "policy cannot be used in "+p.scope+" policies", nil)
+			continue
+		}
+		if !p.conditionMatches(policyEntry) {
+			p.config.metrics.IncPolicyMisses(p.scope, policyEntry.Name, policyEntry.Shadow)
+			p.addTrace(&policyChainResult, policyEntry, "request does not match condition", nil)
+			continue
+		}
+
+		// Shadow policy gets a copy of request and outcome so identity it resolves, such as
+		// key and apiproduct, and anything it modifies cannot affect enforcement
+		policyRequest := p.Request
+		policyOutcome := &policyChainResult
+		if policyEntry.Shadow {
+			policyRequest = p.Request.Clone()
+			policyOutcome = policyChainResult.clone()
+		}
 
 		policy := NewPolicy(p.config)
 
-		policy.ChainOutcome = &policyChainResult
-		policy.Request = p.Request
-		policyResult := policy.Evaluate(trimmedPolicyName, p.Request)
+		policy.ChainOutcome = policyOutcome
+		policy.Request = policyRequest
+		policy.scope = p.scope
+		policy.Parameters = policyEntry.Parameters
+		policyResult := policy.Evaluate(policyEntry.Name, policyRequest)
 
 		p.config.logger.Debug("Evaluating policy",
 			zap.String("scope", p.scope),
-			zap.String("policy", trimmedPolicyName),
+			zap.String("policy", policyEntry.Name),
 			zap.Reflect("result", policyResult))
 
+		p.addTrace(&policyChainResult, policyEntry, "", policyResult)
+
+		// Shadow policy decisions are recorded, but never change the outcome
+		if policyEntry.Shadow {
+			p.recordShadowDecision(&policyChainResult, policyEntry.Name, policyResult)
+			continue
+		}
+
 		if policyResult != nil {
 			// Register this policy evaluation successed
-			p.config.metrics.IncPolicyHits(p.scope, trimmedPolicyName)
+			p.config.metrics.IncPolicyHits(p.scope, policyEntry.Name, false)
 
 			// Add policy generated headers to upstream
 			for key, value := range policyResult.Headers {
 				policyChainResult.UpstreamHeaders[key] = value
 			}
+			policyChainResult.UpstreamHeadersToRemove = append(
+				policyChainResult.UpstreamHeadersToRemove, policyResult.HeadersToRemove...)
 			// Add policy generated metadata
 			for key, value := range policyResult.Metadata {
 				policyChainResult.UpstreamDynamicMetadata[key] = value
@@ -148,8 +235,80 @@ func (p Chain) Evaluate() *ChainOutcome {
 			}
 		} else {
 			// Register this policy evaluation failed
-			p.config.metrics.IncPolicyMisses(p.scope, trimmedPolicyName)
+			p.config.metrics.IncPolicyMisses(p.scope, policyEntry.Name, false)
 		}
 	}
 	return &policyChainResult
 }
+
+// addTrace records evaluation of a policy in chain outcome in case trace is enabled
+func (p Chain) addTrace(outcome *ChainOutcome, policy types.Policy, skipped string, result *Response) {
+
+	if !p.config.trace {
+		return
+	}
+	outcome.Trace = append(outcome.Trace, PolicyTrace{
+		Scope:    p.scope,
+		Policy:   policy.Name,
+		Shadow:   policy.Shadow,
+		Skipped:  skipped,
+		Response: result,
+	})
+}
+
+// recordShadowDecision logs, counts and sets as metadata the decision of a policy
+// evaluated in shadow mode, leaving policy chain outcome untouched
+func (p Chain) recordShadowDecision(outcome *ChainOutcome, name string, result *Response) {
+
+	if result != nil {
+		p.config.metrics.IncPolicyHits(p.scope, name, true)
+	} else {
+		p.config.metrics.IncPolicyMisses(p.scope, name, true)
+	}
+
+	decision := metadataShadowDecisionAllow
+	if result != nil && result.Denied {
+		decision = metadataShadowDecisionDeny
+	}
+	outcome.UpstreamDynamicMetadata[metadataShadowPrefix+name] = decision
+
+	fields := []zap.Field{
+		zap.String("scope", p.scope),
+		zap.String("policy", name),
+		zap.String("decision", decision),
+		zap.String("path", p.Request.URL.Path),
+	}
+	if result != nil && result.Denied {
+		fields = append(fields,
+			zap.Int("statuscode", result.DeniedStatusCode),
+			zap.String("message", result.DeniedMessage))
+	}
+	p.config.logger.Info("Shadow policy decision", fields...)
+}
+
+// isAllowedInScope returns true in case policy can be used in policy chain of this scope
+func (p Chain) isAllowedInScope(policy types.Policy) bool {
+
+	definition, found := types.GetPolicyDefinition(policy.Name)
+	if !found || !definition.IsAllowedInScope(p.scope) {
+		p.config.logger.Warn("Policy cannot be used in policy chain",
+			zap.String("policy", policy.Name), zap.String("scope", p.scope))
+		return false
+	}
+	return true
+}
+
+// conditionMatches returns true in case policy has no condition or request matches it
+func (p Chain) conditionMatches(policy types.Policy) bool {
+
+	if policy.Condition == "" {
+		return true
+	}
+	condition, err := types.ParseAPIResource(policy.Condition)
+	if err != nil {
+		p.config.logger.Warn("Cannot parse policy condition",
+			zap.String("policy", policy.Name), zap.String("condition", policy.Condition), zap.Error(err))
+		return false
+	}
+	return condition.IsRequestAllowed(p.Request.HTTPRequest.Method, p.Request.URL.Path)
+}
@@ -7,12 +7,14 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"go.uber.org/zap"
 
 	"github.com/erikbos/gatekeeper/cmd/authserver/request"
 	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
 // Policy holds input to be to evaluate one policy
@@ -23,6 +25,12 @@ type Policy struct {
 	// Request information
 	Request *request.Request
 
+	// Parameters of policy as configured in policy chain
+	Parameters types.Attributes
+
+	// Policy chain policy is evaluated in: listener or apiproduct
+	scope string
+
 	// Current state of policy evaluation
 	*ChainOutcome
 }
@@ -39,6 +47,8 @@ type Response struct {
 	DeniedMessage string
 	// Additional HTTP Headers to set when forwarding to upstream
 	Headers map[string]string
+	// HTTP Headers to remove when forwarding to upstream
+	HeadersToRemove []string
 	// Dynamic Metadata to set when forwarding to subsequent envoyproxy filter
 	Metadata map[string]string
 }
@@ -48,6 +58,9 @@ const (
 	metadataAuthMethod            = "auth.method"
 	metadataAuthMethodValueAPIKey = "apikey"
 	metadataAuthMethodValueOAuth  = "oauth"
+	metadataAuthMethodValueJWT    = "jwt"
+	metadataAuthMethodValueHMAC   = "hmac"
+	metadataAuthMethodValueCert   = "clientcertificate"
 	metadataAuthAPIKey            = "auth.apikey"
 	metadataAuthOAuthToken        = "auth.oauthtoken"
 	metadataDeveloperEmail        = "developer.email"
@@ -57,6 +70,10 @@ const (
 	metadataAPIProductName        = "apiproduct.name"
 	metadataGeoIPCountry          = "geoip.country"
 	metadataGeoIPState            = "geoip.state"
+	metadataJWTClaimPrefix        = "jwt."
+	metadataShadowPrefix          = "shadow."
+	metadataShadowDecisionAllow   = "allow"
+	metadataShadowDecisionDeny    = "deny"
 )
 
 // NewPolicy returns a new Policy instance
@@ -70,46 +87,24 @@ func NewPolicy(config *ChainConfig) *Policy {
 // Evaluate executes single policy statement
 func (p *Policy) Evaluate(policy string, request *request.Request) *Response {
 
-	switch policy {
-	case "checkAPIKey":
-		return p.checkAPIKey(request)
-	case "checkOAuth2":
-		return p.checkOAuth2(request)
-	case "removeAPIKeyFromQP":
-		return p.removeAPIKeyFromQP()
-	case "lookupGeoIP":
-		return p.lookupGeoIP(request)
-	case "qps":
-		return policyQPS1(request)
-	case "sendAPIKey":
-		return policySendAPIKey(request)
-	case "sendDeveloperEmail":
-		return policySendDeveloperEmail(request)
-	case "sendDeveloperID":
-		return policySendDeveloperID(request)
-	case "sendDeveloperAppName":
-		return policySendDeveloperAppName(request)
-	case "sendDeveloperAppID":
-		return policySendDeveloperAppID(request)
-	case "checkIPAccessList":
-		return policyCheckIPAccessList(request)
-	case "checkReferer":
-		return policycheckReferer(request)
+	evaluate, found := lookupEvaluateFunc(policy)
+	if !found {
+		return nil
 	}
-	return nil
+	return evaluate(p, request)
 }
 
-// checkAPIKey tries to find key in querystring, loads dev app, dev details, and check whether path is allowed
+// checkAPIKey tries to find key in header or querystring, loads dev app, dev details, and check whether path is allowed
 func (p *Policy) checkAPIKey(request *request.Request) *Response {
 
 	var err error
-	request.ConsumerKey, err = getAPIkeyFromQueryString(request.QueryParameters)
+	request.ConsumerKey, err = getAPIKey(request, p.apiKeyHeader(request))
 
-	// In case we cannot find a query parameter we return immediately
+	// In case we cannot find a header or query parameter we return immediately
 	if err == nil && request.ConsumerKey == nil {
 		return nil
 	}
-	// In case we cannot find a query parameter did not have a value we reject request
+	// In case header or query parameter did not have a value we reject request
 	if err != nil {
 		return &Response{
 			Denied:           true,
@@ -118,6 +113,17 @@ func (p *Policy) checkAPIKey(request *request.Request) *Response {
 		}
 	}
 
+	// Keys of client certificates can only be used with the certificate itself
+	if types.IsClientCertificateConsumerKey(*request.ConsumerKey) {
+		p.config.metrics.IncUnknownAPIKey(request)
+
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusBadRequest,
+			DeniedMessage:    "apikey can only be used with client certificate",
+		}
+	}
+
 	// In case we have an apikey we check whether product is allowed to be accessed
 	err = p.CheckProductEntitlement(request)
 	if err != nil {
@@ -141,6 +147,45 @@ func (p *Policy) checkAPIKey(request *request.Request) *Response {
 	}
 }
 
+// getAPIKey extracts apikey from request: the header, if configured, takes
+// precedence over query parameters
+func getAPIKey(request *request.Request, header string) (*string, error) {
+
+	consumerKey, err := getAPIKeyFromHeader(request.HTTPRequest.Headers, header)
+	if err != nil || consumerKey != nil {
+		return consumerKey, err
+	}
+	return getAPIkeyFromQueryString(request.QueryParameters)
+}
+
+// apiKeyHeader returns name of header which can hold apikey, if configured as
+// policy parameter or listener attribute
+func (p *Policy) apiKeyHeader(request *request.Request) string {
+
+	header := p.Parameters.GetAsString("header", "")
+	if header == "" && request.Listener != nil {
+		header = request.Listener.Attributes.GetAsString(types.AttributeAPIKeyHeader, "")
+	}
+	// Envoy provides all header names in lowercase
+	return strings.ToLower(header)
+}
+
+// getAPIKeyFromHeader extracts apikey from header
+func getAPIKeyFromHeader(headers map[string]string, header string) (*string, error) {
+
+	if header == "" {
+		return nil, nil
+	}
+	value, found := headers[header]
+	if !found {
+		return nil, nil
+	}
+	if value == "" {
+		return nil, errors.New("apikey header has no value")
+	}
+	return &value, nil
+}
+
 // getAPIkeyFromQueryString extracts apikey from query parameters
 func getAPIkeyFromQueryString(queryParameters url.Values) (*string, error) {
 
@@ -162,16 +207,8 @@ func getAPIkeyFromQueryString(queryParameters url.Values) (*string, error) {
 // checkOAuth2 tries OAuth authentication, loads dev app, dev details, and check whether path is allowed
 func (p *Policy) checkOAuth2(request *request.Request) *Response {
 
-	authorizationHeader := request.HTTPRequest.Headers["authorization"]
-	if authorizationHeader == "" {
-		return nil
-	}
-
-	prefix := "Bearer "
-	accessToken := ""
-	if authorizationHeader != "" && strings.HasPrefix(authorizationHeader, prefix) {
-		accessToken = authorizationHeader[len(prefix):]
-	} else {
+	accessToken := getBearerToken(request)
+	if accessToken == "" {
 		// Cannot get bearer token from authorization header
 		// Not a problem: apparently this request was not meant to be authenticated using OAuth
 		return nil
@@ -186,10 +223,21 @@ func (p *Policy) checkOAuth2(request *request.Request) *Response {
 			DeniedMessage:    fmt.Sprint(err),
 		}
 	}
-	request.OauthToken = &accessToken
-
 	// The temporary access token contains the apikey (Also Known As clientId)
 	clientID := tokenInfo.GetClientID()
+
+	// Keys of client certificates can only be used with the certificate itself
+	if types.IsClientCertificateConsumerKey(clientID) {
+		p.config.metrics.IncUnknownAPIKey(request)
+
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusForbidden,
+			DeniedMessage:    "client id can only be used with client certificate",
+		}
+	}
+	request.OauthToken = &accessToken
+	request.Scopes = strings.Fields(tokenInfo.GetScope())
 	request.ConsumerKey = &clientID
 
 	err = p.CheckProductEntitlement(request)
@@ -206,6 +254,16 @@ func (p *Policy) checkOAuth2(request *request.Request) *Response {
 		}
 	}
 
+	if err := p.checkScopes(request); err != nil {
+		p.config.metrics.IncInsufficientScope(request)
+
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusForbidden,
+			DeniedMessage:    fmt.Sprint(err),
+		}
+	}
+
 	// Signal that we have authenticated this request
 	return &Response{
 		Authenticated: true,
@@ -213,6 +271,193 @@ func (p *Policy) checkOAuth2(request *request.Request) *Response {
 	}
 }
 
+// getBearerToken returns bearer token from authorization header
+func getBearerToken(request *request.Request) string {
+
+	const prefix = "Bearer "
+
+	authorizationHeader := request.HTTPRequest.Headers["authorization"]
+	if !strings.HasPrefix(authorizationHeader, prefix) {
+		return ""
+	}
+	return authorizationHeader[len(prefix):]
+}
+
+// checkJWT validates JWT bearer token, loads dev app, dev details of token's client id,
+// and check whether path is allowed
+func (p *Policy) checkJWT(request *request.Request) *Response {
+
+	if p.config == nil || p.config.jwt == nil {
+		return nil
+	}
+
+	accessToken := getBearerToken(request)
+	// Opaque tokens can still be authenticated by checkOAuth2
+	if strings.Count(accessToken, ".") != 2 {
+		return nil
+	}
+
+	claims, err := p.config.jwt.Validate(accessToken, time.UnixMilli(request.Timestamp))
+	if err != nil {
+		p.config.logger.Debug("JWT validation failed", zap.String("reason", err.Error()))
+
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusUnauthorized,
+			DeniedMessage:    fmt.Sprint(err),
+		}
+	}
+
+	// Token's client id is the apikey to be used for entitlement
+	clientID := jwtClientID(claims)
+	if clientID == "" {
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusForbidden,
+			DeniedMessage:    "token has no client_id or azp claim",
+		}
+	}
+	// Keys of client certificates can only be used with the certificate itself
+	if types.IsClientCertificateConsumerKey(clientID) {
+		p.config.metrics.IncUnknownAPIKey(request)
+
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusForbidden,
+			DeniedMessage:    "client id can only be used with client certificate",
+		}
+	}
+	request.ConsumerKey = &clientID
+	request.Scopes = jwtScopes(claims)
+
+	err = p.CheckProductEntitlement(request)
+	if err != nil {
+		p.config.logger.Debug("CheckProductEntitlement() not allowed",
+			zap.String("path", request.URL.Path), zap.String("reason", err.Error()))
+
+		p.config.metrics.IncUnknownAPIKey(request)
+
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusForbidden,
+			DeniedMessage:    fmt.Sprint(err),
+		}
+	}
+
+	if err := p.checkScopes(request); err != nil {
+		p.config.metrics.IncInsufficientScope(request)
+
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusForbidden,
+			DeniedMessage:    fmt.Sprint(err),
+		}
+	}
+
+	metadata := buildMetadata(request)
+	metadata[metadataAuthMethod] = metadataAuthMethodValueJWT
+	for key, value := range jwtClaimsMetadata(claims, p.config.jwt.config.Claims) {
+		metadata[key] = value
+	}
+
+	// Signal that we have authenticated this request
+	return &Response{
+		Authenticated: true,
+		Metadata:      metadata,
+	}
+}
+
+// checkHMACSignature verifies HMAC signature of request computed using secret of key,
+// loads dev app, dev details of key, and check whether path is allowed
+func (p *Policy) checkHMACSignature(request *request.Request) *Response {
+
+	if p.config == nil || p.config.hmac == nil {
+		return nil
+	}
+
+	signatureHeader := request.HTTPRequest.Headers[hmacSignatureHeader]
+	if signatureHeader == "" {
+		// Not a problem: apparently this request was not meant to be authenticated using a signature
+		return nil
+	}
+	signature, err := parseSignatureHeader(signatureHeader)
+	if err != nil {
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusBadRequest,
+			DeniedMessage:    fmt.Sprint(err),
+		}
+	}
+	if err := p.config.hmac.checkTimestamp(signature, time.UnixMilli(request.Timestamp)); err != nil {
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusUnauthorized,
+			DeniedMessage:    fmt.Sprint(err),
+		}
+	}
+	// Keys of client certificates can only be used with the certificate itself
+	if types.IsClientCertificateConsumerKey(signature.keyID) {
+		p.config.metrics.IncUnknownAPIKey(request)
+
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusBadRequest,
+			DeniedMessage:    "keyId can only be used with client certificate",
+		}
+	}
+	// Only look up secret of key: entitlements are not checked before the signature
+	// has been verified so unsigned requests cannot be used to probe keys
+	key, err := p.config.db.Key.GetByKey(&request.Organization.Name, &signature.keyID)
+	if err != nil {
+		p.config.metrics.IncUnknownAPIKey(request)
+
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusUnauthorized,
+			DeniedMessage:    "signature mismatch",
+		}
+	}
+	// Only remember nonce of validly signed requests, so nonces cannot be used up by others
+	if err := signature.verify(request.HTTPRequest, key.ConsumerSecret); err != nil {
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusUnauthorized,
+			DeniedMessage:    fmt.Sprint(err),
+		}
+	}
+	if err := p.config.hmac.checkNonce(signature); err != nil {
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusUnauthorized,
+			DeniedMessage:    fmt.Sprint(err),
+		}
+	}
+	request.ConsumerKey = &signature.keyID
+
+	err = p.CheckProductEntitlement(request)
+	if err != nil {
+		p.config.logger.Debug("CheckProductEntitlement() not allowed",
+			zap.String("path", request.URL.Path), zap.String("reason", err.Error()))
+
+		p.config.metrics.IncUnknownAPIKey(request)
+
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusForbidden,
+			DeniedMessage:    fmt.Sprint(err),
+		}
+	}
+
+	metadata := buildMetadata(request)
+	metadata[metadataAuthMethod] = metadataAuthMethodValueHMAC
+
+	// Signal that we have authenticated this request
+	return &Response{
+		Authenticated: true,
+		Metadata:      metadata,
+	}
+}
+
 // buildMetadata returns all authentication & apim metadata to be returned by authserver
 func buildMetadata(request *request.Request) map[string]string {
 
@@ -267,14 +512,40 @@ func (p *Policy) removeAPIKeyFromQP() *Response {
 	}
 }
 
-// lookupGeoIP lookup requestor's ip address in geoip database
-func (p *Policy) lookupGeoIP(request *request.Request) *Response {
+// removeAPIKeyFromHeader removes header holding apikey before request goes upstream
+func (p *Policy) removeAPIKeyFromHeader() *Response {
 
-	if p.config == nil || p.config.geo == nil {
+	// We only remove header in case we know request was authenticated and hence goes upstream
+	if p == nil || !p.ChainOutcome.Authenticated {
 		return nil
 	}
 
-	country, state := p.config.geo.GetCountryAndState(request.IP)
+	header := p.apiKeyHeader(p.Request)
+	if header == "" {
+		return nil
+	}
+	return &Response{
+		HeadersToRemove: []string{header},
+	}
+}
+
+// lookupGeoIP lookup requestor's ip address in geoip database
+func (p *Policy) lookupGeoIP(request *request.Request) *Response {
+
+	allowedCountries := p.Parameters.GetAsString("allowedCountries", "")
+
+	var country, state string
+	if p.config != nil && p.config.geo != nil {
+		country, state = p.config.geo.GetCountryAndState(request.IP)
+	}
+	// In case countries are restricted we reject requests from unknown countries as well
+	if allowedCountries != "" && !isCountryAllowed(country, allowedCountries) {
+		return &Response{
+			Denied:           true,
+			DeniedStatusCode: http.StatusForbidden,
+			DeniedMessage:    "Blocked by country ACL",
+		}
+	}
 	if country == "" {
 		return nil
 	}
@@ -289,10 +560,35 @@ func (p *Policy) lookupGeoIP(request *request.Request) *Response {
 	}
 }
 
+// isCountryAllowed checks country against a comma separated list of country codes
+func isCountryAllowed(country, allowedCountries string) bool {
+
+	if country == "" {
+		return false
+	}
+	for _, allowed := range strings.Split(allowedCountries, ",") {
+		if strings.EqualFold(strings.TrimSpace(allowed), country) {
+			return true
+		}
+	}
+	return false
+}
+
 // policyQPS1 returns QPS quotakey to be used by Lyft ratelimiter
-// QPS set as developer app attribute has priority over quota set as product attribute
+// QPS set as policy parameter has priority over developer app attribute, which has
+// priority over quota set as product attribute
 //
-func policyQPS1(request *request.Request) *Response {
+func policyQPS1(request *request.Request, parameters types.Attributes) *Response {
+
+	if limit := parameters.GetAsString("limit", ""); limit != "" {
+		return &Response{
+			Metadata: map[string]string{
+				"rl.requests_per_unit": limit,
+				"rl.unit":              "SECOND",
+				"rl.descriptor":        "policy",
+			},
+		}
+	}
 
 	if request == nil || request.APIProduct == nil || request.DeveloperApp == nil {
 		return nil
@@ -394,11 +690,15 @@ func policySendDeveloperAppID(request *request.Request) *Response {
 	return nil
 }
 
-// policyCheckIPAccessList checks requestor ip against IP ACL defined in developer app
-func policyCheckIPAccessList(request *request.Request) *Response {
+// policyCheckIPAccessList checks requestor ip against IP ACL defined as policy parameter
+// or in developer app
+func policyCheckIPAccessList(request *request.Request, parameters types.Attributes) *Response {
 
-	ipAccessList, err := request.DeveloperApp.Attributes.Get("IPAccessList")
-	if err == nil && ipAccessList != "" {
+	ipAccessList := parameters.GetAsString("accessList", "")
+	if ipAccessList == "" && request.DeveloperApp != nil {
+		ipAccessList = request.DeveloperApp.Attributes.GetAsString("IPAccessList", "")
+	}
+	if ipAccessList != "" {
 		if shared.CheckIPinAccessList(request.IP, ipAccessList) {
 			// OK, we have a match
 			return nil
@@ -413,11 +713,15 @@ func policyCheckIPAccessList(request *request.Request) *Response {
 	return nil
 }
 
-// policycheckReferer checks request's Host header against host ACL defined in developer app
-func policycheckReferer(request *request.Request) *Response {
+// policycheckReferer checks request's Host header against host ACL defined as policy parameter
+// or in developer app
+func policycheckReferer(request *request.Request, parameters types.Attributes) *Response {
 
-	hostAccessList, err := request.DeveloperApp.Attributes.Get("Referer")
-	if err == nil && hostAccessList != "" {
+	hostAccessList := parameters.GetAsString("accessList", "")
+	if hostAccessList == "" && request.DeveloperApp != nil {
+		hostAccessList = request.DeveloperApp.Attributes.GetAsString("Referer", "")
+	}
+	if hostAccessList != "" {
 		if checkHostinAccessList(request.HTTPRequest.Headers[":authority"], hostAccessList) {
 			return nil
 		}
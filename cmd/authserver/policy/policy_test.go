@@ -0,0 +1,286 @@
+package policy
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	envoy_service_auth_v3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/go-oauth2/oauth2/v4"
+	"github.com/go-oauth2/oauth2/v4/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/request"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func newAPIKeyRequest(apiKeyHeader string, headers map[string]string, rawQuery string) *request.Request {
+
+	r := &request.Request{
+		HTTPRequest: &envoy_service_auth_v3.AttributeContext_HttpRequest{
+			Method:  http.MethodGet,
+			Headers: headers,
+		},
+		URL:          &url.URL{Path: "/orders/42", RawQuery: rawQuery},
+		Listener:     &types.Listener{},
+		Organization: &types.Organization{Name: "default"},
+	}
+	r.QueryParameters = r.URL.Query()
+	if apiKeyHeader != "" {
+		r.Listener.Attributes = types.Attributes{
+			{Name: types.AttributeAPIKeyHeader, Value: apiKeyHeader},
+		}
+	}
+	return r
+}
+
+func Test_getAPIKey(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		apiKeyHeader string
+		parameters   types.Attributes
+		headers      map[string]string
+		rawQuery     string
+		expected     string
+		expectedErr  bool
+	}{
+		{
+			name:     "query parameter",
+			rawQuery: "apikey=qp",
+			expected: "qp",
+		},
+		{
+			name:     "header ignored without listener attribute",
+			headers:  map[string]string{"x-api-key": "header"},
+			rawQuery: "key=qp",
+			expected: "qp",
+		},
+		{
+			name:         "header",
+			apiKeyHeader: "X-API-Key",
+			headers:      map[string]string{"x-api-key": "header"},
+			expected:     "header",
+		},
+		{
+			name:         "header takes precedence",
+			apiKeyHeader: "X-API-Key",
+			headers:      map[string]string{"x-api-key": "header"},
+			rawQuery:     "apikey=qp",
+			expected:     "header",
+		},
+		{
+			name:         "header from policy parameter",
+			apiKeyHeader: "X-API-Key",
+			parameters:   types.Attributes{{Name: "header", Value: "Authorization-Key"}},
+			headers:      map[string]string{"x-api-key": "header", "authorization-key": "parameter"},
+			expected:     "parameter",
+		},
+		{
+			name:         "query parameter in case header absent",
+			apiKeyHeader: "X-API-Key",
+			rawQuery:     "apikey=qp",
+			expected:     "qp",
+		},
+		{
+			name:         "empty header",
+			apiKeyHeader: "X-API-Key",
+			headers:      map[string]string{"x-api-key": ""},
+			rawQuery:     "apikey=qp",
+			expectedErr:  true,
+		},
+		{
+			name: "no apikey",
+		},
+	}
+	for _, test := range tests {
+		p := &Policy{Parameters: test.parameters}
+		r := newAPIKeyRequest(test.apiKeyHeader, test.headers, test.rawQuery)
+		consumerKey, err := getAPIKey(r, p.apiKeyHeader(r))
+		if test.expectedErr {
+			require.Error(t, err, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		if test.expected == "" {
+			require.Nil(t, consumerKey, test.name)
+			continue
+		}
+		require.NotNil(t, consumerKey, test.name)
+		require.Equal(t, test.expected, *consumerKey, test.name)
+	}
+}
+
+func Test_checkAPIKeyFromHeader(t *testing.T) {
+
+	p := newTestPolicy(t)
+
+	r := newAPIKeyRequest("X-API-Key", map[string]string{"x-api-key": "partner"}, "")
+	response := p.checkAPIKey(r)
+	require.NotNil(t, response)
+	require.True(t, response.Authenticated, response.DeniedMessage)
+	require.Equal(t, "partner", response.Metadata[metadataAuthAPIKey])
+
+	r = newAPIKeyRequest("X-API-Key", map[string]string{"x-api-key": "unknown"}, "apikey=partner")
+	response = p.checkAPIKey(r)
+	require.True(t, response.Denied)
+}
+
+func Test_removeAPIKeyFromHeader(t *testing.T) {
+
+	p := newTestPolicy(t)
+	p.ChainOutcome = &ChainOutcome{}
+	p.Request = newAPIKeyRequest("X-API-Key", map[string]string{"x-api-key": "partner"}, "")
+
+	// Unauthenticated requests do not go upstream
+	require.Nil(t, p.removeAPIKeyFromHeader())
+
+	p.ChainOutcome.Authenticated = true
+	response := p.removeAPIKeyFromHeader()
+	require.NotNil(t, response)
+	require.Equal(t, []string{"x-api-key"}, response.HeadersToRemove)
+
+	// Nothing to remove in case listener has no apikey header configured
+	p.Request = newAPIKeyRequest("", nil, "")
+	require.Nil(t, p.removeAPIKeyFromHeader())
+}
+
+func Test_ChainEvaluate(t *testing.T) {
+
+	config := newTestPolicy(t).config
+
+	r := newAPIKeyRequest("", map[string]string{"x-api-key": "partner"}, "")
+	r.Listener.Policies = types.Policies{
+		{Name: "checkAPIKey", Parameters: types.Attributes{{Name: "header", Value: "x-api-key"}}},
+		{Name: "qps", Parameters: types.Attributes{{Name: "limit", Value: "5"}}, Condition: "POST /orders/**"},
+		{Name: "removeAPIKeyFromHeader", Parameters: types.Attributes{{Name: "header", Value: "x-api-key"}}},
+	}
+	outcome := NewChain(r, PolicyScopeVhost, config).Evaluate()
+	require.True(t, outcome.Authenticated, outcome.DeniedMessage)
+	require.Equal(t, []string{"x-api-key"}, outcome.UpstreamHeadersToRemove)
+	// qps is skipped as condition does not match GET request
+	require.NotContains(t, outcome.UpstreamDynamicMetadata, "rl.requests_per_unit")
+
+	r.HTTPRequest.Method = http.MethodPost
+	outcome = NewChain(r, PolicyScopeVhost, config).Evaluate()
+	require.Equal(t, "5", outcome.UpstreamDynamicMetadata["rl.requests_per_unit"])
+
+	// Country restriction rejects requests of unknown origin
+	r.Listener.Policies = types.Policies{
+		{Name: "lookupGeoIP", Parameters: types.Attributes{{Name: "allowedCountries", Value: "NL"}}},
+		{Name: "checkAPIKey", Parameters: types.Attributes{{Name: "header", Value: "x-api-key"}}},
+	}
+	outcome = NewChain(r, PolicyScopeVhost, config).Evaluate()
+	require.True(t, outcome.Denied)
+	require.Equal(t, "Blocked by country ACL", outcome.DeniedMessage)
+}
+
+func Test_ChainEvaluateShadow(t *testing.T) {
+
+	config := newTestPolicy(t).config
+
+	r := newAPIKeyRequest("", map[string]string{"x-api-key": "partner"}, "")
+	r.Listener.Policies = types.Policies{
+		{Name: "checkAPIKey", Parameters: types.Attributes{{Name: "header", Value: "x-api-key"}}},
+		{Name: "checkExpression", Parameters: types.Attributes{
+			{Name: "expression", Value: `request.method == "GET"`},
+		}, Shadow: true},
+		{Name: "removeAPIKeyFromHeader", Parameters: types.Attributes{{Name: "header", Value: "x-api-key"}}},
+	}
+	outcome := NewChain(r, PolicyScopeVhost, config).Evaluate()
+	// Shadow deny does not stop evaluation or reject request
+	require.True(t, outcome.Authenticated, outcome.DeniedMessage)
+	require.NotEqual(t, "Blocked by expression", outcome.DeniedMessage)
+	require.Equal(t, []string{"x-api-key"}, outcome.UpstreamHeadersToRemove)
+	require.Equal(t, "deny", outcome.UpstreamDynamicMetadata["shadow.checkExpression"])
+	require.Equal(t, 1.0, testutil.ToFloat64(
+		config.metrics.PolicyHits.WithLabelValues(PolicyScopeVhost, "checkExpression", "true")))
+	require.Equal(t, 0.0, testutil.ToFloat64(
+		config.metrics.PolicyHits.WithLabelValues(PolicyScopeVhost, "checkExpression", "false")))
+
+	// Shadow authentication does not authenticate request
+	r = newAPIKeyRequest("", map[string]string{"x-api-key": "partner"}, "")
+	r.Listener.Policies = types.Policies{
+		{Name: "checkAPIKey", Parameters: types.Attributes{{Name: "header", Value: "x-api-key"}}, Shadow: true},
+	}
+	outcome = NewChain(r, PolicyScopeVhost, config).Evaluate()
+	require.False(t, outcome.Authenticated)
+	require.Equal(t, "allow", outcome.UpstreamDynamicMetadata["shadow.checkAPIKey"])
+	// Identity resolved by shadow policy is not used by request
+	require.Nil(t, r.ConsumerKey)
+	require.Nil(t, r.Key)
+	require.Nil(t, r.DeveloperApp)
+	require.Nil(t, r.APIProduct)
+}
+
+// testTokenLoader returns a token issued to a fixed client id
+type testTokenLoader struct {
+	clientID string
+}
+
+func (l testTokenLoader) LoadAccessToken(ctx context.Context, accessToken string) (oauth2.TokenInfo, error) {
+
+	return &models.Token{ClientID: l.clientID, Access: accessToken}, nil
+}
+
+func Test_checkOAuth2ClientCertificateKey(t *testing.T) {
+
+	p := newTestPolicy(t)
+
+	p.config.oauth = testTokenLoader{clientID: "partner"}
+	response := p.checkOAuth2(newAPIKeyRequest("", map[string]string{"authorization": "Bearer token"}, ""))
+	require.True(t, response.Authenticated, response.DeniedMessage)
+
+	// Token issued to key of client certificate is rejected
+	consumerKey := types.KeyClientCertificateFingerprintPrefix + "0123456789abcdef"
+	require.Nil(t, p.config.db.Key.UpdateByKey("default", &types.Key{
+		ConsumerKey: consumerKey,
+		AppID:       "app1",
+		Status:      "approved",
+		APIProducts: types.KeyAPIProductStatuses{
+			{Apiproduct: "orders", Status: "approved"},
+		},
+	}))
+	p.config.oauth = testTokenLoader{clientID: consumerKey}
+	r := newAPIKeyRequest("", map[string]string{"authorization": "Bearer token"}, "")
+	response = p.checkOAuth2(r)
+	require.True(t, response.Denied)
+	require.Equal(t, http.StatusForbidden, response.DeniedStatusCode)
+	require.Nil(t, r.Key)
+}
+
+func Test_ChainEvaluateShadowMutations(t *testing.T) {
+
+	config := newTestPolicy(t).config
+
+	// Policy modifying outcome and request directly instead of returning a response
+	require.NoError(t, Register(types.PolicyDefinition{
+		Name:  "testMutateOutcome",
+		Scope: types.PolicyScopeBoth,
+	}, func(p *Policy, request *request.Request) *Response {
+		p.ChainOutcome.Authenticated = false
+		p.ChainOutcome.UpstreamHeaders["x-shadow"] = "1"
+		request.HTTPRequest.Headers["x-shadow"] = "1"
+		request.Scopes = append(request.Scopes, "shadow")
+		return nil
+	}))
+
+	r := newAPIKeyRequest("", map[string]string{}, "apikey=partner&q=1")
+	r.Listener.Policies = types.Policies{
+		{Name: "checkAPIKey"},
+		{Name: "removeAPIKeyFromQP", Shadow: true},
+		{Name: "testMutateOutcome", Shadow: true},
+	}
+	outcome := NewChain(r, PolicyScopeVhost, config).Evaluate()
+	require.True(t, outcome.Authenticated, outcome.DeniedMessage)
+	require.Equal(t, "allow", outcome.UpstreamDynamicMetadata["shadow.removeAPIKeyFromQP"])
+
+	// Shadow policies did not modify request or outcome
+	require.Equal(t, "partner", r.QueryParameters.Get("apikey"))
+	require.NotContains(t, outcome.UpstreamHeaders, ":path")
+	require.NotContains(t, outcome.UpstreamHeaders, "x-shadow")
+	require.NotContains(t, r.HTTPRequest.Headers, "x-shadow")
+	require.Empty(t, r.Scopes)
+}
@@ -0,0 +1,119 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/request"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// EvaluateFunc evaluates a policy for a request, returns nil in case policy did not apply.
+// Policy parameters are available as p.Parameters.
+type EvaluateFunc func(p *Policy, request *request.Request) *Response
+
+var (
+	// evaluateFuncsMutex guards evaluateFuncs against concurrent registration
+	evaluateFuncsMutex sync.RWMutex
+
+	// evaluateFuncs holds evaluate function of each policy which can be used in a policy chain
+	evaluateFuncs = make(map[string]EvaluateFunc)
+
+	// builtinEvaluateFuncs holds evaluate function of each policy defined in pkg/types,
+	// definitions are kept there as managementserver validates policy chains using them
+	builtinEvaluateFuncs = map[string]EvaluateFunc{
+		"checkAPIKey":            (*Policy).checkAPIKey,
+		"checkOAuth2":            (*Policy).checkOAuth2,
+		"checkJWT":               (*Policy).checkJWT,
+		"checkHMACSignature":     (*Policy).checkHMACSignature,
+		"checkClientCertificate": (*Policy).checkClientCertificate,
+		"checkExpression":        (*Policy).checkExpression,
+		"removeAPIKeyFromQP": func(p *Policy, _ *request.Request) *Response {
+			return p.removeAPIKeyFromQP()
+		},
+		"removeAPIKeyFromHeader": func(p *Policy, _ *request.Request) *Response {
+			return p.removeAPIKeyFromHeader()
+		},
+		"lookupGeoIP": (*Policy).lookupGeoIP,
+		"qps": func(p *Policy, request *request.Request) *Response {
+			return policyQPS1(request, p.Parameters)
+		},
+		"sendAPIKey": func(_ *Policy, request *request.Request) *Response {
+			return policySendAPIKey(request)
+		},
+		"sendDeveloperEmail": func(_ *Policy, request *request.Request) *Response {
+			return policySendDeveloperEmail(request)
+		},
+		"sendDeveloperID": func(_ *Policy, request *request.Request) *Response {
+			return policySendDeveloperID(request)
+		},
+		"sendDeveloperAppName": func(_ *Policy, request *request.Request) *Response {
+			return policySendDeveloperAppName(request)
+		},
+		"sendDeveloperAppID": func(_ *Policy, request *request.Request) *Response {
+			return policySendDeveloperAppID(request)
+		},
+		"checkIPAccessList": func(p *Policy, request *request.Request) *Response {
+			return policyCheckIPAccessList(request, p.Parameters)
+		},
+		"checkReferer": func(p *Policy, request *request.Request) *Response {
+			return policycheckReferer(request, p.Parameters)
+		},
+	}
+)
+
+func init() {
+
+	registerBuiltins(types.GetPolicyDefinitions(), builtinEvaluateFuncs)
+}
+
+// registerBuiltins adds evaluate function of each built-in policy definition,
+// it panics in case a definition and evaluate functions do not match one-to-one
+func registerBuiltins(definitions []types.PolicyDefinition, builtins map[string]EvaluateFunc) {
+
+	if len(definitions) != len(builtins) {
+		panic(fmt.Sprintf("%d policy definitions do not match %d evaluate functions",
+			len(definitions), len(builtins)))
+	}
+	for _, definition := range definitions {
+		if _, found := builtins[definition.Name]; !found {
+			panic(fmt.Sprintf("no evaluate function for policy '%s'", definition.Name))
+		}
+	}
+	for name, evaluate := range builtins {
+		evaluateFuncs[name] = evaluate
+	}
+}
+
+// Register adds a policy so it can be used in policy chains. It is meant to be
+// invoked from init() of a package implementing a custom policy, which then needs
+// to be imported by both authserver and managementserver.
+func Register(definition types.PolicyDefinition, evaluate EvaluateFunc) error {
+
+	if evaluate == nil {
+		return errors.New("policy requires evaluate function")
+	}
+	if _, found := lookupEvaluateFunc(definition.Name); found {
+		return fmt.Errorf("policy '%s' already registered", definition.Name)
+	}
+	if err := types.RegisterPolicyDefinition(definition); err != nil {
+		return err
+	}
+
+	evaluateFuncsMutex.Lock()
+	defer evaluateFuncsMutex.Unlock()
+
+	evaluateFuncs[definition.Name] = evaluate
+	return nil
+}
+
+// lookupEvaluateFunc returns evaluate function of named policy
+func lookupEvaluateFunc(name string) (EvaluateFunc, bool) {
+
+	evaluateFuncsMutex.RLock()
+	defer evaluateFuncsMutex.RUnlock()
+
+	evaluate, found := evaluateFuncs[name]
+	return evaluate, found
+}
@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/request"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func Test_RegistryComplete(t *testing.T) {
+
+	definitionNames := make([]string, 0)
+	for _, definition := range types.GetPolicyDefinitions() {
+		// Skip policies registered by other tests
+		if !strings.HasPrefix(definition.Name, "test") {
+			definitionNames = append(definitionNames, definition.Name)
+		}
+	}
+	builtinNames := make([]string, 0, len(builtinEvaluateFuncs))
+	for name := range builtinEvaluateFuncs {
+		builtinNames = append(builtinNames, name)
+	}
+	// Built-in policies are defined in pkg/types, each needs an evaluate function
+	require.ElementsMatch(t, definitionNames, builtinNames)
+
+	for _, name := range definitionNames {
+		_, found := lookupEvaluateFunc(name)
+		require.True(t, found, "no evaluate function for policy '%s'", name)
+	}
+}
+
+func Test_registerBuiltins(t *testing.T) {
+
+	evaluate := func(_ *Policy, _ *request.Request) *Response { return nil }
+	definitions := []types.PolicyDefinition{{Name: "first"}, {Name: "second"}}
+
+	require.Panics(t, func() {
+		registerBuiltins(definitions, map[string]EvaluateFunc{"first": evaluate})
+	}, "missing evaluate function")
+	require.Panics(t, func() {
+		registerBuiltins(definitions, map[string]EvaluateFunc{"first": evaluate, "other": evaluate})
+	}, "evaluate function without definition")
+}
+
+func Test_Register(t *testing.T) {
+
+	definition := types.PolicyDefinition{
+		Name:  "testSendHeader",
+		Scope: types.PolicyScopeAPIProduct,
+		Parameters: []types.PolicyParameter{
+			{Name: "value", Type: types.PolicyParameterTypeString, Required: true},
+		},
+	}
+	sendHeader := func(p *Policy, _ *request.Request) *Response {
+		return &Response{
+			Headers: map[string]string{
+				"x-test": p.Parameters.GetAsString("value", ""),
+			},
+		}
+	}
+	require.NoError(t, Register(definition, sendHeader))
+	require.Error(t, Register(definition, sendHeader), "duplicate registration")
+	require.Error(t, Register(types.PolicyDefinition{
+		Name: "testNoEvaluate", Scope: types.PolicyScopeBoth}, nil))
+	require.Error(t, Register(types.PolicyDefinition{
+		Name: "testNoScope"}, sendHeader))
+
+	policies := types.Policies{
+		{Name: "testSendHeader", Parameters: types.Attributes{{Name: "value", Value: "42"}}},
+	}
+	require.NoError(t, policies.Validate(types.PolicyScopeAPIProduct))
+	require.Error(t, policies.Validate(types.PolicyScopeListener))
+
+	config := newTestPolicy(t).config
+	r := newAPIKeyRequest("", nil, "")
+	r.APIProduct = &types.APIProduct{Policies: policies}
+	outcome := NewChain(r, PolicyScopeAPIProduct, config).Evaluate()
+	require.Equal(t, "42", outcome.UpstreamHeaders["x-test"])
+
+	// Policy is not evaluated in a chain it cannot be used in
+	r.Listener.Policies = policies
+	outcome = NewChain(r, PolicyScopeVhost, config).Evaluate()
+	require.NotContains(t, outcome.UpstreamHeaders, "x-test")
+}
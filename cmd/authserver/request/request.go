@@ -1,11 +1,14 @@
 package request
 
 import (
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"net"
 	"net/url"
 
 	envoy_service_auth_v3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
@@ -21,12 +24,15 @@ type Request struct {
 	QueryParameters url.Values
 	ConsumerKey     *string
 	OauthToken      *string
-	Listener        *types.Listener
-	Organization    *types.Organization
-	Developer       *types.Developer
-	DeveloperApp    *types.DeveloperApp
-	Key             *types.Key
-	APIProduct      *types.APIProduct
+	Scopes          []string
+	// ClientCertificate is the validated certificate of a mutual TLS connection
+	ClientCertificate *x509.Certificate
+	Listener          *types.Listener
+	Organization      *types.Organization
+	Developer         *types.Developer
+	DeveloperApp      *types.DeveloperApp
+	Key               *types.Key
+	APIProduct        *types.APIProduct
 }
 
 // DecodeAuthRequest returns details of an Envoy auth request
@@ -48,6 +54,13 @@ func DecodeAuthRequest(req *envoy_service_auth_v3.CheckRequest) (*Request, error
 		return nil, errors.New("cannot parse query parameters")
 	}
 
+	// Envoy only provides peer certificate in case listener validates client certificates
+	if source := req.Attributes.Source; source != nil && source.Certificate != "" {
+		if r.ClientCertificate, err = parseCertificate(source.Certificate); err != nil {
+			return nil, errors.New("cannot parse client certificate")
+		}
+	}
+
 	// TODO/FIXME not sure if x-forwarded-proto the way to determine original tcp port used
 	if proto, ok := r.HTTPRequest.Headers["x-forwarded-proto"]; ok {
 		switch proto {
@@ -64,3 +77,48 @@ func DecodeAuthRequest(req *envoy_service_auth_v3.CheckRequest) (*Request, error
 
 	return r, nil
 }
+
+// Clone returns a copy of request which can be modified without affecting the original
+// request. Looked up entities are shared: these are replaced, not modified, by policies.
+func (r *Request) Clone() *Request {
+
+	clone := *r
+	if r.HTTPRequest != nil {
+		clone.HTTPRequest = proto.Clone(r.HTTPRequest).(*envoy_service_auth_v3.AttributeContext_HttpRequest)
+		// proto.Clone does not retain an empty map
+		if r.HTTPRequest.Headers != nil && clone.HTTPRequest.Headers == nil {
+			clone.HTTPRequest.Headers = make(map[string]string)
+		}
+	}
+	if r.IP != nil {
+		clone.IP = append(net.IP(nil), r.IP...)
+	}
+	if r.URL != nil {
+		u := *r.URL
+		clone.URL = &u
+	}
+	if r.QueryParameters != nil {
+		clone.QueryParameters = make(url.Values, len(r.QueryParameters))
+		for name, values := range r.QueryParameters {
+			clone.QueryParameters[name] = append([]string(nil), values...)
+		}
+	}
+	if r.Scopes != nil {
+		clone.Scopes = append([]string(nil), r.Scopes...)
+	}
+	return &clone
+}
+
+// parseCertificate parses an url encoded PEM certificate
+func parseCertificate(encodedCertificate string) (*x509.Certificate, error) {
+
+	certificate, err := url.QueryUnescape(encodedCertificate)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil {
+		return nil, errors.New("no pem encoded certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
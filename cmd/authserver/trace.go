@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	envoy_config_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_service_auth_v3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/metrics"
+	"github.com/erikbos/gatekeeper/cmd/authserver/policy"
+	"github.com/erikbos/gatekeeper/cmd/authserver/request"
+	"github.com/erikbos/gatekeeper/pkg/types"
+	"github.com/erikbos/gatekeeper/pkg/webadmin"
+)
+
+// Webadmin endpoint to trace authorization of a synthetic request
+const traceRequestPath = "/trace"
+
+// Steps of a request trace
+const (
+	traceStepDecode      = "decode"
+	traceStepVhostLookup = "vhostLookup"
+	traceStepPolicy      = "policy"
+	traceStepDecision    = "decision"
+)
+
+// traceRequest holds a synthetic request to trace
+type traceRequest struct {
+	Host     string
+	Port     int
+	Method   string
+	Path     string
+	Headers  map[string]string
+	SourceIP string
+}
+
+// checkTrace holds all steps of authorizing a request
+type checkTrace struct {
+	Steps    []traceStep
+	Entities traceEntities
+	Response json.RawMessage
+	// request being traced, entities are copied from it after authorization
+	request *request.Request
+}
+
+// traceStep holds one step of authorizing a request
+type traceStep struct {
+	Step    string
+	Message string              `json:",omitempty"`
+	Policy  *policy.PolicyTrace `json:",omitempty"`
+}
+
+// traceEntities holds all entities looked up while authorizing a request
+type traceEntities struct {
+	Listener     *types.Listener     `json:",omitempty"`
+	Organization *types.Organization `json:",omitempty"`
+	Developer    *types.Developer    `json:",omitempty"`
+	DeveloperApp *types.DeveloperApp `json:",omitempty"`
+	Key          *types.Key          `json:",omitempty"`
+	APIProduct   *types.APIProduct   `json:",omitempty"`
+}
+
+// requestTracer traces authorization of synthetic requests
+type requestTracer struct {
+	server *server
+	// metrics not exported, so traced requests do not show up in operational metrics
+	metrics *metrics.Metrics
+	// separate validator, so nonces of traced requests cannot cause replays of real requests
+	hmac *policy.HMACValidator
+}
+
+// newRequestTracer returns a tracer for synthetic requests
+func newRequestTracer(s *server, applicationName string) *requestTracer {
+
+	return &requestTracer{
+		server:  s,
+		metrics: metrics.NewUnexported(applicationName),
+		hmac:    policy.NewHMACValidator(s.config.HMAC),
+	}
+}
+
+// TraceRequest authorizes a synthetic request and returns all steps taken
+// (POST /trace)
+func (t *requestTracer) TraceRequest(c *gin.Context) {
+
+	var synthetic traceRequest
+	if err := c.ShouldBindJSON(&synthetic); err != nil {
+		webadmin.JSONMessageAndAbort(c, http.StatusBadRequest, err)
+		return
+	}
+	extauthzRequest, err := synthetic.toCheckRequest()
+	if err != nil {
+		webadmin.JSONMessageAndAbort(c, http.StatusBadRequest, err)
+		return
+	}
+
+	trace, err := t.trace(extauthzRequest)
+	if err != nil {
+		webadmin.JSONMessageAndAbort(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, trace)
+}
+
+// trace authorizes request the same way as extauthz does, without changing metrics
+func (t *requestTracer) trace(extauthzRequest *envoy_service_auth_v3.CheckRequest) (*checkTrace, error) {
+
+	tracingServer := *t.server
+	tracingServer.metrics = t.metrics
+	tracingServer.hmac = t.hmac
+
+	trace := &checkTrace{}
+	response, err := tracingServer.check(extauthzRequest, trace)
+	if err != nil {
+		return nil, err
+	}
+	trace.setEntities()
+	if trace.Response, err = protojson.Marshal(response); err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
+
+// toCheckRequest returns synthetic request as it would have been sent by Envoy
+func (r *traceRequest) toCheckRequest() (*envoy_service_auth_v3.CheckRequest, error) {
+
+	if r.Host == "" {
+		return nil, errors.New("host required")
+	}
+	if !strings.HasPrefix(r.Path, "/") {
+		return nil, errors.New("path should start with /")
+	}
+	if r.Method == "" {
+		r.Method = http.MethodGet
+	}
+	headers := make(map[string]string, len(r.Headers)+2)
+	for name, value := range r.Headers {
+		headers[strings.ToLower(name)] = value
+	}
+	// Authserver determines port based upon protocol used by client
+	switch r.Port {
+	case 0, 443:
+		headers["x-forwarded-proto"] = "https"
+	case 80:
+		headers["x-forwarded-proto"] = "http"
+	default:
+		return nil, errors.New("port should be 80 or 443")
+	}
+	sourceAddress := &envoy_config_core_v3.Address{}
+	if r.SourceIP != "" {
+		if net.ParseIP(r.SourceIP) == nil {
+			return nil, fmt.Errorf("cannot parse source ip '%s'", r.SourceIP)
+		}
+		headers["x-forwarded-for"] = r.SourceIP
+		sourceAddress.Address = &envoy_config_core_v3.Address_SocketAddress{
+			SocketAddress: &envoy_config_core_v3.SocketAddress{Address: r.SourceIP},
+		}
+	}
+	return &envoy_service_auth_v3.CheckRequest{
+		Attributes: &envoy_service_auth_v3.AttributeContext{
+			Source: &envoy_service_auth_v3.AttributeContext_Peer{
+				Address: sourceAddress,
+			},
+			Request: &envoy_service_auth_v3.AttributeContext_Request{
+				Http: &envoy_service_auth_v3.AttributeContext_HttpRequest{
+					Host:     r.Host,
+					Method:   r.Method,
+					Path:     r.Path,
+					Protocol: "HTTP/1.1",
+					Headers:  headers,
+				},
+			},
+		},
+	}, nil
+}
+
+// addStep records a step, trace can be nil in case request is not traced
+func (t *checkTrace) addStep(step, message string) {
+
+	if t == nil {
+		return
+	}
+	t.Steps = append(t.Steps, traceStep{
+		Step:    step,
+		Message: message,
+	})
+}
+
+// addPolicies records the evaluation of each policy of a policy chain
+func (t *checkTrace) addPolicies(policies []policy.PolicyTrace) {
+
+	if t == nil {
+		return
+	}
+	for i := range policies {
+		t.Steps = append(t.Steps, traceStep{
+			Step:   traceStepPolicy,
+			Policy: &policies[i],
+		})
+	}
+}
+
+// setRequest records request being traced
+func (t *checkTrace) setRequest(r *request.Request) {
+
+	if t == nil {
+		return
+	}
+	t.request = r
+}
+
+// setEntities copies all entities looked up for request being traced
+func (t *checkTrace) setEntities() {
+
+	if t == nil || t.request == nil {
+		return
+	}
+	t.Entities = traceEntities{
+		Listener:     t.request.Listener,
+		Organization: t.request.Organization,
+		Developer:    t.request.Developer,
+		DeveloperApp: t.request.DeveloperApp,
+		APIProduct:   t.request.APIProduct,
+	}
+	if t.request.Key != nil {
+		// Do not show secret of key
+		key := *t.request.Key
+		key.ConsumerSecret = ""
+		t.Entities.Key = &key
+	}
+}
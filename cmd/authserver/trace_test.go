@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/cmd/authserver/metrics"
+	"github.com/erikbos/gatekeeper/cmd/authserver/policy"
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/db/memory"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func newTestTraceServer(t *testing.T) *server {
+
+	database, err := memory.New(t.Name(), zap.NewNop())
+	require.NoError(t, err)
+	require.Nil(t, database.Organization.Update(&types.Organization{Name: "default"}, db.Precondition{}))
+	require.Nil(t, database.Listener.Update(&types.Listener{
+		Name:         "public",
+		VirtualHosts: []string{"api.example.com"},
+		Port:         443,
+		Policies: types.Policies{
+			{Name: "checkAPIKey"},
+			{Name: "qps", Condition: "POST /orders/**"},
+		},
+	}, db.Precondition{}))
+	require.Nil(t, database.APIProduct.Update("default", &types.APIProduct{
+		Name:         "orders",
+		APIResources: []string{"/orders/**"},
+	}, db.Precondition{}))
+	require.Nil(t, database.Developer.Update("default", &types.Developer{
+		DeveloperID: "dev1",
+		Email:       "joe@example.com",
+		Status:      "active",
+	}, db.Precondition{}))
+	require.Nil(t, database.DeveloperApp.Update("default", &types.DeveloperApp{
+		AppID:       "app1",
+		DeveloperID: "dev1",
+		Name:        "app",
+	}, db.Precondition{}))
+	require.Nil(t, database.Key.UpdateByKey("default", &types.Key{
+		ConsumerKey:    "partner",
+		ConsumerSecret: "secret",
+		AppID:          "app1",
+		Status:         "approved",
+		APIProducts: types.KeyAPIProductStatuses{
+			{Apiproduct: "orders", Status: "approved"},
+		},
+	}))
+
+	s := &server{
+		config:      &AuthServerConfig{},
+		db:          database,
+		vhosts:      newVhostMapping(database, "default", zap.NewNop()),
+		hmac:        policy.NewHMACValidator(policy.HMAC{}),
+		expressions: policy.NewExpressionCache(),
+		metrics:     metrics.NewUnexported(t.Name()),
+		logger:      zap.NewNop(),
+	}
+	s.vhosts.buildVhostMap()
+	return s
+}
+
+func traceTestRequest(t *testing.T, tracer *requestTracer, body string) (int, *checkTrace) {
+
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, traceRequestPath, strings.NewReader(body))
+	c.Request.Header.Set("content-type", "application/json")
+
+	tracer.TraceRequest(c)
+
+	var trace checkTrace
+	if recorder.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &trace))
+	}
+	return recorder.Code, &trace
+}
+
+func Test_TraceRequest(t *testing.T) {
+
+	s := newTestTraceServer(t)
+	tracer := newRequestTracer(s, t.Name())
+
+	statusCode, trace := traceTestRequest(t, tracer, `{
+		"host": "api.example.com", "method": "GET", "path": "/orders/42?apikey=partner",
+		"headers": { "User-Agent": "curl" }, "sourceIP": "192.0.2.1" }`)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Len(t, trace.Steps, 4)
+	require.Equal(t, traceStepVhostLookup, trace.Steps[0].Step)
+	require.Contains(t, trace.Steps[0].Message, "listener public")
+	require.Equal(t, "checkAPIKey", trace.Steps[1].Policy.Policy)
+	require.True(t, trace.Steps[1].Policy.Response.Authenticated)
+	require.Equal(t, "qps", trace.Steps[2].Policy.Policy)
+	require.Equal(t, "request does not match condition", trace.Steps[2].Policy.Skipped)
+	require.Equal(t, traceStepDecision, trace.Steps[3].Step)
+	require.Equal(t, "request allowed", trace.Steps[3].Message)
+	require.Equal(t, "app", trace.Entities.DeveloperApp.Name)
+	require.Equal(t, "orders", trace.Entities.APIProduct.Name)
+	require.Equal(t, "partner", trace.Entities.Key.ConsumerKey)
+	require.Empty(t, trace.Entities.Key.ConsumerSecret)
+	require.Contains(t, string(trace.Response), "okResponse")
+
+	// Unknown apikey is rejected by checkAPIKey
+	statusCode, trace = traceTestRequest(t, tracer, `{
+		"host": "api.example.com", "path": "/orders/42?apikey=unknown" }`)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.True(t, trace.Steps[1].Policy.Response.Denied)
+	require.Equal(t, "request rejected: cannot find apikey", trace.Steps[len(trace.Steps)-1].Message)
+	require.Contains(t, string(trace.Response), "deniedResponse")
+
+	// Unknown vhost
+	statusCode, trace = traceTestRequest(t, tracer, `{ "host": "www.example.com", "path": "/" }`)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Len(t, trace.Steps, 1)
+	require.Equal(t, traceStepVhostLookup, trace.Steps[0].Step)
+	require.Nil(t, trace.Entities.Listener)
+
+	// Invalid synthetic requests
+	for _, body := range []string{
+		`{ "path": "/" }`,
+		`{ "host": "api.example.com", "path": "orders" }`,
+		`{ "host": "api.example.com", "path": "/", "port": 8080 }`,
+		`{ "host": "api.example.com", "path": "/", "sourceIP": "somewhere" }`,
+		`{ "host": `,
+	} {
+		statusCode, _ = traceTestRequest(t, tracer, body)
+		require.Equal(t, http.StatusBadRequest, statusCode, body)
+	}
+
+	// Traced requests are not counted in metrics of server
+	require.Equal(t, 0.0, testutil.ToFloat64(
+		s.metrics.PolicyHits.WithLabelValues(policy.PolicyScopeVhost, "checkAPIKey", "false")))
+}
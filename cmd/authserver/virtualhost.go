@@ -50,7 +50,9 @@ func (v *vhostMapping) WaitFor(entityNotifications chan db.EntityChangeNotificat
 
 	for changedEntity := range entityNotifications {
 		v.logger.Info("Database change notify received",
-			zap.String("entity", changedEntity.Resource))
+			zap.String("entity", changedEntity.Resource),
+			zap.String("name", changedEntity.Name),
+			zap.String("operation", changedEntity.Operation))
 
 		if changedEntity.Resource == types.TypeListenerName ||
 			changedEntity.Resource == types.TypeRouteName {
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// headerFlags holds request headers provided as repeated -header "name: value" flags
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+
+	headers := make([]string, 0, len(h))
+	for name, value := range h {
+		headers = append(headers, name+": "+value)
+	}
+	return strings.Join(headers, ", ")
+}
+
+func (h headerFlags) Set(value string) error {
+
+	name, headerValue, found := strings.Cut(value, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header should be formatted as \"name: value\"")
+	}
+	h[strings.TrimSpace(name)] = strings.TrimSpace(headerValue)
+	return nil
+}
+
+// traceRequest is a synthetic request to be traced by authserver
+type traceRequest struct {
+	Host     string            `json:"host"`
+	Port     int               `json:"port"`
+	Method   string            `json:"method"`
+	Path     string            `json:"path"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	SourceIP string            `json:"sourceIP,omitempty"`
+}
+
+func main() {
+
+	headers := make(headerFlags)
+	authserver := flag.String("authserver", "http://localhost:7777", "Address of authserver webadmin")
+	host := flag.String("host", "", "Host of request")
+	port := flag.Int("port", 443, "Port of request, 80 or 443")
+	method := flag.String("method", http.MethodGet, "HTTP method of request")
+	path := flag.String("path", "/", "Path of request, including query parameters")
+	sourceIP := flag.String("ip", "", "Source ip address of request")
+	flag.Var(headers, "header", "Request header as \"name: value\", can be repeated")
+	flag.Parse()
+
+	if *host == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	body, err := json.Marshal(traceRequest{
+		Host:     *host,
+		Port:     *port,
+		Method:   *method,
+		Path:     *path,
+		Headers:  headers,
+		SourceIP: *sourceIP,
+	})
+	if err != nil {
+		log.Fatalf("Cannot encode request: %s", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	response, err := client.Post(strings.TrimSuffix(*authserver, "/")+"/trace",
+		"application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Fatalf("Cannot trace request: %s", err)
+	}
+	defer response.Body.Close()
+
+	trace, err := io.ReadAll(response.Body)
+	if err != nil {
+		log.Fatalf("Cannot read trace: %s", err)
+	}
+	fmt.Println(string(trace))
+	if response.StatusCode != http.StatusOK {
+		os.Exit(1)
+	}
+}
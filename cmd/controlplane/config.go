@@ -7,7 +7,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/erikbos/gatekeeper/pkg/config"
-	"github.com/erikbos/gatekeeper/pkg/db/cassandra"
+	"github.com/erikbos/gatekeeper/pkg/db/backend"
 	"github.com/erikbos/gatekeeper/pkg/shared"
 	"github.com/erikbos/gatekeeper/pkg/webadmin"
 )
@@ -22,10 +22,10 @@ const (
 
 // ControlPlaneConfig contains our startup configuration data
 type ControlPlaneConfig struct {
-	Logger   shared.Logger            // log configuration of application
-	WebAdmin webadmin.Config          // Admin web interface configuration
-	Database cassandra.DatabaseConfig // Database configuration
-	XDS      xdsConfig                // Control plane configuration
+	Logger   shared.Logger   // log configuration of application
+	WebAdmin webadmin.Config // Admin web interface configuration
+	Database backend.Config  // Database configuration
+	XDS      xdsConfig       // Control plane configuration
 }
 
 const (
@@ -38,6 +38,7 @@ func (config *ControlPlaneConfig) String() string {
 	// We must remove db password from configuration before showing
 	redactedConfig := config
 	redactedConfig.Database.Password = "[redacted]"
+	redactedConfig.Database.SQL.DSN = "[redacted]"
 
 	configAsYAML, err := yaml.Marshal(redactedConfig)
 	if err != nil {
@@ -165,11 +165,35 @@ func (s *server) buildFilterChainEntry(v types.Listener, configuredListener *env
 	downStreamTLSConfig := &envoy_tls.DownstreamTlsContext{
 		CommonTlsContext: buildCommonTLSContext(v.Name, v.Attributes),
 	}
+	buildClientCertificateValidation(v, downStreamTLSConfig)
 	FilterChainEntry.TransportSocket = buildTransportSocket(v.Name, downStreamTLSConfig)
 
 	return FilterChainEntry
 }
 
+// buildClientCertificateValidation enables validation of client certificates
+// in case listener has a CA to validate them with
+func buildClientCertificateValidation(listener types.Listener, tlsContext *envoy_tls.DownstreamTlsContext) {
+
+	clientCA, err := listener.Attributes.Get(types.AttributeTLSClientCertificateCA)
+	if err != nil || clientCA == "" {
+		return
+	}
+	tlsContext.CommonTlsContext.ValidationContextType = &envoy_tls.CommonTlsContext_ValidationContext{
+		ValidationContext: &envoy_tls.CertificateValidationContext{
+			TrustedCa: &envoy_core.DataSource{
+				Specifier: &envoy_core.DataSource_InlineString{
+					InlineString: clientCA,
+				},
+			},
+		},
+	}
+	// Without requiring a certificate, clients without one can still use other authentication methods
+	if listener.Attributes.GetAsString(types.AttributeTLSClientCertificateRequired, "") == types.AttributeValueTrue {
+		tlsContext.RequireClientCertificate = protoBool(true)
+	}
+}
+
 func (s *server) buildConnectionManager(listener types.Listener) *envoy_hcm.HttpConnectionManager {
 
 	connectionManager := &envoy_hcm.HttpConnectionManager{
@@ -258,6 +282,11 @@ func (s *server) buildHTTPFilterExtAuthzConfig(listener types.Listener) *anypb.A
 		TransportApiVersion: envoy_core.ApiVersion_V3,
 	}
 
+	// Send client certificate to authserver, so it can be used for authentication
+	if clientCA, err := listener.Attributes.Get(types.AttributeTLSClientCertificateCA); err == nil && clientCA != "" {
+		extAuthz.IncludePeerCertificate = true
+	}
+
 	requestBodySize := listener.Attributes.GetAsUInt32(types.AttributeExtAuthzRequestBodySize, 0)
 	if requestBodySize > 0 {
 		extAuthz.WithRequestBody = &envoy_filter_extauthz.BufferSettings{
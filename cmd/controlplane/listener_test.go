@@ -12,6 +12,7 @@ import (
 	extauthz "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
 	ratelimit "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ratelimit/v3"
 	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	tls "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
@@ -338,6 +339,29 @@ func Test_buildHTTPFilterExtAuthzConfig(t *testing.T) {
 				TransportApiVersion: core.ApiVersion_V3,
 			}),
 		},
+		{
+			name: "BuildAuthz 3 (client certificate)",
+			listener: types.Listener{
+				Attributes: types.Attributes{
+					{
+						Name:  types.AttributeExtAuthzCluster,
+						Value: "authz_cluster",
+					},
+					{
+						Name:  types.AttributeTLSClientCertificateCA,
+						Value: "-----BEGIN CERTIFICATE-----",
+					},
+				},
+			},
+			expected: mustMarshalAny(&extauthz.ExtAuthz{
+				Services: &extauthz.ExtAuthz_GrpcService{
+					GrpcService: buildGRPCService("authz_cluster",
+						defaultAuthenticationTimeout),
+				},
+				IncludePeerCertificate: true,
+				TransportApiVersion:    core.ApiVersion_V3,
+			}),
+		},
 		{
 			name: "BuildAuthz 2 (not clustername)",
 			listener: types.Listener{
@@ -653,3 +677,80 @@ func Test_buildHTTP2ProtocolOptions(t *testing.T) {
 			buildHTTP2ProtocolOptions(test.listener), test.name)
 	}
 }
+
+func Test_buildClientCertificateValidation(t *testing.T) {
+
+	trustedCA := &tls.CommonTlsContext_ValidationContext{
+		ValidationContext: &tls.CertificateValidationContext{
+			TrustedCa: &core.DataSource{
+				Specifier: &core.DataSource_InlineString{
+					InlineString: "-----BEGIN CERTIFICATE-----",
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name     string
+		listener types.Listener
+		expected *tls.DownstreamTlsContext
+	}{
+		{
+			name: "No client certificate CA",
+			listener: types.Listener{
+				Attributes: types.Attributes{
+					{
+						Name:  types.AttributeTLSClientCertificateRequired,
+						Value: "true",
+					},
+				},
+			},
+			expected: &tls.DownstreamTlsContext{
+				CommonTlsContext: &tls.CommonTlsContext{},
+			},
+		},
+		{
+			name: "Optional client certificate",
+			listener: types.Listener{
+				Attributes: types.Attributes{
+					{
+						Name:  types.AttributeTLSClientCertificateCA,
+						Value: "-----BEGIN CERTIFICATE-----",
+					},
+				},
+			},
+			expected: &tls.DownstreamTlsContext{
+				CommonTlsContext: &tls.CommonTlsContext{
+					ValidationContextType: trustedCA,
+				},
+			},
+		},
+		{
+			name: "Required client certificate",
+			listener: types.Listener{
+				Attributes: types.Attributes{
+					{
+						Name:  types.AttributeTLSClientCertificateCA,
+						Value: "-----BEGIN CERTIFICATE-----",
+					},
+					{
+						Name:  types.AttributeTLSClientCertificateRequired,
+						Value: "true",
+					},
+				},
+			},
+			expected: &tls.DownstreamTlsContext{
+				CommonTlsContext: &tls.CommonTlsContext{
+					ValidationContextType: trustedCA,
+				},
+				RequireClientCertificate: protoBool(true),
+			},
+		},
+	}
+	for _, test := range tests {
+		tlsContext := &tls.DownstreamTlsContext{
+			CommonTlsContext: &tls.CommonTlsContext{},
+		}
+		buildClientCertificateValidation(test.listener, tlsContext)
+		equalf(t, test.expected, tlsContext, test.name)
+	}
+}
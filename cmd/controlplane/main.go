@@ -3,12 +3,13 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
 
 	"go.uber.org/zap"
 
 	"github.com/erikbos/gatekeeper/cmd/controlplane/metrics"
 	"github.com/erikbos/gatekeeper/pkg/db"
-	"github.com/erikbos/gatekeeper/pkg/db/cassandra"
+	"github.com/erikbos/gatekeeper/pkg/db/backend"
 	"github.com/erikbos/gatekeeper/pkg/shared"
 	"github.com/erikbos/gatekeeper/pkg/webadmin"
 )
@@ -31,6 +32,7 @@ func main() {
 	const applicationName = "controlplane"
 
 	filename := flag.String("config", "controlplane-config.yaml", "Configuration filename")
+	migrate := flag.String("migrate", "", "Run database schema migration command (up / status) and exit")
 	flag.Parse()
 
 	var s server
@@ -48,10 +50,17 @@ func main() {
 		zap.String("version", version),
 		zap.String("buildtime", buildTime))
 
+	if *migrate != "" {
+		if err := backend.Migrate(s.config.Database, *migrate, 1, s.logger); err != nil {
+			s.logger.Fatal("Database schema migration failed", zap.Error(err))
+		}
+		os.Exit(0)
+	}
+
 	s.metrics = metrics.New(applicationName)
 	s.metrics.RegisterWithPrometheus()
 
-	if s.db, err = cassandra.New(s.config.Database, applicationName, s.logger, s.config.Database.InitKeyspaces, 1); err != nil {
+	if s.db, err = backend.New(s.config.Database, applicationName, s.logger, s.config.Database.InitKeyspaces, 1); err != nil {
 		s.logger.Fatal("Database connect failed", zap.Error(err))
 	}
 
@@ -69,7 +69,9 @@ func (x *XDS) Start() {
 		select {
 		case n := <-x.notify:
 			x.server.logger.Info("Database change notify received",
-				zap.String("entity", n.Resource))
+				zap.String("entity", n.Resource),
+				zap.String("name", n.Name),
+				zap.String("operation", n.Operation))
 
 			// Create snapshot given we got a notification a entity was updated
 			x.CreateNewSnapshot(streamCallbacks)
@@ -8,7 +8,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/erikbos/gatekeeper/pkg/db"
-	"github.com/erikbos/gatekeeper/pkg/db/cassandra"
+	"github.com/erikbos/gatekeeper/pkg/db/backend"
 	"github.com/erikbos/gatekeeper/pkg/shared"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
@@ -17,15 +17,16 @@ type (
 	// Config holds configuration of an auditlog
 	Config struct {
 		// Database configuration
-		Database cassandra.DatabaseConfig `yaml:"database"`
+		Database backend.Config `yaml:"database"`
 		// audit log configuration
 		Logger shared.Logger `yaml:"logging"`
 	}
 
 	// Audit is a new auditlogger to be used by service layer to log changes to entities
 	Audit struct {
-		db     *db.Database
-		logger *zap.Logger
+		db        *db.Database
+		changeLog db.ChangeLog
+		logger    *zap.Logger
 	}
 
 	// Details of organization, developer and app this audit change applies to
@@ -48,11 +49,14 @@ type (
 )
 
 // New returns a new Auditlog instance which audit events to logfile and/or audit database.
-func New(database *db.Database, logger *zap.Logger) *Audit {
+// Each change is also written to changelog (if not nil) so other instances can invalidate
+// their cached copy of the changed entity.
+func New(database *db.Database, changeLog db.ChangeLog, logger *zap.Logger) *Audit {
 
 	return &Audit{
-		db:     database,
-		logger: logger,
+		db:        database,
+		changeLog: changeLog,
+		logger:    logger,
 	}
 }
 
@@ -115,9 +119,23 @@ func (al *Audit) log(aType auditType, entityType, entityID string, oldValue, new
 	if err := al.db.Audit.Write(auditEntry); err != nil {
 		al.logger.Error("m", zap.Any("error", err))
 	}
+
+	if al.changeLog != nil {
+		change := &types.Change{
+			ID:           auditEntry.ID,
+			Timestamp:    auditEntry.Timestamp,
+			Operation:    aType.String(),
+			EntityType:   entityType,
+			Organization: e.Organization,
+			EntityID:     entityID,
+		}
+		if err := al.changeLog.Write(change); err != nil {
+			al.logger.Error("cannot write changelog", zap.Error(err))
+		}
+	}
 }
 
-// Clear sensitive fields such as User.Password that we do not want end up in the changelog.
+// Clear sensitive fields such as User.Password and OAuth token values that we do not want end up in the changelog.
 // Returns new struct in case fields have been cleared to not modify original.
 func clearSensitiveFields(m interface{}) interface{} {
 
@@ -128,6 +146,15 @@ func clearSensitiveFields(m interface{}) interface{} {
 		scrubbedUser.Password = ""
 
 		return scrubbedUser
+	case types.OAuthAccessToken:
+		// Copy & empty token values
+		scrubbedToken := t
+		scrubbedToken.Access = ""
+		scrubbedToken.Refresh = ""
+		scrubbedToken.Code = ""
+		scrubbedToken.CodeChallenge = ""
+
+		return scrubbedToken
 	default:
 		// Do not modify other types, return as is
 		return m
@@ -35,6 +35,18 @@ func Test_clearSensitiveFields(t *testing.T) {
 				Password: "",
 			},
 		},
+		{
+			name: "Modified OAuth token",
+			value: types.OAuthAccessToken{
+				ClientID: "key",
+				Access:   "secret",
+				Refresh:  "secret",
+				Code:     "secret",
+			},
+			expected: types.OAuthAccessToken{
+				ClientID: "key",
+			},
+		},
 	}
 	for _, test := range tests {
 		require.Equalf(t, test.expected, clearSensitiveFields(test.value), test.name)
@@ -6,8 +6,8 @@ import (
 
 	"github.com/erikbos/gatekeeper/cmd/managementserver/audit"
 	"github.com/erikbos/gatekeeper/pkg/config"
+	"github.com/erikbos/gatekeeper/pkg/db/backend"
 	"github.com/erikbos/gatekeeper/pkg/db/cache"
-	"github.com/erikbos/gatekeeper/pkg/db/cassandra"
 	"github.com/erikbos/gatekeeper/pkg/shared"
 	"github.com/erikbos/gatekeeper/pkg/webadmin"
 )
@@ -25,11 +25,11 @@ const (
 
 // ManagementServerConfig contains our startup configuration data
 type ManagementServerConfig struct {
-	Logger   shared.Logger            // log configuration of application
-	WebAdmin webadmin.Config          // Admin web interface configuration
-	Audit    audit.Config             // Audit configuration
-	Database cassandra.DatabaseConfig // Database configuration
-	Cache    cache.Config             // Cache configuration
+	Logger   shared.Logger   // log configuration of application
+	WebAdmin webadmin.Config // Admin web interface configuration
+	Audit    audit.Config    // Audit configuration
+	Database backend.Config  // Database configuration
+	Cache    cache.Config    // Cache configuration
 }
 
 // String() return our startup configuration as YAML
@@ -38,6 +38,7 @@ func (config *ManagementServerConfig) String() string {
 	// We must remove db password from configuration struct before showing
 	redactedConfig := config
 	redactedConfig.Database.Password = "[redacted]"
+	redactedConfig.Database.SQL.DSN = "[redacted]"
 
 	configAsYAML, err := yaml.Marshal(redactedConfig)
 	if err != nil {
@@ -13,11 +13,22 @@ import (
 func (h *Handler) GetV1OrganizationsOrganizationNameApiproducts(c *gin.Context,
 	organizationName OrganizationName, params GetV1OrganizationsOrganizationNameApiproductsParams) {
 
-	apiproducts, err := h.service.APIProduct.GetAll(string(organizationName))
+	listParams, e := listParams{
+		pageSize:         params.PageSize,
+		pageToken:        params.PageToken,
+		lastModifiedFrom: params.LastModifiedFrom,
+		lastModifiedTo:   params.LastModifiedTo,
+	}.toDBListParams()
+	if e != nil {
+		responseErrorBadRequest(c, e)
+		return
+	}
+	apiproducts, nextPageToken, err := h.service.APIProduct.List(string(organizationName), listParams)
 	if err != nil {
 		responseError(c, err)
 		return
 	}
+	setNextPageToken(c, nextPageToken)
 	// Do we have to return full developer details?
 	if params.Expand != nil && *params.Expand {
 		h.responseAPIproducts(c, apiproducts)
@@ -56,7 +67,7 @@ func (h *Handler) DeleteV1OrganizationsOrganizationNameApiproductsApiproductName
 		return
 	}
 	if err := h.service.APIProduct.Delete(
-		string(organizationName), string(apiproductName), h.who(c)); err != nil {
+		string(organizationName), string(apiproductName), ifMatch(c), h.who(c)); err != nil {
 		responseError(c, err)
 		return
 	}
@@ -88,7 +99,35 @@ func (h *Handler) PostV1OrganizationsOrganizationNameApiproductsApiproductName(
 		return
 	}
 	updatedAPIProduct := fromAPIproduct(receivedAPIProduct)
-	storedAPIProduct, err := h.service.APIProduct.Update(string(organizationName), updatedAPIProduct, h.who(c))
+	storedAPIProduct, err := h.service.APIProduct.Update(string(organizationName), updatedAPIProduct, ifMatch(c), h.who(c))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseAPIproductUpdated(c, storedAPIProduct)
+}
+
+// partially updates an apiproduct
+// (PATCH /v1/organizations/{organization_name}/apiproducts/{apiproduct_name})
+func (h *Handler) PatchV1OrganizationsOrganizationNameApiproductsApiproductName(
+	c *gin.Context, organizationName OrganizationName, apiproductName ApiproductName) {
+
+	apiproduct, err := h.service.APIProduct.Get(string(organizationName), string(apiproductName))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	patchedAPIProduct := h.ToAPIproductResponse(apiproduct)
+	if err := applyMergePatch(c, &patchedAPIProduct); err != nil {
+		responseErrorBadRequest(c, err)
+		return
+	}
+	updatedAPIProduct := fromAPIproduct(patchedAPIProduct)
+	if updatedAPIProduct.Name != string(apiproductName) {
+		responseErrorNameValueMisMatch(c)
+		return
+	}
+	storedAPIProduct, err := h.service.APIProduct.Update(string(organizationName), updatedAPIProduct, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -125,7 +164,7 @@ func (h *Handler) PostV1OrganizationsOrganizationNameApiproductsApiproductNameAt
 		return
 	}
 	apiproduct.Attributes = fromAttributesRequest(receivedAttributes.Attribute)
-	storedAPIProduct, err := h.service.APIProduct.Update(string(organizationName), *apiproduct, h.who(c))
+	storedAPIProduct, err := h.service.APIProduct.Update(string(organizationName), *apiproduct, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -148,7 +187,7 @@ func (h *Handler) DeleteV1OrganizationsOrganizationNameApiproductsApiproductName
 		responseError(c, err)
 		return
 	}
-	_, err = h.service.APIProduct.Update(string(organizationName), *apiproduct, h.who(c))
+	_, err = h.service.APIProduct.Update(string(organizationName), *apiproduct, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -194,7 +233,7 @@ func (h *Handler) PostV1OrganizationsOrganizationNameApiproductsApiproductNameAt
 		responseError(c, err)
 		return
 	}
-	_, err = h.service.APIProduct.Update(string(organizationName), *apiproduct, h.who(c))
+	_, err = h.service.APIProduct.Update(string(organizationName), *apiproduct, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -226,16 +265,19 @@ func (h *Handler) responseAPIproducts(c *gin.Context, apiproducts types.APIProdu
 
 func (h *Handler) responseAPIproduct(c *gin.Context, apiproduct *types.APIProduct) {
 
+	setETag(c, apiproduct.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToAPIproductResponse(apiproduct))
 }
 
 func (h *Handler) responseAPIProductCreated(c *gin.Context, apiproduct *types.APIProduct) {
 
+	setETag(c, apiproduct.LastModifiedAt)
 	c.IndentedJSON(http.StatusCreated, h.ToAPIproductResponse(apiproduct))
 }
 
 func (h *Handler) responseAPIproductUpdated(c *gin.Context, apiproduct *types.APIProduct) {
 
+	setETag(c, apiproduct.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToAPIproductResponse(apiproduct))
 }
 
@@ -253,6 +295,7 @@ func (h *Handler) ToAPIproductResponse(a *types.APIProduct) APIProduct {
 		LastModifiedBy: &a.LastModifiedBy,
 		LastModifiedAt: &a.LastModifiedAt,
 		Name:           &a.Name,
+		Policies:       toPoliciesResponse(a.Policies),
 	}
 	if a.APIResources != nil {
 		p.ApiResources = &a.APIResources
@@ -294,6 +337,9 @@ func fromAPIproduct(p APIProduct) types.APIProduct {
 	if p.ApiResources != nil {
 		product.APIResources = *p.ApiResources
 	}
+	if p.Policies != nil {
+		product.Policies = fromPoliciesRequest(p.Policies)
+	}
 	if p.LastModifiedBy != nil {
 		product.LastModifiedBy = *p.LastModifiedBy
 	}
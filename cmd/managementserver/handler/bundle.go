@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+
+	"github.com/erikbos/gatekeeper/cmd/managementserver/service"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// Bundle format which can be requested instead of json
+	bundleFormatYAML = "yaml"
+	// Content type of yaml formatted bundle
+	contentTypeYAML = "application/yaml"
+)
+
+// exports organization with all its entities
+// (GET /v1/organizations/{organization_name}/export)
+func (h *Handler) GetV1OrganizationsOrganizationNameExport(c *gin.Context,
+	organizationName OrganizationName, params GetV1OrganizationsOrganizationNameExportParams) {
+
+	includeSecrets := params.IncludeSecrets != nil && *params.IncludeSecrets
+	bundle, err := h.service.Bundle.ExportOrganization(string(organizationName), includeSecrets)
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseBundle(c, bundle, params.Format != nil && *params.Format == bundleFormatYAML)
+}
+
+// exports all listeners, routes and clusters
+// (GET /v1/export)
+func (h *Handler) GetV1Export(c *gin.Context, params GetV1ExportParams) {
+
+	bundle, err := h.service.Bundle.ExportGlobal()
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseBundle(c, bundle, params.Format != nil && *params.Format == bundleFormatYAML)
+}
+
+// imports a bundle
+// (POST /v1/import)
+func (h *Handler) PostV1Import(c *gin.Context, params PostV1ImportParams) {
+
+	var receivedBundle ConfigurationBundle
+	if err := bindBundle(c, &receivedBundle); err != nil {
+		responseErrorBadRequest(c, err)
+		return
+	}
+	options := service.ImportOptions{
+		Conflict: service.ConflictFail,
+	}
+	if params.Organization != nil {
+		options.Organization = *params.Organization
+	}
+	if params.DryRun != nil {
+		options.DryRun = *params.DryRun
+	}
+	if params.Conflict != nil {
+		options.Conflict = service.ConflictStrategy(*params.Conflict)
+	}
+	results, err := h.service.Bundle.Import(fromConfigurationBundle(receivedBundle), options, h.who(c))
+	if err != nil && len(results) != 0 {
+		responseImportError(c, err, results)
+		return
+	}
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, ToImportResultResponse(results, options.DryRun))
+}
+
+// bindBundle decodes json or yaml formatted bundle in request body
+func bindBundle(c *gin.Context, bundle *ConfigurationBundle) error {
+
+	switch c.ContentType() {
+	case contentTypeYAML, "application/x-yaml", "text/yaml":
+		body, err := c.GetRawData()
+		if err != nil {
+			return err
+		}
+		// Convert to json first as generated types only have json field tags
+		var decoded interface{}
+		if err := yaml.Unmarshal(body, &decoded); err != nil {
+			return err
+		}
+		converted, err := json.Marshal(decoded)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(converted, bundle)
+	default:
+		return c.ShouldBindJSON(bundle)
+	}
+}
+
+// Responses
+
+// Returns bundle as json or yaml
+func (h *Handler) responseBundle(c *gin.Context, bundle *service.ConfigurationBundle, asYAML bool) {
+
+	response := h.ToConfigurationBundleResponse(bundle)
+	if !asYAML {
+		c.IndentedJSON(http.StatusOK, response)
+		return
+	}
+	out, err := marshalYAML(response)
+	if err != nil {
+		responseErrorBadRequest(c, err)
+		return
+	}
+	c.Data(http.StatusOK, contentTypeYAML, out)
+}
+
+// marshalYAML returns yaml encoding of v, it is converted to json first
+// as generated types only have json field tags
+func marshalYAML(v interface{}) ([]byte, error) {
+
+	converted, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(converted))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(yamlNumbers(decoded))
+}
+
+// yamlNumbers replaces json numbers in decoded json by integers or floats,
+// as yaml would otherwise encode large integers such as timestamps in exponent notation
+func yamlNumbers(v interface{}) interface{} {
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k := range value {
+			value[k] = yamlNumbers(value[k])
+		}
+	case []interface{}:
+		for i := range value {
+			value[i] = yamlNumbers(value[i])
+		}
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	}
+	return v
+}
+
+// type conversion
+
+func (h *Handler) ToConfigurationBundleResponse(b *service.ConfigurationBundle) ConfigurationBundle {
+
+	bundle := ConfigurationBundle{}
+	if b.Organization != nil {
+		organization := h.ToOrganizationResponse(b.Organization)
+		bundle.Organization = &organization
+	}
+	if b.Developers != nil {
+		developers := make([]Developer, len(b.Developers))
+		for i := range b.Developers {
+			developers[i] = h.ToDeveloperResponse(&b.Developers[i])
+		}
+		bundle.Developers = &developers
+	}
+	if b.DeveloperApps != nil {
+		apps := make([]Application, len(b.DeveloperApps))
+		for i := range b.DeveloperApps {
+			apps[i] = ToApplicationResponse(&b.DeveloperApps[i], nil)
+		}
+		bundle.Applications = &apps
+	}
+	if b.Keys != nil {
+		keys := make([]Key, len(b.Keys))
+		for i := range b.Keys {
+			keys[i] = ToKeyResponse(&b.Keys[i])
+			// Secret is absent in case export did not include secrets
+			if b.Keys[i].ConsumerSecret == "" {
+				keys[i].ConsumerSecret = nil
+			}
+		}
+		bundle.Keys = &keys
+	}
+	if b.APIProducts != nil {
+		apiproducts := make([]APIProduct, len(b.APIProducts))
+		for i := range b.APIProducts {
+			apiproducts[i] = h.ToAPIproductResponse(&b.APIProducts[i])
+		}
+		bundle.Apiproducts = &apiproducts
+	}
+	if b.Companies != nil {
+		companies := make([]Company, len(b.Companies))
+		for i := range b.Companies {
+			companies[i] = h.ToCompanyResponse(&b.Companies[i])
+		}
+		bundle.Companies = &companies
+	}
+	if b.Listeners != nil {
+		listeners := make([]Listener, len(b.Listeners))
+		for i := range b.Listeners {
+			listeners[i] = h.ToListenerResponse(&b.Listeners[i])
+		}
+		bundle.Listeners = &listeners
+	}
+	if b.Routes != nil {
+		routes := make([]Route, len(b.Routes))
+		for i := range b.Routes {
+			routes[i] = h.ToRouteResponse(&b.Routes[i])
+		}
+		bundle.Routes = &routes
+	}
+	if b.Clusters != nil {
+		clusters := make([]Cluster, len(b.Clusters))
+		for i := range b.Clusters {
+			clusters[i] = h.ToClusterResponse(&b.Clusters[i])
+		}
+		bundle.Clusters = &clusters
+	}
+	return bundle
+}
+
+// responseImportError returns error of an import which failed after some entities were stored
+func responseImportError(c *gin.Context, e types.Error, results service.ImportResults) {
+
+	code := types.HTTPStatusCode(e)
+	msg := e.ErrorDetails()
+
+	// Save internal error details in request context so we can write it in access log later
+	_ = c.Error(errors.New(msg))
+
+	c.IndentedJSON(code, ImportError{
+		Code:     &code,
+		Message:  &msg,
+		Entities: toImportedEntitiesResponse(results),
+	})
+	c.Abort()
+}
+
+func ToImportResultResponse(results service.ImportResults, dryRun bool) ImportResult {
+
+	return ImportResult{
+		DryRun:   &dryRun,
+		Entities: toImportedEntitiesResponse(results),
+	}
+}
+
+func toImportedEntitiesResponse(results service.ImportResults) *[]ImportedEntity {
+
+	entities := make([]ImportedEntity, len(results))
+	for i := range results {
+		entities[i] = ImportedEntity{
+			Type:   &results[i].EntityType,
+			Name:   &results[i].Name,
+			Action: &results[i].Action,
+		}
+	}
+	return &entities
+}
+
+func fromConfigurationBundle(b ConfigurationBundle) service.ConfigurationBundle {
+
+	bundle := service.ConfigurationBundle{}
+	if b.Organization != nil {
+		organization := fromOrganization(*b.Organization)
+		bundle.Organization = &organization
+	}
+	if b.Developers != nil {
+		for _, developer := range *b.Developers {
+			bundle.Developers = append(bundle.Developers, fromDeveloper(developer))
+		}
+	}
+	if b.Applications != nil {
+		for _, app := range *b.Applications {
+			bundle.DeveloperApps = append(bundle.DeveloperApps, fromApplication(app))
+		}
+	}
+	if b.Keys != nil {
+		for _, k := range *b.Keys {
+			key := fromKey(k)
+			key.APIProducts = fromKeyAPIProductStatuses(k.ApiProducts)
+			bundle.Keys = append(bundle.Keys, key)
+		}
+	}
+	if b.Apiproducts != nil {
+		for _, apiproduct := range *b.Apiproducts {
+			bundle.APIProducts = append(bundle.APIProducts, fromAPIproduct(apiproduct))
+		}
+	}
+	if b.Companies != nil {
+		for _, company := range *b.Companies {
+			bundle.Companies = append(bundle.Companies, fromCompany(company))
+		}
+	}
+	if b.Listeners != nil {
+		for _, listener := range *b.Listeners {
+			bundle.Listeners = append(bundle.Listeners, fromListener(listener))
+		}
+	}
+	if b.Routes != nil {
+		for _, route := range *b.Routes {
+			bundle.Routes = append(bundle.Routes, fromRoute(route))
+		}
+	}
+	if b.Clusters != nil {
+		for _, cluster := range *b.Clusters {
+			bundle.Clusters = append(bundle.Clusters, fromCluster(cluster))
+		}
+	}
+	return bundle
+}
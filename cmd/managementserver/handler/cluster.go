@@ -47,7 +47,7 @@ func (h *Handler) DeleteV1ClustersClusterName(c *gin.Context, clusterName Cluste
 		responseError(c, err)
 		return
 	}
-	if err := h.service.Cluster.Delete(string(clusterName), h.who(c)); err != nil {
+	if err := h.service.Cluster.Delete(string(clusterName), ifMatch(c), h.who(c)); err != nil {
 		responseError(c, err)
 		return
 	}
@@ -79,7 +79,33 @@ func (h *Handler) PostV1ClustersClusterName(c *gin.Context, clusterName ClusterN
 		return
 	}
 	updatedCluster := fromCluster(receivedCluster)
-	storedCluster, err := h.service.Cluster.Update(updatedCluster, h.who(c))
+	storedCluster, err := h.service.Cluster.Update(updatedCluster, ifMatch(c), h.who(c))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseClustersUpdated(c, storedCluster)
+}
+
+// partially updates a cluster
+// (PATCH /v1/clusters/{cluster_name})
+func (h *Handler) PatchV1ClustersClusterName(c *gin.Context, clusterName ClusterName) {
+
+	cluster, err := h.service.Cluster.Get(string(clusterName))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	patchedCluster := h.ToClusterResponse(cluster)
+	if err := applyMergePatch(c, &patchedCluster); err != nil {
+		responseErrorBadRequest(c, err)
+		return
+	}
+	if patchedCluster.Name != string(clusterName) {
+		responseErrorNameValueMisMatch(c)
+		return
+	}
+	storedCluster, err := h.service.Cluster.Update(fromCluster(patchedCluster), ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -114,7 +140,7 @@ func (h *Handler) PostV1ClustersClusterNameAttributes(c *gin.Context, clusterNam
 		return
 	}
 	cluster.Attributes = fromAttributesRequest(receivedAttributes.Attribute)
-	storedCluster, err := h.service.Cluster.Update(*cluster, h.who(c))
+	storedCluster, err := h.service.Cluster.Update(*cluster, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -137,7 +163,7 @@ func (h *Handler) DeleteV1ClustersClusterNameAttributesAttributeName(
 		responseError(c, err)
 		return
 	}
-	_, err = h.service.Cluster.Update(*cluster, h.who(c))
+	_, err = h.service.Cluster.Update(*cluster, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -182,7 +208,7 @@ func (h *Handler) PostV1ClustersClusterNameAttributesAttributeName(
 	if err := cluster.Attributes.Set(newAttribute); err != nil {
 		responseError(c, err)
 	}
-	_, err = h.service.Cluster.Update(*cluster, h.who(c))
+	_, err = h.service.Cluster.Update(*cluster, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -205,16 +231,19 @@ func (h *Handler) responseClusters(c *gin.Context, clusters types.Clusters) {
 
 func (h *Handler) responseCluster(c *gin.Context, cluster *types.Cluster) {
 
+	setETag(c, cluster.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToClusterResponse(cluster))
 }
 
 func (h *Handler) responseClusterCreated(c *gin.Context, cluster *types.Cluster) {
 
+	setETag(c, cluster.LastModifiedAt)
 	c.IndentedJSON(http.StatusCreated, h.ToClusterResponse(cluster))
 }
 
 func (h *Handler) responseClustersUpdated(c *gin.Context, cluster *types.Cluster) {
 
+	setETag(c, cluster.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToClusterResponse(cluster))
 }
 
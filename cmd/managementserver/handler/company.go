@@ -13,11 +13,23 @@ import (
 func (h *Handler) GetV1OrganizationsOrganizationNameCompanies(c *gin.Context,
 	organizationName OrganizationName, params GetV1OrganizationsOrganizationNameCompaniesParams) {
 
-	companies, err := h.service.Company.GetAll(string(organizationName))
+	listParams, e := listParams{
+		pageSize:         params.PageSize,
+		pageToken:        params.PageToken,
+		status:           params.Status,
+		lastModifiedFrom: params.LastModifiedFrom,
+		lastModifiedTo:   params.LastModifiedTo,
+	}.toDBListParams()
+	if e != nil {
+		responseErrorBadRequest(c, e)
+		return
+	}
+	companies, nextPageToken, err := h.service.Company.List(string(organizationName), listParams)
 	if err != nil {
 		responseError(c, err)
 		return
 	}
+	setNextPageToken(c, nextPageToken)
 	// Do we have to return full company details?
 	if params.Expand != nil && *params.Expand {
 		h.responseCompanies(c, companies)
@@ -56,7 +68,7 @@ func (h *Handler) DeleteV1OrganizationsOrganizationNameCompaniesCompanyName(
 		return
 	}
 	if err := h.service.Company.Delete(
-		string(organizationName), string(companyName), h.who(c)); err != nil {
+		string(organizationName), string(companyName), ifMatch(c), h.who(c)); err != nil {
 		responseError(c, err)
 		return
 	}
@@ -96,7 +108,35 @@ func (h *Handler) PostV1OrganizationsOrganizationNameCompaniesCompanyName(
 		responseErrorBadRequest(c, err)
 		return
 	}
-	storedCompany, err := h.service.Company.Update(string(organizationName), fromCompany(receivedCompany), h.who(c))
+	storedCompany, err := h.service.Company.Update(string(organizationName), fromCompany(receivedCompany), ifMatch(c), h.who(c))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseCompanyUpdated(c, storedCompany)
+}
+
+// partially updates a company
+// (PATCH /v1/organizations/{organization_name}/companies/{company_name})
+func (h *Handler) PatchV1OrganizationsOrganizationNameCompaniesCompanyName(
+	c *gin.Context, organizationName OrganizationName, companyName CompanyName) {
+
+	company, err := h.service.Company.Get(string(organizationName), string(companyName))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	patchedCompany := h.ToCompanyResponse(company)
+	if err := applyMergePatch(c, &patchedCompany); err != nil {
+		responseErrorBadRequest(c, err)
+		return
+	}
+	updatedCompany := fromCompany(patchedCompany)
+	if updatedCompany.Name != string(companyName) {
+		responseErrorNameValueMisMatch(c)
+		return
+	}
+	storedCompany, err := h.service.Company.Update(string(organizationName), updatedCompany, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -121,7 +161,7 @@ func (h *Handler) changeCompanyStatus(c *gin.Context, organizationName, companyN
 		responseErrorBadRequest(c, errUnknownDeveloperStatus)
 		return
 	}
-	_, err = h.service.Company.Update(organizationName, *company, h.who(c))
+	_, err = h.service.Company.Update(organizationName, *company, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -158,7 +198,7 @@ func (h *Handler) PostV1OrganizationsOrganizationNameCompaniesCompanyNameAttribu
 		return
 	}
 	company.Attributes = fromAttributesRequest(receivedAttributes.Attribute)
-	storedCompany, err := h.service.Company.Update(string(organizationName), *company, h.who(c))
+	storedCompany, err := h.service.Company.Update(string(organizationName), *company, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -181,7 +221,7 @@ func (h *Handler) DeleteV1OrganizationsOrganizationNameCompaniesCompanyNameAttri
 		responseError(c, err)
 		return
 	}
-	_, err = h.service.Company.Update(string(organizationName), *company, h.who(c))
+	_, err = h.service.Company.Update(string(organizationName), *company, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -226,7 +266,7 @@ func (h *Handler) PostV1OrganizationsOrganizationNameCompaniesCompanyNameAttribu
 	if err := company.Attributes.Set(newAttribute); err != nil {
 		responseError(c, err)
 	}
-	_, err = h.service.Company.Update(string(organizationName), *company, h.who(c))
+	_, err = h.service.Company.Update(string(organizationName), *company, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -258,16 +298,19 @@ func (h *Handler) responseCompanies(c *gin.Context, companys types.Companies) {
 
 func (h *Handler) responseCompany(c *gin.Context, company *types.Company) {
 
+	setETag(c, company.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToCompanyResponse(company))
 }
 
 func (h *Handler) responseCompanyCreated(c *gin.Context, company *types.Company) {
 
+	setETag(c, company.LastModifiedAt)
 	c.IndentedJSON(http.StatusCreated, h.ToCompanyResponse(company))
 }
 
 func (h *Handler) responseCompanyUpdated(c *gin.Context, company *types.Company) {
 
+	setETag(c, company.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToCompanyResponse(company))
 }
 
@@ -18,11 +18,24 @@ var (
 func (h *Handler) GetV1OrganizationsOrganizationNameDevelopers(c *gin.Context,
 	organizationName OrganizationName, params GetV1OrganizationsOrganizationNameDevelopersParams) {
 
-	developers, err := h.service.Developer.GetAll(string(organizationName))
+	listParams, e := listParams{
+		pageSize:         params.PageSize,
+		pageToken:        params.PageToken,
+		status:           params.Status,
+		emailPrefix:      params.EmailPrefix,
+		lastModifiedFrom: params.LastModifiedFrom,
+		lastModifiedTo:   params.LastModifiedTo,
+	}.toDBListParams()
+	if e != nil {
+		responseErrorBadRequest(c, e)
+		return
+	}
+	developers, nextPageToken, err := h.service.Developer.List(string(organizationName), listParams)
 	if err != nil {
 		responseError(c, err)
 		return
 	}
+	setNextPageToken(c, nextPageToken)
 	// Do we have to return full developer details?
 	if params.Expand != nil && *params.Expand {
 		h.responseDevelopers(c, developers)
@@ -62,7 +75,7 @@ func (h *Handler) DeleteV1OrganizationsOrganizationNameDevelopersDeveloperEmaila
 		return
 	}
 	if err := h.service.Developer.Delete(
-		string(organizationName), string(developerEmailaddress), h.who(c)); err != nil {
+		string(organizationName), string(developerEmailaddress), ifMatch(c), h.who(c)); err != nil {
 		responseError(c, err)
 		return
 	}
@@ -104,7 +117,32 @@ func (h *Handler) PostV1OrganizationsOrganizationNameDevelopersDeveloperEmailadd
 	}
 	updatedDeveloper := fromDeveloper(receivedDeveloper)
 	updatedDeveloper.OrganizationName = string(organizationName)
-	storedDeveloper, err := h.service.Developer.Update(string(organizationName), string(developerEmailaddress), updatedDeveloper, h.who(c))
+	storedDeveloper, err := h.service.Developer.Update(string(organizationName), string(developerEmailaddress), updatedDeveloper, ifMatch(c), h.who(c))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseDeveloperUpdated(c, storedDeveloper)
+}
+
+// partially updates a developer
+// (PATCH /v1/organizations/{organization_name}/developers/{developer_emailaddress})
+func (h *Handler) PatchV1OrganizationsOrganizationNameDevelopersDeveloperEmailaddress(
+	c *gin.Context, organizationName OrganizationName, developerEmailaddress DeveloperEmailaddress) {
+
+	developer, err := h.service.Developer.Get(string(organizationName), string(developerEmailaddress))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	patchedDeveloper := h.ToDeveloperResponse(developer)
+	if err := applyMergePatch(c, &patchedDeveloper); err != nil {
+		responseErrorBadRequest(c, err)
+		return
+	}
+	updatedDeveloper := fromDeveloper(patchedDeveloper)
+	updatedDeveloper.OrganizationName = string(organizationName)
+	storedDeveloper, err := h.service.Developer.Update(string(organizationName), string(developerEmailaddress), updatedDeveloper, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -129,7 +167,7 @@ func (h *Handler) changeDeveloperStatus(c *gin.Context, organizationName, develo
 		responseErrorBadRequest(c, errUnknownDeveloperStatus)
 		return
 	}
-	_, err = h.service.Developer.Update(organizationName, developerEmailaddress, *developer, h.who(c))
+	_, err = h.service.Developer.Update(organizationName, developerEmailaddress, *developer, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -166,7 +204,7 @@ func (h *Handler) PostV1OrganizationsOrganizationNameDevelopersDeveloperEmailadd
 		return
 	}
 	developer.Attributes = fromAttributesRequest(receivedAttributes.Attribute)
-	storedDeveloper, err := h.service.Developer.Update(string(organizationName), string(developerEmailaddress), *developer, h.who(c))
+	storedDeveloper, err := h.service.Developer.Update(string(organizationName), string(developerEmailaddress), *developer, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -189,7 +227,7 @@ func (h *Handler) DeleteV1OrganizationsOrganizationNameDevelopersDeveloperEmaila
 		responseError(c, err)
 		return
 	}
-	_, err = h.service.Developer.Update(string(organizationName), string(developerEmailaddress), *developer, h.who(c))
+	_, err = h.service.Developer.Update(string(organizationName), string(developerEmailaddress), *developer, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -235,7 +273,7 @@ func (h *Handler) PostV1OrganizationsOrganizationNameDevelopersDeveloperEmailadd
 		responseError(c, err)
 		return
 	}
-	_, err = h.service.Developer.Update(string(organizationName), string(developerEmailaddress), *developer, h.who(c))
+	_, err = h.service.Developer.Update(string(organizationName), string(developerEmailaddress), *developer, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -270,16 +308,19 @@ func (h *Handler) responseDevelopers(c *gin.Context, developers types.Developers
 
 func (h *Handler) responseDeveloper(c *gin.Context, developer *types.Developer) {
 
+	setETag(c, developer.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToDeveloperResponse(developer))
 }
 
 func (h *Handler) responseDeveloperCreated(c *gin.Context, developer *types.Developer) {
 
+	setETag(c, developer.LastModifiedAt)
 	c.IndentedJSON(http.StatusCreated, h.ToDeveloperResponse(developer))
 }
 
 func (h *Handler) responseDeveloperUpdated(c *gin.Context, developer *types.Developer) {
 
+	setETag(c, developer.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToDeveloperResponse(developer))
 }
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
@@ -18,11 +19,23 @@ var (
 func (h *Handler) GetV1OrganizationsOrganizationNameApps(c *gin.Context,
 	organizationName OrganizationName, params GetV1OrganizationsOrganizationNameAppsParams) {
 
-	apps, err := h.service.DeveloperApp.GetAll(string(organizationName))
+	listParams, e := listParams{
+		pageSize:         params.PageSize,
+		pageToken:        params.PageToken,
+		status:           params.Status,
+		lastModifiedFrom: params.LastModifiedFrom,
+		lastModifiedTo:   params.LastModifiedTo,
+	}.toDBListParams()
+	if e != nil {
+		responseErrorBadRequest(c, e)
+		return
+	}
+	apps, nextPageToken, err := h.service.DeveloperApp.List(string(organizationName), listParams)
 	if err != nil {
 		responseError(c, err)
 		return
 	}
+	setNextPageToken(c, nextPageToken)
 	// Do we have to return full developer details?
 	if params.Expand != nil && *params.Expand {
 		h.responseDeveloperAllApps(c, apps)
@@ -98,7 +111,7 @@ func (h *Handler) DeleteV1OrganizationsOrganizationNameDevelopersDeveloperEmaila
 		return
 	}
 	if err := h.service.DeveloperApp.Delete(
-		string(organizationName), string(developerEmailaddress), string(appName), h.who(c)); err != nil {
+		string(organizationName), string(developerEmailaddress), string(appName), ifMatch(c), h.who(c)); err != nil {
 		responseError(c, err)
 		return
 	}
@@ -145,7 +158,35 @@ func (h *Handler) PostV1OrganizationsOrganizationNameDevelopersDeveloperEmailadd
 		return
 	}
 	updatedApp := fromApplication(receivedApplication)
-	storedApp, err := h.service.DeveloperApp.Update(string(organizationName), string(developerEmailaddress), updatedApp, h.who(c))
+	storedApp, err := h.service.DeveloperApp.Update(string(organizationName), string(developerEmailaddress), updatedApp, ifMatch(c), h.who(c))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseApplicationUpdated(c, storedApp)
+}
+
+// partially updates an application
+// (PATCH /v1/organizations/{organization_name}/developers/{developer_emailaddress}/apps/{app_name})
+func (h *Handler) PatchV1OrganizationsOrganizationNameDevelopersDeveloperEmailaddressAppsAppName(
+	c *gin.Context, organizationName OrganizationName, developerEmailaddress DeveloperEmailaddress, appName AppName) {
+
+	app, err := h.service.DeveloperApp.GetByName(string(organizationName), string(developerEmailaddress), string(appName))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	patchedApp := ToApplicationResponse(app, nil)
+	if err := applyMergePatch(c, &patchedApp); err != nil {
+		responseErrorBadRequest(c, err)
+		return
+	}
+	updatedApp := fromApplication(patchedApp)
+	if updatedApp.Name != string(appName) {
+		responseErrorNameValueMisMatch(c)
+		return
+	}
+	storedApp, err := h.service.DeveloperApp.Update(string(organizationName), string(developerEmailaddress), updatedApp, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -170,7 +211,7 @@ func (h *Handler) changeDeveloperAppStatus(c *gin.Context, organizationName, dev
 		responseErrorBadRequest(c, errUnknownApplicationStatus)
 		return
 	}
-	_, err = h.service.DeveloperApp.Update(organizationName, developerEmailaddress, *app, h.who(c))
+	_, err = h.service.DeveloperApp.Update(organizationName, developerEmailaddress, *app, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -193,6 +234,11 @@ func (h *Handler) PutV1OrganizationsOrganizationNameDevelopersDeveloperEmailaddr
 		responseError(c, err)
 		return
 	}
+	// Check precondition before creating a key
+	if !ifMatch(c).Holds(app.LastModifiedAt) {
+		responseError(c, db.NewPreconditionFailedError(types.TypeDeveloperAppName, app.Name))
+		return
+	}
 	// In case apiproduct(s) were provided we create a new key with all apiproducts assigned
 	if receivedApplicationUpdate.ApiProducts != nil {
 		createdKey, err := h.service.Key.Create(string(organizationName), string(developerEmailaddress), string(appName), types.NullDeveloperAppKey, h.who(c))
@@ -222,7 +268,7 @@ func (h *Handler) PutV1OrganizationsOrganizationNameDevelopersDeveloperEmailaddr
 		applicationChanged = true
 	}
 	if applicationChanged {
-		_, err = h.service.DeveloperApp.Update(string(organizationName), string(developerEmailaddress), *app, h.who(c))
+		_, err = h.service.DeveloperApp.Update(string(organizationName), string(developerEmailaddress), *app, ifMatch(c), h.who(c))
 		if err != nil {
 			responseError(c, err)
 			return
@@ -265,7 +311,7 @@ func (h *Handler) PostV1OrganizationsOrganizationNameDevelopersDeveloperEmailadd
 		return
 	}
 	app.Attributes = fromAttributesRequest(receivedAttributes.Attribute)
-	storedDeveloper, err := h.service.DeveloperApp.Update(string(organizationName), string(developerEmailaddress), *app, h.who(c))
+	storedDeveloper, err := h.service.DeveloperApp.Update(string(organizationName), string(developerEmailaddress), *app, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -288,7 +334,7 @@ func (h *Handler) DeleteV1OrganizationsOrganizationNameDevelopersDeveloperEmaila
 		responseError(c, err)
 		return
 	}
-	_, err = h.service.DeveloperApp.Update(string(organizationName), string(developerEmailaddress), *app, h.who(c))
+	_, err = h.service.DeveloperApp.Update(string(organizationName), string(developerEmailaddress), *app, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -334,7 +380,7 @@ func (h *Handler) PostV1OrganizationsOrganizationNameDevelopersDeveloperEmailadd
 		responseError(c, err)
 		return
 	}
-	_, err = h.service.DeveloperApp.Update(string(organizationName), string(developerEmailaddress), *app, h.who(c))
+	_, err = h.service.DeveloperApp.Update(string(organizationName), string(developerEmailaddress), *app, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -377,16 +423,19 @@ func (h *Handler) responseDeveloperAllApps(c *gin.Context, developerapps types.D
 
 func (h *Handler) responseApplication(c *gin.Context, app *types.DeveloperApp, keys *types.Keys) {
 
+	setETag(c, app.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, ToApplicationResponse(app, keys))
 }
 
 func (h *Handler) responseApplicationCreated(c *gin.Context, app *types.DeveloperApp, keys *types.Keys) {
 
+	setETag(c, app.LastModifiedAt)
 	c.IndentedJSON(http.StatusCreated, ToApplicationResponse(app, keys))
 }
 
 func (h *Handler) responseApplicationUpdated(c *gin.Context, app *types.DeveloperApp) {
 
+	setETag(c, app.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, ToApplicationResponse(app, nil))
 }
 
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+)
+
+// The ETag of an entity is its quoted last modified timestamp, e.g. "1612345678901"
+
+// setETag adds ETag header based upon last modified timestamp of entity to response
+func setETag(c *gin.Context, lastModifiedAt int64) {
+
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(lastModifiedAt, 10)))
+}
+
+// ifMatch returns precondition for updating or deleting an entity based upon
+// If-Match request header: without header or with "*" the entity is changed unconditionally.
+//
+// An ETag which cannot have been issued by us (e.g. a weak ETag or a list of ETags)
+// results in a precondition no entity meets.
+func ifMatch(c *gin.Context) db.Precondition {
+
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" || value == "*" {
+		return db.Precondition{}
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		if lastModifiedAt, err := strconv.ParseInt(unquoted, 10, 64); err == nil && lastModifiedAt > 0 {
+			return db.Precondition{LastModifiedAt: lastModifiedAt}
+		}
+	}
+	return db.Precondition{LastModifiedAt: -1}
+}
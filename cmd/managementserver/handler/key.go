@@ -147,6 +147,35 @@ func (h *Handler) PostV1OrganizationsOrganizationNameDevelopersDeveloperEmailadd
 	h.responseKeyUpdated(c, storedKey)
 }
 
+// partially updates a key
+// (PATCH /v1/organizations/{organization_name}/developers/{developer_emailaddress}/apps/{app_name}/keys/{consumer_key})
+func (h *Handler) PatchV1OrganizationsOrganizationNameDevelopersDeveloperEmailaddressAppsAppNameKeysConsumerKey(
+	c *gin.Context, organizationName OrganizationName, developerEmailaddress DeveloperEmailaddress, appName AppName, consumerKey ConsumerKey) {
+
+	key, err := h.service.Key.Get(string(organizationName), string(developerEmailaddress), string(appName), string(consumerKey))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	patchedKey := ToKeyResponse(key)
+	if err := applyMergePatch(c, &patchedKey); err != nil {
+		responseErrorBadRequest(c, err)
+		return
+	}
+	updatedKey := fromKey(patchedKey)
+	if updatedKey.ConsumerKey != string(consumerKey) {
+		responseErrorNameValueMisMatch(c)
+		return
+	}
+	updatedKey.APIProducts = fromKeyAPIProductStatuses(patchedKey.ApiProducts)
+	storedKey, err := h.service.Key.Update(string(organizationName), string(developerEmailaddress), string(appName), string(consumerKey), updatedKey, h.who(c))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseKeyUpdated(c, storedKey)
+}
+
 // change status of key
 func (h *Handler) changeKeyStatus(c *gin.Context, organizationName,
 	developerEmailaddress, appName, consumerKey, requestedStatus string) {
@@ -429,3 +458,22 @@ func fromKey(k Key) types.Key {
 	}
 	return key
 }
+
+func fromKeyAPIProductStatuses(k *[]KeyProduct) types.KeyAPIProductStatuses {
+
+	if k == nil {
+		return nil
+	}
+	apiProductStatuses := make(types.KeyAPIProductStatuses, 0, len(*k))
+	for _, p := range *k {
+		apiProductStatus := types.KeyAPIProductStatus{}
+		if p.Apiproduct != nil {
+			apiProductStatus.Apiproduct = *p.Apiproduct
+		}
+		if p.Status != nil {
+			apiProductStatus.Status = *p.Status
+		}
+		apiProductStatuses = append(apiProductStatuses, apiProductStatus)
+	}
+	return apiProductStatuses
+}
@@ -47,7 +47,7 @@ func (h *Handler) DeleteV1ListenersListenerName(c *gin.Context, listenerName Lis
 		responseError(c, err)
 		return
 	}
-	if err := h.service.Listener.Delete(string(listenerName), h.who(c)); err != nil {
+	if err := h.service.Listener.Delete(string(listenerName), ifMatch(c), h.who(c)); err != nil {
 		responseError(c, err)
 		return
 	}
@@ -80,7 +80,33 @@ func (h *Handler) PostV1ListenersListenerName(c *gin.Context, listenerName Liste
 		return
 	}
 	updatedListener := fromListener(receivedListener)
-	storedListener, err := h.service.Listener.Update(updatedListener, h.who(c))
+	storedListener, err := h.service.Listener.Update(updatedListener, ifMatch(c), h.who(c))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseListenersUpdated(c, storedListener)
+}
+
+// partially updates a listener
+// (PATCH /v1/listeners/{listener_name})
+func (h *Handler) PatchV1ListenersListenerName(c *gin.Context, listenerName ListenerName) {
+
+	listener, err := h.service.Listener.Get(string(listenerName))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	patchedListener := h.ToListenerResponse(listener)
+	if err := applyMergePatch(c, &patchedListener); err != nil {
+		responseErrorBadRequest(c, err)
+		return
+	}
+	if patchedListener.Name != string(listenerName) {
+		responseErrorNameValueMisMatch(c)
+		return
+	}
+	storedListener, err := h.service.Listener.Update(fromListener(patchedListener), ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -115,7 +141,7 @@ func (h *Handler) PostV1ListenersListenerNameAttributes(c *gin.Context, listener
 		return
 	}
 	listener.Attributes = fromAttributesRequest(receivedAttributes.Attribute)
-	storedListener, err := h.service.Listener.Update(*listener, h.who(c))
+	storedListener, err := h.service.Listener.Update(*listener, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -138,7 +164,7 @@ func (h *Handler) DeleteV1ListenersListenerNameAttributesAttributeName(c *gin.Co
 		responseError(c, err)
 		return
 	}
-	_, err = h.service.Listener.Update(*listener, h.who(c))
+	_, err = h.service.Listener.Update(*listener, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -183,7 +209,7 @@ func (h *Handler) PostV1ListenersListenerNameAttributesAttributeName(c *gin.Cont
 	if err := listener.Attributes.Set(newAttribute); err != nil {
 		responseError(c, err)
 	}
-	_, err = h.service.Listener.Update(*listener, h.who(c))
+	_, err = h.service.Listener.Update(*listener, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -206,16 +232,19 @@ func (h *Handler) responseListeners(c *gin.Context, listeners types.Listeners) {
 
 func (h *Handler) responseListener(c *gin.Context, listener *types.Listener) {
 
+	setETag(c, listener.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToListenerResponse(listener))
 }
 
 func (h *Handler) responseListenerCreated(c *gin.Context, listener *types.Listener) {
 
+	setETag(c, listener.LastModifiedAt)
 	c.IndentedJSON(http.StatusCreated, h.ToListenerResponse(listener))
 }
 
 func (h *Handler) responseListenersUpdated(c *gin.Context, listener *types.Listener) {
 
+	setETag(c, listener.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToListenerResponse(listener))
 }
 
@@ -231,7 +260,7 @@ func (h *Handler) ToListenerResponse(l *types.Listener) Listener {
 		LastModifiedBy: &l.LastModifiedBy,
 		LastModifiedAt: &l.LastModifiedAt,
 		Name:           l.Name,
-		Policies:       &l.Policies,
+		Policies:       toPoliciesResponse(l.Policies),
 		Port:           &l.Port,
 		RouteGroup:     &l.RouteGroup,
 	}
@@ -268,7 +297,7 @@ func fromListener(l Listener) types.Listener {
 		listener.Name = l.Name
 	}
 	if l.Policies != nil {
-		listener.Policies = *l.Policies
+		listener.Policies = fromPoliciesRequest(l.Policies)
 	}
 	if l.Port != nil {
 		listener.Port = *l.Port
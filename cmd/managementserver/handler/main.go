@@ -50,8 +50,9 @@ func New(router *gin.Engine, db *db.Database, s *service.Service,
 	router.Use(cors.New(cors.Options{
 		AllowCredentials: true,
 		AllowedHeaders:   []string{"Authorization", "Content-Type"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
 		AllowedOrigins:   []string{"*"},
+		ExposedHeaders:   []string{nextPageTokenHeader},
 		MaxAge:           3600,
 	}))
 
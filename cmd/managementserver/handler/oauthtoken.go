@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// Token types we report
+	oauthTokenTypeAccessToken       = "access_token"
+	oauthTokenTypeAuthorizationCode = "authorization_code"
+)
+
+// returns all tokens issued to keys of developer application
+// (GET /v1/organizations/{organization_name}/developers/{developer_emailaddress}/apps/{app_name}/tokens)
+func (h *Handler) GetV1OrganizationsOrganizationNameDevelopersDeveloperEmailaddressAppsAppNameTokens(
+	c *gin.Context, organizationName OrganizationName, developerEmailaddress DeveloperEmailaddress, appName AppName) {
+
+	tokens, err := h.service.OAuthToken.GetByDeveloperApp(string(organizationName), string(developerEmailaddress), string(appName))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseOAuthTokens(c, tokens)
+}
+
+// revokes all tokens issued to keys of developer application
+// (DELETE /v1/organizations/{organization_name}/developers/{developer_emailaddress}/apps/{app_name}/tokens)
+func (h *Handler) DeleteV1OrganizationsOrganizationNameDevelopersDeveloperEmailaddressAppsAppNameTokens(
+	c *gin.Context, organizationName OrganizationName, developerEmailaddress DeveloperEmailaddress, appName AppName) {
+
+	tokens, err := h.service.OAuthToken.RevokeByDeveloperApp(string(organizationName), string(developerEmailaddress), string(appName), h.who(c))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseOAuthTokens(c, tokens)
+}
+
+// returns all tokens issued to key
+// (GET /v1/organizations/{organization_name}/developers/{developer_emailaddress}/apps/{app_name}/keys/{consumer_key}/tokens)
+func (h *Handler) GetV1OrganizationsOrganizationNameDevelopersDeveloperEmailaddressAppsAppNameKeysConsumerKeyTokens(
+	c *gin.Context, organizationName OrganizationName, developerEmailaddress DeveloperEmailaddress, appName AppName, consumerKey ConsumerKey) {
+
+	tokens, err := h.service.OAuthToken.GetByKey(string(organizationName), string(developerEmailaddress), string(appName), string(consumerKey))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseOAuthTokens(c, tokens)
+}
+
+// revokes all tokens issued to key
+// (DELETE /v1/organizations/{organization_name}/developers/{developer_emailaddress}/apps/{app_name}/keys/{consumer_key}/tokens)
+func (h *Handler) DeleteV1OrganizationsOrganizationNameDevelopersDeveloperEmailaddressAppsAppNameKeysConsumerKeyTokens(
+	c *gin.Context, organizationName OrganizationName, developerEmailaddress DeveloperEmailaddress, appName AppName, consumerKey ConsumerKey) {
+
+	tokens, err := h.service.OAuthToken.RevokeByKey(string(organizationName), string(developerEmailaddress), string(appName), string(consumerKey), h.who(c))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseOAuthTokens(c, tokens)
+}
+
+// API responses
+
+func (h *Handler) responseOAuthTokens(c *gin.Context, tokens types.OAuthAccessTokens) {
+
+	c.IndentedJSON(http.StatusOK, OAuthTokens{
+		Token: ToOAuthTokenSlice(tokens),
+	})
+}
+
+// type conversion
+
+func ToOAuthTokenSlice(tokens types.OAuthAccessTokens) *[]OAuthToken {
+
+	allTokens := make([]OAuthToken, len(tokens))
+	for i := range tokens {
+		allTokens[i] = ToOAuthTokenResponse(&tokens[i])
+	}
+	return &allTokens
+}
+
+// ToOAuthTokenResponse converts token to response, token values are never returned
+func ToOAuthTokenResponse(t *types.OAuthAccessToken) OAuthToken {
+
+	token := OAuthToken{
+		ConsumerKey: &t.ClientID,
+		UserId:      &t.UserID,
+		Scope:       &t.Scope,
+	}
+	var tokenType string
+	var issuedAt, expiresAt, refreshExpiresAt int64
+	if t.IsAuthorizationCode() {
+		tokenType = oauthTokenTypeAuthorizationCode
+		issuedAt = t.CodeCreatedAt
+		expiresAt = t.CodeCreatedAt + t.CodeExpiresIn
+	} else {
+		tokenType = oauthTokenTypeAccessToken
+		issuedAt = t.AccessCreatedAt
+		expiresAt = t.AccessCreatedAt + t.AccessExpiresIn
+		if t.Refresh != "" {
+			refreshExpiresAt = t.RefreshCreatedAt + t.RefreshExpiresIn
+		}
+	}
+	token.Type = &tokenType
+	token.IssuedAt = &issuedAt
+	token.ExpiresAt = &expiresAt
+	token.RefreshExpiresAt = &refreshExpiresAt
+	return token
+}
@@ -69,7 +69,34 @@ func (h *Handler) PostV1OrganizationsOrganizationName(c *gin.Context, organizati
 		responseErrorNameValueMisMatch(c)
 		return
 	}
-	storedOrganization, err := h.service.Organization.Update(updatedOrganization, h.who(c))
+	storedOrganization, err := h.service.Organization.Update(updatedOrganization, ifMatch(c), h.who(c))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseOrganizationUpdated(c, storedOrganization)
+}
+
+// partially updates an organization
+// (PATCH /v1/organizations/{organization_name})
+func (h *Handler) PatchV1OrganizationsOrganizationName(c *gin.Context, organizationName OrganizationName) {
+
+	organization, err := h.service.Organization.Get(string(organizationName))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	patchedOrganization := h.ToOrganizationResponse(organization)
+	if err := applyMergePatch(c, &patchedOrganization); err != nil {
+		responseErrorBadRequest(c, err)
+		return
+	}
+	updatedOrganization := fromOrganization(patchedOrganization)
+	if updatedOrganization.Name != string(organizationName) {
+		responseErrorNameValueMisMatch(c)
+		return
+	}
+	storedOrganization, err := h.service.Organization.Update(updatedOrganization, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -86,7 +113,7 @@ func (h *Handler) DeleteV1OrganizationsOrganizationName(c *gin.Context, organiza
 		responseError(c, err)
 		return
 	}
-	if err := h.service.Organization.Delete(string(organizationName), h.who(c)); err != nil {
+	if err := h.service.Organization.Delete(string(organizationName), ifMatch(c), h.who(c)); err != nil {
 		responseError(c, err)
 		return
 	}
@@ -108,16 +135,19 @@ func (h *Handler) responseOrganizations(c *gin.Context, organizations types.Orga
 
 func (h *Handler) responseOrganization(c *gin.Context, organization *types.Organization) {
 
+	setETag(c, organization.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToOrganizationResponse(organization))
 }
 
 func (h *Handler) responseOrganizationCreated(c *gin.Context, organization *types.Organization) {
 
+	setETag(c, organization.LastModifiedAt)
 	c.IndentedJSON(http.StatusCreated, h.ToOrganizationResponse(organization))
 }
 
 func (h *Handler) responseOrganizationUpdated(c *gin.Context, organization *types.Organization) {
 
+	setETag(c, organization.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToOrganizationResponse(organization))
 }
 
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+)
+
+const (
+	// Maximum number of entities we return per page
+	maxPageSize = 1000
+
+	// Response header holding token to retrieve next page
+	nextPageTokenHeader = "X-Next-Page-Token"
+)
+
+var (
+	errInvalidPageSize = errors.New("pageSize must be between 1 and 1000")
+)
+
+// listParams holds pagination and filter query parameters of a list request
+type listParams struct {
+	pageSize         *PageSize
+	pageToken        *PageToken
+	status           *Status
+	emailPrefix      *EmailPrefix
+	lastModifiedFrom *LastModifiedFrom
+	lastModifiedTo   *LastModifiedTo
+}
+
+// toDBListParams converts query parameters into database list parameters
+func (p listParams) toDBListParams() (db.ListParams, error) {
+
+	var params db.ListParams
+	if p.pageSize != nil {
+		if *p.pageSize < 1 || *p.pageSize > maxPageSize {
+			return db.ListParams{}, errInvalidPageSize
+		}
+		params.PageSize = *p.pageSize
+	}
+	if p.pageToken != nil {
+		params.PageToken = *p.pageToken
+	}
+	if p.status != nil {
+		params.Status = *p.status
+	}
+	if p.emailPrefix != nil {
+		params.EmailPrefix = *p.emailPrefix
+	}
+	if p.lastModifiedFrom != nil {
+		params.LastModifiedFrom = *p.lastModifiedFrom
+	}
+	if p.lastModifiedTo != nil {
+		params.LastModifiedTo = *p.lastModifiedTo
+	}
+	return params, nil
+}
+
+// setNextPageToken adds token of next page to response, in case there is one
+func setNextPageToken(c *gin.Context, nextPageToken string) {
+
+	if nextPageToken != "" {
+		c.Header(nextPageTokenHeader, nextPageToken)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errPatchNotAnObject = errors.New("merge patch must be a JSON object")
+
+// applyMergePatch applies JSON merge patch (RFC 7396) in request body to entity,
+// entity must be a pointer to the API representation of the entity
+func applyMergePatch(c *gin.Context, entity interface{}) error {
+
+	body, err := c.GetRawData()
+	if err != nil {
+		return err
+	}
+	var patch interface{}
+	if err := json.Unmarshal(body, &patch); err != nil {
+		return err
+	}
+	if _, ok := patch.(map[string]interface{}); !ok {
+		return errPatchNotAnObject
+	}
+
+	current, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	var target interface{}
+	if err := json.Unmarshal(current, &target); err != nil {
+		return err
+	}
+	patched, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return err
+	}
+
+	// Reset entity first so fields removed by patch do not retain their value
+	value := reflect.ValueOf(entity).Elem()
+	value.Set(reflect.Zero(value.Type()))
+	return json.Unmarshal(patched, entity)
+}
+
+// mergePatch returns target with patch merged into it as specified by RFC 7396
+func mergePatch(target, patch interface{}) interface{} {
+
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = mergePatch(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
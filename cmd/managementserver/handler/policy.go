@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// returns all policies which can be used in listener and apiproduct policies
+// (GET /v1/policies)
+func (h *Handler) GetV1Policies(c *gin.Context) {
+
+	h.responsePolicyDefinitions(c, types.GetPolicyDefinitions())
+}
+
+// API responses
+
+func (h *Handler) responsePolicyDefinitions(c *gin.Context, definitions []types.PolicyDefinition) {
+
+	allDefinitions := make([]PolicyDefinition, len(definitions))
+	for i := range definitions {
+		allDefinitions[i] = toPolicyDefinitionResponse(&definitions[i])
+	}
+	c.IndentedJSON(http.StatusOK, PolicyDefinitions{
+		Policy: &allDefinitions,
+	})
+}
+
+// type conversion
+
+func toPolicyDefinitionResponse(d *types.PolicyDefinition) PolicyDefinition {
+
+	scope := PolicyDefinitionScope(d.Scope)
+	parameters := make([]PolicyParameterDefinition, len(d.Parameters))
+	for i := range d.Parameters {
+		parameterType := PolicyParameterDefinitionType(d.Parameters[i].Type)
+		parameters[i] = PolicyParameterDefinition{
+			Name:        &d.Parameters[i].Name,
+			Type:        &parameterType,
+			Required:    &d.Parameters[i].Required,
+			Description: &d.Parameters[i].Description,
+		}
+		if len(d.Parameters[i].Values) != 0 {
+			parameters[i].Values = &d.Parameters[i].Values
+		}
+		if d.Parameters[i].Maximum != 0 {
+			parameters[i].Minimum = &d.Parameters[i].Minimum
+			parameters[i].Maximum = &d.Parameters[i].Maximum
+		}
+	}
+	return PolicyDefinition{
+		Name:        &d.Name,
+		Description: &d.Description,
+		Scope:       &scope,
+		Parameters:  &parameters,
+	}
+}
+
+func toPoliciesResponse(policies types.Policies) *[]Policy {
+
+	allPolicies := make([]Policy, len(policies))
+	for i := range policies {
+		allPolicies[i] = Policy{
+			Name: policies[i].Name,
+		}
+		if len(policies[i].Parameters) != 0 {
+			allPolicies[i].Parameters = toAttributesResponse(policies[i].Parameters)
+		}
+		if policies[i].Condition != "" {
+			allPolicies[i].Condition = &policies[i].Condition
+		}
+		if policies[i].Shadow {
+			allPolicies[i].Shadow = &policies[i].Shadow
+		}
+	}
+	return &allPolicies
+}
+
+func fromPoliciesRequest(policies *[]Policy) types.Policies {
+
+	if policies == nil {
+		return types.Policies{}
+	}
+	allPolicies := make(types.Policies, len(*policies))
+	for i, p := range *policies {
+		allPolicies[i] = types.Policy{
+			Name: p.Name,
+		}
+		if p.Parameters != nil {
+			allPolicies[i].Parameters = fromAttributesRequest(p.Parameters)
+		}
+		if p.Condition != nil {
+			allPolicies[i].Condition = *p.Condition
+		}
+		if p.Shadow != nil {
+			allPolicies[i].Shadow = *p.Shadow
+		}
+	}
+	return allPolicies
+}
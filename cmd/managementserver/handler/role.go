@@ -69,7 +69,7 @@ func (h *Handler) PostV1RolesRoleName(c *gin.Context, roleName RoleName) {
 		responseErrorNameValueMisMatch(c)
 		return
 	}
-	storedRole, err := h.service.Role.Update(updatedRole, h.who(c))
+	storedRole, err := h.service.Role.Update(updatedRole, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -86,7 +86,7 @@ func (h *Handler) DeleteV1RolesRoleName(c *gin.Context, roleName RoleName) {
 		responseError(c, err)
 		return
 	}
-	if err := h.service.Role.Delete(string(roleName), h.who(c)); err != nil {
+	if err := h.service.Role.Delete(string(roleName), ifMatch(c), h.who(c)); err != nil {
 		responseError(c, err)
 		return
 	}
@@ -120,16 +120,19 @@ func (h *Handler) responseRoles(c *gin.Context, roles types.Roles) {
 
 func (h *Handler) responseRole(c *gin.Context, user *types.Role) {
 
+	setETag(c, user.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToRoleResponse(user))
 }
 
 func (h *Handler) responseRoleCreated(c *gin.Context, role *types.Role) {
 
+	setETag(c, role.LastModifiedAt)
 	c.IndentedJSON(http.StatusCreated, h.ToRoleResponse(role))
 }
 
 func (h *Handler) responseRoleUpdated(c *gin.Context, role *types.Role) {
 
+	setETag(c, role.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToRoleResponse(role))
 }
 
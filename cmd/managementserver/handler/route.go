@@ -47,7 +47,7 @@ func (h *Handler) DeleteV1RoutesRouteName(c *gin.Context, routeName RouteName) {
 		responseError(c, err)
 		return
 	}
-	if err := h.service.Route.Delete(string(routeName), h.who(c)); err != nil {
+	if err := h.service.Route.Delete(string(routeName), ifMatch(c), h.who(c)); err != nil {
 		responseError(c, err)
 		return
 	}
@@ -79,7 +79,33 @@ func (h *Handler) PostV1RoutesRouteName(c *gin.Context, routeName RouteName) {
 		return
 	}
 	updatedRoute := fromRoute(receivedRoute)
-	storedRoute, err := h.service.Route.Update(updatedRoute, h.who(c))
+	storedRoute, err := h.service.Route.Update(updatedRoute, ifMatch(c), h.who(c))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	h.responseRoutesUpdated(c, storedRoute)
+}
+
+// partially updates a route
+// (PATCH /v1/routes/{route_name})
+func (h *Handler) PatchV1RoutesRouteName(c *gin.Context, routeName RouteName) {
+
+	route, err := h.service.Route.Get(string(routeName))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	patchedRoute := h.ToRouteResponse(route)
+	if err := applyMergePatch(c, &patchedRoute); err != nil {
+		responseErrorBadRequest(c, err)
+		return
+	}
+	if patchedRoute.Name != string(routeName) {
+		responseErrorNameValueMisMatch(c)
+		return
+	}
+	storedRoute, err := h.service.Route.Update(fromRoute(patchedRoute), ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -114,7 +140,7 @@ func (h *Handler) PostV1RoutesRouteNameAttributes(c *gin.Context, routeName Rout
 		return
 	}
 	route.Attributes = fromAttributesRequest(receivedAttributes.Attribute)
-	storedRoute, err := h.service.Route.Update(*route, h.who(c))
+	storedRoute, err := h.service.Route.Update(*route, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -136,7 +162,7 @@ func (h *Handler) DeleteV1RoutesRouteNameAttributesAttributeName(
 	if err != nil {
 		responseError(c, err)
 	}
-	_, err = h.service.Route.Update(*route, h.who(c))
+	_, err = h.service.Route.Update(*route, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -181,7 +207,7 @@ func (h *Handler) PostV1RoutesRouteNameAttributesAttributeName(
 	if err := route.Attributes.Set(newAttribute); err != nil {
 		responseError(c, err)
 	}
-	_, err = h.service.Route.Update(*route, h.who(c))
+	_, err = h.service.Route.Update(*route, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -204,16 +230,19 @@ func (h *Handler) responseRoutes(c *gin.Context, routes types.Routes) {
 
 func (h *Handler) responseRoute(c *gin.Context, route *types.Route) {
 
+	setETag(c, route.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToRouteResponse(route))
 }
 
 func (h *Handler) responseRouteCreated(c *gin.Context, route *types.Route) {
 
+	setETag(c, route.LastModifiedAt)
 	c.IndentedJSON(http.StatusCreated, h.ToRouteResponse(route))
 }
 
 func (h *Handler) responseRoutesUpdated(c *gin.Context, route *types.Route) {
 
+	setETag(c, route.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToRouteResponse(route))
 }
 
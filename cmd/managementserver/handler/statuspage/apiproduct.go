@@ -87,7 +87,7 @@ const templateAPIProducts string = `
 {{end}}
 </ul>
 </td>
-<td>{{$a.Policies | OrderedList}}</td>
+<td>{{$a.Policies.String | OrderedList}}</td>
 <td>
 <ul>
 {{range $attribute := .Attributes}}
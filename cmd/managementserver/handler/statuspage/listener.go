@@ -103,7 +103,7 @@ const templateHTTPForwarding string = `
 {{end}}
 </ul>
 </td>
-<td>{{$listener.Policies | OrderedList}}</td>
+<td>{{$listener.Policies.String | OrderedList}}</td>
 <td>{{$listener.RouteGroup}}</td>
 <td>{{$listener.LastModifiedAt | ISO8601}} <br> {{$listener.LastModifiedBy}}</td>
 </tr>
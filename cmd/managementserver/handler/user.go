@@ -69,7 +69,7 @@ func (h *Handler) PostV1UsersUserName(c *gin.Context, userName UserName) {
 		responseErrorNameValueMisMatch(c)
 		return
 	}
-	storedUser, err := h.service.User.Update(updatedUser, h.who(c))
+	storedUser, err := h.service.User.Update(updatedUser, ifMatch(c), h.who(c))
 	if err != nil {
 		responseError(c, err)
 		return
@@ -86,7 +86,7 @@ func (h *Handler) DeleteV1UsersUserName(c *gin.Context, userName UserName) {
 		responseError(c, err)
 		return
 	}
-	if err := h.service.User.Delete(string(userName), h.who(c)); err != nil {
+	if err := h.service.User.Delete(string(userName), ifMatch(c), h.who(c)); err != nil {
 		responseError(c, err)
 		return
 	}
@@ -108,16 +108,19 @@ func (h *Handler) responseUsers(c *gin.Context, users types.Users) {
 
 func (h *Handler) responseUser(c *gin.Context, user *types.User) {
 
+	setETag(c, user.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToUserResponse(user))
 }
 
 func (h *Handler) responseUserCreated(c *gin.Context, user *types.User) {
 
+	setETag(c, user.LastModifiedAt)
 	c.IndentedJSON(http.StatusCreated, h.ToUserResponse(user))
 }
 
 func (h *Handler) responseUserUpdated(c *gin.Context, user *types.User) {
 
+	setETag(c, user.LastModifiedAt)
 	c.IndentedJSON(http.StatusOK, h.ToUserResponse(user))
 }
 
@@ -12,6 +12,7 @@ import (
 	"github.com/erikbos/gatekeeper/cmd/managementserver/metrics"
 	"github.com/erikbos/gatekeeper/cmd/managementserver/service"
 	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/db/backend"
 	"github.com/erikbos/gatekeeper/pkg/db/cassandra"
 	"github.com/erikbos/gatekeeper/pkg/shared"
 	"github.com/erikbos/gatekeeper/pkg/webadmin"
@@ -39,6 +40,7 @@ func main() {
 	createSchema := flag.Bool("createschema", false, "Create database schema if it does not exist")
 	replicaCount := flag.Int("replicacount", 1, "Replica count to set for database keyspace")
 	showCreateSchema := flag.Bool("showcreateschema", false, "Show CQL statements to create database")
+	migrate := flag.String("migrate", "", "Run database schema migration command (up / status) and exit")
 	flag.Parse()
 
 	if *showCreateSchema {
@@ -61,10 +63,23 @@ func main() {
 		zap.String("version", version),
 		zap.String("buildtime", buildTime))
 
+	if *migrate != "" {
+		if err := backend.Migrate(s.config.Database, *migrate, *replicaCount, s.logger); err != nil {
+			s.logger.Fatal("Database schema migration failed", zap.Error(err))
+		}
+		// Audit database might be located in a different keyspace
+		if !s.config.Audit.Database.IsMemory() && s.config.Audit.Database != s.config.Database {
+			if err := backend.Migrate(s.config.Audit.Database, *migrate, *replicaCount, s.logger); err != nil {
+				s.logger.Fatal("Audit database schema migration failed", zap.Error(err))
+			}
+		}
+		os.Exit(0)
+	}
+
 	s.metrics = metrics.New(applicationName)
 
 	// Connect to database
-	db, err := cassandra.New(s.config.Database, applicationName,
+	db, err := backend.New(s.config.Database, applicationName,
 		s.logger, *createSchema, *replicaCount)
 	if err != nil {
 		s.logger.Fatal("Database connect failed", zap.Error(err))
@@ -77,15 +92,19 @@ func main() {
 	// 	s.logger.Fatal("Database cache setup failed", zap.Error(err))
 	// }
 
-	// Connect to audit database
-	auditDb, err := cassandra.New(s.config.Audit.Database, applicationName+"_audit",
-		s.logger, *createSchema, *replicaCount)
-	if err != nil {
-		s.logger.Fatal("Audit database connect failed", zap.Error(err))
+	// Connect to audit database, an in-memory database cannot be shared
+	// between two instances so we write audit records to the same one
+	auditDb := db
+	if !s.config.Database.IsMemory() || !s.config.Audit.Database.IsMemory() {
+		auditDb, err = backend.New(s.config.Audit.Database, applicationName+"_audit",
+			s.logger, *createSchema, *replicaCount)
+		if err != nil {
+			s.logger.Fatal("Audit database connect failed", zap.Error(err))
+		}
 	}
 
 	auditLogLogger := shared.NewLogger("audit", &s.config.Audit.Logger)
-	auditlog := audit.New(auditDb, auditLogLogger)
+	auditlog := audit.New(auditDb, db.ChangeLog, auditLogLogger)
 
 	s.webadmin = webadmin.New(s.config.WebAdmin, applicationName)
 	s.webadmin.Router.Use(s.metrics.Middleware())
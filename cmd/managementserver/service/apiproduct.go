@@ -30,6 +30,12 @@ func (ds *APIProductService) GetAll(organizationName string) (apiproducts types.
 	return ds.db.APIProduct.GetAll(organizationName)
 }
 
+// List returns one page of apiproducts
+func (ds *APIProductService) List(organizationName string, params db.ListParams) (apiproducts types.APIProducts, nextPageToken string, err types.Error) {
+
+	return ds.db.APIProduct.List(organizationName, params)
+}
+
 // Get returns details of an apiproduct
 func (ds *APIProductService) Get(organizationName, apiproductName string) (apiproduct *types.APIProduct, err types.Error) {
 
@@ -52,7 +58,7 @@ func (ds *APIProductService) Create(organizationName string, newAPIProduct types
 		newAPIProduct.ApprovalType = "auto"
 	}
 
-	if err := ds.updateAPIProduct(organizationName, &newAPIProduct, who); err != nil {
+	if err := ds.updateAPIProduct(organizationName, &newAPIProduct, db.Precondition{}, who); err != nil {
 		return nil, err
 	}
 	env := &audit.Environment{
@@ -64,7 +70,7 @@ func (ds *APIProductService) Create(organizationName string, newAPIProduct types
 
 // Update updates an existing apiproduct
 func (ds *APIProductService) Update(organizationName string, updatedAPIProduct types.APIProduct,
-	who audit.Requester) (*types.APIProduct, types.Error) {
+	precondition db.Precondition, who audit.Requester) (*types.APIProduct, types.Error) {
 
 	currentAPIProduct, err := ds.db.APIProduct.Get(organizationName, updatedAPIProduct.Name)
 	if err != nil {
@@ -76,7 +82,7 @@ func (ds *APIProductService) Update(organizationName string, updatedAPIProduct t
 	updatedAPIProduct.CreatedAt = currentAPIProduct.CreatedAt
 	updatedAPIProduct.CreatedBy = currentAPIProduct.CreatedBy
 
-	if err = ds.updateAPIProduct(organizationName, &updatedAPIProduct, who); err != nil {
+	if err = ds.updateAPIProduct(organizationName, &updatedAPIProduct, precondition, who); err != nil {
 		return nil, err
 	}
 	env := &audit.Environment{
@@ -88,7 +94,7 @@ func (ds *APIProductService) Update(organizationName string, updatedAPIProduct t
 
 // updateAPIProduct updates last-modified field(s) and updates apiproduct in database
 func (ds *APIProductService) updateAPIProduct(organizationName string,
-	updatedAPIProduct *types.APIProduct, who audit.Requester) types.Error {
+	updatedAPIProduct *types.APIProduct, precondition db.Precondition, who audit.Requester) types.Error {
 
 	updatedAPIProduct.Attributes.Tidy()
 	updatedAPIProduct.LastModifiedAt = shared.GetCurrentTimeMilliseconds()
@@ -97,12 +103,12 @@ func (ds *APIProductService) updateAPIProduct(organizationName string,
 	if err := updatedAPIProduct.Validate(); err != nil {
 		return types.NewBadRequestError(err)
 	}
-	return ds.db.APIProduct.Update(organizationName, updatedAPIProduct)
+	return ds.db.APIProduct.Update(organizationName, updatedAPIProduct, precondition)
 }
 
 // Delete deletes an apiproduct
 func (ds *APIProductService) Delete(organizationName, apiproductName string,
-	who audit.Requester) (e types.Error) {
+	precondition db.Precondition, who audit.Requester) (e types.Error) {
 
 	apiproduct, err := ds.Get(organizationName, apiproductName)
 	if err != nil {
@@ -117,7 +123,7 @@ func (ds *APIProductService) Delete(organizationName, apiproductName string,
 			fmt.Errorf("cannot delete api product '%s' assigned to %d keys",
 				apiproductName, keyWithAPIProduct))
 	}
-	if err := ds.db.APIProduct.Delete(organizationName, apiproductName); err != nil {
+	if err := ds.db.APIProduct.Delete(organizationName, apiproductName, precondition); err != nil {
 		return err
 	}
 	env := &audit.Environment{
@@ -0,0 +1,762 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/erikbos/gatekeeper/cmd/managementserver/audit"
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// ConflictStrategy determines how an import handles entities which already exist
+type ConflictStrategy string
+
+const (
+	// ConflictSkip leaves existing entities untouched
+	ConflictSkip ConflictStrategy = "skip"
+	// ConflictOverwrite replaces existing entities
+	ConflictOverwrite ConflictStrategy = "overwrite"
+	// ConflictFail rejects the import in case any entity already exists
+	ConflictFail ConflictStrategy = "fail"
+)
+
+// Actions taken for an imported entity
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionSkip   = "skip"
+)
+
+type (
+	// ConfigurationBundle holds the configuration of an organization and/or global entities
+	ConfigurationBundle struct {
+		Organization  *types.Organization
+		Developers    types.Developers
+		DeveloperApps types.DeveloperApps
+		Keys          types.Keys
+		APIProducts   types.APIProducts
+		Companies     types.Companies
+		Listeners     types.Listeners
+		Routes        types.Routes
+		Clusters      types.Clusters
+	}
+
+	// ImportOptions holds the options of an import
+	ImportOptions struct {
+		// Name of organization to import into, overrides organization name in bundle
+		Organization string
+		// Only determine what would change, without making changes
+		DryRun bool
+		// How to handle entities which already exist
+		Conflict ConflictStrategy
+	}
+
+	// ImportResult holds the action taken for one imported entity
+	ImportResult struct {
+		EntityType string
+		Name       string
+		Action     string
+	}
+
+	// ImportResults holds the actions taken for all imported entities
+	ImportResults []ImportResult
+)
+
+// BundleService exports and imports configuration bundles
+type BundleService struct {
+	db    *db.Database
+	audit *audit.Audit
+}
+
+// NewBundle returns a new bundle instance
+func NewBundle(database *db.Database, a *audit.Audit) *BundleService {
+
+	return &BundleService{
+		db:    database,
+		audit: a,
+	}
+}
+
+// ExportOrganization returns organization with all its developers, developer apps,
+// keys, apiproducts and companies. Consumer secrets are only included if requested.
+func (bs *BundleService) ExportOrganization(organizationName string,
+	includeSecrets bool) (*ConfigurationBundle, types.Error) {
+
+	organization, err := bs.db.Organization.Get(organizationName)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &ConfigurationBundle{
+		Organization: organization,
+	}
+	if bundle.Developers, err = bs.db.Developer.GetAll(organizationName); err != nil {
+		return nil, err
+	}
+	if bundle.DeveloperApps, err = bs.db.DeveloperApp.GetAll(organizationName); err != nil {
+		return nil, err
+	}
+	for _, developerApp := range bundle.DeveloperApps {
+		keys, err := bs.db.Key.GetByDeveloperAppID(organizationName, developerApp.AppID)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if !includeSecrets {
+				key.ConsumerSecret = ""
+			}
+			bundle.Keys = append(bundle.Keys, key)
+		}
+	}
+	if bundle.APIProducts, err = bs.db.APIProduct.GetAll(organizationName); err != nil {
+		return nil, err
+	}
+	if bundle.Companies, err = bs.db.Company.GetAll(organizationName); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// ExportGlobal returns all listeners, routes and clusters
+func (bs *BundleService) ExportGlobal() (*ConfigurationBundle, types.Error) {
+
+	var err types.Error
+	bundle := &ConfigurationBundle{}
+	if bundle.Listeners, err = bs.db.Listener.GetAll(); err != nil {
+		return nil, err
+	}
+	if bundle.Routes, err = bs.db.Route.GetAll(); err != nil {
+		return nil, err
+	}
+	if bundle.Clusters, err = bs.db.Cluster.GetAll(); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// Import stores all entities of a bundle, an audit record is written for each
+// created or updated entity.
+//
+// All entities are checked before any change is made: no change is made in case
+// an entity is invalid or conflicts with an existing entity. Entities are stored
+// one by one without rollback, in case storing an entity fails the results of
+// entities stored before are returned together with the error.
+func (bs *BundleService) Import(bundle ConfigurationBundle, options ImportOptions,
+	who audit.Requester) (ImportResults, types.Error) {
+
+	plan, err := bs.planImport(bundle, options, who)
+	if err != nil {
+		return nil, err
+	}
+	results := make(ImportResults, 0, len(plan.steps))
+	for _, step := range plan.steps {
+		if !options.DryRun && step.result.Action != ImportActionSkip {
+			if err := step.store(); err != nil {
+				return results, err
+			}
+			if step.result.Action == ImportActionCreate {
+				bs.audit.Create(step.updated, step.env, who)
+			} else {
+				bs.audit.Update(step.current, step.updated, step.env, who)
+			}
+		}
+		results = append(results, step.result)
+	}
+	return results, nil
+}
+
+// importStep is the planned change of one entity of a bundle
+type importStep struct {
+	result  ImportResult
+	current interface{}
+	updated interface{}
+	env     *audit.Environment
+	store   func() types.Error
+}
+
+// importPlan holds all changes required to import a bundle
+type importPlan struct {
+	db           *db.Database
+	conflict     ConflictStrategy
+	organization string
+	now          int64
+	user         string
+	steps        []importStep
+	// Developers and developer apps, as they will be stored, by their id in the bundle
+	developers    map[string]*types.Developer
+	developerApps map[string]*types.DeveloperApp
+	// Index of import step of each developer, by its id in the bundle
+	developerSteps map[string]int
+}
+
+// planImport determines changes required to import bundle
+func (bs *BundleService) planImport(bundle ConfigurationBundle, options ImportOptions,
+	who audit.Requester) (*importPlan, types.Error) {
+
+	switch options.Conflict {
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+	default:
+		return nil, types.NewBadRequestError(
+			fmt.Errorf("unknown conflict strategy '%s'", options.Conflict))
+	}
+
+	p := &importPlan{
+		db:             bs.db,
+		conflict:       options.Conflict,
+		organization:   options.Organization,
+		now:            shared.GetCurrentTimeMilliseconds(),
+		user:           who.User,
+		developers:     make(map[string]*types.Developer),
+		developerApps:  make(map[string]*types.DeveloperApp),
+		developerSteps: make(map[string]int),
+	}
+	if p.organization == "" && bundle.Organization != nil {
+		p.organization = bundle.Organization.Name
+	}
+	if p.organization != "" {
+		if err := p.planOrganization(bundle.Organization); err != nil {
+			return nil, err
+		}
+		if err := p.planAPIProducts(bundle.APIProducts); err != nil {
+			return nil, err
+		}
+		if err := p.planCompanies(bundle.Companies); err != nil {
+			return nil, err
+		}
+		if err := p.planDevelopers(bundle.Developers); err != nil {
+			return nil, err
+		}
+		if err := p.planDeveloperApps(bundle.DeveloperApps); err != nil {
+			return nil, err
+		}
+		if err := p.planKeys(bundle.Keys); err != nil {
+			return nil, err
+		}
+	} else if len(bundle.Developers) != 0 || len(bundle.DeveloperApps) != 0 || len(bundle.Keys) != 0 ||
+		len(bundle.APIProducts) != 0 || len(bundle.Companies) != 0 {
+		return nil, types.NewBadRequestError(
+			errors.New("organization to import developers, apps, keys, apiproducts and companies into is not set"))
+	}
+	if err := p.planClusters(bundle.Clusters); err != nil {
+		return nil, err
+	}
+	if err := p.planRoutes(bundle.Routes); err != nil {
+		return nil, err
+	}
+	if err := p.planListeners(bundle.Listeners); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// action returns the action to take for an entity, depending on whether it already exists
+func (p *importPlan) action(entityType, name string, exists bool) (string, types.Error) {
+
+	if !exists {
+		return ImportActionCreate, nil
+	}
+	switch p.conflict {
+	case ConflictSkip:
+		return ImportActionSkip, nil
+	case ConflictOverwrite:
+		return ImportActionUpdate, nil
+	default:
+		return "", types.NewConflictError(fmt.Errorf("%s '%s' already exists", entityType, name))
+	}
+}
+
+// exists returns whether an entity exists based upon the error of retrieving it
+func exists(err types.Error) (bool, types.Error) {
+
+	if err == nil {
+		return true, nil
+	}
+	if types.IsItemNotFoundError(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// planOrganization plans import of organization. An existing organization
+// does not cause a conflict: it is only updated when overwriting.
+func (p *importPlan) planOrganization(organization *types.Organization) types.Error {
+
+	current, err := p.db.Organization.Get(p.organization)
+	found, err := exists(err)
+	if err != nil {
+		return err
+	}
+	if organization == nil {
+		if !found {
+			return types.NewBadRequestError(
+				fmt.Errorf("organization '%s' does not exist", p.organization))
+		}
+		return nil
+	}
+
+	imported := *organization
+	imported.Name = p.organization
+	action := ImportActionCreate
+	if found {
+		action = ImportActionSkip
+		if p.conflict == ConflictOverwrite {
+			action = ImportActionUpdate
+		}
+		imported.CreatedAt, imported.CreatedBy = current.CreatedAt, current.CreatedBy
+	} else {
+		imported.CreatedAt, imported.CreatedBy = p.now, p.user
+	}
+	imported.LastModifiedAt, imported.LastModifiedBy = p.now, p.user
+	imported.Attributes.Tidy()
+	if err := imported.Validate(); err != nil {
+		return types.NewBadRequestError(err)
+	}
+	p.steps = append(p.steps, importStep{
+		result:  ImportResult{EntityType: types.TypeOrganizationName, Name: imported.Name, Action: action},
+		current: current,
+		updated: &imported,
+		env:     &audit.Environment{Organization: p.organization},
+		store: func() types.Error {
+			return p.db.Organization.Update(&imported, db.Precondition{})
+		},
+	})
+	return nil
+}
+
+// planAPIProducts plans import of apiproducts
+func (p *importPlan) planAPIProducts(apiproducts types.APIProducts) types.Error {
+
+	for _, apiproduct := range apiproducts {
+		current, err := p.db.APIProduct.Get(p.organization, apiproduct.Name)
+		found, err := exists(err)
+		if err != nil {
+			return err
+		}
+		action, err := p.action(types.TypeAPIProductName, apiproduct.Name, found)
+		if err != nil {
+			return err
+		}
+		imported := apiproduct
+		if found {
+			imported.CreatedAt, imported.CreatedBy = current.CreatedAt, current.CreatedBy
+		} else {
+			imported.CreatedAt, imported.CreatedBy = p.now, p.user
+		}
+		imported.LastModifiedAt, imported.LastModifiedBy = p.now, p.user
+		imported.Attributes.Tidy()
+		if err := imported.Validate(); err != nil {
+			return types.NewBadRequestError(err)
+		}
+		p.steps = append(p.steps, importStep{
+			result:  ImportResult{EntityType: types.TypeAPIProductName, Name: imported.Name, Action: action},
+			current: current,
+			updated: &imported,
+			env:     &audit.Environment{Organization: p.organization},
+			store: func() types.Error {
+				return p.db.APIProduct.Update(p.organization, &imported, db.Precondition{})
+			},
+		})
+	}
+	return nil
+}
+
+// planCompanies plans import of companies
+func (p *importPlan) planCompanies(companies types.Companies) types.Error {
+
+	for _, company := range companies {
+		current, err := p.db.Company.Get(p.organization, company.Name)
+		found, err := exists(err)
+		if err != nil {
+			return err
+		}
+		action, err := p.action(types.TypeCompanyName, company.Name, found)
+		if err != nil {
+			return err
+		}
+		imported := company
+		if found {
+			imported.CreatedAt, imported.CreatedBy = current.CreatedAt, current.CreatedBy
+		} else {
+			imported.CreatedAt, imported.CreatedBy = p.now, p.user
+		}
+		imported.LastModifiedAt, imported.LastModifiedBy = p.now, p.user
+		imported.Attributes.Tidy()
+		if err := imported.Validate(); err != nil {
+			return types.NewBadRequestError(err)
+		}
+		p.steps = append(p.steps, importStep{
+			result:  ImportResult{EntityType: types.TypeCompanyName, Name: imported.Name, Action: action},
+			current: current,
+			updated: &imported,
+			env:     &audit.Environment{Organization: p.organization, Company: imported.Name},
+			store: func() types.Error {
+				return p.db.Company.Update(p.organization, &imported, db.Precondition{})
+			},
+		})
+	}
+	return nil
+}
+
+// planDevelopers plans import of developers, an existing developer keeps its id
+// while a new developer gets a new id
+func (p *importPlan) planDevelopers(developers types.Developers) types.Error {
+
+	for _, developer := range developers {
+		current, err := p.db.Developer.GetByEmail(p.organization, developer.Email)
+		found, err := exists(err)
+		if err != nil {
+			return err
+		}
+		action, err := p.action(types.TypeDeveloperName, developer.Email, found)
+		if err != nil {
+			return err
+		}
+		imported := developer
+		// Apps are populated while planning import of developer apps
+		imported.Apps = nil
+		if found {
+			if action == ImportActionSkip {
+				imported = *current
+			}
+			imported.DeveloperID = current.DeveloperID
+			imported.Apps = current.Apps
+			imported.CreatedAt, imported.CreatedBy = current.CreatedAt, current.CreatedBy
+		} else {
+			imported.DeveloperID = generateDeveloperID(imported.Email)
+			imported.CreatedAt, imported.CreatedBy = p.now, p.user
+		}
+		imported.OrganizationName = p.organization
+		imported.LastModifiedAt, imported.LastModifiedBy = p.now, p.user
+		imported.Attributes.Tidy()
+		if err := imported.Validate(); err != nil {
+			return types.NewBadRequestError(err)
+		}
+		p.developers[developer.DeveloperID] = &imported
+		p.developerSteps[developer.DeveloperID] = len(p.steps)
+		p.steps = append(p.steps, importStep{
+			result:  ImportResult{EntityType: types.TypeDeveloperName, Name: imported.Email, Action: action},
+			current: current,
+			updated: &imported,
+			env:     &audit.Environment{Organization: p.organization, DeveloperID: imported.DeveloperID},
+			store: func() types.Error {
+				return p.db.Developer.Update(p.organization, &imported, db.Precondition{})
+			},
+		})
+	}
+	return nil
+}
+
+// developer returns developer as it will be stored, based upon its id in bundle
+func (p *importPlan) developer(developerID string) (*types.Developer, types.Error) {
+
+	if developer, ok := p.developers[developerID]; ok {
+		return developer, nil
+	}
+	current, err := p.db.Developer.GetByID(p.organization, developerID)
+	if err != nil {
+		if types.IsItemNotFoundError(err) {
+			return nil, types.NewBadRequestError(fmt.Errorf("developer '%s' does not exist", developerID))
+		}
+		return nil, err
+	}
+	developer := *current
+	p.developers[developerID] = &developer
+	return &developer, nil
+}
+
+// planDeveloperApps plans import of developer apps, an existing developer app keeps its id
+// while a new developer app gets a new id
+func (p *importPlan) planDeveloperApps(developerApps types.DeveloperApps) types.Error {
+
+	for _, developerApp := range developerApps {
+		developer, err := p.developer(developerApp.DeveloperID)
+		if err != nil {
+			return err
+		}
+		current, err := p.db.DeveloperApp.GetByName(p.organization, developer.Email, developerApp.Name)
+		found, err := exists(err)
+		if err != nil {
+			return err
+		}
+		action, err := p.action(types.TypeDeveloperAppName, developerApp.Name, found)
+		if err != nil {
+			return err
+		}
+		imported := developerApp
+		imported.DeveloperID = developer.DeveloperID
+		if found {
+			if action == ImportActionSkip {
+				imported = *current
+			}
+			imported.AppID = current.AppID
+			imported.CreatedAt, imported.CreatedBy = current.CreatedAt, current.CreatedBy
+		} else {
+			imported.AppID = generateAppID()
+			imported.CreatedAt, imported.CreatedBy = p.now, p.user
+		}
+		imported.LastModifiedAt, imported.LastModifiedBy = p.now, p.user
+		imported.Attributes.Tidy()
+		if err := imported.Validate(); err != nil {
+			return types.NewBadRequestError(err)
+		}
+		// Add app to the apps field of developer, using a copy so we do not
+		// modify the apps of the current developer
+		if !containsString(developer.Apps, imported.Name) {
+			developer.Apps = append(developer.Apps[:len(developer.Apps):len(developer.Apps)], imported.Name)
+			if err := p.planDeveloperAppsUpdate(developerApp.DeveloperID, developer); err != nil {
+				return err
+			}
+		}
+		p.developerApps[developerApp.AppID] = &imported
+		p.steps = append(p.steps, importStep{
+			result:  ImportResult{EntityType: types.TypeDeveloperAppName, Name: imported.Name, Action: action},
+			current: current,
+			updated: &imported,
+			env: &audit.Environment{
+				Organization: p.organization,
+				DeveloperID:  imported.DeveloperID,
+				AppID:        imported.AppID,
+			},
+			store: func() types.Error {
+				return p.db.DeveloperApp.Update(p.organization, &imported, db.Precondition{})
+			},
+		})
+	}
+	return nil
+}
+
+// planDeveloperAppsUpdate makes sure a developer gets stored after its apps have changed:
+// a skipped developer gets updated, a developer not in bundle gets an update step
+func (p *importPlan) planDeveloperAppsUpdate(developerID string, developer *types.Developer) types.Error {
+
+	if i, ok := p.developerSteps[developerID]; ok {
+		if p.steps[i].result.Action == ImportActionSkip {
+			p.steps[i].result.Action = ImportActionUpdate
+		}
+		return nil
+	}
+	current, err := p.db.Developer.GetByID(p.organization, developer.DeveloperID)
+	if err != nil {
+		return err
+	}
+	developer.LastModifiedAt, developer.LastModifiedBy = p.now, p.user
+	p.developerSteps[developerID] = len(p.steps)
+	p.steps = append(p.steps, importStep{
+		result:  ImportResult{EntityType: types.TypeDeveloperName, Name: developer.Email, Action: ImportActionUpdate},
+		current: current,
+		updated: developer,
+		env:     &audit.Environment{Organization: p.organization, DeveloperID: developer.DeveloperID},
+		store: func() types.Error {
+			return p.db.Developer.Update(p.organization, developer, db.Precondition{})
+		},
+	})
+	return nil
+}
+
+// developerApp returns developer app as it will be stored, based upon its id in bundle
+func (p *importPlan) developerApp(appID string) (*types.DeveloperApp, types.Error) {
+
+	if developerApp, ok := p.developerApps[appID]; ok {
+		return developerApp, nil
+	}
+	current, err := p.db.DeveloperApp.GetByID(p.organization, appID)
+	if err != nil {
+		if types.IsItemNotFoundError(err) {
+			return nil, types.NewBadRequestError(fmt.Errorf("developer app '%s' does not exist", appID))
+		}
+		return nil, err
+	}
+	developerApp := *current
+	p.developerApps[appID] = &developerApp
+	return &developerApp, nil
+}
+
+// planKeys plans import of keys. A key without consumer secret keeps the secret
+// of the existing key, or gets a new secret generated.
+func (p *importPlan) planKeys(keys types.Keys) types.Error {
+
+	for _, key := range keys {
+		developerApp, err := p.developerApp(key.AppID)
+		if err != nil {
+			return err
+		}
+		// Consumer keys are unique across organizations
+		current, err := p.db.Key.GetByKey(nil, &key.ConsumerKey)
+		found, err := exists(err)
+		if err != nil {
+			return err
+		}
+		if found {
+			_, err := p.db.Key.GetByKey(&p.organization, &key.ConsumerKey)
+			if types.IsItemNotFoundError(err) {
+				return types.NewConflictError(
+					fmt.Errorf("key '%s' exists in another organization", key.ConsumerKey))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		action, err := p.action(types.TypeKeyName, key.ConsumerKey, found)
+		if err != nil {
+			return err
+		}
+		imported := key
+		imported.AppID = developerApp.AppID
+		if found {
+			if imported.ConsumerSecret == "" {
+				imported.ConsumerSecret = current.ConsumerSecret
+			}
+			if imported.Scopes == nil {
+				imported.Scopes = current.Scopes
+			}
+		} else if imported.ConsumerSecret == "" {
+			imported.ConsumerSecret = generateConsumerSecret()
+		}
+		if imported.IssuedAt == 0 {
+			imported.IssuedAt = p.now
+		}
+		if imported.ExpiresAt == 0 {
+			imported.ExpiresAt = -1
+		}
+		if imported.Status == "" {
+			imported.Approved()
+		}
+		if err := imported.Validate(); err != nil {
+			return types.NewBadRequestError(err)
+		}
+		p.steps = append(p.steps, importStep{
+			result:  ImportResult{EntityType: types.TypeKeyName, Name: imported.ConsumerKey, Action: action},
+			current: current,
+			updated: &imported,
+			env: &audit.Environment{
+				Organization: p.organization,
+				DeveloperID:  developerApp.DeveloperID,
+				AppID:        developerApp.AppID,
+			},
+			store: func() types.Error {
+				return p.db.Key.UpdateByKey(p.organization, &imported)
+			},
+		})
+	}
+	return nil
+}
+
+// planClusters plans import of clusters
+func (p *importPlan) planClusters(clusters types.Clusters) types.Error {
+
+	for _, cluster := range clusters {
+		current, err := p.db.Cluster.Get(cluster.Name)
+		found, err := exists(err)
+		if err != nil {
+			return err
+		}
+		action, err := p.action(types.TypeClusterName, cluster.Name, found)
+		if err != nil {
+			return err
+		}
+		imported := cluster
+		if found {
+			imported.CreatedAt, imported.CreatedBy = current.CreatedAt, current.CreatedBy
+		} else {
+			imported.CreatedAt, imported.CreatedBy = p.now, p.user
+		}
+		imported.LastModifiedAt, imported.LastModifiedBy = p.now, p.user
+		imported.Attributes.Tidy()
+		if err := imported.Validate(); err != nil {
+			return types.NewBadRequestError(err)
+		}
+		p.steps = append(p.steps, importStep{
+			result:  ImportResult{EntityType: types.TypeClusterName, Name: imported.Name, Action: action},
+			current: current,
+			updated: &imported,
+			store: func() types.Error {
+				return p.db.Cluster.Update(&imported, db.Precondition{})
+			},
+		})
+	}
+	return nil
+}
+
+// planRoutes plans import of routes
+func (p *importPlan) planRoutes(routes types.Routes) types.Error {
+
+	for _, route := range routes {
+		current, err := p.db.Route.Get(route.Name)
+		found, err := exists(err)
+		if err != nil {
+			return err
+		}
+		action, err := p.action(types.TypeRouteName, route.Name, found)
+		if err != nil {
+			return err
+		}
+		imported := route
+		if found {
+			imported.CreatedAt, imported.CreatedBy = current.CreatedAt, current.CreatedBy
+		} else {
+			imported.CreatedAt, imported.CreatedBy = p.now, p.user
+		}
+		imported.LastModifiedAt, imported.LastModifiedBy = p.now, p.user
+		imported.Attributes.Tidy()
+		if err := imported.Validate(); err != nil {
+			return types.NewBadRequestError(err)
+		}
+		p.steps = append(p.steps, importStep{
+			result:  ImportResult{EntityType: types.TypeRouteName, Name: imported.Name, Action: action},
+			current: current,
+			updated: &imported,
+			store: func() types.Error {
+				return p.db.Route.Update(&imported, db.Precondition{})
+			},
+		})
+	}
+	return nil
+}
+
+// planListeners plans import of listeners
+func (p *importPlan) planListeners(listeners types.Listeners) types.Error {
+
+	for _, listener := range listeners {
+		current, err := p.db.Listener.Get(listener.Name)
+		found, err := exists(err)
+		if err != nil {
+			return err
+		}
+		action, err := p.action(types.TypeListenerName, listener.Name, found)
+		if err != nil {
+			return err
+		}
+		imported := listener
+		if found {
+			imported.CreatedAt, imported.CreatedBy = current.CreatedAt, current.CreatedBy
+		} else {
+			imported.CreatedAt, imported.CreatedBy = p.now, p.user
+		}
+		imported.LastModifiedAt, imported.LastModifiedBy = p.now, p.user
+		imported.Attributes.Tidy()
+		if err := imported.Validate(); err != nil {
+			return types.NewBadRequestError(err)
+		}
+		p.steps = append(p.steps, importStep{
+			result:  ImportResult{EntityType: types.TypeListenerName, Name: imported.Name, Action: action},
+			current: current,
+			updated: &imported,
+			store: func() types.Error {
+				return p.db.Listener.Update(&imported, db.Precondition{})
+			},
+		})
+	}
+	return nil
+}
+
+// containsString returns true if s is present in slice
+func containsString(slice []string, s string) bool {
+
+	for _, element := range slice {
+		if element == s {
+			return true
+		}
+	}
+	return false
+}
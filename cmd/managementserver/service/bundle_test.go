@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/cmd/managementserver/audit"
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/db/memory"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func newTestService(t *testing.T) *Service {
+
+	database, err := memory.New(t.Name(), zap.NewNop())
+	require.NoError(t, err)
+	return New(database, audit.New(database, nil, zap.NewNop()))
+}
+
+func Test_Bundle_ExportImport(t *testing.T) {
+
+	who := audit.Requester{User: "test"}
+
+	source := newTestService(t)
+	_, err := source.Organization.Create(types.Organization{Name: "staging"}, who)
+	require.Nil(t, err)
+	developer, err := source.Developer.Create("staging",
+		types.Developer{Email: "joe@example.com", OrganizationName: "staging"}, who)
+	require.Nil(t, err)
+	app, err := source.DeveloperApp.Create("staging", developer.Email, types.DeveloperApp{Name: "app"}, who)
+	require.Nil(t, err)
+	key, err := source.Key.Create("staging", developer.Email, app.Name, types.Key{}, who)
+	require.Nil(t, err)
+
+	bundle, err := source.Bundle.ExportOrganization("staging", false)
+	require.Nil(t, err)
+	require.Len(t, bundle.Keys, 1)
+	require.Empty(t, bundle.Keys[0].ConsumerSecret)
+
+	// Dry run should not make any changes
+	target := newTestService(t)
+	options := ImportOptions{Organization: "production", Conflict: ConflictFail, DryRun: true}
+	results, err := target.Bundle.Import(*bundle, options, who)
+	require.Nil(t, err)
+	require.Equal(t, ImportResults{
+		{EntityType: types.TypeOrganizationName, Name: "production", Action: ImportActionCreate},
+		{EntityType: types.TypeDeveloperName, Name: developer.Email, Action: ImportActionCreate},
+		{EntityType: types.TypeDeveloperAppName, Name: app.Name, Action: ImportActionCreate},
+		{EntityType: types.TypeKeyName, Name: key.ConsumerKey, Action: ImportActionCreate},
+	}, results)
+	_, err = target.Organization.Get("production")
+	require.True(t, types.IsItemNotFoundError(err))
+
+	options.DryRun = false
+	_, err = target.Bundle.Import(*bundle, options, who)
+	require.Nil(t, err)
+	importedDeveloper, err := target.Developer.Get("production", developer.Email)
+	require.Nil(t, err)
+	require.Equal(t, []string{app.Name}, importedDeveloper.Apps)
+	importedApp, err := target.DeveloperApp.GetByName("production", developer.Email, app.Name)
+	require.Nil(t, err)
+	require.Equal(t, importedDeveloper.DeveloperID, importedApp.DeveloperID)
+	importedKey, err := target.Key.Get("production", developer.Email, app.Name, key.ConsumerKey)
+	require.Nil(t, err)
+	require.Equal(t, importedApp.AppID, importedKey.AppID)
+	require.NotEmpty(t, importedKey.ConsumerSecret)
+
+	// Second import conflicts with entities imported before
+	_, err = target.Bundle.Import(*bundle, options, who)
+	require.Equal(t, 409, types.HTTPStatusCode(err))
+
+	options.Conflict = ConflictSkip
+	results, err = target.Bundle.Import(*bundle, options, who)
+	require.Nil(t, err)
+	for _, result := range results {
+		require.Equal(t, ImportActionSkip, result.Action)
+	}
+
+	// Overwrite keeps ids and consumer secret of existing entities
+	bundle.Developers[0].FirstName = "Joe"
+	options.Conflict = ConflictOverwrite
+	_, err = target.Bundle.Import(*bundle, options, who)
+	require.Nil(t, err)
+	updatedDeveloper, err := target.Developer.Get("production", developer.Email)
+	require.Nil(t, err)
+	require.Equal(t, "Joe", updatedDeveloper.FirstName)
+	require.Equal(t, importedDeveloper.DeveloperID, updatedDeveloper.DeveloperID)
+	updatedKey, err := target.Key.Get("production", developer.Email, app.Name, key.ConsumerKey)
+	require.Nil(t, err)
+	require.Equal(t, importedKey.ConsumerSecret, updatedKey.ConsumerSecret)
+
+	// Keys cannot be imported in another organization
+	options.Organization = "acceptance"
+	_, err = target.Bundle.Import(*bundle, options, who)
+	require.Equal(t, 409, types.HTTPStatusCode(err))
+}
+
+func Test_Bundle_ImportAppOfExistingDeveloper(t *testing.T) {
+
+	who := audit.Requester{User: "test"}
+
+	s := newTestService(t)
+	_, err := s.Organization.Create(types.Organization{Name: "production"}, who)
+	require.Nil(t, err)
+	developer, err := s.Developer.Create("production",
+		types.Developer{Email: "joe@example.com", OrganizationName: "production"}, who)
+	require.Nil(t, err)
+	_, err = s.DeveloperApp.Create("production", developer.Email, types.DeveloperApp{Name: "app"}, who)
+	require.Nil(t, err)
+	developer, err = s.Developer.Get("production", developer.Email)
+	require.Nil(t, err)
+
+	// Developer in bundle is skipped, but still gets new app added to its apps
+	bundle := ConfigurationBundle{
+		Developers:    types.Developers{*developer},
+		DeveloperApps: types.DeveloperApps{{Name: "mobile", DeveloperID: developer.DeveloperID}},
+	}
+	options := ImportOptions{Organization: "production", Conflict: ConflictSkip}
+	results, err := s.Bundle.Import(bundle, options, who)
+	require.Nil(t, err)
+	require.Equal(t, ImportResults{
+		{EntityType: types.TypeDeveloperName, Name: developer.Email, Action: ImportActionUpdate},
+		{EntityType: types.TypeDeveloperAppName, Name: "mobile", Action: ImportActionCreate},
+	}, results)
+	updatedDeveloper, err := s.Developer.Get("production", developer.Email)
+	require.Nil(t, err)
+	require.ElementsMatch(t, []string{"app", "mobile"}, updatedDeveloper.Apps)
+
+	// Developer not in bundle gets new app added to its apps
+	bundle = ConfigurationBundle{
+		DeveloperApps: types.DeveloperApps{{Name: "web", DeveloperID: developer.DeveloperID}},
+	}
+	results, err = s.Bundle.Import(bundle, options, who)
+	require.Nil(t, err)
+	require.Equal(t, ImportResults{
+		{EntityType: types.TypeDeveloperName, Name: developer.Email, Action: ImportActionUpdate},
+		{EntityType: types.TypeDeveloperAppName, Name: "web", Action: ImportActionCreate},
+	}, results)
+	updatedDeveloper, err = s.Developer.Get("production", developer.Email)
+	require.Nil(t, err)
+	require.ElementsMatch(t, []string{"app", "mobile", "web"}, updatedDeveloper.Apps)
+
+	// Importing an app the developer already has does not change developer
+	results, err = s.Bundle.Import(bundle, options, who)
+	require.Nil(t, err)
+	require.Equal(t, ImportResults{
+		{EntityType: types.TypeDeveloperAppName, Name: "web", Action: ImportActionSkip},
+	}, results)
+}
+
+// failingKeyStore fails storing keys, and retrieving keys within an organization
+type failingKeyStore struct {
+	db.Key
+}
+
+func (s *failingKeyStore) GetByKey(organizationName, key *string) (*types.Key, types.Error) {
+
+	if organizationName != nil {
+		return nil, types.NewDatabaseError(errors.New("database unavailable"))
+	}
+	return s.Key.GetByKey(organizationName, key)
+}
+
+func (s *failingKeyStore) UpdateByKey(organizationName string, key *types.Key) types.Error {
+
+	return types.NewDatabaseError(errors.New("database unavailable"))
+}
+
+func Test_Bundle_ImportStoreFailure(t *testing.T) {
+
+	who := audit.Requester{User: "test"}
+
+	database, err := memory.New(t.Name(), zap.NewNop())
+	require.NoError(t, err)
+	database.Key = &failingKeyStore{Key: database.Key}
+	s := New(database, audit.New(database, nil, zap.NewNop()))
+
+	bundle := ConfigurationBundle{
+		Organization:  &types.Organization{Name: "production"},
+		Developers:    types.Developers{{DeveloperID: "dev1", Email: "joe@example.com"}},
+		DeveloperApps: types.DeveloperApps{{AppID: "app1", Name: "app", DeveloperID: "dev1"}},
+		Keys:          types.Keys{{ConsumerKey: "abc", AppID: "app1"}},
+	}
+	options := ImportOptions{Organization: "production", Conflict: ConflictFail}
+	results, importErr := s.Bundle.Import(bundle, options, who)
+	require.Equal(t, 503, types.HTTPStatusCode(importErr))
+	// Entities stored before key are reported
+	require.Equal(t, ImportResults{
+		{EntityType: types.TypeOrganizationName, Name: "production", Action: ImportActionCreate},
+		{EntityType: types.TypeDeveloperName, Name: "joe@example.com", Action: ImportActionCreate},
+		{EntityType: types.TypeDeveloperAppName, Name: "app", Action: ImportActionCreate},
+	}, results)
+}
+
+func Test_Bundle_ImportKeyLookupFailure(t *testing.T) {
+
+	who := audit.Requester{User: "test"}
+
+	database, err := memory.New(t.Name(), zap.NewNop())
+	require.NoError(t, err)
+	require.Nil(t, database.Key.UpdateByKey("production", &types.Key{ConsumerKey: "abc", AppID: "app1"}))
+	database.Key = &failingKeyStore{Key: database.Key}
+	s := New(database, audit.New(database, nil, zap.NewNop()))
+
+	bundle := ConfigurationBundle{
+		Organization:  &types.Organization{Name: "production"},
+		Developers:    types.Developers{{DeveloperID: "dev1", Email: "joe@example.com"}},
+		DeveloperApps: types.DeveloperApps{{AppID: "app1", Name: "app", DeveloperID: "dev1"}},
+		Keys:          types.Keys{{ConsumerKey: "abc", AppID: "app1"}},
+	}
+	options := ImportOptions{Organization: "production", Conflict: ConflictOverwrite}
+	_, importErr := s.Bundle.Import(bundle, options, who)
+	// Database failure is not reported as key existing in another organization
+	require.Equal(t, 503, types.HTTPStatusCode(importErr))
+}
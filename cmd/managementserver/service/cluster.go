@@ -58,7 +58,7 @@ func (cs *ClusterService) Create(newCluster types.Cluster, who audit.Requester)
 	newCluster.CreatedAt = shared.GetCurrentTimeMilliseconds()
 	newCluster.CreatedBy = who.User
 
-	if err := cs.updateCluster(&newCluster, who); err != nil {
+	if err := cs.updateCluster(&newCluster, db.Precondition{}, who); err != nil {
 		return nil, err
 	}
 	cs.audit.Create(newCluster, nil, who)
@@ -67,7 +67,7 @@ func (cs *ClusterService) Create(newCluster types.Cluster, who audit.Requester)
 
 // Update updates an existing cluster
 func (cs *ClusterService) Update(updatedCluster types.Cluster,
-	who audit.Requester) (*types.Cluster, types.Error) {
+	precondition db.Precondition, who audit.Requester) (*types.Cluster, types.Error) {
 
 	currentCluster, err := cs.db.Cluster.Get(updatedCluster.Name)
 	if err != nil {
@@ -78,7 +78,7 @@ func (cs *ClusterService) Update(updatedCluster types.Cluster,
 	updatedCluster.CreatedAt = currentCluster.CreatedAt
 	updatedCluster.CreatedBy = currentCluster.CreatedBy
 
-	if err = cs.updateCluster(&updatedCluster, who); err != nil {
+	if err = cs.updateCluster(&updatedCluster, precondition, who); err != nil {
 		return nil, err
 	}
 	cs.audit.Update(currentCluster, updatedCluster, nil, who)
@@ -86,7 +86,8 @@ func (cs *ClusterService) Update(updatedCluster types.Cluster,
 }
 
 // updateCluster updates last-modified field(s) and updates cluster in database
-func (cs *ClusterService) updateCluster(updatedCluster *types.Cluster, who audit.Requester) types.Error {
+func (cs *ClusterService) updateCluster(updatedCluster *types.Cluster,
+	precondition db.Precondition, who audit.Requester) types.Error {
 
 	updatedCluster.Attributes.Tidy()
 	updatedCluster.LastModifiedAt = shared.GetCurrentTimeMilliseconds()
@@ -95,17 +96,18 @@ func (cs *ClusterService) updateCluster(updatedCluster *types.Cluster, who audit
 	if err := updatedCluster.Validate(); err != nil {
 		return types.NewBadRequestError(err)
 	}
-	return cs.db.Cluster.Update(updatedCluster)
+	return cs.db.Cluster.Update(updatedCluster, precondition)
 }
 
 // Delete deletes an cluster
-func (cs *ClusterService) Delete(clusterName string, who audit.Requester) (e types.Error) {
+func (cs *ClusterService) Delete(clusterName string,
+	precondition db.Precondition, who audit.Requester) (e types.Error) {
 
 	cluster, err := cs.db.Cluster.Get(clusterName)
 	if err != nil {
 		return err
 	}
-	if err := cs.db.Cluster.Delete(clusterName); err != nil {
+	if err := cs.db.Cluster.Delete(clusterName, precondition); err != nil {
 		return err
 	}
 	cs.audit.Delete(cluster, nil, who)
@@ -30,6 +30,12 @@ func (ds *CompanyService) GetAll(organizationName string) (companys types.Compan
 	return ds.db.Company.GetAll(organizationName)
 }
 
+// List returns one page of companies
+func (ds *CompanyService) List(organizationName string, params db.ListParams) (companys types.Companies, nextPageToken string, err types.Error) {
+
+	return ds.db.Company.List(organizationName, params)
+}
+
 // Get returns details of a company
 func (ds *CompanyService) Get(organizationName, companyName string) (company *types.Company, err types.Error) {
 
@@ -49,7 +55,7 @@ func (ds *CompanyService) Create(organizationName string, newCompany types.Compa
 	newCompany.CreatedBy = who.User
 	newCompany.Activate()
 
-	if err := ds.updateCompany(organizationName, &newCompany, who); err != nil {
+	if err := ds.updateCompany(organizationName, &newCompany, db.Precondition{}, who); err != nil {
 		return nil, err
 	}
 	env := &audit.Environment{
@@ -62,7 +68,7 @@ func (ds *CompanyService) Create(organizationName string, newCompany types.Compa
 
 // Update updates an existing company
 func (ds *CompanyService) Update(organizationName string, updatedCompany types.Company,
-	who audit.Requester) (*types.Company, types.Error) {
+	precondition db.Precondition, who audit.Requester) (*types.Company, types.Error) {
 
 	currentCompany, err := ds.db.Company.Get(organizationName, updatedCompany.Name)
 	if err != nil {
@@ -74,7 +80,7 @@ func (ds *CompanyService) Update(organizationName string, updatedCompany types.C
 	updatedCompany.CreatedAt = currentCompany.CreatedAt
 	updatedCompany.CreatedBy = currentCompany.CreatedBy
 
-	if err = ds.updateCompany(organizationName, &updatedCompany, who); err != nil {
+	if err = ds.updateCompany(organizationName, &updatedCompany, precondition, who); err != nil {
 		return nil, err
 	}
 	env := &audit.Environment{
@@ -87,7 +93,7 @@ func (ds *CompanyService) Update(organizationName string, updatedCompany types.C
 
 // updateCompany updates last-modified field(s) and updates company in database
 func (ds *CompanyService) updateCompany(organizationName string,
-	updatedCompany *types.Company, who audit.Requester) types.Error {
+	updatedCompany *types.Company, precondition db.Precondition, who audit.Requester) types.Error {
 
 	updatedCompany.Attributes.Tidy()
 	// We always set these value, regardless of whether they were provided as part of update
@@ -100,12 +106,12 @@ func (ds *CompanyService) updateCompany(organizationName string,
 	if err := updatedCompany.Validate(); err != nil {
 		return types.NewBadRequestError(err)
 	}
-	return ds.db.Company.Update(organizationName, updatedCompany)
+	return ds.db.Company.Update(organizationName, updatedCompany, precondition)
 }
 
 // Delete deletes an company
 func (ds *CompanyService) Delete(organizationName, companyName string,
-	who audit.Requester) (e types.Error) {
+	precondition db.Precondition, who audit.Requester) (e types.Error) {
 
 	company, err := ds.Get(organizationName, companyName)
 	if err != nil {
@@ -121,7 +127,7 @@ func (ds *CompanyService) Delete(organizationName, companyName string,
 	// 		fmt.Errorf("cannot delete api product '%s' assigned to %d keys",
 	// 			companyName, keyWithCompany))
 	// }
-	if err := ds.db.Company.Delete(organizationName, companyName); err != nil {
+	if err := ds.db.Company.Delete(organizationName, companyName, precondition); err != nil {
 		return err
 	}
 	env := &audit.Environment{
@@ -33,6 +33,12 @@ func (ds *DeveloperService) GetAll(organizationName string) (developers types.De
 	return ds.db.Developer.GetAll(organizationName)
 }
 
+// List returns one page of developers
+func (ds *DeveloperService) List(organizationName string, params db.ListParams) (developers types.Developers, nextPageToken string, err types.Error) {
+
+	return ds.db.Developer.List(organizationName, params)
+}
+
 // Get returns details of an developer, in case name contains a @ assumption is developerId was provided
 func (ds *DeveloperService) Get(organizationName, developerName string) (developer *types.Developer, err types.Error) {
 
@@ -56,7 +62,7 @@ func (ds *DeveloperService) Create(organizationName string, newDeveloper types.D
 	newDeveloper.CreatedBy = who.User
 	newDeveloper.Activate()
 
-	if err := ds.updateDeveloper(organizationName, &newDeveloper, who); err != nil {
+	if err := ds.updateDeveloper(organizationName, &newDeveloper, db.Precondition{}, who); err != nil {
 		return nil, err
 	}
 	env := &audit.Environment{
@@ -68,8 +74,8 @@ func (ds *DeveloperService) Create(organizationName string, newDeveloper types.D
 }
 
 // Update updates an existing developer
-func (ds *DeveloperService) Update(organizationName, developerEmail string, updatedDeveloper types.Developer, who audit.Requester) (
-	*types.Developer, types.Error) {
+func (ds *DeveloperService) Update(organizationName, developerEmail string, updatedDeveloper types.Developer,
+	precondition db.Precondition, who audit.Requester) (*types.Developer, types.Error) {
 
 	currentDeveloper, err := ds.db.Developer.GetByEmail(organizationName, developerEmail)
 	if err != nil {
@@ -82,7 +88,7 @@ func (ds *DeveloperService) Update(organizationName, developerEmail string, upda
 	updatedDeveloper.CreatedAt = currentDeveloper.CreatedAt
 	updatedDeveloper.CreatedBy = currentDeveloper.CreatedBy
 
-	if err = ds.updateDeveloper(organizationName, &updatedDeveloper, who); err != nil {
+	if err = ds.updateDeveloper(organizationName, &updatedDeveloper, precondition, who); err != nil {
 		return nil, err
 	}
 	env := &audit.Environment{
@@ -95,7 +101,7 @@ func (ds *DeveloperService) Update(organizationName, developerEmail string, upda
 
 // updateDeveloper updates last-modified field(s) and updates developer in database
 func (ds *DeveloperService) updateDeveloper(organizationName string,
-	updatedDeveloper *types.Developer, who audit.Requester) types.Error {
+	updatedDeveloper *types.Developer, precondition db.Precondition, who audit.Requester) types.Error {
 
 	updatedDeveloper.Attributes.Tidy()
 	updatedDeveloper.LastModifiedAt = shared.GetCurrentTimeMilliseconds()
@@ -104,11 +110,12 @@ func (ds *DeveloperService) updateDeveloper(organizationName string,
 	if err := updatedDeveloper.Validate(); err != nil {
 		return types.NewBadRequestError(err)
 	}
-	return ds.db.Developer.Update(organizationName, updatedDeveloper)
+	return ds.db.Developer.Update(organizationName, updatedDeveloper, precondition)
 }
 
 // Delete deletes an developer
-func (ds *DeveloperService) Delete(organizationName, developerName string, who audit.Requester) (e types.Error) {
+func (ds *DeveloperService) Delete(organizationName, developerName string,
+	precondition db.Precondition, who audit.Requester) (e types.Error) {
 
 	developer, err := ds.Get(organizationName, developerName)
 	if err != nil {
@@ -123,7 +130,7 @@ func (ds *DeveloperService) Delete(organizationName, developerName string, who a
 			fmt.Errorf("cannot delete developer '%s' with %d active applications",
 				developer.Email, appCountOfDeveloper))
 	}
-	if err := ds.db.Developer.DeleteByID(organizationName, developer.DeveloperID); err != nil {
+	if err := ds.db.Developer.DeleteByID(organizationName, developer.DeveloperID, precondition); err != nil {
 		return err
 	}
 	env := &audit.Environment{
@@ -32,6 +32,12 @@ func (das *DeveloperAppService) GetAll(organizationName string) (developerApps t
 	return das.db.DeveloperApp.GetAll(organizationName)
 }
 
+// List returns one page of developer apps
+func (das *DeveloperAppService) List(organizationName string, params db.ListParams) (developerApps types.DeveloperApps, nextPageToken string, err types.Error) {
+
+	return das.db.DeveloperApp.List(organizationName, params)
+}
+
 // GetAll returns all developer apps of one develoepr
 func (das *DeveloperAppService) GetAllByEmail(organizationName, developerEmail string) (developerApps types.DeveloperApps, err types.Error) {
 
@@ -82,7 +88,7 @@ func (das *DeveloperAppService) Create(organizationName, developerEmail string,
 	// New developer starts approved
 	newDeveloperApp.Approve()
 
-	if err = das.updateDeveloperApp(organizationName, &newDeveloperApp, who); err != nil {
+	if err = das.updateDeveloperApp(organizationName, &newDeveloperApp, db.Precondition{}, who); err != nil {
 		return nil, err
 	}
 	env := &audit.Environment{
@@ -94,7 +100,7 @@ func (das *DeveloperAppService) Create(organizationName, developerEmail string,
 
 	// Add app to the apps field in developer entity
 	developer.Apps = append(developer.Apps, newDeveloperApp.Name)
-	if err := das.db.Developer.Update(organizationName, developer); err != nil {
+	if err := das.db.Developer.Update(organizationName, developer, db.Precondition{}); err != nil {
 		return &newDeveloperApp, err
 	}
 
@@ -103,7 +109,7 @@ func (das *DeveloperAppService) Create(organizationName, developerEmail string,
 
 // Update updates an existing developerApp
 func (das *DeveloperAppService) Update(organizationName, developerEmail string, updatedDeveloperApp types.DeveloperApp,
-	who audit.Requester) (*types.DeveloperApp, types.Error) {
+	precondition db.Precondition, who audit.Requester) (*types.DeveloperApp, types.Error) {
 
 	currentDeveloperApp, err := das.db.DeveloperApp.GetByName(organizationName, developerEmail, updatedDeveloperApp.Name)
 	if err != nil {
@@ -117,7 +123,7 @@ func (das *DeveloperAppService) Update(organizationName, developerEmail string,
 	updatedDeveloperApp.CreatedAt = currentDeveloperApp.CreatedAt
 	updatedDeveloperApp.CreatedBy = currentDeveloperApp.CreatedBy
 
-	if err = das.updateDeveloperApp(organizationName, &updatedDeveloperApp, who); err != nil {
+	if err = das.updateDeveloperApp(organizationName, &updatedDeveloperApp, precondition, who); err != nil {
 		return nil, err
 	}
 	env := &audit.Environment{
@@ -126,12 +132,24 @@ func (das *DeveloperAppService) Update(organizationName, developerEmail string,
 		AppID:        updatedDeveloperApp.AppID,
 	}
 	das.audit.Update(currentDeveloperApp, updatedDeveloperApp, env, who)
+
+	// Tokens issued to keys of a revoked developer app should not be usable anymore
+	if updatedDeveloperApp.IsRevoked() {
+		keys, err := das.db.Key.GetByDeveloperAppID(organizationName, updatedDeveloperApp.AppID)
+		if err != nil && !types.IsItemNotFoundError(err) {
+			return nil, err
+		}
+		if _, err := revokeOAuthTokens(das.db, das.audit, organizationName,
+			&updatedDeveloperApp, keys, who); err != nil {
+			return nil, err
+		}
+	}
 	return &updatedDeveloperApp, nil
 }
 
 // updateDeveloperApp updates last-modified field(s) and updates developer app in database
 func (das *DeveloperAppService) updateDeveloperApp(organizationName string,
-	updatedDeveloperApp *types.DeveloperApp, who audit.Requester) types.Error {
+	updatedDeveloperApp *types.DeveloperApp, precondition db.Precondition, who audit.Requester) types.Error {
 
 	updatedDeveloperApp.Attributes.Tidy()
 	updatedDeveloperApp.LastModifiedAt = shared.GetCurrentTimeMilliseconds()
@@ -140,12 +158,12 @@ func (das *DeveloperAppService) updateDeveloperApp(organizationName string,
 	if err := updatedDeveloperApp.Validate(); err != nil {
 		return types.NewBadRequestError(err)
 	}
-	return das.db.DeveloperApp.Update(organizationName, updatedDeveloperApp)
+	return das.db.DeveloperApp.Update(organizationName, updatedDeveloperApp, precondition)
 }
 
 // Delete deletes an developerApp
 func (das *DeveloperAppService) Delete(organizationName, developerEmail, developerAppName string,
-	who audit.Requester) (e types.Error) {
+	precondition db.Precondition, who audit.Requester) (e types.Error) {
 
 	developer, err := das.db.Developer.GetByEmail(organizationName, developerEmail)
 	if err != nil {
@@ -155,7 +173,15 @@ func (das *DeveloperAppService) Delete(organizationName, developerEmail, develop
 	if err != nil {
 		return err
 	}
+	// Check precondition before removing keys of developer app
+	if !precondition.Holds(developerApp.LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeDeveloperAppName, developerAppName)
+	}
 	developerAppKeys, _ := das.db.Key.GetByDeveloperAppID(organizationName, developerApp.AppID)
+	if _, err := revokeOAuthTokens(das.db, das.audit, organizationName,
+		developerApp, developerAppKeys, who); err != nil {
+		return err
+	}
 	if len(developerAppKeys) != 0 {
 		for _, k := range developerAppKeys {
 			if err := das.db.Key.DeleteByKey(organizationName, k.ConsumerKey); err != nil {
@@ -165,7 +191,7 @@ func (das *DeveloperAppService) Delete(organizationName, developerEmail, develop
 	}
 	// TODO
 	// FIXME, this needs to move to db layer
-	err = das.db.DeveloperApp.DeleteByID(organizationName, developerApp.AppID)
+	err = das.db.DeveloperApp.DeleteByID(organizationName, developerApp.AppID, precondition)
 	if err != nil {
 		return err
 	}
@@ -177,7 +203,7 @@ func (das *DeveloperAppService) Delete(organizationName, developerEmail, develop
 		}
 	}
 	// FIXME	developer.LastmodifiedBy = h.GetSessionUser(c)
-	if err := das.db.Developer.Update(organizationName, developer); err != nil {
+	if err := das.db.Developer.Update(organizationName, developer, db.Precondition{}); err != nil {
 		return err
 	}
 	env := &audit.Environment{
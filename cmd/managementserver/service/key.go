@@ -117,6 +117,14 @@ func (ks *KeyService) Update(organizationName, developerEmail, developerAppName
 		AppID:        developerApp.AppID,
 	}
 	ks.audit.Update(currentKey, updatedKey, env, who)
+
+	// Tokens issued to a revoked key should not be usable anymore
+	if updatedKey.IsRevoked() {
+		if _, err := revokeOAuthTokens(ks.db, ks.audit, organizationName,
+			developerApp, types.Keys{updatedKey}, who); err != nil {
+			return nil, err
+		}
+	}
 	return &updatedKey, nil
 }
 
@@ -132,6 +140,10 @@ func (ks *KeyService) Delete(organizationName, developerEmail, developerAppName,
 	if err != nil {
 		return err
 	}
+	if _, err := revokeOAuthTokens(ks.db, ks.audit, organizationName,
+		developerApp, types.Keys{*key}, who); err != nil {
+		return err
+	}
 	if err = ks.db.Key.DeleteByKey(organizationName, consumerKey); err != nil {
 		return err
 	}
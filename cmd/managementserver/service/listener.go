@@ -47,7 +47,7 @@ func (ls *ListenerService) Create(newListener types.Listener, who audit.Requeste
 	newListener.CreatedAt = shared.GetCurrentTimeMilliseconds()
 	newListener.CreatedBy = who.User
 
-	if err := ls.updateListener(&newListener, who); err != nil {
+	if err := ls.updateListener(&newListener, db.Precondition{}, who); err != nil {
 		return nil, err
 	}
 	ls.audit.Create(newListener, nil, who)
@@ -55,7 +55,8 @@ func (ls *ListenerService) Create(newListener types.Listener, who audit.Requeste
 }
 
 // Update updates an existing listener
-func (ls *ListenerService) Update(updatedListener types.Listener, who audit.Requester) (*types.Listener, types.Error) {
+func (ls *ListenerService) Update(updatedListener types.Listener,
+	precondition db.Precondition, who audit.Requester) (*types.Listener, types.Error) {
 
 	currentListener, err := ls.db.Listener.Get(updatedListener.Name)
 	if err != nil {
@@ -66,7 +67,7 @@ func (ls *ListenerService) Update(updatedListener types.Listener, who audit.Requ
 	updatedListener.CreatedAt = currentListener.CreatedAt
 	updatedListener.CreatedBy = currentListener.CreatedBy
 
-	if err = ls.updateListener(&updatedListener, who); err != nil {
+	if err = ls.updateListener(&updatedListener, precondition, who); err != nil {
 		return nil, err
 	}
 	ls.audit.Update(currentListener, updatedListener, nil, who)
@@ -74,7 +75,8 @@ func (ls *ListenerService) Update(updatedListener types.Listener, who audit.Requ
 }
 
 // updateListener updates last-modified field(s) and updates cluster in database
-func (ls *ListenerService) updateListener(updatedListener *types.Listener, who audit.Requester) types.Error {
+func (ls *ListenerService) updateListener(updatedListener *types.Listener,
+	precondition db.Precondition, who audit.Requester) types.Error {
 
 	updatedListener.Attributes.Tidy()
 	updatedListener.LastModifiedAt = shared.GetCurrentTimeMilliseconds()
@@ -83,17 +85,18 @@ func (ls *ListenerService) updateListener(updatedListener *types.Listener, who a
 	if err := updatedListener.Validate(); err != nil {
 		return types.NewBadRequestError(err)
 	}
-	return ls.db.Listener.Update(updatedListener)
+	return ls.db.Listener.Update(updatedListener, precondition)
 }
 
 // Delete deletes an listener
-func (ls *ListenerService) Delete(listenerName string, who audit.Requester) (e types.Error) {
+func (ls *ListenerService) Delete(listenerName string,
+	precondition db.Precondition, who audit.Requester) (e types.Error) {
 
 	listener, err := ls.db.Listener.Get(listenerName)
 	if err != nil {
 		return err
 	}
-	if err = ls.db.Listener.Delete(listenerName); err != nil {
+	if err = ls.db.Listener.Delete(listenerName, precondition); err != nil {
 		return err
 	}
 	ls.audit.Delete(listener, nil, who)
@@ -16,10 +16,12 @@ func New(database *db.Database, auditlog *audit.Audit) *Service {
 		Developer:    NewDeveloper(database, auditlog),
 		DeveloperApp: NewDeveloperApp(database, auditlog),
 		Key:          NewKey(database, auditlog),
+		OAuthToken:   NewOAuthToken(database, auditlog),
 		Company:      NewCompany(database, auditlog),
 		APIProduct:   NewAPIProduct(database, auditlog),
 		User:         NewUser(database, auditlog),
 		Role:         NewRole(database, auditlog),
 		Audit:        NewAudit(database, auditlog),
+		Bundle:       NewBundle(database, auditlog),
 	}
 }
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/erikbos/gatekeeper/cmd/managementserver/audit"
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// OAuthTokenService manages OAuth tokens issued to keys
+type OAuthTokenService struct {
+	db    *db.Database
+	audit *audit.Audit
+}
+
+// NewOAuthToken returns a new oauth token instance
+func NewOAuthToken(database *db.Database, a *audit.Audit) *OAuthTokenService {
+
+	return &OAuthTokenService{
+		db:    database,
+		audit: a,
+	}
+}
+
+// GetByDeveloperApp returns all tokens issued to keys of a developer app
+func (ots *OAuthTokenService) GetByDeveloperApp(organizationName, developerEmail,
+	developerAppName string) (types.OAuthAccessTokens, types.Error) {
+
+	_, keys, err := ots.getDevAppKeys(organizationName, developerEmail, developerAppName)
+	if err != nil {
+		return nil, err
+	}
+	return getOAuthTokens(ots.db, keys)
+}
+
+// GetByKey returns all tokens issued to a key
+func (ots *OAuthTokenService) GetByKey(organizationName, developerEmail,
+	developerAppName, consumerKey string) (types.OAuthAccessTokens, types.Error) {
+
+	_, key, err := ots.getDevAppKey(organizationName, developerEmail, developerAppName, consumerKey)
+	if err != nil {
+		return nil, err
+	}
+	return getOAuthTokens(ots.db, types.Keys{*key})
+}
+
+// RevokeByDeveloperApp revokes all tokens issued to keys of a developer app
+func (ots *OAuthTokenService) RevokeByDeveloperApp(organizationName, developerEmail,
+	developerAppName string, who audit.Requester) (types.OAuthAccessTokens, types.Error) {
+
+	developerApp, keys, err := ots.getDevAppKeys(organizationName, developerEmail, developerAppName)
+	if err != nil {
+		return nil, err
+	}
+	return revokeOAuthTokens(ots.db, ots.audit, organizationName, developerApp, keys, who)
+}
+
+// RevokeByKey revokes all tokens issued to a key
+func (ots *OAuthTokenService) RevokeByKey(organizationName, developerEmail,
+	developerAppName, consumerKey string, who audit.Requester) (types.OAuthAccessTokens, types.Error) {
+
+	developerApp, key, err := ots.getDevAppKey(organizationName, developerEmail, developerAppName, consumerKey)
+	if err != nil {
+		return nil, err
+	}
+	return revokeOAuthTokens(ots.db, ots.audit, organizationName, developerApp, types.Keys{*key}, who)
+}
+
+// getDevAppKeys gets developer app and all its keys
+func (ots *OAuthTokenService) getDevAppKeys(organizationName, developerEmail, developerAppName string) (
+	*types.DeveloperApp, types.Keys, types.Error) {
+
+	if _, err := ots.db.Developer.GetByEmail(organizationName, developerEmail); err != nil {
+		return nil, nil, err
+	}
+	developerApp, err := ots.db.DeveloperApp.GetByName(organizationName, developerEmail, developerAppName)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys, err := ots.db.Key.GetByDeveloperAppID(organizationName, developerApp.AppID)
+	if err != nil && !types.IsItemNotFoundError(err) {
+		return nil, nil, err
+	}
+	return developerApp, keys, nil
+}
+
+// getDevAppKey gets developer app and one of its keys
+func (ots *OAuthTokenService) getDevAppKey(organizationName, developerEmail, developerAppName, consumerKey string) (
+	*types.DeveloperApp, *types.Key, types.Error) {
+
+	if _, err := ots.db.Developer.GetByEmail(organizationName, developerEmail); err != nil {
+		return nil, nil, err
+	}
+	developerApp, err := ots.db.DeveloperApp.GetByName(organizationName, developerEmail, developerAppName)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := ots.db.Key.GetByKey(&organizationName, &consumerKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key.AppID != developerApp.AppID {
+		return nil, nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find key '%s'", consumerKey))
+	}
+	return developerApp, key, nil
+}
+
+// getOAuthTokens returns all tokens issued to keys, ordered by issue time
+func getOAuthTokens(database *db.Database, keys types.Keys) (types.OAuthAccessTokens, types.Error) {
+
+	tokens := types.OAuthAccessTokens{}
+	for _, key := range keys {
+		keyTokens, err := database.OAuth.OAuthAccessTokenGetByClientID(key.ConsumerKey)
+		if err != nil {
+			return nil, types.NewDatabaseError(err)
+		}
+		tokens = append(tokens, keyTokens...)
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokenIssuedAt(tokens[i]) < tokenIssuedAt(tokens[j])
+	})
+	return tokens, nil
+}
+
+// revokeOAuthTokens removes all tokens issued to keys of a developer app,
+// returns the revoked tokens
+func revokeOAuthTokens(database *db.Database, auditlog *audit.Audit, organizationName string,
+	developerApp *types.DeveloperApp, keys types.Keys, who audit.Requester) (types.OAuthAccessTokens, types.Error) {
+
+	tokens, err := getOAuthTokens(database, keys)
+	if err != nil {
+		return nil, err
+	}
+	env := &audit.Environment{
+		Organization: organizationName,
+		DeveloperID:  developerApp.DeveloperID,
+		AppID:        developerApp.AppID,
+	}
+	for _, token := range tokens {
+		// Access token is primary key, also for authorization codes, removing
+		// it removes all of access token, refresh token and code
+		if err := database.OAuth.OAuthAccessTokenRemoveByAccess(token.Access); err != nil {
+			return nil, types.NewDatabaseError(err)
+		}
+		auditlog.Delete(token, env, who)
+	}
+	return tokens, nil
+}
+
+// tokenIssuedAt returns issue time of token in milliseconds since epoch
+func tokenIssuedAt(token types.OAuthAccessToken) int64 {
+
+	if token.IsAuthorizationCode() {
+		return token.CodeCreatedAt
+	}
+	return token.AccessCreatedAt
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/cmd/managementserver/audit"
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/db/memory"
+	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func Test_OAuthToken_Revoke(t *testing.T) {
+
+	who := audit.Requester{User: "test"}
+	database, e := memory.New(t.Name(), zap.NewNop())
+	require.NoError(t, e)
+	s := New(database, audit.New(database, nil, zap.NewNop()))
+
+	_, err := s.Organization.Create(types.Organization{Name: "org"}, who)
+	require.Nil(t, err)
+	developer, err := s.Developer.Create("org",
+		types.Developer{Email: "joe@example.com", OrganizationName: "org"}, who)
+	require.Nil(t, err)
+	app, err := s.DeveloperApp.Create("org", developer.Email, types.DeveloperApp{Name: "app"}, who)
+	require.Nil(t, err)
+	key1, err := s.Key.Create("org", developer.Email, app.Name, types.Key{}, who)
+	require.Nil(t, err)
+	key2, err := s.Key.Create("org", developer.Email, app.Name, types.Key{}, who)
+	require.Nil(t, err)
+
+	oauth := database.OAuth
+	now := shared.GetCurrentTimeMilliseconds()
+	issue := func(consumerKey, access string) {
+		require.NoError(t, oauth.OAuthAccessTokenCreate(&types.OAuthAccessToken{
+			ClientID: consumerKey, Access: access, AccessCreatedAt: now, AccessExpiresIn: 3600000}))
+	}
+	issue(key1.ConsumerKey, "access1")
+	issue(key1.ConsumerKey, "access2")
+	issue(key2.ConsumerKey, "access3")
+
+	tokens, err := s.OAuthToken.GetByDeveloperApp("org", developer.Email, app.Name)
+	require.Nil(t, err)
+	require.Len(t, tokens, 3)
+
+	// Key must belong to developer app
+	_, err = s.OAuthToken.GetByKey("org", developer.Email, app.Name, "unknown")
+	require.True(t, types.IsItemNotFoundError(err))
+
+	revoked, err := s.OAuthToken.RevokeByKey("org", developer.Email, app.Name, key2.ConsumerKey, who)
+	require.Nil(t, err)
+	require.Len(t, revoked, 1)
+	_, e = oauth.OAuthAccessTokenGetByAccess("access3")
+	require.Error(t, e)
+
+	// Revoking key revokes its tokens
+	key1.Revoke()
+	_, err = s.Key.Update("org", developer.Email, app.Name, key1.ConsumerKey, *key1, who)
+	require.Nil(t, err)
+	tokens, err = s.OAuthToken.GetByKey("org", developer.Email, app.Name, key1.ConsumerKey)
+	require.Nil(t, err)
+	require.Empty(t, tokens)
+
+	// Revoking developer app revokes tokens of all its keys
+	issue(key2.ConsumerKey, "access4")
+	app.Revoke()
+	_, err = s.DeveloperApp.Update("org", developer.Email, *app, db.Precondition{}, who)
+	require.Nil(t, err)
+	tokens, err = s.OAuthToken.GetByDeveloperApp("org", developer.Email, app.Name)
+	require.Nil(t, err)
+	require.Empty(t, tokens)
+}
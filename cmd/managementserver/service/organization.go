@@ -47,7 +47,7 @@ func (os *OrganizationService) Create(newOrganization types.Organization, who au
 	newOrganization.CreatedAt = shared.GetCurrentTimeMilliseconds()
 	newOrganization.CreatedBy = who.User
 
-	if err := os.updateOrganization(&newOrganization, who); err != nil {
+	if err := os.updateOrganization(&newOrganization, db.Precondition{}, who); err != nil {
 		return nil, err
 	}
 	env := &audit.Environment{
@@ -58,7 +58,8 @@ func (os *OrganizationService) Create(newOrganization types.Organization, who au
 }
 
 // Update updates an existing organization
-func (os *OrganizationService) Update(updatedOrganization types.Organization, who audit.Requester) (*types.Organization, types.Error) {
+func (os *OrganizationService) Update(updatedOrganization types.Organization,
+	precondition db.Precondition, who audit.Requester) (*types.Organization, types.Error) {
 
 	currentOrganization, err := os.db.Organization.Get(updatedOrganization.Name)
 	if err != nil {
@@ -69,7 +70,7 @@ func (os *OrganizationService) Update(updatedOrganization types.Organization, wh
 	updatedOrganization.CreatedAt = currentOrganization.CreatedAt
 	updatedOrganization.CreatedBy = currentOrganization.CreatedBy
 
-	if err = os.updateOrganization(&updatedOrganization, who); err != nil {
+	if err = os.updateOrganization(&updatedOrganization, precondition, who); err != nil {
 		return nil, err
 	}
 	env := &audit.Environment{
@@ -80,7 +81,8 @@ func (os *OrganizationService) Update(updatedOrganization types.Organization, wh
 }
 
 // updateOrganization updates last-modified field(s) and updates cluster in database
-func (os *OrganizationService) updateOrganization(updatedOrganization *types.Organization, who audit.Requester) types.Error {
+func (os *OrganizationService) updateOrganization(updatedOrganization *types.Organization,
+	precondition db.Precondition, who audit.Requester) types.Error {
 
 	updatedOrganization.Attributes.Tidy()
 	updatedOrganization.LastModifiedAt = shared.GetCurrentTimeMilliseconds()
@@ -89,17 +91,18 @@ func (os *OrganizationService) updateOrganization(updatedOrganization *types.Org
 	if err := updatedOrganization.Validate(); err != nil {
 		return types.NewBadRequestError(err)
 	}
-	return os.db.Organization.Update(updatedOrganization)
+	return os.db.Organization.Update(updatedOrganization, precondition)
 }
 
 // Delete deletes an organization
-func (os *OrganizationService) Delete(organizationName string, who audit.Requester) (e types.Error) {
+func (os *OrganizationService) Delete(organizationName string,
+	precondition db.Precondition, who audit.Requester) (e types.Error) {
 
 	organization, err := os.db.Organization.Get(organizationName)
 	if err != nil {
 		return err
 	}
-	if err = os.db.Organization.Delete(organizationName); err != nil {
+	if err = os.db.Organization.Delete(organizationName, precondition); err != nil {
 		return err
 	}
 	env := &audit.Environment{
@@ -47,7 +47,7 @@ func (rs *RoleService) Create(newRole types.Role, who audit.Requester) (*types.R
 	newRole.CreatedAt = shared.GetCurrentTimeMilliseconds()
 	newRole.CreatedBy = who.User
 
-	if err := rs.updateRole(&newRole, who); err != nil {
+	if err := rs.updateRole(&newRole, db.Precondition{}, who); err != nil {
 		return nil, err
 	}
 	rs.audit.Create(newRole, nil, who)
@@ -55,7 +55,8 @@ func (rs *RoleService) Create(newRole types.Role, who audit.Requester) (*types.R
 }
 
 // Update updates an existing role
-func (rs *RoleService) Update(updatedRole types.Role, who audit.Requester) (*types.Role, types.Error) {
+func (rs *RoleService) Update(updatedRole types.Role,
+	precondition db.Precondition, who audit.Requester) (*types.Role, types.Error) {
 
 	currentRole, err := rs.db.Role.Get(updatedRole.Name)
 	if err != nil {
@@ -66,7 +67,7 @@ func (rs *RoleService) Update(updatedRole types.Role, who audit.Requester) (*typ
 	updatedRole.CreatedAt = currentRole.CreatedAt
 	updatedRole.CreatedBy = currentRole.CreatedBy
 
-	if err = rs.updateRole(&updatedRole, who); err != nil {
+	if err = rs.updateRole(&updatedRole, precondition, who); err != nil {
 		return nil, err
 	}
 	rs.audit.Update(currentRole, updatedRole, nil, who)
@@ -74,7 +75,8 @@ func (rs *RoleService) Update(updatedRole types.Role, who audit.Requester) (*typ
 }
 
 // updateRole updates last-modified field(s) and updates role in database
-func (rs *RoleService) updateRole(updatedRole *types.Role, who audit.Requester) types.Error {
+func (rs *RoleService) updateRole(updatedRole *types.Role,
+	precondition db.Precondition, who audit.Requester) types.Error {
 
 	updatedRole.LastModifiedAt = shared.GetCurrentTimeMilliseconds()
 	updatedRole.LastModifiedBy = who.User
@@ -82,11 +84,11 @@ func (rs *RoleService) updateRole(updatedRole *types.Role, who audit.Requester)
 	if err := updatedRole.Validate(); err != nil {
 		return types.NewBadRequestError(err)
 	}
-	return rs.db.Role.Update(updatedRole)
+	return rs.db.Role.Update(updatedRole, precondition)
 }
 
 // Delete deletes a role
-func (rs *RoleService) Delete(roleName string, who audit.Requester) (e types.Error) {
+func (rs *RoleService) Delete(roleName string, precondition db.Precondition, who audit.Requester) (e types.Error) {
 
 	role, err := rs.db.Role.Get(roleName)
 	if err != nil {
@@ -98,7 +100,7 @@ func (rs *RoleService) Delete(roleName string, who audit.Requester) (e types.Err
 			fmt.Errorf("cannot delete role '%s' still assigned to %d users",
 				roleName, userWithRoleCount))
 	}
-	if err = rs.db.Role.Delete(roleName); err != nil {
+	if err = rs.db.Role.Delete(roleName, precondition); err != nil {
 		return err
 	}
 	rs.audit.Delete(role, nil, who)
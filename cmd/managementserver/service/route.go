@@ -67,7 +67,7 @@ func (rs *RouteService) Create(newRoute types.Route, who audit.Requester) (*type
 	newRoute.CreatedAt = shared.GetCurrentTimeMilliseconds()
 	newRoute.CreatedBy = who.User
 
-	if err := rs.updateRoute(&newRoute, who); err != nil {
+	if err := rs.updateRoute(&newRoute, db.Precondition{}, who); err != nil {
 		return nil, err
 	}
 	rs.audit.Create(newRoute, nil, who)
@@ -76,7 +76,7 @@ func (rs *RouteService) Create(newRoute types.Route, who audit.Requester) (*type
 
 // Update updates an existing route
 func (rs *RouteService) Update(updatedRoute types.Route,
-	who audit.Requester) (*types.Route, types.Error) {
+	precondition db.Precondition, who audit.Requester) (*types.Route, types.Error) {
 
 	currentRoute, err := rs.db.Route.Get(updatedRoute.Name)
 	if err != nil {
@@ -87,7 +87,7 @@ func (rs *RouteService) Update(updatedRoute types.Route,
 	updatedRoute.CreatedAt = currentRoute.CreatedAt
 	updatedRoute.CreatedBy = currentRoute.CreatedBy
 
-	if err = rs.updateRoute(&updatedRoute, who); err != nil {
+	if err = rs.updateRoute(&updatedRoute, precondition, who); err != nil {
 		return nil, err
 	}
 	rs.audit.Update(currentRoute, updatedRoute, nil, who)
@@ -95,7 +95,8 @@ func (rs *RouteService) Update(updatedRoute types.Route,
 }
 
 // updateRoute updates last-modified field(s) and updates route in database
-func (rs *RouteService) updateRoute(updatedRoute *types.Route, who audit.Requester) types.Error {
+func (rs *RouteService) updateRoute(updatedRoute *types.Route,
+	precondition db.Precondition, who audit.Requester) types.Error {
 
 	updatedRoute.Attributes.Tidy()
 	updatedRoute.LastModifiedAt = shared.GetCurrentTimeMilliseconds()
@@ -104,17 +105,17 @@ func (rs *RouteService) updateRoute(updatedRoute *types.Route, who audit.Request
 	if err := updatedRoute.Validate(); err != nil {
 		return types.NewBadRequestError(err)
 	}
-	return rs.db.Route.Update(updatedRoute)
+	return rs.db.Route.Update(updatedRoute, precondition)
 }
 
 // Delete deletes an route
-func (rs *RouteService) Delete(routeName string, who audit.Requester) (e types.Error) {
+func (rs *RouteService) Delete(routeName string, precondition db.Precondition, who audit.Requester) (e types.Error) {
 
 	route, err := rs.db.Route.Get(routeName)
 	if err != nil {
 		return err
 	}
-	err = rs.db.Route.Delete(routeName)
+	err = rs.db.Route.Delete(routeName, precondition)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"github.com/erikbos/gatekeeper/cmd/managementserver/audit"
+	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
@@ -14,11 +15,13 @@ type Service struct {
 	Developer
 	DeveloperApp
 	Key
+	OAuthToken
 	Company
 	APIProduct
 	User
 	Role
 	Audit
+	Bundle
 }
 
 // All interface of service layer
@@ -31,9 +34,9 @@ type (
 
 		Create(newListener types.Listener, who audit.Requester) (*types.Listener, types.Error)
 
-		Update(updatedListener types.Listener, who audit.Requester) (*types.Listener, types.Error)
+		Update(updatedListener types.Listener, precondition db.Precondition, who audit.Requester) (*types.Listener, types.Error)
 
-		Delete(listenerName string, who audit.Requester) (e types.Error)
+		Delete(listenerName string, precondition db.Precondition, who audit.Requester) (e types.Error)
 	}
 
 	// Route is the service interface to manipulate Route entities
@@ -44,9 +47,9 @@ type (
 
 		Create(newRoute types.Route, who audit.Requester) (*types.Route, types.Error)
 
-		Update(updatedRoute types.Route, who audit.Requester) (*types.Route, types.Error)
+		Update(updatedRoute types.Route, precondition db.Precondition, who audit.Requester) (*types.Route, types.Error)
 
-		Delete(routeName string, who audit.Requester) (e types.Error)
+		Delete(routeName string, precondition db.Precondition, who audit.Requester) (e types.Error)
 	}
 
 	// Cluster is the service interface to manipulate Cluster entities
@@ -57,9 +60,9 @@ type (
 
 		Create(newCluster types.Cluster, who audit.Requester) (*types.Cluster, types.Error)
 
-		Update(updatedCluster types.Cluster, who audit.Requester) (*types.Cluster, types.Error)
+		Update(updatedCluster types.Cluster, precondition db.Precondition, who audit.Requester) (*types.Cluster, types.Error)
 
-		Delete(clusterName string, who audit.Requester) (e types.Error)
+		Delete(clusterName string, precondition db.Precondition, who audit.Requester) (e types.Error)
 	}
 
 	Organization interface {
@@ -69,28 +72,32 @@ type (
 
 		Create(newOrganization types.Organization, who audit.Requester) (*types.Organization, types.Error)
 
-		Update(updatedOrganization types.Organization, who audit.Requester) (*types.Organization, types.Error)
+		Update(updatedOrganization types.Organization, precondition db.Precondition, who audit.Requester) (*types.Organization, types.Error)
 
-		Delete(organizationName string, who audit.Requester) (e types.Error)
+		Delete(organizationName string, precondition db.Precondition, who audit.Requester) (e types.Error)
 	}
 
 	// Developer is the service interface to manipulate Developer entities
 	Developer interface {
 		GetAll(organizationName string) (developers types.Developers, err types.Error)
 
+		List(organizationName string, params db.ListParams) (developers types.Developers, nextPageToken string, err types.Error)
+
 		Get(organizationName, developerEmail string) (developer *types.Developer, err types.Error)
 
 		Create(organizationName string, newDeveloper types.Developer, who audit.Requester) (*types.Developer, types.Error)
 
-		Update(organizationName, developerEmail string, updatedDeveloper types.Developer, who audit.Requester) (*types.Developer, types.Error)
+		Update(organizationName, developerEmail string, updatedDeveloper types.Developer, precondition db.Precondition, who audit.Requester) (*types.Developer, types.Error)
 
-		Delete(organizationName, developerEmail string, who audit.Requester) (e types.Error)
+		Delete(organizationName, developerEmail string, precondition db.Precondition, who audit.Requester) (e types.Error)
 	}
 
 	// DeveloperApp is the service interface to manipulate DeveloperApp entities
 	DeveloperApp interface {
 		GetAll(organizationName string) (developerApps types.DeveloperApps, err types.Error)
 
+		List(organizationName string, params db.ListParams) (developerApps types.DeveloperApps, nextPageToken string, err types.Error)
+
 		GetAllByEmail(organizationName, developerEmail string) (developerApps types.DeveloperApps, err types.Error)
 
 		GetByName(organizationName, developerEmail, developerAppName string) (developerApp *types.DeveloperApp, err types.Error)
@@ -99,9 +106,9 @@ type (
 
 		Create(organizationName, developerEmail string, newDeveloperApp types.DeveloperApp, who audit.Requester) (*types.DeveloperApp, types.Error)
 
-		Update(organizationName, developerEmail string, updatedDeveloperApp types.DeveloperApp, who audit.Requester) (*types.DeveloperApp, types.Error)
+		Update(organizationName, developerEmail string, updatedDeveloperApp types.DeveloperApp, precondition db.Precondition, who audit.Requester) (*types.DeveloperApp, types.Error)
 
-		Delete(organizationName, developerEmail, developerAppName string, who audit.Requester) (e types.Error)
+		Delete(organizationName, developerEmail, developerAppName string, precondition db.Precondition, who audit.Requester) (e types.Error)
 	}
 
 	// Key is the service interface to manipulate Key entities
@@ -117,29 +124,44 @@ type (
 		Delete(organizationName, developerEmail, developerAppName string, consumerKey string, who audit.Requester) (e types.Error)
 	}
 
+	// OAuthToken is the service interface to manage OAuth tokens issued to keys
+	OAuthToken interface {
+		GetByDeveloperApp(organizationName, developerEmail, developerAppName string) (tokens types.OAuthAccessTokens, err types.Error)
+
+		GetByKey(organizationName, developerEmail, developerAppName, consumerKey string) (tokens types.OAuthAccessTokens, err types.Error)
+
+		RevokeByDeveloperApp(organizationName, developerEmail, developerAppName string, who audit.Requester) (tokens types.OAuthAccessTokens, err types.Error)
+
+		RevokeByKey(organizationName, developerEmail, developerAppName, consumerKey string, who audit.Requester) (tokens types.OAuthAccessTokens, err types.Error)
+	}
+
 	Company interface {
 		GetAll(organizationName string) (companys types.Companies, err types.Error)
 
+		List(organizationName string, params db.ListParams) (companys types.Companies, nextPageToken string, err types.Error)
+
 		Get(organizationName, companyName string) (company *types.Company, err types.Error)
 
 		Create(organizationName string, newCompany types.Company, who audit.Requester) (*types.Company, types.Error)
 
-		Update(organizationName string, updatedCompany types.Company, who audit.Requester) (*types.Company, types.Error)
+		Update(organizationName string, updatedCompany types.Company, precondition db.Precondition, who audit.Requester) (*types.Company, types.Error)
 
-		Delete(organizationName, companyName string, who audit.Requester) (e types.Error)
+		Delete(organizationName, companyName string, precondition db.Precondition, who audit.Requester) (e types.Error)
 	}
 
 	// APIProduct is the service interface to manipulate APIProduct entities
 	APIProduct interface {
 		GetAll(organizationName string) (apiproducts types.APIProducts, err types.Error)
 
+		List(organizationName string, params db.ListParams) (apiproducts types.APIProducts, nextPageToken string, err types.Error)
+
 		Get(organizationName, apiproductName string) (apiproduct *types.APIProduct, err types.Error)
 
 		Create(organizationName string, newAPIProduct types.APIProduct, who audit.Requester) (*types.APIProduct, types.Error)
 
-		Update(organizationName string, updatedAPIProduct types.APIProduct, who audit.Requester) (*types.APIProduct, types.Error)
+		Update(organizationName string, updatedAPIProduct types.APIProduct, precondition db.Precondition, who audit.Requester) (*types.APIProduct, types.Error)
 
-		Delete(organizationName string, apiproductName string, who audit.Requester) (e types.Error)
+		Delete(organizationName string, apiproductName string, precondition db.Precondition, who audit.Requester) (e types.Error)
 	}
 
 	// User is the service interface to manipulate User entities
@@ -152,9 +174,9 @@ type (
 
 		Create(newUser types.User, who audit.Requester) (*types.User, types.Error)
 
-		Update(updatedUser types.User, who audit.Requester) (*types.User, types.Error)
+		Update(updatedUser types.User, precondition db.Precondition, who audit.Requester) (*types.User, types.Error)
 
-		Delete(userName string, who audit.Requester) (e types.Error)
+		Delete(userName string, precondition db.Precondition, who audit.Requester) (e types.Error)
 	}
 
 	// Role is the service interface to manipulate Role entities
@@ -165,9 +187,9 @@ type (
 
 		Create(newRole types.Role, who audit.Requester) (*types.Role, types.Error)
 
-		Update(updatedRole types.Role, who audit.Requester) (*types.Role, types.Error)
+		Update(updatedRole types.Role, precondition db.Precondition, who audit.Requester) (*types.Role, types.Error)
 
-		Delete(roleName string, who audit.Requester) (e types.Error)
+		Delete(roleName string, precondition db.Precondition, who audit.Requester) (e types.Error)
 	}
 
 	// Audit is the service interface to retrieve audit records
@@ -182,4 +204,13 @@ type (
 
 		GetUser(userName string, params AuditQueryParams) (audits types.Audits, err types.Error)
 	}
+
+	// Bundle is the service interface to export and import configuration bundles
+	Bundle interface {
+		ExportOrganization(organizationName string, includeSecrets bool) (bundle *ConfigurationBundle, err types.Error)
+
+		ExportGlobal() (bundle *ConfigurationBundle, err types.Error)
+
+		Import(bundle ConfigurationBundle, options ImportOptions, who audit.Requester) (results ImportResults, err types.Error)
+	}
 )
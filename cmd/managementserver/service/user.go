@@ -78,7 +78,7 @@ func (us *UserService) Create(newUser types.User, who audit.Requester) (*types.U
 	}
 	newUser.Password = encryptedPassword
 
-	if err := us.updateUser(&newUser, who); err != nil {
+	if err := us.updateUser(&newUser, db.Precondition{}, who); err != nil {
 		return nil, err
 	}
 	us.audit.Create(newUser, nil, who)
@@ -87,7 +87,7 @@ func (us *UserService) Create(newUser types.User, who audit.Requester) (*types.U
 
 // Update updates an existing user
 func (us *UserService) Update(updatedUser types.User,
-	who audit.Requester) (*types.User, types.Error) {
+	precondition db.Precondition, who audit.Requester) (*types.User, types.Error) {
 
 	currentUser, err := us.db.User.Get(updatedUser.Name)
 	if err != nil {
@@ -109,7 +109,7 @@ func (us *UserService) Update(updatedUser types.User,
 		updatedUser.Password = currentUser.Password
 	}
 
-	if err = us.updateUser(&updatedUser, who); err != nil {
+	if err = us.updateUser(&updatedUser, precondition, who); err != nil {
 		return nil, err
 	}
 	us.audit.Update(currentUser, updatedUser, nil, who)
@@ -117,7 +117,8 @@ func (us *UserService) Update(updatedUser types.User,
 }
 
 // updateUser updates last-modified field(s) and updates user in database
-func (us *UserService) updateUser(updatedUser *types.User, who audit.Requester) types.Error {
+func (us *UserService) updateUser(updatedUser *types.User,
+	precondition db.Precondition, who audit.Requester) types.Error {
 
 	updatedUser.LastModifiedAt = shared.GetCurrentTimeMilliseconds()
 	updatedUser.LastModifiedBy = who.User
@@ -125,17 +126,17 @@ func (us *UserService) updateUser(updatedUser *types.User, who audit.Requester)
 	if err := updatedUser.Validate(); err != nil {
 		return types.NewBadRequestError(err)
 	}
-	return us.db.User.Update(updatedUser)
+	return us.db.User.Update(updatedUser, precondition)
 }
 
 // Delete deletes an user
-func (us *UserService) Delete(userName string, who audit.Requester) (e types.Error) {
+func (us *UserService) Delete(userName string, precondition db.Precondition, who audit.Requester) (e types.Error) {
 
 	user, err := us.db.User.Get(userName)
 	if err != nil {
 		return err
 	}
-	if err = us.db.User.Delete(userName); err != nil {
+	if err = us.db.User.Delete(userName, precondition); err != nil {
 		return err
 	}
 	us.audit.Delete(user, nil, who)
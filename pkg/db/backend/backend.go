@@ -0,0 +1,82 @@
+package backend
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/db/cassandra"
+	"github.com/erikbos/gatekeeper/pkg/db/memory"
+	"github.com/erikbos/gatekeeper/pkg/db/sql"
+)
+
+// Supported database backends
+const (
+	// TypeCassandra stores all entities in Cassandra (default)
+	TypeCassandra = "cassandra"
+
+	// TypeMemory stores all entities in memory, for testing and development purposes
+	TypeMemory = "memory"
+
+	// TypeSQL stores all entities in a SQL database (PostgreSQL or SQLite)
+	TypeSQL = "sql"
+)
+
+// Supported schema migration commands
+const (
+	// MigrateUp applies all pending schema migrations
+	MigrateUp = "up"
+
+	// MigrateStatus shows which schema migrations have been applied
+	MigrateStatus = "status"
+)
+
+// Config holds the configuration of the database backend to use
+type Config struct {
+	// Type of database backend, "cassandra", "memory" or "sql"
+	Type string `yaml:"type"`
+
+	// Cassandra connection configuration, only used by cassandra backend
+	cassandra.DatabaseConfig `mapstructure:",squash" yaml:",inline"`
+
+	// SQL connection configuration, only used by sql backend
+	SQL sql.DatabaseConfig `yaml:"sql"`
+}
+
+// New returns a database instance of the configured backend type
+func New(config Config, serviceName string, logger *zap.Logger,
+	createSchema bool, replicationCount int) (*db.Database, error) {
+
+	switch config.Type {
+	case "", TypeCassandra:
+		return cassandra.New(config.DatabaseConfig, serviceName, logger, createSchema, replicationCount)
+	case TypeMemory:
+		return memory.New(serviceName, logger)
+	case TypeSQL:
+		return sql.New(config.SQL, serviceName, logger, createSchema)
+	}
+	return nil, fmt.Errorf("unknown database type '%s'", config.Type)
+}
+
+// IsMemory returns true if in-memory backend is configured
+func (c Config) IsMemory() bool {
+
+	return c.Type == TypeMemory
+}
+
+// Migrate runs a schema migration command against the configured database
+func Migrate(config Config, command string, replicationCount int, logger *zap.Logger) error {
+
+	if config.Type != "" && config.Type != TypeCassandra {
+		return fmt.Errorf("schema migrations are not supported for database type '%s'", config.Type)
+	}
+	switch command {
+	case MigrateUp:
+		return cassandra.MigrateUp(config.DatabaseConfig, replicationCount, logger)
+	case MigrateStatus:
+		return cassandra.ShowMigrationStatus(config.DatabaseConfig, logger)
+	}
+	return fmt.Errorf("unknown migrate command '%s', should be '%s' or '%s'",
+		command, MigrateUp, MigrateStatus)
+}
@@ -26,12 +26,18 @@ func (s *APIProductCache) GetAll(organizationName string) (types.APIProducts, ty
 		return s.apiproduct.GetAll(organizationName)
 	}
 	var apiproducts types.APIProducts
-	if err := s.cache.fetchEntity(types.TypeAPIProductName, "", &apiproducts, getAll); err != nil {
+	if err := s.cache.fetchEntity(types.TypeAPIProductName, organizationName, "all", &apiproducts, getAll); err != nil {
 		return nil, err
 	}
 	return apiproducts, nil
 }
 
+// List retrieves one page of api products, pages are not cached
+func (s *APIProductCache) List(organizationName string, params db.ListParams) (types.APIProducts, string, types.Error) {
+
+	return s.apiproduct.List(organizationName, params)
+}
+
 // Get returns an apiproduct
 func (s *APIProductCache) Get(organizationName, apiproductName string) (*types.APIProduct, types.Error) {
 
@@ -39,22 +45,28 @@ func (s *APIProductCache) Get(organizationName, apiproductName string) (*types.A
 		return s.apiproduct.Get(organizationName, apiproductName)
 	}
 	var apiproduct types.APIProduct
-	if err := s.cache.fetchEntity(types.TypeAPIProductName, apiproductName, &apiproduct, getAPIProduct); err != nil {
+	if err := s.cache.fetchEntity(types.TypeAPIProductName, organizationName, "name/"+apiproductName, &apiproduct, getAPIProduct); err != nil {
 		return nil, err
 	}
 	return &apiproduct, nil
 }
 
 // Update UPSERTs an apiproduct in database
-func (s *APIProductCache) Update(organizationName string, p *types.APIProduct) types.Error {
+func (s *APIProductCache) Update(organizationName string, p *types.APIProduct, precondition db.Precondition) types.Error {
 
-	s.cache.deleteEntry(types.TypeAPIProductName, p.Name)
-	return s.apiproduct.Update(organizationName, p)
+	if err := s.apiproduct.Update(organizationName, p, precondition); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeAPIProductName, organizationName)
+	return nil
 }
 
 // Delete deletes an apiproduct
-func (s *APIProductCache) Delete(organizationName, apiProduct string) types.Error {
+func (s *APIProductCache) Delete(organizationName, apiProduct string, precondition db.Precondition) types.Error {
 
-	s.cache.deleteEntry(types.TypeAPIProductName, apiProduct)
-	return s.apiproduct.Delete(organizationName, apiProduct)
+	if err := s.apiproduct.Delete(organizationName, apiProduct, precondition); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeAPIProductName, organizationName)
+	return nil
 }
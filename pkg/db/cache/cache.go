@@ -3,29 +3,52 @@ package cache
 import (
 	"bytes"
 	"encoding/gob"
+	"errors"
+	"strconv"
 
 	"go.uber.org/zap"
 
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
-// fetchEntity fetches an named entity from cache, or from the database
-// using the provided fuction.
-func (c *Cache) fetchEntity(entityType, itemName string, entity interface{},
+// Each cache entry is prefixed with its kind: either an encoded entity,
+// or the details of a failed lookup of an non-existing entity
+const (
+	entryEntity   byte = 'e'
+	entryNotFound byte = 'n'
+)
+
+// fetchResult holds the outcome of a database lookup shared by all coalesced requests
+type fetchResult struct {
+	encodedData []byte
+	err         types.Error
+}
+
+// fetchEntity fetches an named entity of an organization from cache,
+// or from the database using the provided fuction.
+//
+// Concurrent cache misses for the same entity are coalesced into a single
+// database lookup, a non-existing entity is cached for NegativeTTL seconds.
+func (c *Cache) fetchEntity(entityType, organization, itemName string, entity interface{},
 	dataRetrieveFunction func() (interface{}, types.Error)) types.Error {
 
 	if c == nil || c.freecache == nil {
 		return types.NewDatabaseError(nil)
 	}
 
-	// Get cachekey based upon object type & name of item we retrieve
-	cacheKey := getCacheKeyAndType(entityType, itemName)
+	// Get cachekey based upon object type, organization & name of item we retrieve
+	cacheKey := getCacheKeyAndType(entityType, organization,
+		c.generation(entityType, organization), itemName)
 	c.logger.Debug("fetchEntry", zap.String("cachekey", string(cacheKey)))
 
 	// Do we have a cache entry?
-	if cachedData, err := c.freecache.Get(cacheKey); err == nil && cachedData != nil {
+	if cachedData, err := c.freecache.Get(cacheKey); err == nil && len(cachedData) > 0 {
+		if cachedData[0] == entryNotFound {
+			c.metrics.EntityCacheNegativeHit(entityType)
+			return types.NewItemNotFoundError(errors.New(string(cachedData[1:])))
+		}
 		// If yes, let's try to decode it
-		if err = decode(cachedData, entity); err != nil {
+		if err = decode(cachedData[1:], entity); err != nil {
 			c.logger.Error("cache decode failed", zap.Error(err))
 			return types.NewDatabaseError(err)
 		}
@@ -35,34 +58,87 @@ func (c *Cache) fetchEntity(entityType, itemName string, entity interface{},
 
 	// No entry in cache miss
 	c.metrics.EntityCacheMiss(entityType)
-	// Try to retrieve requested entity from database layer
+	// Try to retrieve requested entity from database layer, only one lookup per cachekey
+	// is executed at a time: concurrent requests wait and share its result
+	result, _, shared := c.inflight.Do(string(cacheKey), func() (interface{}, error) {
+		return c.retrieveEntity(cacheKey, dataRetrieveFunction), nil
+	})
+	if shared {
+		c.metrics.EntityCacheCoalesced(entityType)
+	}
+	r := result.(fetchResult)
+	if r.err != nil {
+		// We could not find entity in db, or error occured
+		return r.err
+	}
+	// We decode the encoded data back into native type(!)
+	// We do this do provide the retrieve database back to the calling function
+	_ = decode(r.encodedData, entity)
+	return nil
+}
+
+// retrieveEntity retrieves entity from database and stores it in cache
+func (c *Cache) retrieveEntity(cacheKey []byte,
+	dataRetrieveFunction func() (interface{}, types.Error)) fetchResult {
+
 	data, err := dataRetrieveFunction()
 	if err != nil {
-		// We could not find entity in db, or error occured
-		return err
+		// Store non-existing entity for a short period to not hit database on repeated lookups
+		if types.IsItemNotFoundError(err) && c.config.NegativeTTL > 0 {
+			entry := append([]byte{entryNotFound}, err.ErrorDetails()...)
+			if e := c.freecache.Set(cacheKey, entry, c.config.NegativeTTL); e != nil {
+				c.logger.Error("cache store failed", zap.Error(e))
+			}
+		}
+		return fetchResult{err: err}
 	}
 	encodedData, e := encode(data)
 	if e != nil {
-		c.logger.Error("cache encoding failed", zap.Error(err))
-		return types.NewDatabaseError(e)
+		c.logger.Error("cache encoding failed", zap.Error(e))
+		return fetchResult{err: types.NewDatabaseError(e)}
 	}
 	// Store in cache
-	if err := c.freecache.Set(cacheKey, encodedData, c.config.TTL); err != nil {
+	entry := append([]byte{entryEntity}, encodedData...)
+	if err := c.freecache.Set(cacheKey, entry, c.config.TTL); err != nil {
 		c.logger.Error("cache store failed", zap.Error(err))
 	}
-	// We decode the encoded data back into native type(!)
-	// We do this do provide the retrieve database back to the calling function
-	_ = decode(encodedData, entity)
-	return nil
+	return fetchResult{encodedData: encodedData}
 }
 
-// deleteEntry removes an entry from cache
-func (c *Cache) deleteEntry(entityType, itemName string) {
+// invalidate makes all cached entries of an entity type within an organization stale.
+//
+// Instead of deleting individual entries we increase the generation number which
+// is part of each cachekey: an entity can be cached under multiple keys (e.g. by id
+// and by email), and lists of entities need to be invalidated as well. Stale entries
+// are no longer retrieved and will expire from cache eventually.
+//
+// Invalidate after a successful database write: a lookup between invalidation and
+// write would otherwise cache the previous version under the new generation.
+func (c *Cache) invalidate(entityType, organization string) {
+
+	c.generationsMutex.Lock()
+	defer c.generationsMutex.Unlock()
+
+	c.generations[generationKey(entityType, organization)]++
+	// Some entities are retrieved without organization (e.g. key by consumer key),
+	// these entries are cached without organization and need to be invalidated too
+	if organization != "" {
+		c.generations[generationKey(entityType, "")]++
+	}
+}
+
+// generation returns current generation of an entity type within an organization
+func (c *Cache) generation(entityType, organization string) uint64 {
+
+	c.generationsMutex.RLock()
+	defer c.generationsMutex.RUnlock()
+
+	return c.generations[generationKey(entityType, organization)]
+}
 
-	// Get cachekey based upon object type and item's name to delete
-	cachekey := getCacheKeyAndType(entityType, itemName)
+func generationKey(entityType, organization string) string {
 
-	_ = c.freecache.Del(cachekey)
+	return entityType + "%" + organization
 }
 
 // decode turns a cached entity back into a native object
@@ -83,10 +159,11 @@ func encode(data interface{}) ([]byte, error) {
 
 // getCacheKeyAndType builds cachekey for an entity
 // the cachekey is used to uniquely identify an entity in the cache
-func getCacheKeyAndType(entityType, itemName string) (cacheKey []byte) {
+func getCacheKeyAndType(entityType, organization string, generation uint64, itemName string) (cacheKey []byte) {
 
-	// We use the name of the type as prefix for the cachekey.
+	// We use the name of the type and organization as prefix for the cachekey.
 	// This is done to prevent cache key collisions for similar
-	// named entities of different types.
-	return []byte(entityType + "%" + itemName)
+	// named entities of different types or organizations.
+	return []byte(entityType + "%" + organization + "%" +
+		strconv.FormatUint(generation, 10) + "%" + itemName)
 }
@@ -0,0 +1,247 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/db/memory"
+	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func newTestCache(t *testing.T) (*Cache, *db.Database) {
+
+	database, err := memory.New(t.Name(), zap.NewNop())
+	require.NoError(t, err)
+
+	c := &Cache{
+		config:      &Config{Size: 1024 * 1024, TTL: 60, NegativeTTL: 60},
+		db:          database,
+		freecache:   freecache.NewCache(1024 * 1024),
+		generations: make(map[string]uint64),
+		logger:      zap.NewNop(),
+	}
+	// Prometheus metrics can only be registered once per name, so each test uses its own namespace
+	c.metrics = newMetrics(c)
+	c.metrics.registerMetricsWithPrometheus(t.Name())
+
+	return c, database
+}
+
+func Test_getCacheKeyAndType(t *testing.T) {
+
+	require.NotEqual(t,
+		getCacheKeyAndType(types.TypeKeyName, "org1", 0, "key/abc"),
+		getCacheKeyAndType(types.TypeKeyName, "org2", 0, "key/abc"))
+
+	require.NotEqual(t,
+		getCacheKeyAndType(types.TypeKeyName, "org1", 0, "key/abc"),
+		getCacheKeyAndType(types.TypeKeyName, "org1", 1, "key/abc"))
+}
+
+func Test_OrganizationIsolation(t *testing.T) {
+
+	c, database := newTestCache(t)
+	apiproducts := NewAPIProductCache(c, database.APIProduct)
+
+	require.Nil(t, database.APIProduct.Update("org1", &types.APIProduct{Name: "p1", DisplayName: "one"}, db.Precondition{}))
+	require.Nil(t, database.APIProduct.Update("org2", &types.APIProduct{Name: "p1", DisplayName: "two"}, db.Precondition{}))
+
+	p, err := apiproducts.Get("org1", "p1")
+	require.Nil(t, err)
+	require.Equal(t, "one", p.DisplayName)
+
+	p, err = apiproducts.Get("org2", "p1")
+	require.Nil(t, err)
+	require.Equal(t, "two", p.DisplayName)
+}
+
+func Test_InvalidateByChangeLog(t *testing.T) {
+
+	c, database := newTestCache(t)
+	apiproducts := NewAPIProductCache(c, database.APIProduct)
+
+	require.Nil(t, database.APIProduct.Update("org1", &types.APIProduct{Name: "p1", DisplayName: "old"}, db.Precondition{}))
+	require.Nil(t, database.APIProduct.Update("org2", &types.APIProduct{Name: "p1", DisplayName: "old"}, db.Precondition{}))
+	for _, org := range []string{"org1", "org2"} {
+		_, err := apiproducts.Get(org, "p1")
+		require.Nil(t, err)
+	}
+
+	reader := db.NewChangeLogReader(database.ChangeLog)
+
+	// Another instance updates apiproduct in database directly
+	lastTimestamp := shared.GetCurrentTimeMilliseconds()
+	require.Nil(t, database.APIProduct.Update("org1", &types.APIProduct{Name: "p1", DisplayName: "new"}, db.Precondition{}))
+	require.Nil(t, database.APIProduct.Update("org2", &types.APIProduct{Name: "p1", DisplayName: "new"}, db.Precondition{}))
+	require.Nil(t, database.ChangeLog.Write(&types.Change{
+		ID:           "change1",
+		Timestamp:    lastTimestamp + 1,
+		Operation:    types.ChangeOperationUpdate,
+		EntityType:   types.TypeAPIProductName,
+		Organization: "org1",
+		EntityID:     "p1",
+	}))
+
+	c.processChanges(reader)
+
+	p, err := apiproducts.Get("org1", "p1")
+	require.Nil(t, err)
+	require.Equal(t, "new", p.DisplayName)
+
+	// Entry of other organization has not been invalidated
+	p, err = apiproducts.Get("org2", "p1")
+	require.Nil(t, err)
+	require.Equal(t, "old", p.DisplayName)
+
+	// Processing the same change again does not invalidate again
+	generation := c.generation(types.TypeAPIProductName, "org1")
+	c.processChanges(reader)
+	require.Equal(t, generation, c.generation(types.TypeAPIProductName, "org1"))
+}
+
+func Test_KeyLookupsDoNotCollide(t *testing.T) {
+
+	c, database := newTestCache(t)
+	keys := NewKeyCache(c, database.Key)
+
+	require.Nil(t, database.Developer.Update("org1", &types.Developer{DeveloperID: "dev1", Email: "joe@example.com"}, db.Precondition{}))
+	require.Nil(t, database.DeveloperApp.Update("org1", &types.DeveloperApp{AppID: "app1", DeveloperID: "dev1", Name: "shop"}, db.Precondition{}))
+	require.Nil(t, database.Key.UpdateByKey("org1", &types.Key{ConsumerKey: "app1", AppID: "app1"}))
+
+	// Consumer key equal to app id must not return cached list of keys, or vice versa
+	consumerKey := "app1"
+	key, err := keys.GetByKey(nil, &consumerKey)
+	require.Nil(t, err)
+	require.Equal(t, "app1", key.ConsumerKey)
+
+	appKeys, err := keys.GetByDeveloperAppID("org1", "app1")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(appKeys))
+
+	// Local update invalidates lookups without organization as well
+	require.Nil(t, keys.UpdateByKey("org1", &types.Key{ConsumerKey: "app1", AppID: "app1", Status: "revoked"}))
+	key, err = keys.GetByKey(nil, &consumerKey)
+	require.Nil(t, err)
+	require.Equal(t, "revoked", key.Status)
+}
+
+func Test_NegativeCaching(t *testing.T) {
+
+	c, _ := newTestCache(t)
+
+	var lookups int32
+	notFound := func() (interface{}, types.Error) {
+		atomic.AddInt32(&lookups, 1)
+		return nil, types.NewItemNotFoundError(errors.New("cannot find key 'unknown'"))
+	}
+
+	for i := 0; i < 3; i++ {
+		var key types.Key
+		err := c.fetchEntity(types.TypeKeyName, "org1", "key/unknown", &key, notFound)
+		require.True(t, types.IsItemNotFoundError(err))
+		require.Equal(t, "cannot find key 'unknown'", err.ErrorDetails())
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+
+	// Database errors are not cached
+	databaseError := func() (interface{}, types.Error) {
+		atomic.AddInt32(&lookups, 1)
+		return nil, types.NewDatabaseError(errors.New("timeout"))
+	}
+	for i := 0; i < 2; i++ {
+		var key types.Key
+		require.NotNil(t, c.fetchEntity(types.TypeKeyName, "org1", "key/other", &key, databaseError))
+	}
+	require.Equal(t, int32(3), atomic.LoadInt32(&lookups))
+}
+
+func Test_CoalescedLookups(t *testing.T) {
+
+	c, _ := newTestCache(t)
+
+	var lookups int32
+	release := make(chan struct{})
+	slowLookup := func() (interface{}, types.Error) {
+		atomic.AddInt32(&lookups, 1)
+		<-release
+		return types.Key{ConsumerKey: "popular"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var key types.Key
+			require.Nil(t, c.fetchEntity(types.TypeKeyName, "org1", "key/popular", &key, slowLookup))
+			require.Equal(t, "popular", key.ConsumerKey)
+		}()
+	}
+	// Give all requests time to wait on the lookup in progress
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+}
+
+func Test_OAuthRemoveByCodeAndRefresh(t *testing.T) {
+
+	c, database := newTestCache(t)
+	oauth := NewOAuthCache(c, database.OAuth)
+
+	require.Nil(t, oauth.OAuthAccessTokenCreate(&types.OAuthAccessToken{Access: "code:c1", Code: "c1"}))
+	require.Nil(t, oauth.OAuthAccessTokenCreate(&types.OAuthAccessToken{Access: "a1", Refresh: "r1"}))
+
+	require.Nil(t, oauth.OAuthAccessTokenRemoveByCode("c1"))
+	_, err := oauth.OAuthAccessTokenGetByCode("c1")
+	require.Error(t, err)
+
+	require.Nil(t, oauth.OAuthAccessTokenRemoveByRefresh("r1"))
+	_, err = oauth.OAuthAccessTokenGetByRefresh("r1")
+	require.Error(t, err)
+}
+
+// slowKeyStore invokes beforeWrite just before a key is written to database,
+// to simulate a lookup by another request while the write is in progress
+type slowKeyStore struct {
+	db.Key
+	beforeWrite func()
+}
+
+func (s *slowKeyStore) UpdateByKey(organizationName string, key *types.Key) types.Error {
+
+	s.beforeWrite()
+	return s.Key.UpdateByKey(organizationName, key)
+}
+
+func Test_InvalidateAfterWrite(t *testing.T) {
+
+	c, database := newTestCache(t)
+	store := &slowKeyStore{Key: database.Key}
+	keys := NewKeyCache(c, store)
+
+	require.Nil(t, database.Key.UpdateByKey("org1", &types.Key{ConsumerKey: "abc", Status: "approved"}))
+
+	consumerKey := "abc"
+	store.beforeWrite = func() {
+		key, err := keys.GetByKey(nil, &consumerKey)
+		require.Nil(t, err)
+		require.Equal(t, "approved", key.Status)
+	}
+	require.Nil(t, keys.UpdateByKey("org1", &types.Key{ConsumerKey: "abc", Status: "revoked"}))
+
+	// Lookup during write must not leave previous version in cache
+	key, err := keys.GetByKey(nil, &consumerKey)
+	require.Nil(t, err)
+	require.Equal(t, "revoked", key.Status)
+}
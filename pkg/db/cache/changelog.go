@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+)
+
+// followChangeLog continously retrieves changes made to entities by other instances
+// (e.g. managementserver) and invalidates cache entries of changed entity types
+func (c *Cache) followChangeLog(changeLog db.ChangeLog) {
+
+	interval := time.Duration(c.config.InvalidationInterval) * time.Second
+	reader := db.NewChangeLogReader(changeLog)
+
+	for {
+		time.Sleep(interval)
+		c.processChanges(reader)
+	}
+}
+
+// processChanges invalidates cache entries of all changes which have not been processed yet
+func (c *Cache) processChanges(reader *db.ChangeLogReader) {
+
+	changes, err := reader.Next()
+	if err != nil {
+		c.logger.Error("Cannot retrieve changes from database", zap.Error(err))
+		return
+	}
+	for _, change := range changes {
+		c.logger.Debug("invalidate",
+			zap.String("operation", change.Operation),
+			zap.String("entitytype", change.EntityType),
+			zap.String("organization", change.Organization),
+			zap.String("entityid", change.EntityID))
+		c.invalidate(change.EntityType, change.Organization)
+		c.metrics.EntityCacheInvalidation(change.EntityType)
+	}
+}
@@ -26,13 +26,18 @@ func (s *DeveloperCache) GetAll(organizationName string) (types.Developers, type
 		return s.developer.GetAll(organizationName)
 	}
 	var developers types.Developers
-	// TODO/FIXME
-	if err := s.cache.fetchEntity(types.TypeDeveloperName, "--all-developers--", &developers, getDevelopers); err != nil {
+	if err := s.cache.fetchEntity(types.TypeDeveloperName, organizationName, "all", &developers, getDevelopers); err != nil {
 		return nil, err
 	}
 	return developers, nil
 }
 
+// List retrieves one page of developers, pages are not cached
+func (s *DeveloperCache) List(organizationName string, params db.ListParams) (types.Developers, string, types.Error) {
+
+	return s.developer.List(organizationName, params)
+}
+
 // GetByEmail retrieves a developer from database
 func (s *DeveloperCache) GetByEmail(organizationName, developerEmail string) (*types.Developer, types.Error) {
 
@@ -40,7 +45,7 @@ func (s *DeveloperCache) GetByEmail(organizationName, developerEmail string) (*t
 		return s.developer.GetByEmail(organizationName, developerEmail)
 	}
 	var developer types.Developer
-	if err := s.cache.fetchEntity(types.TypeDeveloperName, developerEmail, &developer, getDeveloperByEmail); err != nil {
+	if err := s.cache.fetchEntity(types.TypeDeveloperName, organizationName, "email/"+developerEmail, &developer, getDeveloperByEmail); err != nil {
 		return nil, err
 	}
 	return &developer, nil
@@ -53,23 +58,28 @@ func (s *DeveloperCache) GetByID(organizationName, developerID string) (*types.D
 		return s.developer.GetByID(organizationName, developerID)
 	}
 	var developer types.Developer
-	if err := s.cache.fetchEntity(types.TypeDeveloperName, developerID, &developer, getDeveloperByID); err != nil {
+	if err := s.cache.fetchEntity(types.TypeDeveloperName, organizationName, "id/"+developerID, &developer, getDeveloperByID); err != nil {
 		return nil, err
 	}
 	return &developer, nil
 }
 
 // Update UPSERTs a developer in database
-func (s *DeveloperCache) Update(organizationName string, d *types.Developer) types.Error {
+func (s *DeveloperCache) Update(organizationName string, d *types.Developer, precondition db.Precondition) types.Error {
 
-	s.cache.deleteEntry(types.TypeDeveloperName, d.DeveloperID)
-	s.cache.deleteEntry(types.TypeDeveloperName, d.Email)
-	return s.developer.Update(organizationName, d)
+	if err := s.developer.Update(organizationName, d, precondition); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeDeveloperName, organizationName)
+	return nil
 }
 
 // DeleteByID deletes a developer
-func (s *DeveloperCache) DeleteByID(organizationName, developerID string) types.Error {
+func (s *DeveloperCache) DeleteByID(organizationName, developerID string, precondition db.Precondition) types.Error {
 
-	s.cache.deleteEntry(types.TypeDeveloperName, developerID)
-	return s.developer.DeleteByID(organizationName, developerID)
+	if err := s.developer.DeleteByID(organizationName, developerID, precondition); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeDeveloperName, organizationName)
+	return nil
 }
@@ -1,8 +1,6 @@
 package cache
 
 import (
-	"fmt"
-
 	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
@@ -28,13 +26,18 @@ func (s *DeveloperAppCache) GetAll(organizationName string) (types.DeveloperApps
 		return s.developerapp.GetAll(organizationName)
 	}
 	var developerApps types.DeveloperApps
-	// TODO/FIXME
-	if err := s.cache.fetchEntity(types.TypeDeveloperAppName, "--all-apps--", &developerApps, getDeveloperApps); err != nil {
+	if err := s.cache.fetchEntity(types.TypeDeveloperAppName, organizationName, "all", &developerApps, getDeveloperApps); err != nil {
 		return nil, err
 	}
 	return developerApps, nil
 }
 
+// List retrieves one page of developer apps, pages are not cached
+func (s *DeveloperAppCache) List(organizationName string, params db.ListParams) (types.DeveloperApps, string, types.Error) {
+
+	return s.developerapp.List(organizationName, params)
+}
+
 // GetAllByDeveloperID retrieves all developer apps from one developer
 func (s *DeveloperAppCache) GetAllByDeveloperID(organizationName, developerID string) (types.DeveloperApps, types.Error) {
 
@@ -42,8 +45,7 @@ func (s *DeveloperAppCache) GetAllByDeveloperID(organizationName, developerID st
 		return s.developerapp.GetAllByDeveloperID(organizationName, developerID)
 	}
 	var developerApps types.DeveloperApps
-	cacheKey := fmt.Sprintf("--all-apps-%s-", developerID)
-	if err := s.cache.fetchEntity(types.TypeDeveloperAppName, cacheKey, &developerApps, getDeveloperApps); err != nil {
+	if err := s.cache.fetchEntity(types.TypeDeveloperAppName, organizationName, "developer/"+developerID, &developerApps, getDeveloperApps); err != nil {
 		return nil, err
 	}
 	return developerApps, nil
@@ -56,7 +58,7 @@ func (s *DeveloperAppCache) GetByName(organizationName string, developerEmail, d
 		return s.developerapp.GetByName(organizationName, developerEmail, developerAppName)
 	}
 	var developerApp types.DeveloperApp
-	if err := s.cache.fetchEntity(types.TypeDeveloperAppName, developerAppName, &developerApp, getDeveloperAppByName); err != nil {
+	if err := s.cache.fetchEntity(types.TypeDeveloperAppName, organizationName, "name/"+developerEmail+"/"+developerAppName, &developerApp, getDeveloperAppByName); err != nil {
 		return nil, err
 	}
 	return &developerApp, nil
@@ -69,7 +71,7 @@ func (s *DeveloperAppCache) GetByID(organizationName, developerAppID string) (*t
 		return s.developerapp.GetByID(organizationName, developerAppID)
 	}
 	var developerApp types.DeveloperApp
-	if err := s.cache.fetchEntity(types.TypeDeveloperAppName, developerAppID, &developerApp, getDeveloperAppByID); err != nil {
+	if err := s.cache.fetchEntity(types.TypeDeveloperAppName, organizationName, "id/"+developerAppID, &developerApp, getDeveloperAppByID); err != nil {
 		return nil, err
 	}
 	return &developerApp, nil
@@ -82,15 +84,21 @@ func (s *DeveloperAppCache) GetCountByDeveloperID(organizationName, developerID
 }
 
 // Update UPSERTs a developer app
-func (s *DeveloperAppCache) Update(organizationName string, app *types.DeveloperApp) types.Error {
+func (s *DeveloperAppCache) Update(organizationName string, app *types.DeveloperApp, precondition db.Precondition) types.Error {
 
-	s.cache.deleteEntry(types.TypeDeveloperAppName, app.AppID)
-	return s.developerapp.Update(organizationName, app)
+	if err := s.developerapp.Update(organizationName, app, precondition); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeDeveloperAppName, organizationName)
+	return nil
 }
 
 // DeleteByID deletes a developer app
-func (s *DeveloperAppCache) DeleteByID(organizationName, developerAppID string) types.Error {
+func (s *DeveloperAppCache) DeleteByID(organizationName, developerAppID string, precondition db.Precondition) types.Error {
 
-	s.cache.deleteEntry(types.TypeDeveloperAppName, developerAppID)
-	return s.developerapp.DeleteByID(organizationName, developerAppID)
+	if err := s.developerapp.DeleteByID(organizationName, developerAppID, precondition); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeDeveloperAppName, organizationName)
+	return nil
 }
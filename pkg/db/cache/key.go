@@ -25,8 +25,13 @@ func (s *KeyCache) GetByKey(organizationName, key *string) (*types.Key, types.Er
 	getByKey := func() (interface{}, types.Error) {
 		return s.key.GetByKey(organizationName, key)
 	}
+	// Key can be retrieved without organization, these are cached separately
+	var organization string
+	if organizationName != nil {
+		organization = *organizationName
+	}
 	var retrievedKey types.Key
-	if err := s.cache.fetchEntity(types.TypeKeyName, *key, &retrievedKey, getByKey); err != nil {
+	if err := s.cache.fetchEntity(types.TypeKeyName, organization, "key/"+*key, &retrievedKey, getByKey); err != nil {
 		return nil, err
 	}
 	return &retrievedKey, nil
@@ -39,7 +44,7 @@ func (s *KeyCache) GetByDeveloperAppID(organizationName, developerAppID string)
 		return s.key.GetByDeveloperAppID(organizationName, developerAppID)
 	}
 	var retrievedKeys types.Keys
-	if err := s.cache.fetchEntity(types.TypeKeyName, developerAppID, &retrievedKeys, getByAppID); err != nil {
+	if err := s.cache.fetchEntity(types.TypeKeyName, organizationName, "app/"+developerAppID, &retrievedKeys, getByAppID); err != nil {
 		return nil, err
 	}
 	return retrievedKeys, nil
@@ -52,7 +57,7 @@ func (s *KeyCache) GetCountByAPIProductName(organizationName, apiProductName str
 		return s.key.GetCountByAPIProductName(organizationName, apiProductName)
 	}
 	var apiProductCount int
-	if err := s.cache.fetchEntity(types.TypeKeyName, apiProductName, &apiProductCount, getCountOfAPIProduct); err != nil {
+	if err := s.cache.fetchEntity(types.TypeKeyName, organizationName, "count/"+apiProductName, &apiProductCount, getCountOfAPIProduct); err != nil {
 		return 0, err
 	}
 	return apiProductCount, nil
@@ -61,13 +66,19 @@ func (s *KeyCache) GetCountByAPIProductName(organizationName, apiProductName str
 // UpdateByKey UPSERTs keys in database
 func (s *KeyCache) UpdateByKey(organizationName string, c *types.Key) types.Error {
 
-	s.cache.deleteEntry(types.TypeKeyName, c.ConsumerKey)
-	return s.key.UpdateByKey(organizationName, c)
+	if err := s.key.UpdateByKey(organizationName, c); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeKeyName, organizationName)
+	return nil
 }
 
 // DeleteByKey deletes keys
 func (s *KeyCache) DeleteByKey(organizationName, consumerKey string) types.Error {
 
-	s.cache.deleteEntry(types.TypeKeyName, consumerKey)
-	return s.key.DeleteByKey(organizationName, consumerKey)
+	if err := s.key.DeleteByKey(organizationName, consumerKey); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeKeyName, organizationName)
+	return nil
 }
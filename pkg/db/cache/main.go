@@ -1,8 +1,11 @@
 package cache
 
 import (
+	"sync"
+
 	"github.com/coocood/freecache"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/erikbos/gatekeeper/pkg/db"
 )
@@ -12,25 +15,32 @@ type Config struct {
 	Size        int
 	TTL         int
 	NegativeTTL int
+	// Interval in seconds between checks of database changelog for changed entities,
+	// 0 disables invalidation and entries will only be refreshed after TTL
+	InvalidationInterval int
 }
 
 // Cache holds our runtime parameters
 type Cache struct {
-	config    *Config
-	db        *db.Database
-	freecache *freecache.Cache
-	logger    *zap.Logger
-	metrics   *metrics
+	config           *Config
+	db               *db.Database
+	freecache        *freecache.Cache
+	generations      map[string]uint64
+	generationsMutex sync.RWMutex
+	inflight         singleflight.Group
+	logger           *zap.Logger
+	metrics          *metrics
 }
 
 // New initializes read through cache for database access
 func New(config *Config, d *db.Database, applicationName string, logger *zap.Logger) (*db.Database, error) {
 
 	c := &Cache{
-		config:    config,
-		db:        d,
-		freecache: freecache.NewCache(config.Size),
-		logger:    logger.With(zap.String("system", "cache")),
+		config:      config,
+		db:          d,
+		freecache:   freecache.NewCache(config.Size),
+		generations: make(map[string]uint64),
+		logger:      logger.With(zap.String("system", "cache")),
 	}
 	c.metrics = newMetrics(c)
 	c.metrics.registerMetricsWithPrometheus(applicationName)
@@ -38,12 +48,19 @@ func New(config *Config, d *db.Database, applicationName string, logger *zap.Log
 	c.logger.Info("new",
 		zap.Int("size", config.Size),
 		zap.Int("ttl", config.TTL),
-		zap.Int("negativettl", config.NegativeTTL))
+		zap.Int("negativettl", config.NegativeTTL),
+		zap.Int("invalidationinterval", config.InvalidationInterval))
+
+	if config.InvalidationInterval > 0 && d.ChangeLog != nil {
+		go c.followChangeLog(d.ChangeLog)
+	}
 
 	return &db.Database{
 		Listener:     d.Listener,
 		Route:        d.Route,
 		Cluster:      d.Cluster,
+		Organization: d.Organization,
+		Company:      d.Company,
 		Developer:    NewDeveloperCache(c, d.Developer),
 		DeveloperApp: NewDeveloperAppCache(c, d.DeveloperApp),
 		APIProduct:   NewAPIProductCache(c, d.APIProduct),
@@ -51,5 +68,7 @@ func New(config *Config, d *db.Database, applicationName string, logger *zap.Log
 		OAuth:        NewOAuthCache(c, d.OAuth),
 		User:         NewUserCache(c, d.User),
 		Role:         NewRoleCache(c, d.Role),
+		Audit:        d.Audit,
+		ChangeLog:    d.ChangeLog,
 	}, nil
 }
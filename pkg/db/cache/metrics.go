@@ -5,8 +5,11 @@ import "github.com/prometheus/client_golang/prometheus"
 type metrics struct {
 	cache *Cache
 
-	cacheHits   *prometheus.CounterVec
-	cacheMisses *prometheus.CounterVec
+	cacheHits          *prometheus.CounterVec
+	cacheMisses        *prometheus.CounterVec
+	cacheInvalidations *prometheus.CounterVec
+	cacheNegativeHits  *prometheus.CounterVec
+	cacheCoalesced     *prometheus.CounterVec
 }
 
 func newMetrics(cache *Cache) *metrics {
@@ -32,6 +35,30 @@ func (m *metrics) registerMetricsWithPrometheus(applicationName string) {
 		}, []string{"entity"})
 	prometheus.MustRegister(m.cacheMisses)
 
+	m.cacheInvalidations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: applicationName,
+			Name:      "cache_invalidations_total",
+			Help:      "Number of cache invalidations due to changed entities.",
+		}, []string{"entity"})
+	prometheus.MustRegister(m.cacheInvalidations)
+
+	m.cacheNegativeHits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: applicationName,
+			Name:      "cache_negative_hits_total",
+			Help:      "Number of cache hits of non-existing entities.",
+		}, []string{"entity"})
+	prometheus.MustRegister(m.cacheNegativeHits)
+
+	m.cacheCoalesced = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: applicationName,
+			Name:      "cache_coalesced_total",
+			Help:      "Number of cache misses which shared a concurrent database lookup.",
+		}, []string{"entity"})
+	prometheus.MustRegister(m.cacheCoalesced)
+
 	prometheus.MustRegister(prometheus.NewGaugeFunc(
 		prometheus.GaugeOpts{
 			Namespace: applicationName,
@@ -110,3 +137,21 @@ func (m *metrics) EntityCacheMiss(entityType string) {
 
 	m.cacheMisses.WithLabelValues(entityType).Inc()
 }
+
+// EntityCacheInvalidation
+func (m *metrics) EntityCacheInvalidation(entityType string) {
+
+	m.cacheInvalidations.WithLabelValues(entityType).Inc()
+}
+
+// EntityCacheNegativeHit
+func (m *metrics) EntityCacheNegativeHit(entityType string) {
+
+	m.cacheNegativeHits.WithLabelValues(entityType).Inc()
+}
+
+// EntityCacheCoalesced
+func (m *metrics) EntityCacheCoalesced(entityType string) {
+
+	m.cacheCoalesced.WithLabelValues(entityType).Inc()
+}
@@ -30,7 +30,7 @@ func (s *OAuthCache) OAuthAccessTokenGetByAccess(accessToken string) (*types.OAu
 		return token, nil
 	}
 	var oauthToken types.OAuthAccessToken
-	if err := s.cache.fetchEntity(types.TypeOAuthName, accessToken, &oauthToken, getTokenByAccess); err != nil {
+	if err := s.cache.fetchEntity(types.TypeOAuthName, "", "access/"+accessToken, &oauthToken, getTokenByAccess); err != nil {
 		return nil, err
 	}
 	return &oauthToken, nil
@@ -47,7 +47,7 @@ func (s *OAuthCache) OAuthAccessTokenGetByCode(code string) (*types.OAuthAccessT
 		return token, nil
 	}
 	var oauthToken types.OAuthAccessToken
-	if err := s.cache.fetchEntity(types.TypeOAuthName, code, &oauthToken, getTokenByCode); err != nil {
+	if err := s.cache.fetchEntity(types.TypeOAuthName, "", "code/"+code, &oauthToken, getTokenByCode); err != nil {
 		return nil, err
 	}
 	return &oauthToken, nil
@@ -64,12 +64,18 @@ func (s *OAuthCache) OAuthAccessTokenGetByRefresh(refresh string) (*types.OAuthA
 		return token, nil
 	}
 	var oauthToken types.OAuthAccessToken
-	if err := s.cache.fetchEntity(types.TypeOAuthName, refresh, &oauthToken, getTokenByRefresh); err != nil {
+	if err := s.cache.fetchEntity(types.TypeOAuthName, "", "refresh/"+refresh, &oauthToken, getTokenByRefresh); err != nil {
 		return nil, err
 	}
 	return &oauthToken, nil
 }
 
+// OAuthAccessTokenGetByClientID retrieves all tokens issued to a client, these are not cached
+func (s *OAuthCache) OAuthAccessTokenGetByClientID(clientID string) (types.OAuthAccessTokens, error) {
+
+	return s.oauth.OAuthAccessTokenGetByClientID(clientID)
+}
+
 // OAuthAccessTokenCreate UPSERTs a token in database
 func (s *OAuthCache) OAuthAccessTokenCreate(t *types.OAuthAccessToken) error {
 
@@ -79,20 +85,29 @@ func (s *OAuthCache) OAuthAccessTokenCreate(t *types.OAuthAccessToken) error {
 // OAuthAccessTokenRemoveByAccess deletes an access token
 func (s *OAuthCache) OAuthAccessTokenRemoveByAccess(accessTokenToDelete string) error {
 
-	s.cache.deleteEntry(types.TypeOAuthName, accessTokenToDelete)
-	return s.oauth.OAuthAccessTokenRemoveByAccess(accessTokenToDelete)
+	if err := s.oauth.OAuthAccessTokenRemoveByAccess(accessTokenToDelete); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeOAuthName, "")
+	return nil
 }
 
 // OAuthAccessTokenRemoveByCode deletes an access token
 func (s *OAuthCache) OAuthAccessTokenRemoveByCode(codeToDelete string) error {
 
-	s.cache.deleteEntry(types.TypeOAuthName, codeToDelete)
-	return s.oauth.OAuthAccessTokenRemoveByAccess(codeToDelete)
+	if err := s.oauth.OAuthAccessTokenRemoveByCode(codeToDelete); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeOAuthName, "")
+	return nil
 }
 
 // OAuthAccessTokenRemoveByRefresh deletes an access token
 func (s *OAuthCache) OAuthAccessTokenRemoveByRefresh(refreshToDelete string) error {
 
-	s.cache.deleteEntry(types.TypeOAuthName, refreshToDelete)
-	return s.oauth.OAuthAccessTokenRemoveByAccess(refreshToDelete)
+	if err := s.oauth.OAuthAccessTokenRemoveByRefresh(refreshToDelete); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeOAuthName, "")
+	return nil
 }
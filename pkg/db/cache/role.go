@@ -26,7 +26,7 @@ func (s *RoleCache) GetAll() (types.Roles, types.Error) {
 		return s.role.GetAll()
 	}
 	var roles types.Roles
-	if err := s.cache.fetchEntity(types.TypeRoleName, "", &roles, getAll); err != nil {
+	if err := s.cache.fetchEntity(types.TypeRoleName, "", "all", &roles, getAll); err != nil {
 		return nil, err
 	}
 	return roles, nil
@@ -39,22 +39,28 @@ func (s *RoleCache) Get(roleName string) (*types.Role, types.Error) {
 		return s.role.Get(roleName)
 	}
 	var role types.Role
-	if err := s.cache.fetchEntity(types.TypeRoleName, roleName, &role, getRole); err != nil {
+	if err := s.cache.fetchEntity(types.TypeRoleName, "", "name/"+roleName, &role, getRole); err != nil {
 		return nil, err
 	}
 	return &role, nil
 }
 
 // Update UPSERTs an role in database
-func (s *RoleCache) Update(c *types.Role) types.Error {
+func (s *RoleCache) Update(c *types.Role, precondition db.Precondition) types.Error {
 
-	s.cache.deleteEntry(types.TypeRoleName, c.Name)
-	return s.role.Update(c)
+	if err := s.role.Update(c, precondition); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeRoleName, "")
+	return nil
 }
 
 // Delete deletes a role
-func (s *RoleCache) Delete(roleToDelete string) types.Error {
+func (s *RoleCache) Delete(roleToDelete string, precondition db.Precondition) types.Error {
 
-	s.cache.deleteEntry(types.TypeRoleName, roleToDelete)
-	return s.role.Delete(roleToDelete)
+	if err := s.role.Delete(roleToDelete, precondition); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeRoleName, "")
+	return nil
 }
@@ -26,7 +26,7 @@ func (s *UserCache) GetAll() (types.Users, types.Error) {
 		return s.user.GetAll()
 	}
 	var users types.Users
-	if err := s.cache.fetchEntity(types.TypeUserName, "", &users, getAll); err != nil {
+	if err := s.cache.fetchEntity(types.TypeUserName, "", "all", &users, getAll); err != nil {
 		return nil, err
 	}
 	return users, nil
@@ -39,22 +39,28 @@ func (s *UserCache) Get(userName string) (*types.User, types.Error) {
 		return s.user.Get(userName)
 	}
 	var user types.User
-	if err := s.cache.fetchEntity(types.TypeUserName, userName, &user, getUser); err != nil {
+	if err := s.cache.fetchEntity(types.TypeUserName, "", "name/"+userName, &user, getUser); err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
 // Update UPSERTs an user in database
-func (s *UserCache) Update(c *types.User) types.Error {
+func (s *UserCache) Update(c *types.User, precondition db.Precondition) types.Error {
 
-	s.cache.deleteEntry(types.TypeUserName, c.Name)
-	return s.user.Update(c)
+	if err := s.user.Update(c, precondition); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeUserName, "")
+	return nil
 }
 
 // Delete deletes a user
-func (s *UserCache) Delete(userToDelete string) types.Error {
+func (s *UserCache) Delete(userToDelete string, precondition db.Precondition) types.Error {
 
-	s.cache.deleteEntry(types.TypeUserName, userToDelete)
-	return s.user.Delete(userToDelete)
+	if err := s.user.Delete(userToDelete, precondition); err != nil {
+		return err
+	}
+	s.cache.invalidate(types.TypeUserName, "")
+	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/gocql/gocql"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
@@ -58,6 +59,34 @@ func (s *APIProductStore) GetAll(organizationName string) (types.APIProducts, ty
 	return apiproducts, nil
 }
 
+// List retrieves one page of api products matching filter.
+// As filtering happens after retrieval a page can contain less than the requested number of api products.
+func (s *APIProductStore) List(organizationName string, params db.ListParams) (types.APIProducts, string, types.Error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	query := "SELECT " + apiProductsColumns + " FROM api_products WHERE organization_name = ?"
+	iter, nextPageToken, e := s.db.pagedQuery(params, query, organizationName)
+	if e != nil {
+		return types.NullAPIProducts, "", e
+	}
+	apiproducts, err := s.scanAPIProducts(iter)
+	if err != nil {
+		s.db.metrics.QueryFailed(apiProductsMetricLabel)
+		return types.NullAPIProducts, "", types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(apiProductsMetricLabel)
+	matching := types.APIProducts{}
+	for _, apiproduct := range apiproducts {
+		if params.Matches("", "", apiproduct.LastModifiedAt) {
+			matching = append(matching, apiproduct)
+		}
+	}
+	return matching, nextPageToken, nil
+}
+
 // Get returns an apiproduct
 func (s *APIProductStore) Get(organizationName, apiproductName string) (*types.APIProduct, types.Error) {
 
@@ -80,7 +109,6 @@ func (s *APIProductStore) Get(organizationName, apiproductName string) (*types.A
 
 // runGetAPIProductQuery executes CQL query and returns resultset
 func (s *APIProductStore) runGetAPIProductQuery(query string, queryParameters ...interface{}) (types.APIProducts, error) {
-	var apiproducts types.APIProducts
 
 	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
 	defer timer.ObserveDuration()
@@ -91,6 +119,13 @@ func (s *APIProductStore) runGetAPIProductQuery(query string, queryParameters ..
 	} else {
 		iter = s.db.CassandraSession.Query(query, queryParameters...).Iter()
 	}
+	return s.scanAPIProducts(iter)
+}
+
+// scanAPIProducts returns all apiproducts of resultset
+func (s *APIProductStore) scanAPIProducts(iter *gocql.Iter) (types.APIProducts, error) {
+	var apiproducts types.APIProducts
+
 	if iter.NumRows() == 0 {
 		_ = iter.Close()
 		return types.NullAPIProducts, nil
@@ -106,7 +141,7 @@ func (s *APIProductStore) runGetAPIProductQuery(query string, queryParameters ..
 			APIResources:   columnToStringSlice(m, "api_resources"),
 			RouteGroup:     columnToString(m, "route_group"),
 			Scopes:         columnToStringSlice(m, "scopes"),
-			Policies:       columnToString(m, "policies"),
+			Policies:       columnToPolicies(m, "policies"),
 			Attributes:     columnToAttributes(m, "attributes"),
 			CreatedAt:      columnToInt64(m, "created_at"),
 			CreatedBy:      columnToString(m, "created_by"),
@@ -124,10 +159,9 @@ func (s *APIProductStore) runGetAPIProductQuery(query string, queryParameters ..
 }
 
 // Update UPSERTs an apiproduct in database
-func (s *APIProductStore) Update(organizationName string, p *types.APIProduct) types.Error {
+func (s *APIProductStore) Update(organizationName string, p *types.APIProduct, precondition db.Precondition) types.Error {
 
-	query := "INSERT INTO api_products (" + apiProductsColumns + ") VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
-	if err := s.db.CassandraSession.Query(query,
+	if err := s.db.upsert("api_products", apiProductsColumns, precondition,
 		s.generatePrimaryKey(organizationName, p.Name),
 		p.Name,
 		p.Description,
@@ -137,13 +171,16 @@ func (s *APIProductStore) Update(organizationName string, p *types.APIProduct) t
 		p.APIResources,
 		p.RouteGroup,
 		p.Scopes,
-		p.Policies,
+		policiesToColumn(p.Policies),
 		attributesToColumn(p.Attributes),
 		p.CreatedAt,
 		p.CreatedBy,
 		p.LastModifiedAt,
-		p.LastModifiedBy).Exec(); err != nil {
+		p.LastModifiedBy); err != nil {
 
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeAPIProductName, p.Name)
+		}
 		s.db.metrics.QueryFailed(apiProductsMetricLabel)
 		return types.NewDatabaseError(
 			fmt.Errorf("cannot update apiproduct '%s' (%s)", p.Name, err))
@@ -152,11 +189,14 @@ func (s *APIProductStore) Update(organizationName string, p *types.APIProduct) t
 }
 
 // Delete deletes an apiproduct
-func (s *APIProductStore) Delete(organizationName, apiProduct string) types.Error {
+func (s *APIProductStore) Delete(organizationName, apiProduct string, precondition db.Precondition) types.Error {
 
 	query := "DELETE FROM api_products WHERE key = ?"
 	key := s.generatePrimaryKey(organizationName, apiProduct)
-	if err := s.db.CassandraSession.Query(query, key).Exec(); err != nil {
+	if err := s.db.delete(query, precondition, key); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeAPIProductName, apiProduct)
+		}
 		s.db.metrics.QueryFailed(apiProductsMetricLabel)
 		return types.NewDatabaseError(err)
 	}
@@ -0,0 +1,106 @@
+package cassandra
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of changelog columns we use
+	changeLogColumns = `change_id,
+timestamp,
+operation,
+entity_type,
+organization,
+entity_id`
+
+	// Prometheus label for metrics of db interactions
+	changeLogMetricLabel = "changelog"
+
+	// Default TTL for changelog row, Cassandra will expire row afer this period
+	defaultChangeLogTTL = 86400
+)
+
+// ChangeLogStore holds our database config
+type ChangeLogStore struct {
+	db *Database
+}
+
+// NewChangeLogStore creates changelog instance
+func NewChangeLogStore(database *Database) *ChangeLogStore {
+	return &ChangeLogStore{
+		db: database,
+	}
+}
+
+// GetSince retrieves changes made after timestamp
+func (s *ChangeLogStore) GetSince(timestamp int64) (types.Changes, types.Error) {
+
+	query := "SELECT " + changeLogColumns + " FROM changelog WHERE timestamp > ? ALLOW FILTERING"
+	changes, err := s.runGetChangeLogQuery(query, timestamp)
+	if err != nil {
+		s.db.metrics.QueryFailed(changeLogMetricLabel)
+		return types.NullChanges, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(changeLogMetricLabel)
+	return changes, nil
+}
+
+// runGetChangeLogQuery executes CQL query and returns resultset ordered by timestamp
+func (s *ChangeLogStore) runGetChangeLogQuery(query string, queryParameters ...interface{}) (types.Changes, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	changes := types.Changes{}
+
+	iter := s.db.CassandraSession.Query(query, queryParameters...).Iter()
+	m := make(map[string]interface{})
+	for iter.MapScan(m) {
+		changes = append(changes, types.Change{
+			ID:           columnToString(m, "change_id"),
+			Timestamp:    columnToInt64(m, "timestamp"),
+			Operation:    columnToString(m, "operation"),
+			EntityType:   columnToString(m, "entity_type"),
+			Organization: columnToString(m, "organization"),
+			EntityID:     columnToString(m, "entity_id"),
+		})
+		m = map[string]interface{}{}
+	}
+	// In case query failed we return query error
+	if err := iter.Close(); err != nil {
+		return types.NullChanges, err
+	}
+	// Cassandra cannot order on a non-clustering column
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Timestamp < changes[j].Timestamp
+	})
+	return changes, nil
+}
+
+// Write inserts a change record
+func (s *ChangeLogStore) Write(c *types.Change) types.Error {
+
+	// "USING TTL %d" is used to give each inserted row a time-to-live,
+	// this will force the database to expire the row.
+	query := fmt.Sprintf("INSERT INTO changelog ("+changeLogColumns+
+		") VALUES(?,?,?,?,?,?) USING TTL %d", defaultChangeLogTTL)
+	if err := s.db.CassandraSession.Query(query,
+		c.ID,
+		c.Timestamp,
+		c.Operation,
+		c.EntityType,
+		c.Organization,
+		c.EntityID).Exec(); err != nil {
+
+		s.db.metrics.QueryFailed(changeLogMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot write change (%s)", err))
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
@@ -97,18 +98,20 @@ func (s *ClusterStore) runGetClusterQuery(query string, queryParameters ...inter
 }
 
 // Update UPSERTs an cluster in database
-func (s *ClusterStore) Update(c *types.Cluster) types.Error {
+func (s *ClusterStore) Update(c *types.Cluster, precondition db.Precondition) types.Error {
 
-	query := "INSERT INTO clusters (" + clusterColumns + ") VALUES(?,?,?,?,?,?,?)"
-	if err := s.db.CassandraSession.Query(query,
+	if err := s.db.upsert("clusters", clusterColumns, precondition,
 		c.Name,
 		c.DisplayName,
 		attributesToColumn(c.Attributes),
 		c.CreatedAt,
 		c.CreatedBy,
 		c.LastModifiedAt,
-		c.LastModifiedBy).Exec(); err != nil {
+		c.LastModifiedBy); err != nil {
 
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeClusterName, c.Name)
+		}
 		s.db.metrics.QueryFailed(clusterMetricLabel)
 		return types.NewDatabaseError(
 			fmt.Errorf("cannot update cluster '%s' (%s)", c.Name, err))
@@ -117,10 +120,13 @@ func (s *ClusterStore) Update(c *types.Cluster) types.Error {
 }
 
 // Delete deletes a cluster
-func (s *ClusterStore) Delete(clusterToDelete string) types.Error {
+func (s *ClusterStore) Delete(clusterToDelete string, precondition db.Precondition) types.Error {
 
 	query := "DELETE FROM clusters WHERE name = ?"
-	if err := s.db.CassandraSession.Query(query, clusterToDelete).Exec(); err != nil {
+	if err := s.db.delete(query, precondition, clusterToDelete); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeClusterName, clusterToDelete)
+		}
 		s.db.metrics.QueryFailed(clusterMetricLabel)
 		return types.NewDatabaseError(err)
 	}
@@ -3,8 +3,10 @@ package cassandra
 import (
 	"fmt"
 
+	"github.com/gocql/gocql"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
@@ -50,6 +52,34 @@ func (s *CompanyStore) GetAll(organizationName string) (types.Companies, types.E
 	return companies, nil
 }
 
+// List retrieves one page of companies matching filter.
+// As filtering happens after retrieval a page can contain less than the requested number of companies.
+func (s *CompanyStore) List(organizationName string, params db.ListParams) (types.Companies, string, types.Error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	query := "SELECT " + companyColumns + " FROM companies WHERE organization_name = ?"
+	iter, nextPageToken, e := s.db.pagedQuery(params, query, organizationName)
+	if e != nil {
+		return types.NullCompanies, "", e
+	}
+	companies, err := s.scanCompanies(iter)
+	if err != nil {
+		s.db.metrics.QueryFailed(companyMetricLabel)
+		return types.NullCompanies, "", types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(companyMetricLabel)
+	matching := types.Companies{}
+	for _, company := range companies {
+		if params.Matches(company.Status, "", company.LastModifiedAt) {
+			matching = append(matching, company)
+		}
+	}
+	return matching, nextPageToken, nil
+}
+
 // Get retrieves a company from database
 func (s *CompanyStore) Get(organizationName, companyName string) (*types.Company, types.Error) {
 
@@ -72,12 +102,17 @@ func (s *CompanyStore) Get(organizationName, companyName string) (*types.Company
 
 // runGetCompanyQuery executes CQL query and returns resultset
 func (s *CompanyStore) runGetCompanyQuery(query string, queryParameters ...interface{}) (types.Companies, error) {
-	var companies types.Companies
 
 	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
 	defer timer.ObserveDuration()
 
-	iter := s.db.CassandraSession.Query(query, queryParameters...).Iter()
+	return s.scanCompanies(s.db.CassandraSession.Query(query, queryParameters...).Iter())
+}
+
+// scanCompanies returns all companies of resultset
+func (s *CompanyStore) scanCompanies(iter *gocql.Iter) (types.Companies, error) {
+	var companies types.Companies
+
 	m := make(map[string]interface{})
 	for iter.MapScan(m) {
 		companies = append(companies, types.Company{
@@ -100,10 +135,9 @@ func (s *CompanyStore) runGetCompanyQuery(query string, queryParameters ...inter
 }
 
 // Update UPSERTs an company in database
-func (s *CompanyStore) Update(organizationName string, c *types.Company) types.Error {
+func (s *CompanyStore) Update(organizationName string, c *types.Company, precondition db.Precondition) types.Error {
 
-	query := "INSERT INTO companies (" + companyColumns + ") VALUES(?,?,?,?,?,?,?,?,?,?)"
-	if err := s.db.CassandraSession.Query(query,
+	if err := s.db.upsert("companies", companyColumns, precondition,
 		s.generatePrimaryKey(organizationName, c.Name),
 		c.Name,
 		organizationName,
@@ -113,8 +147,11 @@ func (s *CompanyStore) Update(organizationName string, c *types.Company) types.E
 		c.CreatedAt,
 		c.CreatedBy,
 		c.LastModifiedAt,
-		c.LastModifiedBy).Exec(); err != nil {
+		c.LastModifiedBy); err != nil {
 
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeCompanyName, c.Name)
+		}
 		s.db.metrics.QueryFailed(companyMetricLabel)
 		return types.NewDatabaseError(
 			fmt.Errorf("cannot update company '%s' (%s)", c.Name, err))
@@ -123,11 +160,14 @@ func (s *CompanyStore) Update(organizationName string, c *types.Company) types.E
 }
 
 // Delete deletes a company
-func (s *CompanyStore) Delete(organizationName, companyToDelete string) types.Error {
+func (s *CompanyStore) Delete(organizationName, companyToDelete string, precondition db.Precondition) types.Error {
 
 	query := "DELETE FROM companies WHERE key = ?"
 	key := s.generatePrimaryKey(organizationName, companyToDelete)
-	if err := s.db.CassandraSession.Query(query, key).Exec(); err != nil {
+	if err := s.db.delete(query, precondition, key); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeCompanyName, companyToDelete)
+		}
 		s.db.metrics.QueryFailed(companyMetricLabel)
 		return types.NewDatabaseError(err)
 	}
@@ -3,8 +3,10 @@ package cassandra
 import (
 	"fmt"
 
+	"github.com/gocql/gocql"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
@@ -54,6 +56,34 @@ func (s *DeveloperStore) GetAll(organizationName string) (types.Developers, type
 	return developers, nil
 }
 
+// List retrieves one page of developers matching filter.
+// As filtering happens after retrieval a page can contain less than the requested number of developers.
+func (s *DeveloperStore) List(organizationName string, params db.ListParams) (types.Developers, string, types.Error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	query := "SELECT " + developerColumns + " FROM developers WHERE organization_name = ? ALLOW FILTERING"
+	iter, nextPageToken, e := s.db.pagedQuery(params, query, organizationName)
+	if e != nil {
+		return types.NullDevelopers, "", e
+	}
+	developers, err := s.scanDevelopers(iter)
+	if err != nil {
+		s.db.metrics.QueryFailed(developerMetricLabel)
+		return types.NullDevelopers, "", types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(developerMetricLabel)
+	matching := types.Developers{}
+	for _, developer := range developers {
+		if params.Matches(developer.Status, developer.Email, developer.LastModifiedAt) {
+			matching = append(matching, developer)
+		}
+	}
+	return matching, nextPageToken, nil
+}
+
 // GetByEmail retrieves a developer from database
 func (s *DeveloperStore) GetByEmail(organizationName, developerEmail string) (*types.Developer, types.Error) {
 
@@ -97,13 +127,18 @@ func (s *DeveloperStore) GetByID(organizationName, developerID string) (*types.D
 // runDeveloperQuery executes CQL query and returns resultset
 func (s *DeveloperStore) runGetDeveloperQuery(query string, queryParameters ...interface{}) (types.Developers, error) {
 
-	var developers types.Developers
-
 	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
 	defer timer.ObserveDuration()
 
 	// Run query, and transfer in batches of 100 rows
-	iterable := s.db.CassandraSession.Query(query, queryParameters...).PageSize(100).Iter()
+	return s.scanDevelopers(s.db.CassandraSession.Query(query, queryParameters...).PageSize(100).Iter())
+}
+
+// scanDevelopers returns all developers of resultset
+func (s *DeveloperStore) scanDevelopers(iterable *gocql.Iter) (types.Developers, error) {
+
+	var developers types.Developers
+
 	m := make(map[string]interface{})
 	for iterable.MapScan(m) {
 		developers = append(developers, types.Developer{
@@ -130,10 +165,9 @@ func (s *DeveloperStore) runGetDeveloperQuery(query string, queryParameters ...i
 }
 
 // Update UPSERTs a developer in database
-func (s *DeveloperStore) Update(organizationName string, d *types.Developer) types.Error {
+func (s *DeveloperStore) Update(organizationName string, d *types.Developer, precondition db.Precondition) types.Error {
 
-	query := "INSERT INTO developers (" + developerColumns + ") VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
-	if err := s.db.CassandraSession.Query(query,
+	if err := s.db.upsert("developers", developerColumns, precondition,
 		s.developerPrimaryKey(organizationName, d.DeveloperID),
 		d.DeveloperID,
 		d.Apps,
@@ -147,8 +181,11 @@ func (s *DeveloperStore) Update(organizationName string, d *types.Developer) typ
 		d.CreatedAt,
 		d.CreatedBy,
 		d.LastModifiedAt,
-		d.LastModifiedBy).Exec(); err != nil {
+		d.LastModifiedBy); err != nil {
 
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeDeveloperName, d.Email)
+		}
 		s.db.metrics.QueryFailed(developerMetricLabel)
 		return types.NewDatabaseError(
 			fmt.Errorf("cannot update developer '%s' (%s)", d.DeveloperID, err))
@@ -157,11 +194,14 @@ func (s *DeveloperStore) Update(organizationName string, d *types.Developer) typ
 }
 
 // DeleteByID deletes a developer
-func (s *DeveloperStore) DeleteByID(organizationName, developerID string) types.Error {
+func (s *DeveloperStore) DeleteByID(organizationName, developerID string, precondition db.Precondition) types.Error {
 
 	query := "DELETE FROM developers WHERE key = ?"
-	if err := s.db.CassandraSession.Query(query,
-		s.developerPrimaryKey(organizationName, developerID)).Exec(); err != nil {
+	if err := s.db.delete(query, precondition,
+		s.developerPrimaryKey(organizationName, developerID)); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeDeveloperName, developerID)
+		}
 		s.db.metrics.QueryFailed(developerMetricLabel)
 		return types.NewDatabaseError(err)
 	}
@@ -3,8 +3,10 @@ package cassandra
 import (
 	"fmt"
 
+	"github.com/gocql/gocql"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
@@ -53,6 +55,34 @@ func (s *DeveloperAppStore) GetAll(organizationName string) (types.DeveloperApps
 	return developerapps, nil
 }
 
+// List retrieves one page of developer apps matching filter.
+// As filtering happens after retrieval a page can contain less than the requested number of developer apps.
+func (s *DeveloperAppStore) List(organizationName string, params db.ListParams) (types.DeveloperApps, string, types.Error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	query := "SELECT " + developerAppColumns + " FROM developer_apps"
+	iter, nextPageToken, e := s.db.pagedQuery(params, query)
+	if e != nil {
+		return types.NullDeveloperApps, "", e
+	}
+	developerapps, err := s.scanDeveloperApps(iter)
+	if err != nil {
+		s.db.metrics.QueryFailed(developerAppsMetricLabel)
+		return types.NullDeveloperApps, "", types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(developerAppsMetricLabel)
+	matching := types.DeveloperApps{}
+	for _, developerapp := range developerapps {
+		if params.Matches(developerapp.Status, "", developerapp.LastModifiedAt) {
+			matching = append(matching, developerapp)
+		}
+	}
+	return matching, nextPageToken, nil
+}
+
 // GetAllByDeveloperID retrieves all developer apps from a developer
 func (s *DeveloperAppStore) GetAllByDeveloperID(organizationName, developerID string) (types.DeveloperApps, types.Error) {
 
@@ -122,12 +152,17 @@ func (s *DeveloperAppStore) GetCountByDeveloperID(organizationName, developerID
 
 // runGetDeveloperAppQuery executes CQL query and returns resultset
 func (s *DeveloperAppStore) runGetDeveloperAppQuery(query string, queryParameters ...interface{}) (types.DeveloperApps, error) {
-	var developerapps types.DeveloperApps
 
 	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
 	defer timer.ObserveDuration()
 
-	iterable := s.db.CassandraSession.Query(query, queryParameters...).Iter()
+	return s.scanDeveloperApps(s.db.CassandraSession.Query(query, queryParameters...).Iter())
+}
+
+// scanDeveloperApps returns all developer apps of resultset
+func (s *DeveloperAppStore) scanDeveloperApps(iterable *gocql.Iter) (types.DeveloperApps, error) {
+	var developerapps types.DeveloperApps
+
 	m := make(map[string]interface{})
 	for iterable.MapScan(m) {
 		developerapps = append(developerapps, types.DeveloperApp{
@@ -154,10 +189,9 @@ func (s *DeveloperAppStore) runGetDeveloperAppQuery(query string, queryParameter
 }
 
 // Update UPSERTs a developer app
-func (s *DeveloperAppStore) Update(organizationName string, app *types.DeveloperApp) types.Error {
+func (s *DeveloperAppStore) Update(organizationName string, app *types.DeveloperApp, precondition db.Precondition) types.Error {
 
-	query := "INSERT INTO developer_apps (" + developerAppColumns + ") VALUES(?,?,?,?,?,?,?,?,?,?,?,?)"
-	if err := s.db.CassandraSession.Query(query,
+	if err := s.db.upsert("developer_apps", developerAppColumns, precondition,
 		app.AppID,
 		app.DeveloperID,
 		app.Name,
@@ -169,8 +203,11 @@ func (s *DeveloperAppStore) Update(organizationName string, app *types.Developer
 		app.CreatedAt,
 		app.CreatedBy,
 		app.LastModifiedAt,
-		app.LastModifiedBy).Exec(); err != nil {
+		app.LastModifiedBy); err != nil {
 
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeDeveloperAppName, app.Name)
+		}
 		s.db.metrics.QueryFailed(developerAppsMetricLabel)
 		return types.NewDatabaseError(
 			fmt.Errorf("cannot update developer app '%s' (%s)", app.AppID, err))
@@ -179,10 +216,13 @@ func (s *DeveloperAppStore) Update(organizationName string, app *types.Developer
 }
 
 // DeleteByID deletes a developer app
-func (s *DeveloperAppStore) DeleteByID(organizationName, developerAppID string) types.Error {
+func (s *DeveloperAppStore) DeleteByID(organizationName, developerAppID string, precondition db.Precondition) types.Error {
 
 	query := "DELETE FROM developer_apps WHERE app_id = ?"
-	if err := s.db.CassandraSession.Query(query, developerAppID).Exec(); err != nil {
+	if err := s.db.delete(query, precondition, developerAppID); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeDeveloperAppName, developerAppID)
+		}
 		s.db.metrics.QueryFailed(developerAppsMetricLabel)
 		return types.NewDatabaseError(err)
 	}
@@ -30,29 +30,18 @@ func createKeyspace(s *gocql.Session, keyspace string, replicationCount int, log
 	return nil
 }
 
-// createTables adds required tables if they do not yet exist
-func createTables(s *gocql.Session, logger *zap.Logger) error {
-
-	logger.Info("Creating all tables if not existing")
-	for _, query := range createTablesCQL {
-		logger.Debug("init database", zap.String("cql", query))
-		if err := s.Query(query).Exec(); err != nil {
-			logger.Warn("init database statement failed", zap.Error(err))
-			return err
-		}
-	}
-
-	logger.Info("Tables and indices created if not existing")
-	return nil
-}
-
-// ShowCreateSchemaStatements show CQL statements to create all tables
+// ShowCreateSchemaStatements show CQL statements of all migrations to create all tables
 func ShowCreateSchemaStatements() {
 
 	fmt.Printf(createKeyspaceCQL+"\n\n", "keyspace", 3)
 
-	for _, query := range createTablesCQL {
-		fmt.Printf("%s\n\n", query)
+	fmt.Printf("%s\n\n", createSchemaVersionCQL)
+
+	for _, m := range migrations {
+		fmt.Printf("-- Migration %d: %s\n\n", m.version, m.description)
+		for _, query := range m.statements {
+			fmt.Printf("%s\n\n", query)
+		}
 	}
 }
 
@@ -0,0 +1,28 @@
+package cassandra
+
+import (
+	"github.com/gocql/gocql"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// pagedQuery executes query and returns iterator over the requested page of the resultset,
+// and the token of the next page which holds Cassandra's paging state.
+// In case no page size has been requested the iterator returns the full resultset.
+func (d *Database) pagedQuery(params db.ListParams, query string,
+	queryParameters ...interface{}) (*gocql.Iter, string, types.Error) {
+
+	if params.PageSize <= 0 {
+		return d.CassandraSession.Query(query, queryParameters...).Iter(), "", nil
+	}
+	pageState, err := db.DecodePageToken(params.PageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	// Setting page state disables automatic fetching of next pages by iterator
+	iter := d.CassandraSession.Query(query, queryParameters...).
+		PageSize(params.PageSize).PageState(pageState).Iter()
+
+	return iter, db.EncodePageToken(iter.PageState()), nil
+}
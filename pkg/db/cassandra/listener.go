@@ -5,6 +5,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
@@ -94,7 +95,7 @@ func (s *ListenerStore) runGetListenerQuery(query string,
 			DisplayName:    columnToString(m, "display_name"),
 			LastModifiedAt: columnToInt64(m, "lastmodified_at"),
 			LastModifiedBy: columnToString(m, "lastmodified_by"),
-			Policies:       columnToString(m, "policies"),
+			Policies:       columnToPolicies(m, "policies"),
 			Port:           columnToInt(m, "port"),
 			RouteGroup:     columnToString(m, "route_group"),
 			VirtualHosts:   columnToStringSlice(m, "virtual_hosts"),
@@ -109,22 +110,24 @@ func (s *ListenerStore) runGetListenerQuery(query string,
 }
 
 // Update updates a listener
-func (s *ListenerStore) Update(l *types.Listener) types.Error {
+func (s *ListenerStore) Update(l *types.Listener, precondition db.Precondition) types.Error {
 
-	query := "INSERT INTO listeners (" + listenerColumns + ") VALUES(?,?,?,?,?,?,?,?,?,?,?)"
-	if err := s.db.CassandraSession.Query(query,
+	if err := s.db.upsert("listeners", listenerColumns, precondition,
 		l.Name,
 		l.DisplayName,
 		l.VirtualHosts,
 		l.Port,
 		l.RouteGroup,
-		l.Policies,
+		policiesToColumn(l.Policies),
 		attributesToColumn(l.Attributes),
 		l.CreatedAt,
 		l.CreatedBy,
 		l.LastModifiedAt,
-		l.LastModifiedBy).Exec(); err != nil {
+		l.LastModifiedBy); err != nil {
 
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeListenerName, l.Name)
+		}
 		s.db.metrics.QueryFailed(listenerMetricLabel)
 		return types.NewDatabaseError(
 			fmt.Errorf("cannot update listener '%s' (%s)", l.Name, err))
@@ -133,10 +136,13 @@ func (s *ListenerStore) Update(l *types.Listener) types.Error {
 }
 
 // Delete deletes a listener
-func (s *ListenerStore) Delete(listenerToDelete string) types.Error {
+func (s *ListenerStore) Delete(listenerToDelete string, precondition db.Precondition) types.Error {
 
 	query := "DELETE FROM listeners WHERE name = ?"
-	if err := s.db.CassandraSession.Query(query, listenerToDelete).Exec(); err != nil {
+	if err := s.db.delete(query, precondition, listenerToDelete); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeListenerName, listenerToDelete)
+		}
 		s.db.metrics.QueryFailed(listenerMetricLabel)
 		return types.NewDatabaseError(err)
 	}
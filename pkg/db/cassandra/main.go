@@ -65,12 +65,17 @@ func New(config DatabaseConfig, serviceName string, logger *zap.Logger,
 	if err != nil {
 		return nil, err
 	}
-	// Create tables within keyspace if requested
+	// Apply pending schema migrations within keyspace if requested
 	if createSchema {
-		if err := createTables(cassandraSession, logger); err != nil {
+		if err := migrateUp(cassandraSession, config.Keyspace, logger); err != nil {
 			return nil, err
 		}
 	}
+	// Refuse to use a keyspace which has not been migrated to the schema we require
+	if err := checkSchemaVersion(cassandraSession, config.Keyspace, logger); err != nil {
+		cassandraSession.Close()
+		return nil, err
+	}
 
 	dbConfig := Database{
 		CassandraSession: cassandraSession,
@@ -93,6 +98,7 @@ func New(config DatabaseConfig, serviceName string, logger *zap.Logger,
 		User:         NewUserStore(&dbConfig),
 		Role:         NewRoleStore(&dbConfig),
 		Audit:        NewAuditStore(&dbConfig),
+		ChangeLog:    NewChangeLogStore(&dbConfig),
 	}
 	return &database, nil
 }
@@ -0,0 +1,306 @@
+package cassandra
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gocql/gocql"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/pkg/shared"
+)
+
+// migration is a numbered set of CQL statements which brings the keyspace
+// schema from the previous version to this version.
+type migration struct {
+	version     int
+	description string
+	statements  []string
+}
+
+// migrations holds all schema migrations, ordered by version.
+//
+// A released migration must never be changed, schema changes such as adding a column
+// require a new migration with the next version number. As Cassandra does not support
+// transactions all statements of a migration should be safe to execute more than once:
+// use IF NOT EXISTS where possible, adding an existing column is ignored by migrateUp.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "initial schema",
+		statements:  createTablesCQL[:],
+	},
+	{
+		version:     2,
+		description: "add changelog table",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS changelog (
+        change_id text,
+        timestamp bigint,
+        operation text,
+        entity_type text,
+        organization text,
+        entity_id text,
+        PRIMARY KEY (change_id)
+        )`,
+		},
+	},
+	{
+		version:     3,
+		description: "allow PATCH in default admin role",
+		statements: []string{
+			`UPDATE roles SET permissions = '[{"methods":["GET","POST","DELETE","PUT","PATCH"],"paths":["/v1/**"]}]' WHERE name = 'admin' IF permissions = '[{"methods":["GET","POST","DELETE", "PUT"],"paths":["/v1/**"]}]'`,
+		},
+	},
+	{
+		version:     4,
+		description: "add pkce code challenge to oauth_access_token",
+		statements: []string{
+			`ALTER TABLE oauth_access_token ADD code_challenge text`,
+			`ALTER TABLE oauth_access_token ADD code_challenge_method text`,
+		},
+	},
+	{
+		version:     5,
+		description: "add client_id index to oauth_access_token",
+		statements: []string{
+			`CREATE INDEX IF NOT EXISTS ON oauth_access_token (client_id)`,
+		},
+	},
+}
+
+// MigrationStatus holds the state of a schema migration in a keyspace
+type MigrationStatus struct {
+	// Version of migration
+	Version int
+	// Description of migration
+	Description string
+	// Indicates whether the migration has been applied to the keyspace
+	Applied bool
+	// Timestamp of when migration was applied in milliseconds since epoch
+	AppliedAt int64
+}
+
+const (
+	// Table to keep track of migrations applied to keyspace
+	schemaVersionTable     = "schema_version"
+	createSchemaVersionCQL = `CREATE TABLE IF NOT EXISTS ` + schemaVersionTable + ` (
+        version int,
+        description text,
+        applied_at bigint,
+        PRIMARY KEY (version)
+        )`
+)
+
+// ExpectedSchemaVersion returns the schema version this release requires
+func ExpectedSchemaVersion() int {
+
+	return migrations[len(migrations)-1].version
+}
+
+// MigrateUp applies all pending schema migrations to keyspace,
+// the keyspace is created in case it does not exist yet
+func MigrateUp(config DatabaseConfig, replicationCount int, logger *zap.Logger) error {
+
+	logger = logger.With(zap.String("system", "db"))
+
+	cassandraClusterConfig := buildClusterConfig(config)
+
+	// Connect to system keyspace first as our keyspace might not exist yet
+	cassandraClusterConfig.Keyspace = "system"
+	systemSession, err := connect(cassandraClusterConfig, config, logger)
+	if err != nil {
+		return err
+	}
+	err = createKeyspace(systemSession, config.Keyspace, replicationCount, logger)
+	systemSession.Close()
+	if err != nil {
+		return err
+	}
+
+	cassandraClusterConfig.Keyspace = config.Keyspace
+	cassandraSession, err := connect(cassandraClusterConfig, config, logger)
+	if err != nil {
+		return err
+	}
+	defer cassandraSession.Close()
+
+	return migrateUp(cassandraSession, config.Keyspace, logger)
+}
+
+// ShowMigrationStatus prints status of all schema migrations of keyspace
+func ShowMigrationStatus(config DatabaseConfig, logger *zap.Logger) error {
+
+	logger = logger.With(zap.String("system", "db"))
+
+	cassandraClusterConfig := buildClusterConfig(config)
+	cassandraClusterConfig.Keyspace = config.Keyspace
+	cassandraSession, err := connect(cassandraClusterConfig, config, logger)
+	if err != nil {
+		return err
+	}
+	defer cassandraSession.Close()
+
+	status, err := migrationStatus(cassandraSession, config.Keyspace)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "KEYSPACE\tVERSION\tDESCRIPTION\tAPPLIED AT\n")
+	for _, m := range status {
+		appliedAt := "pending"
+		if m.Applied {
+			appliedAt = time.UnixMilli(m.AppliedAt).UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", config.Keyspace, m.Version, m.Description, appliedAt)
+	}
+	return w.Flush()
+}
+
+// migrateUp applies all migrations with a version higher than the current schema version
+func migrateUp(s *gocql.Session, keyspace string, logger *zap.Logger) error {
+
+	if err := s.Query(createSchemaVersionCQL).Exec(); err != nil {
+		return err
+	}
+
+	currentVersion, err := currentSchemaVersion(s, keyspace)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= currentVersion {
+			continue
+		}
+		logger.Info("Applying schema migration",
+			zap.String("keyspace", keyspace),
+			zap.Int("version", m.version),
+			zap.String("description", m.description))
+
+		for _, query := range m.statements {
+			logger.Debug("migrate database", zap.String("cql", query))
+			if err := s.Query(query).Exec(); err != nil {
+				// Column might have been added by a previous, partially failed, run of this migration
+				if isColumnAlreadyAdded(query, err) {
+					logger.Info("schema migration column already exists",
+						zap.Int("version", m.version), zap.String("cql", query))
+					continue
+				}
+				logger.Warn("schema migration statement failed",
+					zap.Int("version", m.version), zap.Error(err))
+				return fmt.Errorf("schema migration %d failed (%s)", m.version, err)
+			}
+		}
+		query := "INSERT INTO " + schemaVersionTable + " (version, description, applied_at) VALUES(?,?,?)"
+		if err := s.Query(query, m.version, m.description,
+			shared.GetCurrentTimeMilliseconds()).Exec(); err != nil {
+			return fmt.Errorf("cannot store schema version %d (%s)", m.version, err)
+		}
+	}
+
+	logger.Info("Schema is up to date",
+		zap.String("keyspace", keyspace),
+		zap.Int("version", ExpectedSchemaVersion()))
+	return nil
+}
+
+// isColumnAlreadyAdded returns true in case an ALTER TABLE ADD statement failed
+// because the column already exists
+func isColumnAlreadyAdded(query string, err error) bool {
+
+	statement := strings.ToUpper(strings.Join(strings.Fields(query), " "))
+	if !strings.HasPrefix(statement, "ALTER TABLE ") || !strings.Contains(statement, " ADD ") {
+		return false
+	}
+	return strings.Contains(err.Error(), "conflicts with an existing column")
+}
+
+// checkSchemaVersion returns error in case keyspace schema version is behind
+// the version this release requires
+func checkSchemaVersion(s *gocql.Session, keyspace string, logger *zap.Logger) error {
+
+	currentVersion, err := currentSchemaVersion(s, keyspace)
+	if err != nil {
+		return err
+	}
+	if currentVersion < ExpectedSchemaVersion() {
+		return fmt.Errorf("schema version %d of keyspace '%s' is behind required version %d, "+
+			"run migrate up first", currentVersion, keyspace, ExpectedSchemaVersion())
+	}
+	if currentVersion > ExpectedSchemaVersion() {
+		logger.Warn("Schema version is newer than version of this release",
+			zap.String("keyspace", keyspace),
+			zap.Int("version", currentVersion),
+			zap.Int("expected", ExpectedSchemaVersion()))
+	}
+	return nil
+}
+
+// migrationStatus returns state of all known migrations in keyspace
+func migrationStatus(s *gocql.Session, keyspace string) ([]MigrationStatus, error) {
+
+	applied, err := appliedMigrations(s, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		appliedAt, ok := applied[m.version]
+		status = append(status, MigrationStatus{
+			Version:     m.version,
+			Description: m.description,
+			Applied:     ok,
+			AppliedAt:   appliedAt,
+		})
+	}
+	return status, nil
+}
+
+// currentSchemaVersion returns highest migration version applied to keyspace,
+// 0 indicates no migration has been applied
+func currentSchemaVersion(s *gocql.Session, keyspace string) (int, error) {
+
+	applied, err := appliedMigrations(s, keyspace)
+	if err != nil {
+		return 0, err
+	}
+	currentVersion := 0
+	for version := range applied {
+		if version > currentVersion {
+			currentVersion = version
+		}
+	}
+	return currentVersion, nil
+}
+
+// appliedMigrations returns version and timestamp of all migrations applied to keyspace
+func appliedMigrations(s *gocql.Session, keyspace string) (map[int]int64, error) {
+
+	applied := make(map[int]int64)
+
+	// A keyspace without schema version table has no migrations applied
+	var tableName string
+	query := "SELECT table_name FROM system_schema.tables WHERE keyspace_name = ? AND table_name = ?"
+	if err := s.Query(query, keyspace, schemaVersionTable).Scan(&tableName); err != nil {
+		if err == gocql.ErrNotFound {
+			return applied, nil
+		}
+		return nil, err
+	}
+
+	var version int
+	var appliedAt int64
+	iter := s.Query("SELECT version, applied_at FROM " + schemaVersionTable).Iter()
+	for iter.Scan(&version, &appliedAt) {
+		applied[version] = appliedAt
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
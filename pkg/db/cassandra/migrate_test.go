@@ -0,0 +1,31 @@
+package cassandra
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MigrationsNumbering(t *testing.T) {
+
+	// Migrations must be numbered consecutively starting at 1
+	for i, m := range migrations {
+		require.Equal(t, i+1, m.version, "migration '%s'", m.description)
+		require.NotEmpty(t, m.description)
+		require.NotEmpty(t, m.statements, "migration %d", m.version)
+	}
+	require.Equal(t, len(migrations), ExpectedSchemaVersion())
+}
+
+func Test_isColumnAlreadyAdded(t *testing.T) {
+
+	conflict := errors.New("Invalid column name code_challenge because it conflicts with an existing column")
+
+	require.True(t, isColumnAlreadyAdded(`ALTER TABLE oauth_access_token ADD code_challenge text`, conflict))
+	require.True(t, isColumnAlreadyAdded("alter table oauth_access_token\n  add code_challenge text", conflict))
+	require.False(t, isColumnAlreadyAdded(`ALTER TABLE oauth_access_token ADD code_challenge text`,
+		errors.New("unconfigured table oauth_access_token")))
+	require.False(t, isColumnAlreadyAdded(`ALTER TABLE oauth_access_token DROP code_challenge`, conflict))
+	require.False(t, isColumnAlreadyAdded(`CREATE INDEX IF NOT EXISTS ON oauth_access_token (client_id)`, conflict))
+}
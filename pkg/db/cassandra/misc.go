@@ -128,6 +128,25 @@ func attributesToColumn(attributes types.Attributes) map[string]string {
 	return a
 }
 
+// columnToPolicies converts a JSON-encoded column value into policies
+func columnToPolicies(m map[string]interface{}, columnName string) types.Policies {
+
+	policies, err := types.ParsePolicies(columnToString(m, columnName))
+	if err != nil {
+		return types.NullPolicies
+	}
+	return policies
+}
+
+// policiesToColumn converts policies into a JSON-encoded column value
+func policiesToColumn(policies types.Policies) string {
+
+	if len(policies) == 0 {
+		return ""
+	}
+	return valueToJSON(policies)
+}
+
 func valueToJSON(s interface{}) string {
 
 	jsonEncoded, err := json.Marshal(s)
@@ -1,6 +1,7 @@
 package cassandra
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -27,9 +28,14 @@ access_created_at,
 access_expires_in,
 refresh,
 refresh_created_at,
-refresh_expires_in`
+refresh_expires_in,
+code_challenge,
+code_challenge_method`
 )
 
+// errOAuthTokenNotFound indicates no token row matches
+var errOAuthTokenNotFound = errors.New("cannot find token")
+
 // OAuthStore holds our database config
 type OAuthStore struct {
 	db *Database
@@ -63,10 +69,30 @@ func (s *OAuthStore) OAuthAccessTokenGetByRefresh(refresh string) (*types.OAuthA
 	return s.runGetOAuthAccessTokenQuery(query, refresh)
 }
 
-// runGetOAuthAccessTokenQuery executes CQL query and returns resultset
+// OAuthAccessTokenGetByClientID retrieves all tokens issued to a client
+func (s *OAuthStore) OAuthAccessTokenGetByClientID(clientID string) (types.OAuthAccessTokens, error) {
+
+	query := "SELECT " + oauthColumns + " FROM oauth_access_token WHERE client_id = ?"
+	return s.runGetOAuthAccessTokensQuery(query, clientID)
+}
+
+// runGetOAuthAccessTokenQuery executes CQL query and returns first token of resultset
 func (s *OAuthStore) runGetOAuthAccessTokenQuery(query, queryParameter string) (*types.OAuthAccessToken, error) {
 
-	var accessToken types.OAuthAccessToken
+	tokens, err := s.runGetOAuthAccessTokensQuery(query, queryParameter)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errOAuthTokenNotFound
+	}
+	return &tokens[0], nil
+}
+
+// runGetOAuthAccessTokensQuery executes CQL query and returns resultset
+func (s *OAuthStore) runGetOAuthAccessTokensQuery(query, queryParameter string) (types.OAuthAccessTokens, error) {
+
+	var tokens types.OAuthAccessTokens
 
 	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
 	defer timer.ObserveDuration()
@@ -74,29 +100,36 @@ func (s *OAuthStore) runGetOAuthAccessTokenQuery(query, queryParameter string) (
 	iterable := s.db.CassandraSession.Query(query, queryParameter).Iter()
 	m := make(map[string]interface{})
 	for iterable.MapScan(m) {
-		accessToken = types.OAuthAccessToken{
-			ClientID:         columnToString(m, "client_id"),
-			UserID:           columnToString(m, "user_id"),
-			RedirectURI:      columnToString(m, "redirect_uri"),
-			Scope:            columnToString(m, "scope"),
-			Code:             columnToString(m, "code"),
-			CodeCreatedAt:    columnToInt64(m, "code_created_at"),
-			CodeExpiresIn:    columnToInt64(m, "code_expires_in"),
-			Access:           columnToString(m, "access"),
-			AccessCreatedAt:  columnToInt64(m, "access_created_at"),
-			AccessExpiresIn:  columnToInt64(m, "access_expires_in"),
-			Refresh:          columnToString(m, "refresh"),
-			RefreshCreatedAt: columnToInt64(m, "refresh_created_at"),
-			RefreshExpiresIn: columnToInt64(m, "refresh_expires_in"),
-		}
+		tokens = append(tokens, types.OAuthAccessToken{
+			ClientID:            columnToString(m, "client_id"),
+			UserID:              columnToString(m, "user_id"),
+			RedirectURI:         columnToString(m, "redirect_uri"),
+			Scope:               columnToString(m, "scope"),
+			Code:                columnToString(m, "code"),
+			CodeCreatedAt:       columnToInt64(m, "code_created_at"),
+			CodeExpiresIn:       columnToInt64(m, "code_expires_in"),
+			Access:              columnToString(m, "access"),
+			AccessCreatedAt:     columnToInt64(m, "access_created_at"),
+			AccessExpiresIn:     columnToInt64(m, "access_expires_in"),
+			Refresh:             columnToString(m, "refresh"),
+			RefreshCreatedAt:    columnToInt64(m, "refresh_created_at"),
+			RefreshExpiresIn:    columnToInt64(m, "refresh_expires_in"),
+			CodeChallenge:       columnToString(m, "code_challenge"),
+			CodeChallengeMethod: columnToString(m, "code_challenge_method"),
+		})
+		m = map[string]interface{}{}
 	}
 	if err := iterable.Close(); err != nil {
-		s.db.metrics.QueryNotFound(oauthMetricLabel)
+		s.db.metrics.QueryFailed(oauthMetricLabel)
 		return nil, err
 	}
+	if len(tokens) == 0 {
+		s.db.metrics.QueryNotFound(oauthMetricLabel)
+		return types.OAuthAccessTokens{}, nil
+	}
 	s.db.metrics.QuerySuccessful(oauthMetricLabel)
 
-	return &accessToken, nil
+	return tokens, nil
 }
 
 // OAuthAccessTokenCreate UPSERTs a token in database
@@ -108,7 +141,7 @@ func (s *OAuthStore) OAuthAccessTokenCreate(t *types.OAuthAccessToken) error {
 	// OAuth packages will check CreatedAt + ExpiresIn to check validity of a retrieved token,
 	/// but does not actively delete from database.
 	query := fmt.Sprintf("INSERT INTO oauth_access_token ("+oauthColumns+
-		") VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?) USING TTL %d", defaultOAuthtokenTTL)
+		") VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?) USING TTL %d", defaultOAuthtokenTTL)
 	if err := s.db.CassandraSession.Query(query,
 		t.ClientID,
 		t.UserID,
@@ -122,7 +155,9 @@ func (s *OAuthStore) OAuthAccessTokenCreate(t *types.OAuthAccessToken) error {
 		t.AccessExpiresIn,
 		t.Refresh,
 		t.RefreshCreatedAt,
-		t.RefreshExpiresIn).Exec(); err != nil {
+		t.RefreshExpiresIn,
+		t.CodeChallenge,
+		t.CodeChallengeMethod).Exec(); err != nil {
 
 		return fmt.Errorf("cannot update access token '%s' (%s)", t.Access, err)
 	}
@@ -140,15 +175,27 @@ func (s *OAuthStore) OAuthAccessTokenRemoveByAccess(accessTokenToDelete string)
 // OAuthAccessTokenRemoveByCode deletes an access token
 func (s *OAuthStore) OAuthAccessTokenRemoveByCode(codeToDelete string) error {
 
-	query := "DELETE FROM oauth_access_token WHERE code = ?"
-
-	return s.db.CassandraSession.Query(query, codeToDelete).Exec()
+	token, err := s.OAuthAccessTokenGetByCode(codeToDelete)
+	if err == errOAuthTokenNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// Cassandra can only delete by primary key
+	return s.OAuthAccessTokenRemoveByAccess(token.Access)
 }
 
 // OAuthAccessTokenRemoveByRefresh deletes an access token
 func (s *OAuthStore) OAuthAccessTokenRemoveByRefresh(refreshToDelete string) error {
 
-	query := "DELETE FROM oauth_access_token WHERE refresh = ?"
-
-	return s.db.CassandraSession.Query(query, refreshToDelete).Exec()
+	token, err := s.OAuthAccessTokenGetByRefresh(refreshToDelete)
+	if err == errOAuthTokenNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// Cassandra can only delete by primary key
+	return s.OAuthAccessTokenRemoveByAccess(token.Access)
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
@@ -96,18 +97,20 @@ func (s *OrganizationStore) runGetOrganizationQuery(query string, queryParameter
 }
 
 // Update UPSERTs an organization in database
-func (s *OrganizationStore) Update(o *types.Organization) types.Error {
+func (s *OrganizationStore) Update(o *types.Organization, precondition db.Precondition) types.Error {
 
-	query := "INSERT INTO organizations (" + organizationColumns + ") VALUES(?,?,?,?,?,?,?)"
-	if err := s.db.CassandraSession.Query(query,
+	if err := s.db.upsert("organizations", organizationColumns, precondition,
 		o.Name,
 		o.DisplayName,
 		attributesToColumn(o.Attributes),
 		o.CreatedAt,
 		o.CreatedBy,
 		o.LastModifiedAt,
-		o.LastModifiedBy).Exec(); err != nil {
+		o.LastModifiedBy); err != nil {
 
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeOrganizationName, o.Name)
+		}
 		s.db.metrics.QueryFailed(organizationMetricLabel)
 		return types.NewDatabaseError(
 			fmt.Errorf("cannot update organization '%s' (%s)", o.Name, err))
@@ -116,10 +119,13 @@ func (s *OrganizationStore) Update(o *types.Organization) types.Error {
 }
 
 // Delete deletes a organization
-func (s *OrganizationStore) Delete(organizationToDelete string) types.Error {
+func (s *OrganizationStore) Delete(organizationToDelete string, precondition db.Precondition) types.Error {
 
 	query := "DELETE FROM organizations WHERE name = ?"
-	if err := s.db.CassandraSession.Query(query, organizationToDelete).Exec(); err != nil {
+	if err := s.db.delete(query, precondition, organizationToDelete); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeOrganizationName, organizationToDelete)
+		}
 		s.db.metrics.QueryFailed(organizationMetricLabel)
 		return types.NewDatabaseError(err)
 	}
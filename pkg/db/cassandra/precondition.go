@@ -0,0 +1,69 @@
+package cassandra
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+)
+
+// errPreconditionFailed indicates the stored entity does not meet the precondition of a write
+var errPreconditionFailed = errors.New("precondition failed")
+
+// upsert inserts or updates an entity, the first column must be the primary key of table.
+//
+// In case precondition is set the entity is updated using a lightweight transaction,
+// which only succeeds if the entity exists and has the expected last modified timestamp.
+func (d *Database) upsert(table, columns string, precondition db.Precondition, values ...interface{}) error {
+
+	columnNames := strings.Split(columns, ",")
+	for i := range columnNames {
+		columnNames[i] = strings.TrimSpace(columnNames[i])
+	}
+
+	if !precondition.IsSet() {
+		query := "INSERT INTO " + table + " (" + strings.Join(columnNames, ",") + ") VALUES(" +
+			strings.TrimSuffix(strings.Repeat("?,", len(columnNames)), ",") + ")"
+		return d.CassandraSession.Query(query, values...).Exec()
+	}
+
+	updates := make([]string, 0, len(columnNames)-1)
+	for _, column := range columnNames[1:] {
+		updates = append(updates, column+" = ?")
+	}
+	query := "UPDATE " + table + " SET " + strings.Join(updates, ", ") +
+		" WHERE " + columnNames[0] + " = ? IF lastmodified_at = ?"
+
+	queryParameters := make([]interface{}, 0, len(values)+1)
+	queryParameters = append(queryParameters, values[1:]...)
+	queryParameters = append(queryParameters, values[0], precondition.LastModifiedAt)
+	return d.execConditional(query, queryParameters...)
+}
+
+// delete executes a delete statement, in case precondition is set as lightweight
+// transaction which only succeeds if the entity has the expected last modified timestamp
+func (d *Database) delete(query string, precondition db.Precondition, queryParameters ...interface{}) error {
+
+	if !precondition.IsSet() {
+		return d.CassandraSession.Query(query, queryParameters...).Exec()
+	}
+	query += " IF lastmodified_at = ?"
+	queryParameters = append(queryParameters, precondition.LastModifiedAt)
+
+	return d.execConditional(query, queryParameters...)
+}
+
+// execConditional executes a lightweight transaction,
+// returns errPreconditionFailed in case the transaction was not applied
+func (d *Database) execConditional(query string, queryParameters ...interface{}) error {
+
+	applied, err := d.CassandraSession.Query(query, queryParameters...).
+		MapScanCAS(make(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return errPreconditionFailed
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
@@ -98,18 +99,20 @@ func (s *RoleStore) runGetRoleQuery(query string, queryParameters ...interface{}
 }
 
 // Update UPSERTs an role in database
-func (s *RoleStore) Update(c *types.Role) types.Error {
+func (s *RoleStore) Update(c *types.Role, precondition db.Precondition) types.Error {
 
-	query := "INSERT INTO roles (" + roleColumns + ") VALUES(?,?,?,?,?,?,?)"
-	if err := s.db.CassandraSession.Query(query,
+	if err := s.db.upsert("roles", roleColumns, precondition,
 		c.Name,
 		c.DisplayName,
 		PermissionsMarshal(c.Permissions),
 		c.CreatedAt,
 		c.CreatedBy,
 		c.LastModifiedAt,
-		c.LastModifiedBy).Exec(); err != nil {
+		c.LastModifiedBy); err != nil {
 
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeRoleName, c.Name)
+		}
 		s.db.metrics.QueryFailed(roleMetricLabel)
 		return types.NewDatabaseError(
 			fmt.Errorf("cannot update role '%s' (%s)", c.Name, err))
@@ -118,10 +121,13 @@ func (s *RoleStore) Update(c *types.Role) types.Error {
 }
 
 // Delete deletes a role
-func (s *RoleStore) Delete(roleToDelete string) types.Error {
+func (s *RoleStore) Delete(roleToDelete string, precondition db.Precondition) types.Error {
 
 	query := "DELETE FROM roles WHERE name = ?"
-	if err := s.db.CassandraSession.Query(query, roleToDelete).Exec(); err != nil {
+	if err := s.db.delete(query, precondition, roleToDelete); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeRoleName, roleToDelete)
+		}
 		s.db.metrics.QueryFailed(roleMetricLabel)
 		return types.NewDatabaseError(err)
 	}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
@@ -103,10 +104,9 @@ func (s *RouteStore) runGetRouteQuery(query string, queryParameters ...interface
 }
 
 // Update UPSERTs an route
-func (s *RouteStore) Update(r *types.Route) types.Error {
+func (s *RouteStore) Update(r *types.Route, precondition db.Precondition) types.Error {
 
-	query := "INSERT INTO routes (" + routeColumns + ") VALUES(?,?,?,?,?,?,?,?,?,?)"
-	if err := s.db.CassandraSession.Query(query,
+	if err := s.db.upsert("routes", routeColumns, precondition,
 		r.Name,
 		r.DisplayName,
 		r.RouteGroup,
@@ -116,8 +116,11 @@ func (s *RouteStore) Update(r *types.Route) types.Error {
 		r.CreatedAt,
 		r.CreatedBy,
 		r.LastModifiedAt,
-		r.LastModifiedBy).Exec(); err != nil {
+		r.LastModifiedBy); err != nil {
 
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeRouteName, r.Name)
+		}
 		s.db.metrics.QueryFailed(routeMetricLabel)
 		return types.NewDatabaseError(
 			fmt.Errorf("cannot update route '%s' (%s)", r.Name, err))
@@ -126,10 +129,13 @@ func (s *RouteStore) Update(r *types.Route) types.Error {
 }
 
 // Delete deletes a route
-func (s *RouteStore) Delete(routeToDelete string) types.Error {
+func (s *RouteStore) Delete(routeToDelete string, precondition db.Precondition) types.Error {
 
 	query := "DELETE FROM routes WHERE name = ?"
-	if err := s.db.CassandraSession.Query(query, routeToDelete).Exec(); err != nil {
+	if err := s.db.delete(query, precondition, routeToDelete); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeRouteName, routeToDelete)
+		}
 		s.db.metrics.QueryFailed(routeMetricLabel)
 		return types.NewDatabaseError(err)
 	}
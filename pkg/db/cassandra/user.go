@@ -5,6 +5,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/erikbos/gatekeeper/pkg/db"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
@@ -100,10 +101,9 @@ func (s *UserStore) runGetUserQuery(query string, queryParameters ...interface{}
 }
 
 // Update UPSERTs an user in database
-func (s *UserStore) Update(c *types.User) types.Error {
+func (s *UserStore) Update(c *types.User, precondition db.Precondition) types.Error {
 
-	query := "INSERT INTO users (" + userColumns + ") VALUES(?,?,?,?,?,?,?,?,?)"
-	if err := s.db.CassandraSession.Query(query,
+	if err := s.db.upsert("users", userColumns, precondition,
 		c.Name,
 		c.DisplayName,
 		c.Password,
@@ -112,8 +112,11 @@ func (s *UserStore) Update(c *types.User) types.Error {
 		c.CreatedAt,
 		c.CreatedBy,
 		c.LastModifiedAt,
-		c.LastModifiedBy).Exec(); err != nil {
+		c.LastModifiedBy); err != nil {
 
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeUserName, c.Name)
+		}
 		s.db.metrics.QueryFailed(userMetricLabel)
 		return types.NewDatabaseError(
 			fmt.Errorf("cannot update user '%s' (%s)", c.Name, err))
@@ -122,10 +125,13 @@ func (s *UserStore) Update(c *types.User) types.Error {
 }
 
 // Delete deletes a user
-func (s *UserStore) Delete(userToDelete string) types.Error {
+func (s *UserStore) Delete(userToDelete string, precondition db.Precondition) types.Error {
 
 	query := "DELETE FROM users WHERE name = ?"
-	if err := s.db.CassandraSession.Query(query, userToDelete).Exec(); err != nil {
+	if err := s.db.delete(query, precondition, userToDelete); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeUserName, userToDelete)
+		}
 		s.db.metrics.QueryFailed(userMetricLabel)
 		return types.NewDatabaseError(err)
 	}
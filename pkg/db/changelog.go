@@ -0,0 +1,55 @@
+package db
+
+import (
+	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// Changes are written with the clock of the instance making the change, to not miss
+// changes due to clock skew we always re-read changes of the last few seconds.
+const changeLogOverlap = 5 * 1000
+
+// ChangeLogReader returns changes from changelog, each change is returned only once
+type ChangeLogReader struct {
+	changeLog     ChangeLog        // Changelog to read from
+	lastTimestamp int64            // Timestamp of most recent change returned
+	seen          map[string]int64 // Changes already returned, and their timestamp
+}
+
+// NewChangeLogReader returns a reader which returns changes made from now on
+func NewChangeLogReader(changeLog ChangeLog) *ChangeLogReader {
+
+	return &ChangeLogReader{
+		changeLog:     changeLog,
+		lastTimestamp: shared.GetCurrentTimeMilliseconds(),
+		seen:          make(map[string]int64),
+	}
+}
+
+// Next returns all changes which have not been returned before, ordered by timestamp
+func (r *ChangeLogReader) Next() (types.Changes, types.Error) {
+
+	changes, err := r.changeLog.GetSince(r.lastTimestamp - changeLogOverlap)
+	if err != nil {
+		return types.NullChanges, err
+	}
+	newChanges := types.Changes{}
+	for _, change := range changes {
+		if _, ok := r.seen[change.ID]; ok {
+			continue
+		}
+		r.seen[change.ID] = change.Timestamp
+		newChanges = append(newChanges, change)
+
+		if change.Timestamp > r.lastTimestamp {
+			r.lastTimestamp = change.Timestamp
+		}
+	}
+	// Forget about changes which are outside of the window we retrieve
+	for id, timestamp := range r.seen {
+		if timestamp < r.lastTimestamp-changeLogOverlap {
+			delete(r.seen, id)
+		}
+	}
+	return newChanges, nil
+}
@@ -21,6 +21,7 @@ type (
 		User
 		Role
 		Audit
+		ChangeLog
 	}
 
 	// Listener is the listener information storage interface
@@ -32,10 +33,10 @@ type (
 		Get(listener string) (*types.Listener, types.Error)
 
 		// Update updates a listener
-		Update(listener *types.Listener) types.Error
+		Update(listener *types.Listener, precondition Precondition) types.Error
 
 		// Delete deletes a listener
-		Delete(listenerToDelete string) types.Error
+		Delete(listenerToDelete string, precondition Precondition) types.Error
 	}
 
 	// Route the route information storage interface
@@ -47,10 +48,10 @@ type (
 		Get(routeName string) (*types.Route, types.Error)
 
 		// Update UPSERTs an route
-		Update(route *types.Route) types.Error
+		Update(route *types.Route, precondition Precondition) types.Error
 
 		// Delete deletes a route
-		Delete(routeToDelete string) types.Error
+		Delete(routeToDelete string, precondition Precondition) types.Error
 	}
 
 	// Cluster the cluster information storage interface
@@ -62,10 +63,10 @@ type (
 		Get(clusterName string) (*types.Cluster, types.Error)
 
 		// Update UPSERTs an cluster in database
-		Update(c *types.Cluster) types.Error
+		Update(c *types.Cluster, precondition Precondition) types.Error
 
 		// Update UPSERTs an cluster in database
-		Delete(clusterToDelete string) types.Error
+		Delete(clusterToDelete string, precondition Precondition) types.Error
 	}
 
 	Organization interface {
@@ -76,16 +77,19 @@ type (
 		Get(userName string) (*types.Organization, types.Error)
 
 		// Update UPSERTs an user in database
-		Update(c *types.Organization) types.Error
+		Update(c *types.Organization, precondition Precondition) types.Error
 
 		// Update UPSERTs an user in database
-		Delete(organizationToDelete string) types.Error
+		Delete(organizationToDelete string, precondition Precondition) types.Error
 	}
 	// Developer the developer information storage interface
 	Developer interface {
 		// GetAll retrieves all developer
 		GetAll(organizationName string) (types.Developers, types.Error)
 
+		// List retrieves one page of developers matching filter, and token of next page
+		List(organizationName string, params ListParams) (types.Developers, string, types.Error)
+
 		// GetByEmail retrieves a developer
 		GetByEmail(organizationName, developerEmail string) (*types.Developer, types.Error)
 
@@ -93,10 +97,10 @@ type (
 		GetByID(organizationName, developerID string) (*types.Developer, types.Error)
 
 		// Update UPSERTs a developer
-		Update(organizationName string, dev *types.Developer) types.Error
+		Update(organizationName string, dev *types.Developer, precondition Precondition) types.Error
 
 		// DeleteByID deletes a developer
-		DeleteByID(organizationName, developerID string) types.Error
+		DeleteByID(organizationName, developerID string, precondition Precondition) types.Error
 	}
 
 	// DeveloperApp the developer app information storage interface
@@ -104,6 +108,9 @@ type (
 		// GetAll retrieves all developer apps
 		GetAll(organizationName string) (types.DeveloperApps, types.Error)
 
+		// List retrieves one page of developer apps matching filter, and token of next page
+		List(organizationName string, params ListParams) (types.DeveloperApps, string, types.Error)
+
 		// GetAllByDeveloperID retrieves all developer apps from one developer
 		GetAllByDeveloperID(organizationName, developerID string) (types.DeveloperApps, types.Error)
 
@@ -117,10 +124,10 @@ type (
 		GetCountByDeveloperID(organizationName, developerID string) (int, types.Error)
 
 		// UpdateByName UPSERTs a developer app
-		Update(organizationName string, app *types.DeveloperApp) types.Error
+		Update(organizationName string, app *types.DeveloperApp, precondition Precondition) types.Error
 
 		// DeleteByID deletes a developer app
-		DeleteByID(organizationName, developerAppID string) types.Error
+		DeleteByID(organizationName, developerAppID string, precondition Precondition) types.Error
 	}
 
 	// Key the key information storage interface
@@ -145,11 +152,14 @@ type (
 	Company interface {
 		GetAll(organizationName string) (types.Companies, types.Error)
 
+		// List retrieves one page of companies matching filter, and token of next page
+		List(organizationName string, params ListParams) (types.Companies, string, types.Error)
+
 		Get(organizationName, companyName string) (*types.Company, types.Error)
 
-		Update(organizationName string, c *types.Company) types.Error
+		Update(organizationName string, c *types.Company, precondition Precondition) types.Error
 
-		Delete(organizationName, company string) types.Error
+		Delete(organizationName, company string, precondition Precondition) types.Error
 	}
 
 	// APIProduct the apiproduct information storage interface
@@ -157,14 +167,17 @@ type (
 		// GetAll retrieves all api products
 		GetAll(organizationName string) (types.APIProducts, types.Error)
 
+		// List retrieves one page of api products matching filter, and token of next page
+		List(organizationName string, params ListParams) (types.APIProducts, string, types.Error)
+
 		// Get returns an apiproduct
 		Get(organizationName, apiproductName string) (*types.APIProduct, types.Error)
 
 		// Update UPSERTs an apiproduct in database
-		Update(organizationName string, p *types.APIProduct) types.Error
+		Update(organizationName string, p *types.APIProduct, precondition Precondition) types.Error
 
 		// Delete deletes an apiproduct
-		Delete(organizationName, apiProduct string) types.Error
+		Delete(organizationName, apiProduct string, precondition Precondition) types.Error
 	}
 
 	// OAuth the oauth information storage interface
@@ -178,6 +191,9 @@ type (
 		// OAuthAccessTokenGetByRefresh retrieves token by refreshcode
 		OAuthAccessTokenGetByRefresh(refresh string) (*types.OAuthAccessToken, error)
 
+		// OAuthAccessTokenGetByClientID retrieves all tokens issued to a client
+		OAuthAccessTokenGetByClientID(clientID string) (types.OAuthAccessTokens, error)
+
 		// OAuthAccessTokenCreate creates an access token
 		OAuthAccessTokenCreate(t *types.OAuthAccessToken) error
 
@@ -200,10 +216,10 @@ type (
 		Get(userName string) (*types.User, types.Error)
 
 		// Update UPSERTs an user in database
-		Update(c *types.User) types.Error
+		Update(c *types.User, precondition Precondition) types.Error
 
 		// Update UPSERTs an user in database
-		Delete(userToDelete string) types.Error
+		Delete(userToDelete string, precondition Precondition) types.Error
 	}
 
 	// Role the role information storage interface
@@ -215,10 +231,10 @@ type (
 		Get(roleName string) (*types.Role, types.Error)
 
 		// Update UPSERTs a role
-		Update(c *types.Role) types.Error
+		Update(c *types.Role, precondition Precondition) types.Error
 
 		// Delete removes a role
-		Delete(roleToDelete string) types.Error
+		Delete(roleToDelete string, precondition Precondition) types.Error
 	}
 
 	Audit interface {
@@ -241,6 +257,16 @@ type (
 		Write(l *types.Audit) types.Error
 	}
 
+	// ChangeLog keeps track of changes made to entities,
+	// so other instances can learn about changes
+	ChangeLog interface {
+		// GetSince retrieves changes made after timestamp, ordered by timestamp
+		GetSince(timestamp int64) (types.Changes, types.Error)
+
+		// Write inserts a change record
+		Write(c *types.Change) types.Error
+	}
+
 	AuditFilterParams struct {
 		// Start timestamp in epoch milliseconds
 		StartTime int64
@@ -249,4 +275,26 @@ type (
 		// Maximum number of entities to return
 		Count int64
 	}
+
+	// ListParams holds pagination and filter parameters to list entities
+	ListParams struct {
+		// Maximum number of entities to return, 0 returns all entities
+		PageSize int
+		// Token of page to return as returned by previous list call, empty for first page
+		PageToken string
+		// Only return entities with this status
+		Status string
+		// Only return entities with email address starting with this prefix
+		EmailPrefix string
+		// Only return entities last modified at or after this timestamp in epoch milliseconds
+		LastModifiedFrom int64
+		// Only return entities last modified at or before this timestamp in epoch milliseconds, 0 means no limit
+		LastModifiedTo int64
+	}
+
+	// Precondition holds the state a stored entity must have to be updated or deleted
+	Precondition struct {
+		// Last modified timestamp the stored entity must have, 0 means no precondition
+		LastModifiedAt int64
+	}
 )
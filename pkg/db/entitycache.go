@@ -1,43 +1,49 @@
 package db
 
 import (
+	"reflect"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
-	"github.com/erikbos/gatekeeper/pkg/shared"
 	"github.com/erikbos/gatekeeper/pkg/types"
 )
 
 // EntityCache contains up to date entities like listeners, routes, clusters, users and roles
 type EntityCache struct {
-	db                  *Database         // Database handle
-	config              EntityCacheConfig // Loader configuration
-	listeners           types.Listeners   // All listeners loaded from database
-	routes              types.Routes      // All routes loaded from database
-	clusters            types.Clusters    // All clusters loaded from database
-	listenersLastUpdate int64             // Timestamp of most recent load of listeners
-	routesLastUpdate    int64             // Timestamp of most recent load of routes
-	clustersLastUpdate  int64             // Timestamp of most recent load of clusters
-	mutex               sync.Mutex        // Mutex to use when updating
-	logger              *zap.Logger       // Logger
+	db        *Database         // Database handle
+	config    EntityCacheConfig // Loader configuration
+	listeners types.Listeners   // All listeners loaded from database
+	routes    types.Routes      // All routes loaded from database
+	clusters  types.Clusters    // All clusters loaded from database
+	mutex     sync.Mutex        // Mutex to use when updating
+	logger    *zap.Logger       // Logger
 }
 
 // EntityCacheConfig contains configuration on which entities we continously load
 type EntityCacheConfig struct {
-	RefreshInterval time.Duration                 // Interval between entity loads
-	Notify          chan EntityChangeNotification // Notification channel to emit change events
+	RefreshInterval    time.Duration                 // Interval between checks of changelog for changed entities
+	FullReloadInterval time.Duration                 // Interval between loads of all entities, to catch up on missed changes
+	Notify             chan EntityChangeNotification // Notification channel to emit change events
 }
 
 // EntityChangeNotification is the msg send when we noticed a change in an entity
 type EntityChangeNotification struct {
-	Resource string // Name of resource type that has been changed
+	Resource  string // Name of resource type that has been changed
+	Name      string // Name of entity that has been changed, empty in case of full reload
+	Operation string // Operation performed on entity: "create", "update" or "delete", empty in case of full reload
 }
 
+// Default interval between full reloads of all entities
+const defaultFullReloadInterval = 5 * time.Minute
+
 // NewEntityCache returns a new entity loader
 func NewEntityCache(database *Database, config EntityCacheConfig, logger *zap.Logger) *EntityCache {
 
+	if config.FullReloadInterval == 0 {
+		config.FullReloadInterval = defaultFullReloadInterval
+	}
 	return &EntityCache{config: config,
 		db:     database,
 		logger: logger.With(zap.String("system", "entitycache")),
@@ -50,151 +56,170 @@ func (ec *EntityCache) Start() {
 	go ec.loadContinously()
 }
 
-// loadContinously continuously loads listeners, routes and clusters
-// entities from database and updates the in-memory slices.
+// loadContinously loads all listeners, routes and clusters entities from database,
+// and afterwards follows the changelog to reload entity types which have changed.
 //
-// In case a changed entity has been detect a notification will be sent
+// In case a changed entity has been detect a notification will be sent.
+// All entities are reloaded at FullReloadInterval as safety net in case a change
+// was missed, for example when changelog could not be read.
 func (ec *EntityCache) loadContinously() {
 
-	for {
-		ec.checkForChangedListeners()
-		ec.checkForChangedRoutes()
-		ec.checkForChangedClusters()
-		time.Sleep(ec.config.RefreshInterval)
+	var reader *ChangeLogReader
+	if ec.db.ChangeLog != nil {
+		reader = NewChangeLogReader(ec.db.ChangeLog)
+	} else {
+		ec.logger.Warn("Database has no changelog, reloading all entities every refresh interval")
 	}
-}
 
-// checkForChangedListeners if the loaded list of listeners is shorter
-// or one entry has been updated
-func (ec *EntityCache) checkForChangedListeners() {
+	ec.reloadAll()
+	lastFullReload := time.Now()
+	for {
+		time.Sleep(ec.config.RefreshInterval)
 
-	loadedListeners, err := ec.db.Listener.GetAll()
-	if err != nil {
-		ec.logger.Error("Cannot retrieve listeners from database", zap.Error(err))
-		return
-	}
-	// In case we have less routes one or more was deleted
-	if len(loadedListeners) < len(ec.listeners) {
-		ec.updateListeners(loadedListeners)
-	}
-	for _, listener := range loadedListeners {
-		if ec.listenersLastUpdate == 0 || listener.LastModifiedAt > ec.listenersLastUpdate {
-			ec.updateListeners(loadedListeners)
+		if reader == nil || time.Since(lastFullReload) >= ec.config.FullReloadInterval {
+			ec.reloadAll()
+			lastFullReload = time.Now()
+			continue
 		}
+		ec.processChanges(reader)
 	}
 }
 
-func (ec *EntityCache) updateListeners(newListeners types.Listeners) {
+// reloadAll loads all entities and notifies about each entity type which has changed
+func (ec *EntityCache) reloadAll() {
 
-	ec.mutex.Lock()
-	ec.listeners = newListeners
-	ec.mutex.Unlock()
-	ec.listenersLastUpdate = shared.GetCurrentTimeMilliseconds()
-
-	ec.logger.Info("Listener entities reloaded")
-	if ec.config.Notify != nil {
-		ec.config.Notify <- EntityChangeNotification{Resource: types.TypeListenerName}
+	for _, resource := range []string{types.TypeListenerName, types.TypeRouteName, types.TypeClusterName} {
+		if ec.reload(resource) {
+			ec.notify(EntityChangeNotification{Resource: resource})
+		}
 	}
 }
 
-// checkForChangedRoutes if the loaded list of routes is shorter
-// or one entry has been updated
-func (ec *EntityCache) checkForChangedRoutes() {
+// processChanges reloads entity types which have been changed according to changelog,
+// and emits a notification for each changed entity
+func (ec *EntityCache) processChanges(reader *ChangeLogReader) {
 
-	loadedRoutes, err := ec.db.Route.GetAll()
+	changes, err := reader.Next()
 	if err != nil {
-		ec.logger.Error("Cannot retrieve routes from database", zap.Error(err))
+		ec.logger.Error("Cannot retrieve changes from database", zap.Error(err))
 		return
 	}
-	// In case we have less routes one or more was deleted
-	if len(loadedRoutes) < len(ec.routes) {
-		ec.updateRoutes(loadedRoutes)
-	}
-	for _, route := range loadedRoutes {
-		if ec.routesLastUpdate == 0 || route.LastModifiedAt > ec.routesLastUpdate {
-			ec.updateRoutes(loadedRoutes)
+
+	reloaded := make(map[string]bool)
+	for _, change := range changes {
+		switch change.EntityType {
+		case types.TypeListenerName, types.TypeRouteName, types.TypeClusterName:
+		default:
+			continue
+		}
+		// Reload each entity type only once per batch of changes
+		if _, ok := reloaded[change.EntityType]; !ok {
+			reloaded[change.EntityType] = ec.reload(change.EntityType)
 		}
+		ec.notify(EntityChangeNotification{
+			Resource:  change.EntityType,
+			Name:      change.EntityID,
+			Operation: change.Operation,
+		})
 	}
 }
 
-func (ec *EntityCache) updateRoutes(newRoutes types.Routes) {
+// reload loads all entities of a type from database, returns true if they have changed
+func (ec *EntityCache) reload(resource string) bool {
 
-	ec.mutex.Lock()
-	ec.routes = newRoutes
-	ec.mutex.Unlock()
-	ec.routesLastUpdate = shared.GetCurrentTimeMilliseconds()
+	var loaded, current interface{}
+	var err types.Error
 
-	ec.logger.Info("Route entities reloaded")
-	if ec.config.Notify != nil {
-		ec.config.Notify <- EntityChangeNotification{Resource: types.TypeRouteName}
+	switch resource {
+	case types.TypeListenerName:
+		var listeners types.Listeners
+		if listeners, err = ec.db.Listener.GetAll(); err == nil {
+			loaded, current = listeners, ec.GetListeners()
+		}
+	case types.TypeRouteName:
+		var routes types.Routes
+		if routes, err = ec.db.Route.GetAll(); err == nil {
+			loaded, current = routes, ec.GetRoutes()
+		}
+	case types.TypeClusterName:
+		var clusters types.Clusters
+		if clusters, err = ec.db.Cluster.GetAll(); err == nil {
+			loaded, current = clusters, ec.GetClusters()
+		}
 	}
-}
-
-// checkForChangedClusters checks if the loaded list of clusters is shorter
-// or one entry has been updated
-func (ec *EntityCache) checkForChangedClusters() {
-
-	loadedClusters, err := ec.db.Cluster.GetAll()
 	if err != nil {
-		ec.logger.Error("Cannot retrieve clusters from database", zap.Error(err))
-		return
-	}
-	// In case we have less routes one or more was deleted
-	if len(loadedClusters) < len(ec.clusters) {
-		ec.updateClusters(loadedClusters)
+		ec.logger.Error("Cannot retrieve entities from database",
+			zap.String("entity", resource), zap.Error(err))
+		return false
 	}
-	for _, cluster := range loadedClusters {
-		if ec.clustersLastUpdate == 0 || cluster.LastModifiedAt > ec.clustersLastUpdate {
-			ec.updateClusters(loadedClusters)
-		}
+	if reflect.DeepEqual(loaded, current) {
+		return false
 	}
-}
-
-func (ec *EntityCache) updateClusters(newClusters types.Clusters) {
 
 	ec.mutex.Lock()
-	ec.clusters = newClusters
+	switch v := loaded.(type) {
+	case types.Listeners:
+		ec.listeners = v
+	case types.Routes:
+		ec.routes = v
+	case types.Clusters:
+		ec.clusters = v
+	}
 	ec.mutex.Unlock()
-	ec.clustersLastUpdate = shared.GetCurrentTimeMilliseconds()
 
-	ec.logger.Info("Cluster entities reloaded")
+	ec.logger.Info("Entities reloaded", zap.String("entity", resource))
+	return true
+}
+
+// notify emits notification about a changed entity
+func (ec *EntityCache) notify(n EntityChangeNotification) {
+
 	if ec.config.Notify != nil {
-		ec.config.Notify <- EntityChangeNotification{Resource: types.TypeClusterName}
+		ec.config.Notify <- n
 	}
 }
 
 // GetListeners returns all listeners
 func (ec *EntityCache) GetListeners() types.Listeners {
 
+	ec.mutex.Lock()
+	defer ec.mutex.Unlock()
+
 	return ec.listeners
 }
 
 // GetRoutes returns all routes
 func (ec *EntityCache) GetRoutes() types.Routes {
 
+	ec.mutex.Lock()
+	defer ec.mutex.Unlock()
+
 	return ec.routes
 }
 
 // GetClusters returns all clusters
 func (ec *EntityCache) GetClusters() types.Clusters {
 
+	ec.mutex.Lock()
+	defer ec.mutex.Unlock()
+
 	return ec.clusters
 }
 
 // GetListenerCount returns number of listeners
 func (ec *EntityCache) GetListenerCount() int {
 
-	return len(ec.listeners)
+	return len(ec.GetListeners())
 }
 
 // GetRouteCount returns number of routes
 func (ec *EntityCache) GetRouteCount() int {
 
-	return len(ec.routes)
+	return len(ec.GetRoutes())
 }
 
 // GetClusterCount returns number of clusters
 func (ec *EntityCache) GetClusterCount() int {
 
-	return len(ec.clusters)
+	return len(ec.GetClusters())
 }
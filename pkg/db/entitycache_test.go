@@ -0,0 +1,89 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/db/memory"
+	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// waitForNotification returns the next notification, or fails the test after timeout
+func waitForNotification(t *testing.T, notify chan db.EntityChangeNotification) db.EntityChangeNotification {
+
+	select {
+	case n := <-notify:
+		return n
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no entity change notification received")
+	}
+	return db.EntityChangeNotification{}
+}
+
+func Test_EntityCache_ChangeLog(t *testing.T) {
+
+	database, err := memory.New(t.Name(), zap.NewNop())
+	require.NoError(t, err)
+	require.Nil(t, database.Route.Update(&types.Route{Name: "r1", Path: "/old"}, db.Precondition{}))
+
+	config := db.EntityCacheConfig{
+		RefreshInterval:    10 * time.Millisecond,
+		FullReloadInterval: time.Hour,
+		Notify:             make(chan db.EntityChangeNotification),
+	}
+	entities := db.NewEntityCache(database, config, zap.NewNop())
+	entities.Start()
+
+	// Initial load notifies about each entity type
+	for i := 0; i < 3; i++ {
+		n := waitForNotification(t, config.Notify)
+		require.Empty(t, n.Name)
+	}
+	require.Equal(t, 1, entities.GetRouteCount())
+
+	// Delete and create of a route within one refresh interval
+	require.Nil(t, database.Route.Delete("r1", db.Precondition{}))
+	require.Nil(t, database.Route.Update(&types.Route{Name: "r1", Path: "/new"}, db.Precondition{}))
+	now := shared.GetCurrentTimeMilliseconds()
+	require.Nil(t, database.ChangeLog.Write(&types.Change{ID: "c1", Timestamp: now,
+		Operation: types.ChangeOperationDelete, EntityType: types.TypeRouteName, EntityID: "r1"}))
+	require.Nil(t, database.ChangeLog.Write(&types.Change{ID: "c2", Timestamp: now + 1,
+		Operation: types.ChangeOperationCreate, EntityType: types.TypeRouteName, EntityID: "r1"}))
+
+	require.Equal(t, db.EntityChangeNotification{Resource: types.TypeRouteName,
+		Name: "r1", Operation: types.ChangeOperationDelete}, waitForNotification(t, config.Notify))
+	require.Equal(t, db.EntityChangeNotification{Resource: types.TypeRouteName,
+		Name: "r1", Operation: types.ChangeOperationCreate}, waitForNotification(t, config.Notify))
+	require.Equal(t, "/new", entities.GetRoutes()[0].Path)
+}
+
+func Test_ChangeLogReader(t *testing.T) {
+
+	database, err := memory.New(t.Name(), zap.NewNop())
+	require.NoError(t, err)
+
+	reader := db.NewChangeLogReader(database.ChangeLog)
+	now := shared.GetCurrentTimeMilliseconds()
+
+	// Change with a timestamp slightly in the past, e.g. due to clock skew, is still returned
+	require.Nil(t, database.ChangeLog.Write(&types.Change{ID: "c1", Timestamp: now - 1000}))
+	changes, err := reader.Next()
+	require.Nil(t, err)
+	require.Equal(t, 1, len(changes))
+
+	// A change is returned only once
+	require.Nil(t, database.ChangeLog.Write(&types.Change{ID: "c2", Timestamp: now + 1}))
+	changes, err = reader.Next()
+	require.Nil(t, err)
+	require.Equal(t, 1, len(changes))
+	require.Equal(t, "c2", changes[0].ID)
+
+	changes, err = reader.Next()
+	require.Nil(t, err)
+	require.Equal(t, 0, len(changes))
+}
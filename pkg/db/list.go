@@ -0,0 +1,50 @@
+package db
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// EncodePageToken converts backend specific paging state into an opaque page token
+func EncodePageToken(state []byte) string {
+
+	if len(state) == 0 {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(state)
+}
+
+// DecodePageToken converts an opaque page token back into backend specific paging state
+func DecodePageToken(token string) ([]byte, types.Error) {
+
+	if token == "" {
+		return nil, nil
+	}
+	state, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, types.NewBadRequestError(errors.New("invalid page token"))
+	}
+	return state, nil
+}
+
+// Matches returns true if an entity with status, email address and
+// last modified timestamp matches all filter parameters
+func (p ListParams) Matches(status, email string, lastModifiedAt int64) bool {
+
+	if p.Status != "" && p.Status != status {
+		return false
+	}
+	if p.EmailPrefix != "" && !strings.HasPrefix(email, p.EmailPrefix) {
+		return false
+	}
+	if lastModifiedAt < p.LastModifiedFrom {
+		return false
+	}
+	if p.LastModifiedTo != 0 && lastModifiedAt > p.LastModifiedTo {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// APIProductStore holds our database config
+type APIProductStore struct {
+	db *Database
+}
+
+// NewAPIProductStore creates api product instance
+func NewAPIProductStore(database *Database) *APIProductStore {
+	return &APIProductStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all api products
+func (s *APIProductStore) GetAll(organizationName string) (types.APIProducts, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	apiproducts := types.APIProducts{}
+	for key, apiproduct := range s.db.apiproducts {
+		if strings.HasPrefix(key, primaryKey(organizationName, "")) {
+			var apiproductCopy types.APIProduct
+			copyEntity(apiproduct, &apiproductCopy)
+			apiproducts = append(apiproducts, apiproductCopy)
+		}
+	}
+	sort.SliceStable(apiproducts, func(i, j int) bool {
+		return apiproducts[i].Name < apiproducts[j].Name
+	})
+	return apiproducts, nil
+}
+
+// List retrieves one page of api products matching filter, ordered by name
+func (s *APIProductStore) List(organizationName string, params db.ListParams) (types.APIProducts, string, types.Error) {
+
+	all, _ := s.GetAll(organizationName)
+
+	apiproducts := types.APIProducts{}
+	keys := []string{}
+	for _, apiproduct := range all {
+		if params.Matches("", "", apiproduct.LastModifiedAt) {
+			apiproducts = append(apiproducts, apiproduct)
+			keys = append(keys, apiproduct.Name)
+		}
+	}
+	start, end, nextPageToken, err := selectPage(keys, params)
+	if err != nil {
+		return types.NullAPIProducts, "", err
+	}
+	return apiproducts[start:end], nextPageToken, nil
+}
+
+// Get returns an apiproduct
+func (s *APIProductStore) Get(organizationName, apiproductName string) (*types.APIProduct, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	apiproduct, found := s.db.apiproducts[primaryKey(organizationName, apiproductName)]
+	if !found {
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find apiproduct '%s'", apiproductName))
+	}
+	var apiproductCopy types.APIProduct
+	copyEntity(apiproduct, &apiproductCopy)
+	return &apiproductCopy, nil
+}
+
+// Update UPSERTs an apiproduct
+func (s *APIProductStore) Update(organizationName string, p *types.APIProduct, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.apiproducts[primaryKey(organizationName, p.Name)].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeAPIProductName, p.Name)
+	}
+
+	var apiproductCopy types.APIProduct
+	copyEntity(p, &apiproductCopy)
+	s.db.apiproducts[primaryKey(organizationName, p.Name)] = apiproductCopy
+	return nil
+}
+
+// Delete deletes an apiproduct
+func (s *APIProductStore) Delete(organizationName, apiproductToDelete string, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.apiproducts[primaryKey(organizationName, apiproductToDelete)].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeAPIProductName, apiproductToDelete)
+	}
+
+	delete(s.db.apiproducts, primaryKey(organizationName, apiproductToDelete))
+	return nil
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// AuditStore holds our AuditStore config
+type AuditStore struct {
+	db *Database
+}
+
+// NewAuditStore creates audit instance
+func NewAuditStore(database *Database) *AuditStore {
+	return &AuditStore{
+		db: database,
+	}
+}
+
+// GetOrganization retrieves audit records of an organization
+func (s *AuditStore) GetOrganization(organizationName string, params db.AuditFilterParams) (types.Audits, types.Error) {
+
+	return s.selectAudits(params, func(a *types.Audit) bool {
+		return a.Organization == organizationName
+	}), nil
+}
+
+// GetAPIProduct retrieves audit records of an apiproduct
+func (s *AuditStore) GetAPIProduct(organizationName, apiproductName string, params db.AuditFilterParams) (types.Audits, types.Error) {
+
+	return s.selectAudits(params, func(a *types.Audit) bool {
+		return a.Organization == organizationName &&
+			a.EntityType == types.TypeAPIProductName &&
+			a.EntityID == apiproductName
+	}), nil
+}
+
+// GetDeveloper retrieves audit records of a developer
+func (s *AuditStore) GetDeveloper(organizationName, developerID string, params db.AuditFilterParams) (types.Audits, types.Error) {
+
+	return s.selectAudits(params, func(a *types.Audit) bool {
+		return a.Organization == organizationName &&
+			a.DeveloperID == developerID
+	}), nil
+}
+
+// GetApplication retrieves audit records of an application
+func (s *AuditStore) GetApplication(organizationName, developerID, appID string, params db.AuditFilterParams) (types.Audits, types.Error) {
+
+	return s.selectAudits(params, func(a *types.Audit) bool {
+		return a.Organization == organizationName &&
+			a.DeveloperID == developerID &&
+			a.AppID == appID
+	}), nil
+}
+
+// GetUser retrieves audit records of a user
+func (s *AuditStore) GetUser(userName string, params db.AuditFilterParams) (types.Audits, types.Error) {
+
+	return s.selectAudits(params, func(a *types.Audit) bool {
+		return a.User == userName
+	}), nil
+}
+
+// selectAudits returns audit records matching filter, within the requested
+// timestamp range and limited to the requested number of records
+func (s *AuditStore) selectAudits(params db.AuditFilterParams, filter func(a *types.Audit) bool) types.Audits {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	audits := types.Audits{}
+	for i := range s.db.audits {
+		if params.Count > 0 && int64(len(audits)) >= params.Count {
+			break
+		}
+		audit := &s.db.audits[i]
+		if audit.Timestamp >= params.StartTime &&
+			audit.Timestamp <= params.EndTime &&
+			filter(audit) {
+
+			var auditCopy types.Audit
+			copyEntity(audit, &auditCopy)
+			audits = append(audits, auditCopy)
+		}
+	}
+	return audits
+}
+
+// Write an entry in audit log
+func (s *AuditStore) Write(a *types.Audit) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	var auditCopy types.Audit
+	copyEntity(a, &auditCopy)
+	s.db.audits = append(s.db.audits, auditCopy)
+	return nil
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// Retention of changes in seconds, identical to TTL used by Cassandra
+const changeLogRetention = 86400
+
+// ChangeLogStore holds our ChangeLogStore config
+type ChangeLogStore struct {
+	db *Database
+}
+
+// NewChangeLogStore creates changelog instance
+func NewChangeLogStore(database *Database) *ChangeLogStore {
+	return &ChangeLogStore{
+		db: database,
+	}
+}
+
+// GetSince retrieves changes made after timestamp
+func (s *ChangeLogStore) GetSince(timestamp int64) (types.Changes, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	changes := types.Changes{}
+	for _, c := range s.db.changes {
+		if c.Timestamp > timestamp {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+// Write inserts a change record, expired changes are removed
+func (s *ChangeLogStore) Write(c *types.Change) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	oldest := shared.GetCurrentTimeMilliseconds() - changeLogRetention*1000
+	changes := s.db.changes[:0]
+	for _, existing := range s.db.changes {
+		if existing.Timestamp >= oldest {
+			changes = append(changes, existing)
+		}
+	}
+	s.db.changes = append(changes, *c)
+	return nil
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// ClusterStore holds our database config
+type ClusterStore struct {
+	db *Database
+}
+
+// NewClusterStore creates cluster instance
+func NewClusterStore(database *Database) *ClusterStore {
+	return &ClusterStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all clusters
+func (s *ClusterStore) GetAll() (types.Clusters, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	clusters := make(types.Clusters, 0, len(s.db.clusters))
+	for _, cluster := range s.db.clusters {
+		var clusterCopy types.Cluster
+		copyEntity(cluster, &clusterCopy)
+		clusters = append(clusters, clusterCopy)
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].Name < clusters[j].Name
+	})
+	return clusters, nil
+}
+
+// Get retrieves a cluster
+func (s *ClusterStore) Get(clusterName string) (*types.Cluster, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	cluster, found := s.db.clusters[clusterName]
+	if !found {
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find cluster '%s'", clusterName))
+	}
+	var clusterCopy types.Cluster
+	copyEntity(cluster, &clusterCopy)
+	return &clusterCopy, nil
+}
+
+// Update UPSERTs a cluster
+func (s *ClusterStore) Update(c *types.Cluster, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.clusters[c.Name].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeClusterName, c.Name)
+	}
+
+	var clusterCopy types.Cluster
+	copyEntity(c, &clusterCopy)
+	s.db.clusters[c.Name] = clusterCopy
+	return nil
+}
+
+// Delete deletes a cluster
+func (s *ClusterStore) Delete(clusterToDelete string, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.clusters[clusterToDelete].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeClusterName, clusterToDelete)
+	}
+
+	delete(s.db.clusters, clusterToDelete)
+	return nil
+}
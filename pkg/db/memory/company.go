@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// CompanyStore holds our database config
+type CompanyStore struct {
+	db *Database
+}
+
+// NewCompanyStore creates company instance
+func NewCompanyStore(database *Database) *CompanyStore {
+	return &CompanyStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all companies
+func (s *CompanyStore) GetAll(organizationName string) (types.Companies, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	companies := types.Companies{}
+	for key, company := range s.db.companies {
+		if strings.HasPrefix(key, primaryKey(organizationName, "")) {
+			var companyCopy types.Company
+			copyEntity(company, &companyCopy)
+			companies = append(companies, companyCopy)
+		}
+	}
+	sort.SliceStable(companies, func(i, j int) bool {
+		return companies[i].Name < companies[j].Name
+	})
+	return companies, nil
+}
+
+// List retrieves one page of companies matching filter, ordered by name
+func (s *CompanyStore) List(organizationName string, params db.ListParams) (types.Companies, string, types.Error) {
+
+	all, _ := s.GetAll(organizationName)
+
+	companies := types.Companies{}
+	keys := []string{}
+	for _, company := range all {
+		if params.Matches(company.Status, "", company.LastModifiedAt) {
+			companies = append(companies, company)
+			keys = append(keys, company.Name)
+		}
+	}
+	start, end, nextPageToken, err := selectPage(keys, params)
+	if err != nil {
+		return types.NullCompanies, "", err
+	}
+	return companies[start:end], nextPageToken, nil
+}
+
+// Get retrieves a company
+func (s *CompanyStore) Get(organizationName, companyName string) (*types.Company, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	company, found := s.db.companies[primaryKey(organizationName, companyName)]
+	if !found {
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find company '%s'", companyName))
+	}
+	var companyCopy types.Company
+	copyEntity(company, &companyCopy)
+	return &companyCopy, nil
+}
+
+// Update UPSERTs a company
+func (s *CompanyStore) Update(organizationName string, c *types.Company, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.companies[primaryKey(organizationName, c.Name)].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeCompanyName, c.Name)
+	}
+
+	var companyCopy types.Company
+	copyEntity(c, &companyCopy)
+	s.db.companies[primaryKey(organizationName, c.Name)] = companyCopy
+	return nil
+}
+
+// Delete deletes a company
+func (s *CompanyStore) Delete(organizationName, companyToDelete string, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.companies[primaryKey(organizationName, companyToDelete)].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeCompanyName, companyToDelete)
+	}
+
+	delete(s.db.companies, primaryKey(organizationName, companyToDelete))
+	return nil
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// DeveloperStore holds our database config
+type DeveloperStore struct {
+	db *Database
+}
+
+// NewDeveloperStore creates developer instance
+func NewDeveloperStore(database *Database) *DeveloperStore {
+	return &DeveloperStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all developer
+func (s *DeveloperStore) GetAll(organizationName string) (types.Developers, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	developers := types.Developers{}
+	for _, developer := range s.db.developers {
+		if developer.OrganizationName == organizationName {
+			var developerCopy types.Developer
+			copyEntity(developer, &developerCopy)
+			developers = append(developers, developerCopy)
+		}
+	}
+	sort.SliceStable(developers, func(i, j int) bool {
+		return developers[i].Email < developers[j].Email
+	})
+	return developers, nil
+}
+
+// List retrieves one page of developers matching filter, ordered by email address
+func (s *DeveloperStore) List(organizationName string, params db.ListParams) (types.Developers, string, types.Error) {
+
+	all, _ := s.GetAll(organizationName)
+
+	developers := types.Developers{}
+	keys := []string{}
+	for _, developer := range all {
+		if params.Matches(developer.Status, developer.Email, developer.LastModifiedAt) {
+			developers = append(developers, developer)
+			keys = append(keys, developer.Email)
+		}
+	}
+	start, end, nextPageToken, err := selectPage(keys, params)
+	if err != nil {
+		return types.NullDevelopers, "", err
+	}
+	return developers[start:end], nextPageToken, nil
+}
+
+// GetByEmail retrieves a developer
+func (s *DeveloperStore) GetByEmail(organizationName, developerEmail string) (*types.Developer, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	developer := s.db.findDeveloperByEmail(organizationName, developerEmail)
+	if developer == nil {
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find developer '%s'", developerEmail))
+	}
+	var developerCopy types.Developer
+	copyEntity(developer, &developerCopy)
+	return &developerCopy, nil
+}
+
+// GetByID retrieves a developer
+func (s *DeveloperStore) GetByID(organizationName, developerID string) (*types.Developer, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	developer, found := s.db.developers[primaryKey(organizationName, developerID)]
+	if !found {
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find developerId '%s'", developerID))
+	}
+	var developerCopy types.Developer
+	copyEntity(developer, &developerCopy)
+	return &developerCopy, nil
+}
+
+// Update UPSERTs a developer
+func (s *DeveloperStore) Update(organizationName string, d *types.Developer, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.developers[primaryKey(organizationName, d.DeveloperID)].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeDeveloperName, d.Email)
+	}
+
+	var developerCopy types.Developer
+	copyEntity(d, &developerCopy)
+	s.db.developers[primaryKey(organizationName, d.DeveloperID)] = developerCopy
+	return nil
+}
+
+// DeleteByID deletes a developer
+func (s *DeveloperStore) DeleteByID(organizationName, developerID string, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.developers[primaryKey(organizationName, developerID)].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeDeveloperName, developerID)
+	}
+
+	delete(s.db.developers, primaryKey(organizationName, developerID))
+	return nil
+}
+
+// findDeveloperByEmail returns developer with matching email address, caller must hold lock
+func (d *Database) findDeveloperByEmail(organizationName, developerEmail string) *types.Developer {
+
+	for key, developer := range d.developers {
+		if key == primaryKey(organizationName, developer.DeveloperID) &&
+			developer.Email == developerEmail {
+			return &developer
+		}
+	}
+	return nil
+}
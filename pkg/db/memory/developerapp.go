@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// DeveloperAppStore holds our database config
+type DeveloperAppStore struct {
+	db *Database
+}
+
+// NewDeveloperAppStore creates developer app instance
+func NewDeveloperAppStore(database *Database) *DeveloperAppStore {
+	return &DeveloperAppStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all developer apps
+func (s *DeveloperAppStore) GetAll(organizationName string) (types.DeveloperApps, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	return s.db.selectDeveloperApps(organizationName, func(app types.DeveloperApp) bool {
+		return true
+	}), nil
+}
+
+// List retrieves one page of developer apps matching filter, ordered by name
+func (s *DeveloperAppStore) List(organizationName string, params db.ListParams) (types.DeveloperApps, string, types.Error) {
+
+	all, _ := s.GetAll(organizationName)
+	// App names are only unique per developer, hence we order on name and id
+	sort.SliceStable(all, func(i, j int) bool {
+		return developerAppListKey(all[i]) < developerAppListKey(all[j])
+	})
+
+	developerApps := types.DeveloperApps{}
+	keys := []string{}
+	for _, developerApp := range all {
+		if params.Matches(developerApp.Status, "", developerApp.LastModifiedAt) {
+			developerApps = append(developerApps, developerApp)
+			keys = append(keys, developerAppListKey(developerApp))
+		}
+	}
+	start, end, nextPageToken, err := selectPage(keys, params)
+	if err != nil {
+		return types.NullDeveloperApps, "", err
+	}
+	return developerApps[start:end], nextPageToken, nil
+}
+
+func developerAppListKey(app types.DeveloperApp) string {
+
+	return app.Name + "\x00" + app.AppID
+}
+
+// GetAllByDeveloperID retrieves all developer apps from a developer
+func (s *DeveloperAppStore) GetAllByDeveloperID(organizationName, developerID string) (types.DeveloperApps, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	return s.db.selectDeveloperApps(organizationName, func(app types.DeveloperApp) bool {
+		return app.DeveloperID == developerID
+	}), nil
+}
+
+// GetByName returns a developer app
+func (s *DeveloperAppStore) GetByName(organizationName, developerEmail, developerAppName string) (*types.DeveloperApp, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	notFound := types.NewItemNotFoundError(
+		fmt.Errorf("cannot find developer app '%s'", developerAppName))
+
+	developer := s.db.findDeveloperByEmail(organizationName, developerEmail)
+	if developer == nil {
+		return nil, notFound
+	}
+	developerApps := s.db.selectDeveloperApps(organizationName, func(app types.DeveloperApp) bool {
+		return app.DeveloperID == developer.DeveloperID && app.Name == developerAppName
+	})
+	if len(developerApps) == 0 {
+		return nil, notFound
+	}
+	return &developerApps[0], nil
+}
+
+// GetByID returns a developer app
+func (s *DeveloperAppStore) GetByID(organizationName, developerAppID string) (*types.DeveloperApp, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	developerApp, found := s.db.developerApps[primaryKey(organizationName, developerAppID)]
+	if !found {
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find developer app id '%s'", developerAppID))
+	}
+	var developerAppCopy types.DeveloperApp
+	copyEntity(developerApp, &developerAppCopy)
+	return &developerAppCopy, nil
+}
+
+// GetCountByDeveloperID retrieves number of apps belonging to a developer
+func (s *DeveloperAppStore) GetCountByDeveloperID(organizationName, developerID string) (int, types.Error) {
+
+	developerApps, err := s.GetAllByDeveloperID(organizationName, developerID)
+	if err != nil {
+		return -1, err
+	}
+	return len(developerApps), nil
+}
+
+// Update UPSERTs a developer app
+func (s *DeveloperAppStore) Update(organizationName string, app *types.DeveloperApp, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.developerApps[primaryKey(organizationName, app.AppID)].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeDeveloperAppName, app.Name)
+	}
+
+	var developerAppCopy types.DeveloperApp
+	copyEntity(app, &developerAppCopy)
+	s.db.developerApps[primaryKey(organizationName, app.AppID)] = developerAppCopy
+	return nil
+}
+
+// DeleteByID deletes a developer app
+func (s *DeveloperAppStore) DeleteByID(organizationName, developerAppID string, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.developerApps[primaryKey(organizationName, developerAppID)].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeDeveloperAppName, developerAppID)
+	}
+
+	delete(s.db.developerApps, primaryKey(organizationName, developerAppID))
+	return nil
+}
+
+// selectDeveloperApps returns copies of all developer apps of an organization
+// which match the provided filter, caller must hold lock
+func (d *Database) selectDeveloperApps(organizationName string,
+	filter func(app types.DeveloperApp) bool) types.DeveloperApps {
+
+	developerApps := types.DeveloperApps{}
+	for key, developerApp := range d.developerApps {
+		if key == primaryKey(organizationName, developerApp.AppID) && filter(developerApp) {
+			var developerAppCopy types.DeveloperApp
+			copyEntity(developerApp, &developerAppCopy)
+			developerApps = append(developerApps, developerAppCopy)
+		}
+	}
+	sort.SliceStable(developerApps, func(i, j int) bool {
+		return developerApps[i].Name < developerApps[j].Name
+	})
+	return developerApps
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// keyEntry holds a key together with the organization it belongs to
+type keyEntry struct {
+	organization string
+	key          types.Key
+}
+
+// KeyStore holds our database config
+type KeyStore struct {
+	db *Database
+}
+
+// NewKeyStore creates key instance
+func NewKeyStore(database *Database) *KeyStore {
+	return &KeyStore{
+		db: database,
+	}
+}
+
+// GetByKey returns details of a single apikey,
+// in case organization is nil the key is looked up in all organizations
+func (s *KeyStore) GetByKey(organization, key *string) (*types.Key, types.Error) {
+
+	if key == nil {
+		return nil, types.NewBadRequestError(fmt.Errorf("no apikey provided"))
+	}
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	entry, found := s.db.keys[*key]
+	if !found || (organization != nil && entry.organization != *organization) {
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find apikey '%s'", *key))
+	}
+	var keyCopy types.Key
+	copyEntity(entry.key, &keyCopy)
+	return &keyCopy, nil
+}
+
+// GetByDeveloperAppID returns an array with apikey details of a developer app
+func (s *KeyStore) GetByDeveloperAppID(organization, developerAppID string) (types.Keys, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	keys := types.Keys{}
+	for _, entry := range s.db.keys {
+		if entry.organization == organization && entry.key.AppID == developerAppID {
+			var keyCopy types.Key
+			copyEntity(entry.key, &keyCopy)
+			keys = append(keys, keyCopy)
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].IssuedAt < keys[j].IssuedAt
+	})
+	return keys, nil
+}
+
+// GetCountByAPIProductName counts the number of times an apiproduct has been assigned to keys
+func (s *KeyStore) GetCountByAPIProductName(organization, apiProductName string) (int, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	var count int
+	for _, entry := range s.db.keys {
+		if entry.organization != organization {
+			continue
+		}
+		for _, product := range entry.key.APIProducts {
+			if product.Apiproduct == apiProductName {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// UpdateByKey UPSERTs keys
+func (s *KeyStore) UpdateByKey(organization string, k *types.Key) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	entry := keyEntry{
+		organization: organization,
+	}
+	copyEntity(k, &entry.key)
+	s.db.keys[k.ConsumerKey] = entry
+	return nil
+}
+
+// DeleteByKey deletes keys
+func (s *KeyStore) DeleteByKey(organization, consumerKey string) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if entry, found := s.db.keys[consumerKey]; found && entry.organization == organization {
+		delete(s.db.keys, consumerKey)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// ListenerStore holds our database config
+type ListenerStore struct {
+	db *Database
+}
+
+// NewListenerStore creates listener instance
+func NewListenerStore(database *Database) *ListenerStore {
+	return &ListenerStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all listeners
+func (s *ListenerStore) GetAll() (types.Listeners, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	listeners := make(types.Listeners, 0, len(s.db.listeners))
+	for _, listener := range s.db.listeners {
+		var listenerCopy types.Listener
+		copyEntity(listener, &listenerCopy)
+		listeners = append(listeners, listenerCopy)
+	}
+	sort.SliceStable(listeners, func(i, j int) bool {
+		return listeners[i].Name < listeners[j].Name
+	})
+	return listeners, nil
+}
+
+// Get retrieves a listener
+func (s *ListenerStore) Get(listenerName string) (*types.Listener, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	listener, found := s.db.listeners[listenerName]
+	if !found {
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find listener '%s'", listenerName))
+	}
+	var listenerCopy types.Listener
+	copyEntity(listener, &listenerCopy)
+	return &listenerCopy, nil
+}
+
+// Update UPSERTs a listener
+func (s *ListenerStore) Update(l *types.Listener, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.listeners[l.Name].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeListenerName, l.Name)
+	}
+
+	var listenerCopy types.Listener
+	copyEntity(l, &listenerCopy)
+	s.db.listeners[l.Name] = listenerCopy
+	return nil
+}
+
+// Delete deletes a listener
+func (s *ListenerStore) Delete(listenerToDelete string, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.listeners[listenerToDelete].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeListenerName, listenerToDelete)
+	}
+
+	delete(s.db.listeners, listenerToDelete)
+	return nil
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// Database holds all entities of our in-memory database
+type Database struct {
+	mutex         sync.RWMutex
+	listeners     map[string]types.Listener
+	routes        map[string]types.Route
+	clusters      map[string]types.Cluster
+	organizations map[string]types.Organization
+	developers    map[string]types.Developer
+	developerApps map[string]types.DeveloperApp
+	keys          map[string]keyEntry
+	companies     map[string]types.Company
+	apiproducts   map[string]types.APIProduct
+	oauthTokens   map[string]oauthTokenEntry
+	users         map[string]types.User
+	roles         map[string]types.Role
+	audits        types.Audits
+	changes       types.Changes
+}
+
+// New builds new in-memory database instance, all data is lost when process stops
+func New(serviceName string, logger *zap.Logger) (*db.Database, error) {
+
+	logger = logger.With(zap.String("system", "db"))
+
+	dbConfig := Database{
+		listeners:     make(map[string]types.Listener),
+		routes:        make(map[string]types.Route),
+		clusters:      make(map[string]types.Cluster),
+		organizations: make(map[string]types.Organization),
+		developers:    make(map[string]types.Developer),
+		developerApps: make(map[string]types.DeveloperApp),
+		keys:          make(map[string]keyEntry),
+		companies:     make(map[string]types.Company),
+		apiproducts:   make(map[string]types.APIProduct),
+		oauthTokens:   make(map[string]oauthTokenEntry),
+		users:         make(map[string]types.User),
+		roles:         make(map[string]types.Role),
+	}
+	dbConfig.createDefaultEntities()
+
+	logger.Warn("Using in-memory database, all changes will be lost on exit",
+		zap.String("service", serviceName))
+
+	database := db.Database{
+		Listener:     NewListenerStore(&dbConfig),
+		Route:        NewRouteStore(&dbConfig),
+		Cluster:      NewClusterStore(&dbConfig),
+		Organization: NewOrganizationStore(&dbConfig),
+		Developer:    NewDeveloperStore(&dbConfig),
+		DeveloperApp: NewDeveloperAppStore(&dbConfig),
+		APIProduct:   NewAPIProductStore(&dbConfig),
+		Key:          NewKeyStore(&dbConfig),
+		Company:      NewCompanyStore(&dbConfig),
+		OAuth:        NewOAuthStore(&dbConfig),
+		User:         NewUserStore(&dbConfig),
+		Role:         NewRoleStore(&dbConfig),
+		Audit:        NewAuditStore(&dbConfig),
+		ChangeLog:    NewChangeLogStore(&dbConfig),
+	}
+	return &database, nil
+}
+
+const (
+	// Name of default admin user and role, identical to Cassandra's initial schema
+	defaultAdminName = "admin"
+	// Bcrypt hash of default admin password
+	defaultAdminPassword = "$2a$07$zWlw6WvswAFGZzNpBJg5qelwyg87NM/w4ypXP.NhfpuYmmv.WPyJO"
+	// Name of user who created default entities
+	defaultCreatedBy = "initdb"
+)
+
+// createDefaultEntities adds admin user and role so managementserver can be accessed
+func (d *Database) createDefaultEntities() {
+
+	now := shared.GetCurrentTimeMilliseconds()
+
+	d.users[defaultAdminName] = types.User{
+		Name:           defaultAdminName,
+		Password:       defaultAdminPassword,
+		Status:         "active",
+		Roles:          []string{defaultAdminName},
+		CreatedAt:      now,
+		CreatedBy:      defaultCreatedBy,
+		LastModifiedAt: now,
+	}
+	d.roles[defaultAdminName] = types.Role{
+		Name: defaultAdminName,
+		Permissions: types.Permissions{
+			{
+				Methods: []string{"GET", "POST", "DELETE", "PUT", "PATCH"},
+				Paths:   []string{"/v1/**"},
+			},
+		},
+		CreatedAt:      now,
+		CreatedBy:      defaultCreatedBy,
+		LastModifiedAt: now,
+	}
+}
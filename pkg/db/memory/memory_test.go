@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func newTestDatabase(t *testing.T) *db.Database {
+
+	database, err := New("test", zap.NewNop())
+	require.NoError(t, err)
+	return database
+}
+
+func Test_DeveloperApp_Lookups(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	require.Nil(t, database.Developer.Update("org1", &types.Developer{
+		DeveloperID: "dev1", Email: "joe@example.com", OrganizationName: "org1"}, db.Precondition{}))
+	require.Nil(t, database.Developer.Update("org2", &types.Developer{
+		DeveloperID: "dev2", Email: "joe@example.com", OrganizationName: "org2"}, db.Precondition{}))
+	require.Nil(t, database.DeveloperApp.Update("org1", &types.DeveloperApp{
+		AppID: "app1", DeveloperID: "dev1", Name: "shop"}, db.Precondition{}))
+	require.Nil(t, database.DeveloperApp.Update("org1", &types.DeveloperApp{
+		AppID: "app2", DeveloperID: "dev1", Name: "mobile"}, db.Precondition{}))
+	require.Nil(t, database.DeveloperApp.Update("org2", &types.DeveloperApp{
+		AppID: "app3", DeveloperID: "dev2", Name: "shop"}, db.Precondition{}))
+
+	apps, err := database.DeveloperApp.GetAllByDeveloperID("org1", "dev1")
+	require.Nil(t, err)
+	require.Equal(t, 2, len(apps))
+
+	count, err := database.DeveloperApp.GetCountByDeveloperID("org2", "dev2")
+	require.Nil(t, err)
+	require.Equal(t, 1, count)
+
+	app, err := database.DeveloperApp.GetByName("org2", "joe@example.com", "shop")
+	require.Nil(t, err)
+	require.Equal(t, "app3", app.AppID)
+
+	_, err = database.DeveloperApp.GetByID("org2", "app1")
+	require.Equal(t, http.StatusNotFound, types.HTTPStatusCode(err))
+}
+
+func Test_Key_GetCountByAPIProductName(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	keys := []struct {
+		organization string
+		key          types.Key
+	}{
+		{"org1", types.Key{ConsumerKey: "k1", APIProducts: types.KeyAPIProductStatuses{
+			{Apiproduct: "p1"}, {Apiproduct: "p2"}}}},
+		{"org1", types.Key{ConsumerKey: "k2", APIProducts: types.KeyAPIProductStatuses{
+			{Apiproduct: "p1"}}}},
+		{"org2", types.Key{ConsumerKey: "k3", APIProducts: types.KeyAPIProductStatuses{
+			{Apiproduct: "p1"}}}},
+	}
+	for _, k := range keys {
+		key := k.key
+		require.Nil(t, database.Key.UpdateByKey(k.organization, &key))
+	}
+
+	tests := []struct {
+		organization string
+		apiproduct   string
+		expected     int
+	}{
+		{"org1", "p1", 2},
+		{"org1", "p2", 1},
+		{"org2", "p1", 1},
+		{"org2", "p2", 0},
+	}
+	for _, test := range tests {
+		count, err := database.Key.GetCountByAPIProductName(test.organization, test.apiproduct)
+		require.Nil(t, err)
+		require.Equalf(t, test.expected, count, "%s/%s", test.organization, test.apiproduct)
+	}
+}
+
+func Test_Audit_FilterParams(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	for timestamp := int64(1); timestamp <= 10; timestamp++ {
+		require.Nil(t, database.Audit.Write(&types.Audit{
+			Timestamp:    timestamp,
+			Organization: "org1",
+			User:         "admin",
+		}))
+	}
+
+	tests := []struct {
+		name     string
+		params   db.AuditFilterParams
+		expected int
+	}{
+		{"all", db.AuditFilterParams{StartTime: 0, EndTime: 100}, 10},
+		{"time range", db.AuditFilterParams{StartTime: 3, EndTime: 5}, 3},
+		{"count", db.AuditFilterParams{StartTime: 0, EndTime: 100, Count: 4}, 4},
+		{"time range and count", db.AuditFilterParams{StartTime: 8, EndTime: 100, Count: 5}, 3},
+		{"no match", db.AuditFilterParams{StartTime: 11, EndTime: 100}, 0},
+	}
+	for _, test := range tests {
+		audits, err := database.Audit.GetOrganization("org1", test.params)
+		require.Nil(t, err)
+		require.Equal(t, test.expected, len(audits), test.name)
+
+		audits, err = database.Audit.GetUser("admin", test.params)
+		require.Nil(t, err)
+		require.Equal(t, test.expected, len(audits), test.name)
+	}
+}
+
+func Test_Developer_List(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	for i := 1; i <= 5; i++ {
+		status := "active"
+		if i%2 == 0 {
+			status = "inactive"
+		}
+		require.Nil(t, database.Developer.Update("org1", &types.Developer{
+			DeveloperID:      fmt.Sprintf("dev%d", i),
+			Email:            fmt.Sprintf("dev%d@example.com", i),
+			Status:           status,
+			LastModifiedAt:   int64(i),
+			OrganizationName: "org1",
+		}, db.Precondition{}))
+	}
+	require.Nil(t, database.Developer.Update("org1", &types.Developer{
+		DeveloperID: "dev6", Email: "joe@example.org", Status: "active",
+		LastModifiedAt: 6, OrganizationName: "org1"}, db.Precondition{}))
+
+	// Walk all pages
+	var emails []string
+	params := db.ListParams{PageSize: 2}
+	for {
+		developers, nextPageToken, err := database.Developer.List("org1", params)
+		require.Nil(t, err)
+		require.LessOrEqual(t, len(developers), 2)
+		for _, developer := range developers {
+			emails = append(emails, developer.Email)
+		}
+		if nextPageToken == "" {
+			break
+		}
+		params.PageToken = nextPageToken
+	}
+	require.Equal(t, []string{"dev1@example.com", "dev2@example.com", "dev3@example.com",
+		"dev4@example.com", "dev5@example.com", "joe@example.org"}, emails)
+
+	tests := []struct {
+		name     string
+		params   db.ListParams
+		expected int
+	}{
+		{"all", db.ListParams{}, 6},
+		{"status", db.ListParams{Status: "inactive"}, 2},
+		{"email prefix", db.ListParams{EmailPrefix: "dev"}, 5},
+		{"last modified", db.ListParams{LastModifiedFrom: 2, LastModifiedTo: 4}, 3},
+		{"combined", db.ListParams{Status: "active", EmailPrefix: "dev", LastModifiedFrom: 2}, 2},
+	}
+	for _, test := range tests {
+		developers, _, err := database.Developer.List("org1", test.params)
+		require.Nil(t, err, test.name)
+		require.Equal(t, test.expected, len(developers), test.name)
+	}
+
+	_, _, err := database.Developer.List("org1", db.ListParams{PageToken: "%%%"})
+	require.Equal(t, http.StatusBadRequest, types.HTTPStatusCode(err))
+}
+
+func Test_Route_Precondition(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	require.Nil(t, database.Route.Update(&types.Route{
+		Name: "r1", Path: "/", LastModifiedAt: 10}, db.Precondition{}))
+
+	// Update of stored entity with different last modified timestamp fails
+	err := database.Route.Update(&types.Route{
+		Name: "r1", Path: "/new", LastModifiedAt: 20}, db.Precondition{LastModifiedAt: 9})
+	require.Equal(t, http.StatusPreconditionFailed, types.HTTPStatusCode(err))
+
+	require.Nil(t, database.Route.Update(&types.Route{
+		Name: "r1", Path: "/new", LastModifiedAt: 20}, db.Precondition{LastModifiedAt: 10}))
+	route, err := database.Route.Get("r1")
+	require.Nil(t, err)
+	require.Equal(t, "/new", route.Path)
+	require.Equal(t, int64(20), route.LastModifiedAt)
+
+	// Conditional update must not create an entity
+	err = database.Route.Update(&types.Route{
+		Name: "r2", Path: "/", LastModifiedAt: 20}, db.Precondition{LastModifiedAt: 10})
+	require.Equal(t, http.StatusPreconditionFailed, types.HTTPStatusCode(err))
+
+	err = database.Route.Delete("r1", db.Precondition{LastModifiedAt: 10})
+	require.Equal(t, http.StatusPreconditionFailed, types.HTTPStatusCode(err))
+
+	require.Nil(t, database.Route.Delete("r1", db.Precondition{LastModifiedAt: 20}))
+	_, err = database.Route.Get("r1")
+	require.Equal(t, http.StatusNotFound, types.HTTPStatusCode(err))
+}
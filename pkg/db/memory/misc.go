@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// primaryKey generates primary key,
+// we combine org & name to make entity names unique per organization
+func primaryKey(organization, name string) string {
+
+	return fmt.Sprintf("%s@@@%s", organization, name)
+}
+
+// copyEntity deep copies an entity so a caller cannot modify stored data
+// via slices or maps it holds
+func copyEntity(in, out interface{}) {
+
+	// All our entity types only have exported fields which survive a JSON roundtrip
+	value, err := json.Marshal(in)
+	if err != nil {
+		return
+	}
+	_ = json.Unmarshal(value, out)
+}
+
+// selectPage returns index range of the requested page within a sorted list of unique keys,
+// the page token holds the key of the last entity of the previous page
+func selectPage(keys []string, params db.ListParams) (start, end int, nextPageToken string, err types.Error) {
+
+	state, err := db.DecodePageToken(params.PageToken)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	if state != nil {
+		lastKey := string(state)
+		start = sort.SearchStrings(keys, lastKey)
+		if start < len(keys) && keys[start] == lastKey {
+			start++
+		}
+	}
+	end = len(keys)
+	if params.PageSize > 0 && start+params.PageSize < end {
+		end = start + params.PageSize
+		nextPageToken = db.EncodePageToken([]byte(keys[end-1]))
+	}
+	return start, end, nextPageToken, nil
+}
@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// Default TTL for OAuth token in milliseconds, identical to the Cassandra row TTL
+	defaultOAuthtokenTTL = 86400 * 1000
+)
+
+// oauthTokenEntry holds a token together with its time of insertion
+type oauthTokenEntry struct {
+	insertedAt int64
+	token      types.OAuthAccessToken
+}
+
+// OAuthStore holds our database config
+type OAuthStore struct {
+	db *Database
+}
+
+// NewOAuthStore creates oauth instance
+func NewOAuthStore(database *Database) *OAuthStore {
+	return &OAuthStore{
+		db: database,
+	}
+}
+
+// OAuthAccessTokenGetByAccess retrieves an access token
+func (s *OAuthStore) OAuthAccessTokenGetByAccess(accessToken string) (*types.OAuthAccessToken, error) {
+
+	return s.findToken("access", func(t types.OAuthAccessToken) bool {
+		return t.Access == accessToken
+	})
+}
+
+// OAuthAccessTokenGetByCode retrieves token by code
+func (s *OAuthStore) OAuthAccessTokenGetByCode(code string) (*types.OAuthAccessToken, error) {
+
+	return s.findToken("code", func(t types.OAuthAccessToken) bool {
+		return t.Code == code
+	})
+}
+
+// OAuthAccessTokenGetByRefresh retrieves token by refreshcode
+func (s *OAuthStore) OAuthAccessTokenGetByRefresh(refresh string) (*types.OAuthAccessToken, error) {
+
+	return s.findToken("refresh", func(t types.OAuthAccessToken) bool {
+		return t.Refresh == refresh
+	})
+}
+
+// OAuthAccessTokenGetByClientID retrieves all tokens issued to a client
+func (s *OAuthStore) OAuthAccessTokenGetByClientID(clientID string) (types.OAuthAccessTokens, error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	now := shared.GetCurrentTimeMilliseconds()
+	tokens := types.OAuthAccessTokens{}
+	for _, entry := range s.db.oauthTokens {
+		if now-entry.insertedAt < defaultOAuthtokenTTL && entry.token.ClientID == clientID {
+			tokens = append(tokens, entry.token)
+		}
+	}
+	return tokens, nil
+}
+
+// findToken returns first non-expired token matching filter
+func (s *OAuthStore) findToken(method string, filter func(t types.OAuthAccessToken) bool) (*types.OAuthAccessToken, error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	now := shared.GetCurrentTimeMilliseconds()
+	for _, entry := range s.db.oauthTokens {
+		if now-entry.insertedAt < defaultOAuthtokenTTL && filter(entry.token) {
+			token := entry.token
+			return &token, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot find token by %s", method)
+}
+
+// OAuthAccessTokenCreate UPSERTs a token
+func (s *OAuthStore) OAuthAccessTokenCreate(t *types.OAuthAccessToken) error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	now := shared.GetCurrentTimeMilliseconds()
+
+	// Purge expired tokens, as we do not have a database to expire rows for us
+	for access, entry := range s.db.oauthTokens {
+		if now-entry.insertedAt >= defaultOAuthtokenTTL {
+			delete(s.db.oauthTokens, access)
+		}
+	}
+	s.db.oauthTokens[t.Access] = oauthTokenEntry{
+		insertedAt: now,
+		token:      *t,
+	}
+	return nil
+}
+
+// OAuthAccessTokenRemoveByAccess deletes an access token
+func (s *OAuthStore) OAuthAccessTokenRemoveByAccess(accessTokenToDelete string) error {
+
+	return s.removeTokens(func(t types.OAuthAccessToken) bool {
+		return t.Access == accessTokenToDelete
+	})
+}
+
+// OAuthAccessTokenRemoveByCode deletes an access token
+func (s *OAuthStore) OAuthAccessTokenRemoveByCode(codeToDelete string) error {
+
+	return s.removeTokens(func(t types.OAuthAccessToken) bool {
+		return t.Code == codeToDelete
+	})
+}
+
+// OAuthAccessTokenRemoveByRefresh deletes an access token
+func (s *OAuthStore) OAuthAccessTokenRemoveByRefresh(refreshToDelete string) error {
+
+	return s.removeTokens(func(t types.OAuthAccessToken) bool {
+		return t.Refresh == refreshToDelete
+	})
+}
+
+// removeTokens deletes all tokens matching filter
+func (s *OAuthStore) removeTokens(filter func(t types.OAuthAccessToken) bool) error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	for access, entry := range s.db.oauthTokens {
+		if filter(entry.token) {
+			delete(s.db.oauthTokens, access)
+		}
+	}
+	return nil
+}
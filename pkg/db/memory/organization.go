@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// OrganizationStore holds our database config
+type OrganizationStore struct {
+	db *Database
+}
+
+// NewOrganizationStore creates organization instance
+func NewOrganizationStore(database *Database) *OrganizationStore {
+	return &OrganizationStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all organizations
+func (s *OrganizationStore) GetAll() (types.Organizations, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	organizations := make(types.Organizations, 0, len(s.db.organizations))
+	for _, organization := range s.db.organizations {
+		var organizationCopy types.Organization
+		copyEntity(organization, &organizationCopy)
+		organizations = append(organizations, organizationCopy)
+	}
+	sort.SliceStable(organizations, func(i, j int) bool {
+		return organizations[i].Name < organizations[j].Name
+	})
+	return organizations, nil
+}
+
+// Get retrieves a organization
+func (s *OrganizationStore) Get(organizationName string) (*types.Organization, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	organization, found := s.db.organizations[organizationName]
+	if !found {
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find organization '%s'", organizationName))
+	}
+	var organizationCopy types.Organization
+	copyEntity(organization, &organizationCopy)
+	return &organizationCopy, nil
+}
+
+// Update UPSERTs a organization
+func (s *OrganizationStore) Update(o *types.Organization, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.organizations[o.Name].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeOrganizationName, o.Name)
+	}
+
+	var organizationCopy types.Organization
+	copyEntity(o, &organizationCopy)
+	s.db.organizations[o.Name] = organizationCopy
+	return nil
+}
+
+// Delete deletes a organization
+func (s *OrganizationStore) Delete(organizationToDelete string, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.organizations[organizationToDelete].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeOrganizationName, organizationToDelete)
+	}
+
+	delete(s.db.organizations, organizationToDelete)
+	return nil
+}
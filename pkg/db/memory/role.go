@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// RoleStore holds our database config
+type RoleStore struct {
+	db *Database
+}
+
+// NewRoleStore creates role instance
+func NewRoleStore(database *Database) *RoleStore {
+	return &RoleStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all roles
+func (s *RoleStore) GetAll() (types.Roles, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	roles := make(types.Roles, 0, len(s.db.roles))
+	for _, role := range s.db.roles {
+		var roleCopy types.Role
+		copyEntity(role, &roleCopy)
+		roles = append(roles, roleCopy)
+	}
+	sort.SliceStable(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+	return roles, nil
+}
+
+// Get retrieves a role
+func (s *RoleStore) Get(roleName string) (*types.Role, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	role, found := s.db.roles[roleName]
+	if !found {
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find role '%s'", roleName))
+	}
+	var roleCopy types.Role
+	copyEntity(role, &roleCopy)
+	return &roleCopy, nil
+}
+
+// Update UPSERTs a role
+func (s *RoleStore) Update(r *types.Role, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.roles[r.Name].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeRoleName, r.Name)
+	}
+
+	var roleCopy types.Role
+	copyEntity(r, &roleCopy)
+	s.db.roles[r.Name] = roleCopy
+	return nil
+}
+
+// Delete deletes a role
+func (s *RoleStore) Delete(roleToDelete string, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.roles[roleToDelete].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeRoleName, roleToDelete)
+	}
+
+	delete(s.db.roles, roleToDelete)
+	return nil
+}
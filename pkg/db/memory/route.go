@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// RouteStore holds our database config
+type RouteStore struct {
+	db *Database
+}
+
+// NewRouteStore creates route instance
+func NewRouteStore(database *Database) *RouteStore {
+	return &RouteStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all routes
+func (s *RouteStore) GetAll() (types.Routes, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	routes := make(types.Routes, 0, len(s.db.routes))
+	for _, route := range s.db.routes {
+		var routeCopy types.Route
+		copyEntity(route, &routeCopy)
+		routes = append(routes, routeCopy)
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].Name < routes[j].Name
+	})
+	return routes, nil
+}
+
+// Get retrieves a route
+func (s *RouteStore) Get(routeName string) (*types.Route, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	route, found := s.db.routes[routeName]
+	if !found {
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find route '%s'", routeName))
+	}
+	var routeCopy types.Route
+	copyEntity(route, &routeCopy)
+	return &routeCopy, nil
+}
+
+// Update UPSERTs a route
+func (s *RouteStore) Update(r *types.Route, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.routes[r.Name].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeRouteName, r.Name)
+	}
+
+	var routeCopy types.Route
+	copyEntity(r, &routeCopy)
+	s.db.routes[r.Name] = routeCopy
+	return nil
+}
+
+// Delete deletes a route
+func (s *RouteStore) Delete(routeToDelete string, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.routes[routeToDelete].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeRouteName, routeToDelete)
+	}
+
+	delete(s.db.routes, routeToDelete)
+	return nil
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// UserStore holds our database config
+type UserStore struct {
+	db *Database
+}
+
+// NewUserStore creates user instance
+func NewUserStore(database *Database) *UserStore {
+	return &UserStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all users
+func (s *UserStore) GetAll() (types.Users, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	users := make(types.Users, 0, len(s.db.users))
+	for _, user := range s.db.users {
+		var userCopy types.User
+		copyEntity(user, &userCopy)
+		users = append(users, userCopy)
+	}
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+	return users, nil
+}
+
+// Get retrieves a user
+func (s *UserStore) Get(userName string) (*types.User, types.Error) {
+
+	s.db.mutex.RLock()
+	defer s.db.mutex.RUnlock()
+
+	user, found := s.db.users[userName]
+	if !found {
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find user '%s'", userName))
+	}
+	var userCopy types.User
+	copyEntity(user, &userCopy)
+	return &userCopy, nil
+}
+
+// Update UPSERTs a user
+func (s *UserStore) Update(u *types.User, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.users[u.Name].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeUserName, u.Name)
+	}
+
+	var userCopy types.User
+	copyEntity(u, &userCopy)
+	s.db.users[u.Name] = userCopy
+	return nil
+}
+
+// Delete deletes a user
+func (s *UserStore) Delete(userToDelete string, precondition db.Precondition) types.Error {
+
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	if !precondition.Holds(s.db.users[userToDelete].LastModifiedAt) {
+		return db.NewPreconditionFailedError(types.TypeUserName, userToDelete)
+	}
+
+	delete(s.db.users, userToDelete)
+	return nil
+}
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// IsSet returns true if the stored entity must have a particular state
+func (p Precondition) IsSet() bool {
+
+	return p.LastModifiedAt != 0
+}
+
+// Holds returns true if a stored entity with last modified timestamp meets precondition
+func (p Precondition) Holds(lastModifiedAt int64) bool {
+
+	return !p.IsSet() || p.LastModifiedAt == lastModifiedAt
+}
+
+// NewPreconditionFailedError returns error indicating stored entity does not meet precondition
+func NewPreconditionFailedError(entityType, name string) types.Error {
+
+	return types.NewPreconditionFailedError(
+		fmt.Errorf("%s '%s' has been modified", entityType, name))
+}
@@ -0,0 +1,186 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of apiproduct columns we use
+	apiProductsColumns = `key,
+name,
+description,
+display_name,
+organization_name,
+approval_type,
+api_resources,
+route_group,
+scopes,
+policies,
+attributes,
+created_at,
+created_by,
+lastmodified_at,
+lastmodified_by`
+
+	// Prometheus label for metrics of db interactions
+	apiProductsMetricLabel = "apiproducts"
+)
+
+// APIProductStore holds our database config
+type APIProductStore struct {
+	db *Database
+}
+
+// NewAPIProductStore creates api product instance
+func NewAPIProductStore(database *Database) *APIProductStore {
+	return &APIProductStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all api products
+func (s *APIProductStore) GetAll(organizationName string) (types.APIProducts, types.Error) {
+
+	query := "SELECT " + apiProductsColumns + " FROM api_products WHERE organization_name = ? ORDER BY name"
+	apiproducts, err := s.runGetAPIProductQuery(query, organizationName)
+	if err != nil {
+		s.db.metrics.QueryFailed(apiProductsMetricLabel)
+		return types.NullAPIProducts, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(apiProductsMetricLabel)
+	return apiproducts, nil
+}
+
+// List retrieves one page of api products matching filter, ordered by name
+func (s *APIProductStore) List(organizationName string, params db.ListParams) (types.APIProducts, string, types.Error) {
+
+	query, queryParameters, e := listQuery("SELECT "+apiProductsColumns+" FROM api_products WHERE organization_name = ?",
+		[]interface{}{organizationName}, listColumns{order: "name", status: "", email: ""}, params)
+	if e != nil {
+		return types.NullAPIProducts, "", e
+	}
+	apiproducts, err := s.runGetAPIProductQuery(query, queryParameters...)
+	if err != nil {
+		s.db.metrics.QueryFailed(apiProductsMetricLabel)
+		return types.NullAPIProducts, "", types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(apiProductsMetricLabel)
+	count, nextPageToken := listPage(len(apiproducts), params, func(i int) string {
+		return apiproducts[i].Name
+	})
+	return apiproducts[:count], nextPageToken, nil
+}
+
+// Get returns an apiproduct
+func (s *APIProductStore) Get(organizationName, apiproductName string) (*types.APIProduct, types.Error) {
+
+	query := "SELECT " + apiProductsColumns + " FROM api_products WHERE key = ? LIMIT 1"
+	apiproducts, err := s.runGetAPIProductQuery(query, primaryKey(organizationName, apiproductName))
+	if err != nil {
+		s.db.metrics.QueryFailed(apiProductsMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(apiproducts) == 0 {
+		s.db.metrics.QueryNotFound(apiProductsMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find apiproduct '%s'", apiproductName))
+	}
+
+	s.db.metrics.QuerySuccessful(apiProductsMetricLabel)
+	return &apiproducts[0], nil
+}
+
+// runGetAPIProductQuery executes SQL query and returns resultset
+func (s *APIProductStore) runGetAPIProductQuery(query string, queryParameters ...interface{}) (types.APIProducts, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	rows, err := s.db.query(query, queryParameters...)
+	if err != nil {
+		return types.NullAPIProducts, err
+	}
+	defer rows.Close()
+
+	apiproducts := types.APIProducts{}
+	for rows.Next() {
+		var p types.APIProduct
+		var key, organizationName, apiResources, scopes, policies, attributes string
+		if err := rows.Scan(
+			&key,
+			&p.Name,
+			&p.Description,
+			&p.DisplayName,
+			&organizationName,
+			&p.ApprovalType,
+			&apiResources,
+			&p.RouteGroup,
+			&scopes,
+			&policies,
+			&attributes,
+			&p.CreatedAt,
+			&p.CreatedBy,
+			&p.LastModifiedAt,
+			&p.LastModifiedBy); err != nil {
+			return types.NullAPIProducts, err
+		}
+		p.APIResources = columnToStringSlice(apiResources)
+		p.Policies = columnToPolicies(policies)
+		p.Scopes = columnToStringSlice(scopes)
+		p.Attributes = columnToAttributes(attributes)
+		apiproducts = append(apiproducts, p)
+	}
+	return apiproducts, rows.Err()
+}
+
+// Update UPSERTs an apiproduct in database
+func (s *APIProductStore) Update(organizationName string, p *types.APIProduct, precondition db.Precondition) types.Error {
+
+	if err := s.db.upsert("api_products", apiProductsColumns, "key", precondition,
+		primaryKey(organizationName, p.Name),
+		p.Name,
+		p.Description,
+		p.DisplayName,
+		organizationName,
+		p.ApprovalType,
+		stringSliceToColumn(p.APIResources),
+		p.RouteGroup,
+		stringSliceToColumn(p.Scopes),
+		policiesToColumn(p.Policies),
+		attributesToColumn(p.Attributes),
+		p.CreatedAt,
+		p.CreatedBy,
+		p.LastModifiedAt,
+		p.LastModifiedBy); err != nil {
+
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeAPIProductName, p.Name)
+		}
+		s.db.metrics.QueryFailed(apiProductsMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot update apiproduct '%s' (%s)", p.Name, err))
+	}
+	return nil
+}
+
+// Delete deletes an apiproduct
+func (s *APIProductStore) Delete(organizationName, apiProduct string, precondition db.Precondition) types.Error {
+
+	query := "DELETE FROM api_products WHERE key = ?"
+	if err := s.db.delete(query, precondition, primaryKey(organizationName, apiProduct)); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeAPIProductName, apiProduct)
+		}
+		s.db.metrics.QueryFailed(apiProductsMetricLabel)
+		return types.NewDatabaseError(err)
+	}
+	return nil
+}
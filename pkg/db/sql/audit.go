@@ -0,0 +1,164 @@
+package sql
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of audit columns we use
+	auditColumns = `audit_id,
+audit_type,
+timestamp,
+entity_type,
+entity_id,
+ipaddress,
+request_id,
+"user",
+role,
+user_agent,
+organization,
+developer_id,
+app_id,
+old_value,
+new_value`
+
+	// Prometheus label for metrics of db interactions
+	auditMetricLabel = "audits"
+)
+
+// AuditStore holds our AuditStore config
+type AuditStore struct {
+	db *Database
+}
+
+// NewAuditStore creates audit instance
+func NewAuditStore(database *Database) *AuditStore {
+	return &AuditStore{
+		db: database,
+	}
+}
+
+// GetOrganization retrieves audit records of an organization
+func (s *AuditStore) GetOrganization(organizationName string, params db.AuditFilterParams) (types.Audits, types.Error) {
+
+	query := "SELECT " + auditColumns + " FROM audits WHERE organization = ? AND timestamp >= ? AND timestamp <= ?"
+	return s.runGetAuditQuery(query, params, organizationName, params.StartTime, params.EndTime)
+}
+
+// GetAPIProduct retrieves audit records of an apiproduct
+func (s *AuditStore) GetAPIProduct(organizationName, apiproductName string, params db.AuditFilterParams) (types.Audits, types.Error) {
+
+	query := "SELECT " + auditColumns + " FROM audits WHERE organization = ? AND entity_type = ? AND entity_id = ? AND timestamp >= ? AND timestamp <= ?"
+	return s.runGetAuditQuery(query, params, organizationName, types.TypeAPIProductName, apiproductName, params.StartTime, params.EndTime)
+}
+
+// GetDeveloper retrieves audit records of a developer
+func (s *AuditStore) GetDeveloper(organizationName, developerID string, params db.AuditFilterParams) (types.Audits, types.Error) {
+
+	query := "SELECT " + auditColumns + " FROM audits WHERE organization = ? AND developer_id = ? AND timestamp >= ? AND timestamp <= ?"
+	return s.runGetAuditQuery(query, params, organizationName, developerID, params.StartTime, params.EndTime)
+}
+
+// GetApplication retrieves audit records of an application
+func (s *AuditStore) GetApplication(organizationName, developerID, appID string, params db.AuditFilterParams) (types.Audits, types.Error) {
+
+	query := "SELECT " + auditColumns + " FROM audits WHERE organization = ? AND developer_id = ? AND app_id = ? AND timestamp >= ? AND timestamp <= ?"
+	return s.runGetAuditQuery(query, params, organizationName, developerID, appID, params.StartTime, params.EndTime)
+}
+
+// GetUser retrieves audit records of a user
+func (s *AuditStore) GetUser(userName string, params db.AuditFilterParams) (types.Audits, types.Error) {
+
+	query := "SELECT " + auditColumns + ` FROM audits WHERE "user" = ? AND timestamp >= ? AND timestamp <= ?`
+	return s.runGetAuditQuery(query, params, userName, params.StartTime, params.EndTime)
+}
+
+// runGetAuditQuery executes SQL query and returns resultset,
+// ordered by timestamp and limited to requested number of records
+func (s *AuditStore) runGetAuditQuery(query string, params db.AuditFilterParams,
+	queryParameters ...interface{}) (types.Audits, types.Error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	query += " ORDER BY timestamp"
+	if params.Count > 0 {
+		query += " LIMIT " + strconv.FormatInt(params.Count, 10)
+	}
+
+	rows, err := s.db.query(query, queryParameters...)
+	if err != nil {
+		s.db.metrics.QueryFailed(auditMetricLabel)
+		return types.Audits{}, types.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	audits := types.Audits{}
+	for rows.Next() {
+		var a types.Audit
+		var oldValue, newValue string
+		if err := rows.Scan(
+			&a.ID,
+			&a.AuditType,
+			&a.Timestamp,
+			&a.EntityType,
+			&a.EntityID,
+			&a.IPaddress,
+			&a.RequestID,
+			&a.User,
+			&a.Role,
+			&a.UserAgent,
+			&a.Organization,
+			&a.DeveloperID,
+			&a.AppID,
+			&oldValue,
+			&newValue); err != nil {
+			s.db.metrics.QueryFailed(auditMetricLabel)
+			return types.Audits{}, types.NewDatabaseError(err)
+		}
+		a.OldValue = columnToMapString(oldValue)
+		a.NewValue = columnToMapString(newValue)
+		audits = append(audits, a)
+	}
+	// In case query failed we return query error
+	if err := rows.Err(); err != nil {
+		s.db.metrics.QueryFailed(auditMetricLabel)
+		return types.Audits{}, types.NewDatabaseError(err)
+	}
+	s.db.metrics.QuerySuccessful(auditMetricLabel)
+	return audits, nil
+}
+
+// Write an entry in audit log
+func (s *AuditStore) Write(a *types.Audit) types.Error {
+
+	query := "INSERT INTO audits (" + auditColumns + ") VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
+	if err := s.db.exec(query,
+		a.ID,
+		a.AuditType,
+		a.Timestamp,
+		a.EntityType,
+		a.EntityID,
+		a.IPaddress,
+		a.RequestID,
+		a.User,
+		a.Role,
+		a.UserAgent,
+		a.Organization,
+		a.DeveloperID,
+		a.AppID,
+		valueToJSON(a.OldValue),
+		valueToJSON(a.NewValue)); err != nil {
+
+		s.db.metrics.QueryFailed(auditMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot update audit (%s)", err))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of changelog columns we use
+	changeLogColumns = `change_id,
+timestamp,
+operation,
+entity_type,
+organization,
+entity_id`
+
+	// Prometheus label for metrics of db interactions
+	changeLogMetricLabel = "changelog"
+
+	// Retention of changelog rows in seconds, identical to TTL used by Cassandra
+	changeLogRetention = 86400
+)
+
+// ChangeLogStore holds our database config
+type ChangeLogStore struct {
+	db *Database
+}
+
+// NewChangeLogStore creates changelog instance
+func NewChangeLogStore(database *Database) *ChangeLogStore {
+	return &ChangeLogStore{
+		db: database,
+	}
+}
+
+// GetSince retrieves changes made after timestamp
+func (s *ChangeLogStore) GetSince(timestamp int64) (types.Changes, types.Error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	query := "SELECT " + changeLogColumns + " FROM changelog WHERE timestamp > ? ORDER BY timestamp"
+	rows, err := s.db.query(query, timestamp)
+	if err != nil {
+		s.db.metrics.QueryFailed(changeLogMetricLabel)
+		return types.NullChanges, types.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	changes := types.Changes{}
+	for rows.Next() {
+		var c types.Change
+		if err := rows.Scan(
+			&c.ID,
+			&c.Timestamp,
+			&c.Operation,
+			&c.EntityType,
+			&c.Organization,
+			&c.EntityID); err != nil {
+			s.db.metrics.QueryFailed(changeLogMetricLabel)
+			return types.NullChanges, types.NewDatabaseError(err)
+		}
+		changes = append(changes, c)
+	}
+	// In case query failed we return query error
+	if err := rows.Err(); err != nil {
+		s.db.metrics.QueryFailed(changeLogMetricLabel)
+		return types.NullChanges, types.NewDatabaseError(err)
+	}
+	s.db.metrics.QuerySuccessful(changeLogMetricLabel)
+	return changes, nil
+}
+
+// Write inserts a change record
+func (s *ChangeLogStore) Write(c *types.Change) types.Error {
+
+	// SQL databases do not support a row time-to-live, hence we remove expired rows ourselves.
+	if err := s.db.exec("DELETE FROM changelog WHERE timestamp < ?",
+		shared.GetCurrentTimeMilliseconds()-changeLogRetention*1000); err != nil {
+		s.db.metrics.QueryFailed(changeLogMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot remove expired changes (%s)", err))
+	}
+
+	query := "INSERT INTO changelog (" + changeLogColumns + ") VALUES(?,?,?,?,?,?)"
+	if err := s.db.exec(query,
+		c.ID,
+		c.Timestamp,
+		c.Operation,
+		c.EntityType,
+		c.Organization,
+		c.EntityID); err != nil {
+
+		s.db.metrics.QueryFailed(changeLogMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot write change (%s)", err))
+	}
+	return nil
+}
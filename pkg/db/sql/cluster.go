@@ -0,0 +1,138 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of cluster columns we use
+	clusterColumns = `name,
+display_name,
+attributes,
+created_at,
+created_by,
+lastmodified_at,
+lastmodified_by`
+
+	// Prometheus label for metrics of db interactions
+	clusterMetricLabel = "clusters"
+)
+
+// ClusterStore holds our database config
+type ClusterStore struct {
+	db *Database
+}
+
+// NewClusterStore creates cluster instance
+func NewClusterStore(database *Database) *ClusterStore {
+	return &ClusterStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all clusters
+func (s *ClusterStore) GetAll() (types.Clusters, types.Error) {
+
+	query := "SELECT " + clusterColumns + " FROM clusters ORDER BY name"
+	clusters, err := s.runGetClusterQuery(query)
+	if err != nil {
+		s.db.metrics.QueryFailed(clusterMetricLabel)
+		return types.NullClusters, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(clusterMetricLabel)
+	return clusters, nil
+}
+
+// Get retrieves a cluster from database
+func (s *ClusterStore) Get(clusterName string) (*types.Cluster, types.Error) {
+
+	query := "SELECT " + clusterColumns + " FROM clusters WHERE name = ? LIMIT 1"
+	clusters, err := s.runGetClusterQuery(query, clusterName)
+	if err != nil {
+		s.db.metrics.QueryFailed(clusterMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(clusters) == 0 {
+		s.db.metrics.QueryNotFound(clusterMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find cluster (%s)", clusterName))
+	}
+
+	s.db.metrics.QuerySuccessful(clusterMetricLabel)
+	return &clusters[0], nil
+}
+
+// runGetClusterQuery executes SQL query and returns resultset
+func (s *ClusterStore) runGetClusterQuery(query string, queryParameters ...interface{}) (types.Clusters, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	rows, err := s.db.query(query, queryParameters...)
+	if err != nil {
+		return types.NullClusters, err
+	}
+	defer rows.Close()
+
+	clusters := types.Clusters{}
+	for rows.Next() {
+		var c types.Cluster
+		var attributes string
+		if err := rows.Scan(
+			&c.Name,
+			&c.DisplayName,
+			&attributes,
+			&c.CreatedAt,
+			&c.CreatedBy,
+			&c.LastModifiedAt,
+			&c.LastModifiedBy); err != nil {
+			return types.NullClusters, err
+		}
+		c.Attributes = columnToAttributes(attributes)
+		clusters = append(clusters, c)
+	}
+	return clusters, rows.Err()
+}
+
+// Update UPSERTs a cluster in database
+func (s *ClusterStore) Update(c *types.Cluster, precondition db.Precondition) types.Error {
+
+	if err := s.db.upsert("clusters", clusterColumns, "name", precondition,
+		c.Name,
+		c.DisplayName,
+		attributesToColumn(c.Attributes),
+		c.CreatedAt,
+		c.CreatedBy,
+		c.LastModifiedAt,
+		c.LastModifiedBy); err != nil {
+
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeClusterName, c.Name)
+		}
+		s.db.metrics.QueryFailed(clusterMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot update cluster '%s' (%s)", c.Name, err))
+	}
+	return nil
+}
+
+// Delete deletes a cluster
+func (s *ClusterStore) Delete(clusterToDelete string, precondition db.Precondition) types.Error {
+
+	query := "DELETE FROM clusters WHERE name = ?"
+	if err := s.db.delete(query, precondition, clusterToDelete); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeClusterName, clusterToDelete)
+		}
+		s.db.metrics.QueryFailed(clusterMetricLabel)
+		return types.NewDatabaseError(err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of company columns we use
+	companyColumns = `key,
+name,
+organization_name,
+display_name,
+attributes,
+status,
+created_at,
+created_by,
+lastmodified_at,
+lastmodified_by`
+
+	// Prometheus label for metrics of db interactions
+	companyMetricLabel = "companies"
+)
+
+// CompanyStore holds our database config
+type CompanyStore struct {
+	db *Database
+}
+
+// NewCompanyStore creates company instance
+func NewCompanyStore(database *Database) *CompanyStore {
+	return &CompanyStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all companies
+func (s *CompanyStore) GetAll(organizationName string) (types.Companies, types.Error) {
+
+	query := "SELECT " + companyColumns + " FROM companies WHERE organization_name = ? ORDER BY name"
+	companies, err := s.runGetCompanyQuery(query, organizationName)
+	if err != nil {
+		s.db.metrics.QueryFailed(companyMetricLabel)
+		return types.NullCompanies, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(companyMetricLabel)
+	return companies, nil
+}
+
+// List retrieves one page of companies matching filter, ordered by name
+func (s *CompanyStore) List(organizationName string, params db.ListParams) (types.Companies, string, types.Error) {
+
+	query, queryParameters, e := listQuery("SELECT "+companyColumns+" FROM companies WHERE organization_name = ?",
+		[]interface{}{organizationName}, listColumns{order: "name", status: "status", email: ""}, params)
+	if e != nil {
+		return types.NullCompanies, "", e
+	}
+	companies, err := s.runGetCompanyQuery(query, queryParameters...)
+	if err != nil {
+		s.db.metrics.QueryFailed(companyMetricLabel)
+		return types.NullCompanies, "", types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(companyMetricLabel)
+	count, nextPageToken := listPage(len(companies), params, func(i int) string {
+		return companies[i].Name
+	})
+	return companies[:count], nextPageToken, nil
+}
+
+// Get retrieves a company from database
+func (s *CompanyStore) Get(organizationName, companyName string) (*types.Company, types.Error) {
+
+	query := "SELECT " + companyColumns + " FROM companies WHERE key = ? LIMIT 1"
+	companies, err := s.runGetCompanyQuery(query, primaryKey(organizationName, companyName))
+	if err != nil {
+		s.db.metrics.QueryFailed(companyMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(companies) == 0 {
+		s.db.metrics.QueryNotFound(companyMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find company '%s'", companyName))
+	}
+
+	s.db.metrics.QuerySuccessful(companyMetricLabel)
+	return &companies[0], nil
+}
+
+// runGetCompanyQuery executes SQL query and returns resultset
+func (s *CompanyStore) runGetCompanyQuery(query string, queryParameters ...interface{}) (types.Companies, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	rows, err := s.db.query(query, queryParameters...)
+	if err != nil {
+		return types.NullCompanies, err
+	}
+	defer rows.Close()
+
+	companies := types.Companies{}
+	for rows.Next() {
+		var c types.Company
+		var key, organizationName, attributes string
+		if err := rows.Scan(
+			&key,
+			&c.Name,
+			&organizationName,
+			&c.DisplayName,
+			&attributes,
+			&c.Status,
+			&c.CreatedAt,
+			&c.CreatedBy,
+			&c.LastModifiedAt,
+			&c.LastModifiedBy); err != nil {
+			return types.NullCompanies, err
+		}
+		c.Attributes = columnToAttributes(attributes)
+		companies = append(companies, c)
+	}
+	return companies, rows.Err()
+}
+
+// Update UPSERTs an company in database
+func (s *CompanyStore) Update(organizationName string, c *types.Company, precondition db.Precondition) types.Error {
+
+	if err := s.db.upsert("companies", companyColumns, "key", precondition,
+		primaryKey(organizationName, c.Name),
+		c.Name,
+		organizationName,
+		c.DisplayName,
+		attributesToColumn(c.Attributes),
+		c.Status,
+		c.CreatedAt,
+		c.CreatedBy,
+		c.LastModifiedAt,
+		c.LastModifiedBy); err != nil {
+
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeCompanyName, c.Name)
+		}
+		s.db.metrics.QueryFailed(companyMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot update company '%s' (%s)", c.Name, err))
+	}
+	return nil
+}
+
+// Delete deletes a company
+func (s *CompanyStore) Delete(organizationName, companyToDelete string, precondition db.Precondition) types.Error {
+
+	query := "DELETE FROM companies WHERE key = ?"
+	if err := s.db.delete(query, precondition, primaryKey(organizationName, companyToDelete)); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeCompanyName, companyToDelete)
+		}
+		s.db.metrics.QueryFailed(companyMetricLabel)
+		return types.NewDatabaseError(err)
+	}
+	return nil
+}
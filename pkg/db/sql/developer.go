@@ -0,0 +1,202 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of developer columns we use
+	developerColumns = `key, developer_id,
+apps,
+attributes,
+status,
+user_name,
+email,
+first_name,
+last_name,
+organization_name,
+created_at,
+created_by,
+lastmodified_at,
+lastmodified_by`
+
+	// Prometheus label for metrics of db interactions
+	developerMetricLabel = "developers"
+)
+
+// DeveloperStore holds our database config
+type DeveloperStore struct {
+	db *Database
+}
+
+// NewDeveloperStore creates developer instance
+func NewDeveloperStore(database *Database) *DeveloperStore {
+	return &DeveloperStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all developer
+func (s *DeveloperStore) GetAll(organizationName string) (types.Developers, types.Error) {
+
+	query := "SELECT " + developerColumns + " FROM developers WHERE organization_name = ? ORDER BY email"
+	developers, err := s.runGetDeveloperQuery(query, organizationName)
+	if err != nil {
+		s.db.metrics.QueryFailed(developerMetricLabel)
+		return types.NullDevelopers, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(developerMetricLabel)
+	return developers, nil
+}
+
+// List retrieves one page of developers matching filter, ordered by email address
+func (s *DeveloperStore) List(organizationName string, params db.ListParams) (types.Developers, string, types.Error) {
+
+	query, queryParameters, e := listQuery("SELECT "+developerColumns+" FROM developers WHERE organization_name = ?",
+		[]interface{}{organizationName}, listColumns{order: "email", status: "status", email: "email"}, params)
+	if e != nil {
+		return types.NullDevelopers, "", e
+	}
+	developers, err := s.runGetDeveloperQuery(query, queryParameters...)
+	if err != nil {
+		s.db.metrics.QueryFailed(developerMetricLabel)
+		return types.NullDevelopers, "", types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(developerMetricLabel)
+	count, nextPageToken := listPage(len(developers), params, func(i int) string {
+		return developers[i].Email
+	})
+	return developers[:count], nextPageToken, nil
+}
+
+// GetByEmail retrieves a developer from database
+func (s *DeveloperStore) GetByEmail(organizationName, developerEmail string) (*types.Developer, types.Error) {
+
+	query := "SELECT " + developerColumns + " FROM developers WHERE organization_name = ? AND email = ? LIMIT 1"
+	developers, err := s.runGetDeveloperQuery(query, organizationName, developerEmail)
+	if err != nil {
+		s.db.metrics.QueryFailed(developerMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(developers) == 0 {
+		s.db.metrics.QueryNotFound(developerMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find developer '%s'", developerEmail))
+	}
+
+	s.db.metrics.QuerySuccessful(developerMetricLabel)
+	return &developers[0], nil
+}
+
+// GetByID retrieves a developer from database
+func (s *DeveloperStore) GetByID(organizationName, developerID string) (*types.Developer, types.Error) {
+
+	query := "SELECT " + developerColumns + " FROM developers WHERE key = ? LIMIT 1"
+	developers, err := s.runGetDeveloperQuery(query, primaryKey(organizationName, developerID))
+	if err != nil {
+		s.db.metrics.QueryFailed(developerMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(developers) == 0 {
+		s.db.metrics.QueryNotFound(developerMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find developerId '%s'", developerID))
+	}
+
+	s.db.metrics.QuerySuccessful(developerMetricLabel)
+	return &developers[0], nil
+}
+
+// runGetDeveloperQuery executes SQL query and returns resultset
+func (s *DeveloperStore) runGetDeveloperQuery(query string, queryParameters ...interface{}) (types.Developers, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	rows, err := s.db.query(query, queryParameters...)
+	if err != nil {
+		return types.NullDevelopers, err
+	}
+	defer rows.Close()
+
+	developers := types.Developers{}
+	for rows.Next() {
+		var d types.Developer
+		var key, apps, attributes string
+		if err := rows.Scan(
+			&key,
+			&d.DeveloperID,
+			&apps,
+			&attributes,
+			&d.Status,
+			&d.UserName,
+			&d.Email,
+			&d.FirstName,
+			&d.LastName,
+			&d.OrganizationName,
+			&d.CreatedAt,
+			&d.CreatedBy,
+			&d.LastModifiedAt,
+			&d.LastModifiedBy); err != nil {
+			return types.NullDevelopers, err
+		}
+		d.Apps = columnToStringSlice(apps)
+		d.Attributes = columnToAttributes(attributes)
+		developers = append(developers, d)
+	}
+	return developers, rows.Err()
+}
+
+// Update UPSERTs a developer in database
+func (s *DeveloperStore) Update(organizationName string, d *types.Developer, precondition db.Precondition) types.Error {
+
+	// organization_name is set to the organization the developer is stored in,
+	// as developer apps refer to developers by organization and developerID
+	if err := s.db.upsert("developers", developerColumns, "key", precondition,
+		primaryKey(organizationName, d.DeveloperID),
+		d.DeveloperID,
+		stringSliceToColumn(d.Apps),
+		attributesToColumn(d.Attributes),
+		d.Status,
+		d.UserName,
+		d.Email,
+		d.FirstName,
+		d.LastName,
+		organizationName,
+		d.CreatedAt,
+		d.CreatedBy,
+		d.LastModifiedAt,
+		d.LastModifiedBy); err != nil {
+
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeDeveloperName, d.Email)
+		}
+		s.db.metrics.QueryFailed(developerMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot update developer '%s' (%s)", d.DeveloperID, err))
+	}
+	return nil
+}
+
+// DeleteByID deletes a developer, including its developer apps and keys
+func (s *DeveloperStore) DeleteByID(organizationName, developerID string, precondition db.Precondition) types.Error {
+
+	query := "DELETE FROM developers WHERE key = ?"
+	if err := s.db.delete(query, precondition, primaryKey(organizationName, developerID)); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeDeveloperName, developerID)
+		}
+		s.db.metrics.QueryFailed(developerMetricLabel)
+		return types.NewDatabaseError(err)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of developer app columns we use
+	developerAppColumns = `app_id,
+organization_name,
+developer_id,
+name,
+display_name,
+attributes,
+status,
+callback_url,
+scopes,
+created_at,
+created_by,
+lastmodified_at,
+lastmodified_by`
+
+	// Prometheus label for metrics of db interactions
+	developerAppsMetricLabel = "developerapps"
+)
+
+// DeveloperAppStore holds our database config
+type DeveloperAppStore struct {
+	db *Database
+}
+
+// NewDeveloperAppStore creates developer app instance
+func NewDeveloperAppStore(database *Database) *DeveloperAppStore {
+	return &DeveloperAppStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all developer apps
+func (s *DeveloperAppStore) GetAll(organizationName string) (types.DeveloperApps, types.Error) {
+
+	query := "SELECT " + developerAppColumns + " FROM developer_apps WHERE organization_name = ? ORDER BY name"
+	developerapps, err := s.runGetDeveloperAppQuery(query, organizationName)
+	if err != nil {
+		s.db.metrics.QueryFailed(developerAppsMetricLabel)
+		return types.NullDeveloperApps, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(developerAppsMetricLabel)
+	return developerapps, nil
+}
+
+// List retrieves one page of developer apps matching filter, ordered by id
+func (s *DeveloperAppStore) List(organizationName string, params db.ListParams) (types.DeveloperApps, string, types.Error) {
+
+	query, queryParameters, e := listQuery("SELECT "+developerAppColumns+" FROM developer_apps WHERE organization_name = ?",
+		[]interface{}{organizationName}, listColumns{order: "app_id", status: "status", email: ""}, params)
+	if e != nil {
+		return types.NullDeveloperApps, "", e
+	}
+	developerapps, err := s.runGetDeveloperAppQuery(query, queryParameters...)
+	if err != nil {
+		s.db.metrics.QueryFailed(developerAppsMetricLabel)
+		return types.NullDeveloperApps, "", types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(developerAppsMetricLabel)
+	count, nextPageToken := listPage(len(developerapps), params, func(i int) string {
+		return developerapps[i].AppID
+	})
+	return developerapps[:count], nextPageToken, nil
+}
+
+// GetAllByDeveloperID retrieves all developer apps from a developer
+func (s *DeveloperAppStore) GetAllByDeveloperID(organizationName, developerID string) (types.DeveloperApps, types.Error) {
+
+	query := "SELECT " + developerAppColumns + " FROM developer_apps WHERE organization_name = ? AND developer_id = ? ORDER BY name"
+	developerapps, err := s.runGetDeveloperAppQuery(query, organizationName, developerID)
+	if err != nil {
+		s.db.metrics.QueryFailed(developerAppsMetricLabel)
+		return types.NullDeveloperApps, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(developerAppsMetricLabel)
+	return developerapps, nil
+}
+
+// GetByName returns a developer app
+func (s *DeveloperAppStore) GetByName(organizationName, developerEmail, developerAppName string) (*types.DeveloperApp, types.Error) {
+
+	query := "SELECT " + developerAppColumns + " FROM developer_apps WHERE organization_name = ? AND name = ? AND developer_id IN " +
+		"(SELECT developer_id FROM developers WHERE organization_name = ? AND email = ?) LIMIT 1"
+	developerapps, err := s.runGetDeveloperAppQuery(query,
+		organizationName, developerAppName, organizationName, developerEmail)
+	if err != nil {
+		s.db.metrics.QueryFailed(developerAppsMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(developerapps) == 0 {
+		s.db.metrics.QueryNotFound(developerAppsMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find developer app '%s'", developerAppName))
+	}
+
+	s.db.metrics.QuerySuccessful(developerAppsMetricLabel)
+	return &developerapps[0], nil
+}
+
+// GetByID returns a developer app
+func (s *DeveloperAppStore) GetByID(organizationName, developerAppID string) (*types.DeveloperApp, types.Error) {
+
+	query := "SELECT " + developerAppColumns + " FROM developer_apps WHERE organization_name = ? AND app_id = ? LIMIT 1"
+	developerapps, err := s.runGetDeveloperAppQuery(query, organizationName, developerAppID)
+	if err != nil {
+		s.db.metrics.QueryFailed(developerAppsMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(developerapps) == 0 {
+		s.db.metrics.QueryNotFound(developerAppsMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find developer app id '%s'", developerAppID))
+	}
+
+	s.db.metrics.QuerySuccessful(developerAppsMetricLabel)
+	return &developerapps[0], nil
+}
+
+// GetCountByDeveloperID retrieves number of apps belonging to a developer
+func (s *DeveloperAppStore) GetCountByDeveloperID(organizationName, developerID string) (int, types.Error) {
+
+	var developerAppCount int
+	query := "SELECT count(*) FROM developer_apps WHERE organization_name = ? AND developer_id = ?"
+	if err := s.db.SQLDB.QueryRow(s.db.rebind(query),
+		organizationName, developerID).Scan(&developerAppCount); err != nil {
+		s.db.metrics.QueryFailed(developerAppsMetricLabel)
+		return -1, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(developerAppsMetricLabel)
+	return developerAppCount, nil
+}
+
+// runGetDeveloperAppQuery executes SQL query and returns resultset
+func (s *DeveloperAppStore) runGetDeveloperAppQuery(query string, queryParameters ...interface{}) (types.DeveloperApps, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	rows, err := s.db.query(query, queryParameters...)
+	if err != nil {
+		return types.NullDeveloperApps, err
+	}
+	defer rows.Close()
+
+	developerapps := types.DeveloperApps{}
+	for rows.Next() {
+		var app types.DeveloperApp
+		var organizationName, attributes, scopes string
+		if err := rows.Scan(
+			&app.AppID,
+			&organizationName,
+			&app.DeveloperID,
+			&app.Name,
+			&app.DisplayName,
+			&attributes,
+			&app.Status,
+			&app.CallbackURL,
+			&scopes,
+			&app.CreatedAt,
+			&app.CreatedBy,
+			&app.LastModifiedAt,
+			&app.LastModifiedBy); err != nil {
+			return types.NullDeveloperApps, err
+		}
+		app.Attributes = columnToAttributes(attributes)
+		app.Scopes = columnToStringSlice(scopes)
+		developerapps = append(developerapps, app)
+	}
+	return developerapps, rows.Err()
+}
+
+// Update UPSERTs a developer app
+func (s *DeveloperAppStore) Update(organizationName string, app *types.DeveloperApp, precondition db.Precondition) types.Error {
+
+	if err := s.db.upsert("developer_apps", developerAppColumns, "app_id", precondition,
+		app.AppID,
+		organizationName,
+		app.DeveloperID,
+		app.Name,
+		app.DisplayName,
+		attributesToColumn(app.Attributes),
+		app.Status,
+		app.CallbackURL,
+		stringSliceToColumn(app.Scopes),
+		app.CreatedAt,
+		app.CreatedBy,
+		app.LastModifiedAt,
+		app.LastModifiedBy); err != nil {
+
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeDeveloperAppName, app.Name)
+		}
+		s.db.metrics.QueryFailed(developerAppsMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot update developer app '%s' (%s)", app.AppID, err))
+	}
+	return nil
+}
+
+// DeleteByID deletes a developer app, including its keys
+func (s *DeveloperAppStore) DeleteByID(organizationName, developerAppID string, precondition db.Precondition) types.Error {
+
+	query := "DELETE FROM developer_apps WHERE organization_name = ? AND app_id = ?"
+	if err := s.db.delete(query, precondition, organizationName, developerAppID); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeDeveloperAppName, developerAppID)
+		}
+		s.db.metrics.QueryFailed(developerAppsMetricLabel)
+		return types.NewDatabaseError(err)
+	}
+	return nil
+}
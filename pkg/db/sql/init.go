@@ -0,0 +1,352 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/pkg/shared"
+)
+
+// createTables adds required tables if they do not yet exist
+func (d *Database) createTables(logger *zap.Logger) error {
+
+	logger.Info("Creating all tables if not existing")
+	for _, query := range createTablesSQL {
+		logger.Debug("init database", zap.String("sql", query))
+		if err := d.exec(query); err != nil {
+			logger.Warn("init database statement failed", zap.Error(err))
+			return err
+		}
+	}
+	for _, column := range addedColumns {
+		if err := d.addColumn(column.table, column.name, column.definition); err != nil {
+			logger.Warn("init database add column failed", zap.Error(err))
+			return err
+		}
+	}
+	if err := d.createDefaultEntities(); err != nil {
+		logger.Warn("init database default entities failed", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Tables and indices created if not existing")
+	return nil
+}
+
+// addColumn adds a column to an existing table in case it does not exist yet
+func (d *Database) addColumn(table, column, definition string) error {
+
+	// Not all databases support "ADD COLUMN IF NOT EXISTS", hence we check ourselves
+	rows, err := d.SQLDB.Query("SELECT " + column + " FROM " + table + " LIMIT 0")
+	if err == nil {
+		return rows.Close()
+	}
+	return d.exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+}
+
+// createDefaultEntities adds default user 'admin', password 'passwd', role 'admin'
+// allowing GET, POST, PUT, PATCH, DELETE on /v1/** path
+func (d *Database) createDefaultEntities() error {
+
+	const (
+		adminPermissions         = `[{"methods":["GET","POST","DELETE","PUT","PATCH"],"paths":["/v1/**"]}]`
+		previousAdminPermissions = `[{"methods":["GET","POST","DELETE","PUT"],"paths":["/v1/**"]}]`
+	)
+
+	now := shared.GetCurrentTimeMilliseconds()
+
+	if err := d.exec(`INSERT INTO users (`+userColumns+`) VALUES(?,?,?,?,?,?,?,?,?) ON CONFLICT (name) DO NOTHING`,
+		"admin", "", "$2a$07$zWlw6WvswAFGZzNpBJg5qelwyg87NM/w4ypXP.NhfpuYmmv.WPyJO", "active", `["admin"]`, now, "initdb", now, "initdb"); err != nil {
+		return err
+	}
+	if err := d.exec(`INSERT INTO roles (`+roleColumns+`) VALUES(?,?,?,?,?,?,?) ON CONFLICT (name) DO NOTHING`,
+		"admin", "", adminPermissions, now, "initdb", now, "initdb"); err != nil {
+		return err
+	}
+	// Allow PATCH in case default admin role was created by a previous release
+	return d.exec(`UPDATE roles SET permissions = ? WHERE name = ? AND permissions = ?`,
+		adminPermissions, "admin", previousAdminPermissions)
+}
+
+// ShowCreateSchemaStatements show SQL statements to create all tables
+func ShowCreateSchemaStatements() {
+
+	for _, query := range createTablesSQL {
+		fmt.Printf("%s;\n\n", query)
+	}
+}
+
+// upsertQuery returns statement to insert a row, or update all columns of the row
+// in case a row with the same primary key already exists
+func upsertQuery(table, columns string, primaryKeyColumns ...string) string {
+
+	columnNames := strings.Split(columns, ",")
+	placeholders := make([]string, 0, len(columnNames))
+	updates := make([]string, 0, len(columnNames))
+	for i := range columnNames {
+		columnNames[i] = strings.TrimSpace(columnNames[i])
+		placeholders = append(placeholders, "?")
+		updates = append(updates, columnNames[i]+" = excluded."+columnNames[i])
+	}
+	return "INSERT INTO " + table + " (" + strings.Join(columnNames, ",") + ") VALUES(" +
+		strings.Join(placeholders, ",") + ") ON CONFLICT (" + strings.Join(primaryKeyColumns, ",") +
+		") DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+// Columns added to tables after their initial release, createTables adds them to tables
+// created by a previous release
+var addedColumns = []struct {
+	table      string
+	name       string
+	definition string
+}{
+	{"oauth_access_token", "code_challenge", "text"},
+	{"oauth_access_token", "code_challenge_method", "text"},
+}
+
+// Equivalent of the Cassandra schema, collection columns (sets, maps) are stored JSON-encoded as text.
+// All statements are supported by both PostgreSQL and SQLite.
+var createTablesSQL = [...]string{
+
+	`CREATE TABLE IF NOT EXISTS users (
+        name text,
+        display_name text,
+        password text,
+        status text,
+        roles text,
+        created_at bigint,
+        created_by text,
+        lastmodified_at bigint,
+        lastmodified_by text,
+        PRIMARY KEY (name)
+        )`,
+
+	`CREATE TABLE IF NOT EXISTS roles (
+        name text,
+        display_name text,
+        permissions text,
+        created_at bigint,
+        created_by text,
+        lastmodified_at bigint,
+        lastmodified_by text,
+        PRIMARY KEY (name)
+        )`,
+
+	`CREATE TABLE IF NOT EXISTS audits (
+        audit_id text PRIMARY KEY,
+        audit_type text,
+        app_id text,
+        developer_id text,
+        entity_id text,
+        entity_type text,
+        ipaddress text,
+        new_value text,
+        old_value text,
+        organization text,
+        request_id text,
+        role text,
+        timestamp bigint,
+        "user" text,
+        user_agent text
+        )`,
+
+	`CREATE INDEX IF NOT EXISTS audits_timestamp ON audits (timestamp)`,
+	`CREATE INDEX IF NOT EXISTS audits_entity_id ON audits (entity_id)`,
+	`CREATE INDEX IF NOT EXISTS audits_organization ON audits (organization)`,
+	`CREATE INDEX IF NOT EXISTS audits_developer_id ON audits (developer_id)`,
+	`CREATE INDEX IF NOT EXISTS audits_user ON audits ("user")`,
+
+	`CREATE TABLE IF NOT EXISTS changelog (
+        change_id text,
+        timestamp bigint,
+        operation text,
+        entity_type text,
+        organization text,
+        entity_id text,
+        PRIMARY KEY (change_id)
+        )`,
+
+	`CREATE INDEX IF NOT EXISTS changelog_timestamp ON changelog (timestamp)`,
+
+	`CREATE TABLE IF NOT EXISTS listeners (
+        attributes text,
+        created_at bigint,
+        created_by text,
+        display_name text,
+        lastmodified_at bigint,
+        lastmodified_by text,
+        name text,
+        policies text,
+        port integer,
+        route_group text,
+        virtual_hosts text,
+        PRIMARY KEY (name)
+        )`,
+
+	`CREATE TABLE IF NOT EXISTS routes (
+        attributes text,
+        created_at bigint,
+        created_by text,
+        display_name text,
+        lastmodified_at bigint,
+        lastmodified_by text,
+        name text,
+        path text,
+        path_type text,
+        route_group text,
+        PRIMARY KEY (name)
+        )`,
+
+	`CREATE TABLE IF NOT EXISTS clusters (
+        attributes text,
+        created_at bigint,
+        created_by text,
+        display_name text,
+        lastmodified_at bigint,
+        lastmodified_by text,
+        name text,
+        PRIMARY KEY (name)
+        )`,
+
+	`CREATE TABLE IF NOT EXISTS organizations (
+        attributes text,
+        created_at bigint,
+        created_by text,
+        display_name text,
+        lastmodified_at bigint,
+        lastmodified_by text,
+        name text,
+        PRIMARY KEY (name)
+        )`,
+
+	`CREATE TABLE IF NOT EXISTS companies (
+        attributes text,
+        created_at bigint,
+        created_by text,
+        display_name text,
+        key text,
+        lastmodified_at bigint,
+        lastmodified_by text,
+        name text,
+        organization_name text,
+        status text,
+        PRIMARY KEY (key)
+        )`,
+
+	`CREATE INDEX IF NOT EXISTS companies_organization_name ON companies (organization_name)`,
+
+	`CREATE TABLE IF NOT EXISTS company_developers (
+        company_name text,
+        email text,
+        organization_name text,
+        role text,
+        key text PRIMARY KEY
+        )`,
+
+	`CREATE INDEX IF NOT EXISTS company_developers_email ON company_developers (email)`,
+
+	`CREATE TABLE IF NOT EXISTS developers (
+        apps text,
+        attributes text,
+        created_at bigint,
+        created_by text,
+        developer_id text NOT NULL,
+        email text,
+        first_name text,
+        key text,
+        last_name text,
+        lastmodified_at bigint,
+        lastmodified_by text,
+        organization_name text NOT NULL,
+        status text,
+        user_name text,
+        PRIMARY KEY (key),
+        UNIQUE (organization_name, developer_id)
+        )`,
+
+	`CREATE INDEX IF NOT EXISTS developers_email ON developers (organization_name, email)`,
+
+	`CREATE TABLE IF NOT EXISTS developer_apps (
+        app_id text,
+        attributes text,
+        created_at bigint,
+        created_by text,
+        developer_id text NOT NULL,
+        display_name text,
+        lastmodified_at bigint,
+        lastmodified_by text,
+        name text,
+        organization_name text NOT NULL,
+        status text,
+        scopes text,
+        callback_url text,
+        PRIMARY KEY (app_id),
+        FOREIGN KEY (organization_name, developer_id)
+            REFERENCES developers (organization_name, developer_id) ON DELETE CASCADE
+        )`,
+
+	`CREATE INDEX IF NOT EXISTS developer_apps_developer_id ON developer_apps (organization_name, developer_id)`,
+
+	`CREATE TABLE IF NOT EXISTS keys (
+        api_products text,
+        attributes text,
+        consumer_key text,
+        consumer_secret text,
+        scopes text,
+        app_id text NOT NULL REFERENCES developer_apps (app_id) ON DELETE CASCADE,
+        expires_at bigint,
+        issued_at bigint,
+        organization_name text NOT NULL,
+        status text,
+        PRIMARY KEY (consumer_key)
+        )`,
+
+	`CREATE INDEX IF NOT EXISTS keys_app_id ON keys (app_id)`,
+
+	`CREATE TABLE IF NOT EXISTS oauth_access_token (
+        access text,
+        access_created_at bigint,
+        access_expires_in bigint,
+        client_id text,
+        code text,
+        code_created_at bigint,
+        code_expires_in bigint,
+        redirect_uri text,
+        refresh text,
+        refresh_created_at bigint,
+        refresh_expires_in bigint,
+        scope text,
+        user_id text,
+        code_challenge text,
+        code_challenge_method text,
+        inserted_at bigint,
+        PRIMARY KEY (access)
+        )`,
+
+	`CREATE INDEX IF NOT EXISTS oauth_access_token_refresh ON oauth_access_token (refresh)`,
+	`CREATE INDEX IF NOT EXISTS oauth_access_token_code ON oauth_access_token (code)`,
+	`CREATE INDEX IF NOT EXISTS oauth_access_token_client_id ON oauth_access_token (client_id)`,
+
+	`CREATE TABLE IF NOT EXISTS api_products (
+        approval_type text,
+        api_resources text,
+        attributes text,
+        created_at bigint,
+        created_by text,
+        description text,
+        display_name text,
+        key text,
+        lastmodified_at bigint,
+        lastmodified_by text,
+        organization_name text,
+        name text,
+        policies text,
+        route_group text,
+        scopes text,
+        PRIMARY KEY (key)
+        )`,
+
+	`CREATE INDEX IF NOT EXISTS api_products_organization_name ON api_products (organization_name)`,
+}
@@ -0,0 +1,178 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/db/cassandra"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+
+	// Prometheus label for metrics of db interactions
+	keysMetricLabel = "keys"
+
+	// List of key columns we use
+	keysColumn = `consumer_key,
+consumer_secret,
+organization_name,
+scopes,
+api_products,
+attributes,
+app_id,
+status,
+issued_at,
+expires_at`
+)
+
+// KeyStore holds our database config
+type KeyStore struct {
+	db *Database
+}
+
+// NewKeyStore creates key instance
+func NewKeyStore(database *Database) *KeyStore {
+	return &KeyStore{
+		db: database,
+	}
+}
+
+// GetByKey returns details of a single apikey,
+// in case organization is nil the key is looked up in all organizations
+func (s *KeyStore) GetByKey(organization, key *string) (*types.Key, types.Error) {
+
+	if key == nil {
+		return nil, types.NewBadRequestError(fmt.Errorf("no apikey provided"))
+	}
+
+	var keys types.Keys
+	var err error
+
+	if organization == nil {
+		query := "SELECT " + keysColumn + " FROM keys WHERE consumer_key = ? LIMIT 1"
+		keys, err = s.runGetKeyQuery(query, *key)
+	} else {
+		query := "SELECT " + keysColumn + " FROM keys WHERE organization_name = ? AND consumer_key = ? LIMIT 1"
+		keys, err = s.runGetKeyQuery(query, *organization, *key)
+	}
+	if err != nil {
+		s.db.metrics.QueryFailed(keysMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(keys) == 0 {
+		s.db.metrics.QueryNotFound(keysMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find apikey '%s'", *key))
+	}
+
+	s.db.metrics.QuerySuccessful(keysMetricLabel)
+	return &keys[0], nil
+}
+
+// GetByDeveloperAppID returns an array with apikey details of a developer app
+func (s *KeyStore) GetByDeveloperAppID(organization, developerAppID string) (types.Keys, types.Error) {
+
+	query := "SELECT " + keysColumn + " FROM keys WHERE organization_name = ? AND app_id = ? ORDER BY issued_at"
+	keys, err := s.runGetKeyQuery(query, organization, developerAppID)
+	if err != nil {
+		s.db.metrics.QueryFailed(keysMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+	s.db.metrics.QuerySuccessful(keysMetricLabel)
+	return keys, nil
+}
+
+// GetCountByAPIProductName counts the number of times an apiproduct has been assigned to keys
+func (s *KeyStore) GetCountByAPIProductName(organization, apiProductName string) (int, types.Error) {
+
+	query := "SELECT " + keysColumn + " FROM keys WHERE organization_name = ?"
+	keys, err := s.runGetKeyQuery(query, organization)
+	if err != nil {
+		s.db.metrics.QueryFailed(keysMetricLabel)
+		return 0, types.NewDatabaseError(err)
+	}
+	var count int
+	for _, key := range keys {
+		for _, product := range key.APIProducts {
+			if product.Apiproduct == apiProductName {
+				count++
+			}
+		}
+	}
+	s.db.metrics.QuerySuccessful(keysMetricLabel)
+	return count, nil
+}
+
+// runGetKeyQuery executes SQL query and returns resultset
+func (s *KeyStore) runGetKeyQuery(query string, queryParameters ...interface{}) (types.Keys, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	rows, err := s.db.query(query, queryParameters...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := types.Keys{}
+	for rows.Next() {
+		var k types.Key
+		var organizationName, scopes, apiProducts, attributes string
+		if err := rows.Scan(
+			&k.ConsumerKey,
+			&k.ConsumerSecret,
+			&organizationName,
+			&scopes,
+			&apiProducts,
+			&attributes,
+			&k.AppID,
+			&k.Status,
+			&k.IssuedAt,
+			&k.ExpiresAt); err != nil {
+			return nil, err
+		}
+		k.Scopes = columnToStringSlice(scopes)
+		k.APIProducts = cassandra.KeyAPIProductStatusesUnmarshal(apiProducts)
+		k.Attributes = columnToAttributes(attributes)
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// UpdateByKey UPSERTs keys in database
+func (s *KeyStore) UpdateByKey(organization string, k *types.Key) types.Error {
+
+	query := upsertQuery("keys", keysColumn, "consumer_key")
+	if err := s.db.exec(query,
+		k.ConsumerKey,
+		k.ConsumerSecret,
+		organization,
+		stringSliceToColumn(k.Scopes),
+		cassandra.KeyAPIProductStatusesMarshal(k.APIProducts),
+		attributesToColumn(k.Attributes),
+		k.AppID,
+		k.Status,
+		k.IssuedAt,
+		k.ExpiresAt); err != nil {
+
+		s.db.metrics.QueryFailed(keysMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot update key '%s' (%s)", k.ConsumerKey, err))
+	}
+	return nil
+}
+
+// DeleteByKey deletes keys
+func (s *KeyStore) DeleteByKey(organization, consumerKey string) types.Error {
+
+	query := "DELETE FROM keys WHERE organization_name = ? AND consumer_key = ?"
+	if err := s.db.exec(query, organization, consumerKey); err != nil {
+		s.db.metrics.QueryFailed(keysMetricLabel)
+		return types.NewDatabaseError(err)
+	}
+	return nil
+}
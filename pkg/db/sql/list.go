@@ -0,0 +1,71 @@
+package sql
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// listColumns holds the columns list parameters are applied to
+type listColumns struct {
+	// Column to order and paginate on, must hold a unique value within an organization
+	order string
+	// Column to filter status on, empty if entity has no status
+	status string
+	// Column to filter email prefix on, empty if entity has no email address
+	email string
+}
+
+// listQuery extends a select query with filter and pagination clauses of list parameters.
+// One more row than the page size is requested to detect whether a next page exists.
+func listQuery(query string, queryParameters []interface{}, columns listColumns,
+	params db.ListParams) (string, []interface{}, types.Error) {
+
+	lastKey, err := db.DecodePageToken(params.PageToken)
+	if err != nil {
+		return "", nil, err
+	}
+	if lastKey != nil {
+		query += " AND " + columns.order + " > ?"
+		queryParameters = append(queryParameters, string(lastKey))
+	}
+	if params.Status != "" && columns.status != "" {
+		query += " AND " + columns.status + " = ?"
+		queryParameters = append(queryParameters, params.Status)
+	}
+	if params.EmailPrefix != "" && columns.email != "" {
+		query += " AND " + columns.email + ` LIKE ? ESCAPE '\'`
+		queryParameters = append(queryParameters, escapeLike(params.EmailPrefix)+"%")
+	}
+	if params.LastModifiedFrom != 0 {
+		query += " AND lastmodified_at >= ?"
+		queryParameters = append(queryParameters, params.LastModifiedFrom)
+	}
+	if params.LastModifiedTo != 0 {
+		query += " AND lastmodified_at <= ?"
+		queryParameters = append(queryParameters, params.LastModifiedTo)
+	}
+	query += " ORDER BY " + columns.order
+	if params.PageSize > 0 {
+		query += " LIMIT " + strconv.Itoa(params.PageSize+1)
+	}
+	return query, queryParameters, nil
+}
+
+// listPage returns number of retrieved entities on requested page, and token of next page
+// which holds the order column value of the last entity on the page
+func listPage(retrieved int, params db.ListParams, orderValueOf func(i int) string) (int, string) {
+
+	if params.PageSize <= 0 || retrieved <= params.PageSize {
+		return retrieved, ""
+	}
+	return params.PageSize, db.EncodePageToken([]byte(orderValueOf(params.PageSize - 1)))
+}
+
+// escapeLike escapes wildcard characters of a LIKE pattern
+func escapeLike(s string) string {
+
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
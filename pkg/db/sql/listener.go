@@ -0,0 +1,153 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of listener columns we use
+	listenerColumns = `name,
+display_name,
+virtual_hosts,
+port,
+route_group,
+policies,
+attributes,
+created_at,
+created_by,
+lastmodified_at,
+lastmodified_by`
+
+	// Prometheus label for metrics of db interactions
+	listenerMetricLabel = "listeners"
+)
+
+// ListenerStore holds our ListenerStore config
+type ListenerStore struct {
+	db *Database
+}
+
+// NewListenerStore creates listener instance
+func NewListenerStore(database *Database) *ListenerStore {
+	return &ListenerStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all listeners
+func (s *ListenerStore) GetAll() (types.Listeners, types.Error) {
+
+	query := "SELECT " + listenerColumns + " FROM listeners ORDER BY name"
+	listeners, err := s.runGetListenerQuery(query)
+	if err != nil {
+		s.db.metrics.QueryFailed(listenerMetricLabel)
+		return types.NullListeners, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(listenerMetricLabel)
+	return listeners, nil
+}
+
+// Get retrieves a listener
+func (s *ListenerStore) Get(listenerName string) (*types.Listener, types.Error) {
+
+	query := "SELECT " + listenerColumns + " FROM listeners WHERE name = ? LIMIT 1"
+	listeners, err := s.runGetListenerQuery(query, listenerName)
+	if err != nil {
+		s.db.metrics.QueryFailed(listenerMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(listeners) == 0 {
+		s.db.metrics.QueryNotFound(listenerMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find listener '%s'", listenerName))
+	}
+
+	s.db.metrics.QuerySuccessful(listenerMetricLabel)
+	return &listeners[0], nil
+}
+
+// runGetListenerQuery executes SQL query and returns resultset
+func (s *ListenerStore) runGetListenerQuery(query string,
+	queryParameters ...interface{}) (types.Listeners, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	rows, err := s.db.query(query, queryParameters...)
+	if err != nil {
+		return types.NullListeners, err
+	}
+	defer rows.Close()
+
+	listeners := types.Listeners{}
+	for rows.Next() {
+		var l types.Listener
+		var virtualHosts, policies, attributes string
+		if err := rows.Scan(
+			&l.Name,
+			&l.DisplayName,
+			&virtualHosts,
+			&l.Port,
+			&l.RouteGroup,
+			&policies,
+			&attributes,
+			&l.CreatedAt,
+			&l.CreatedBy,
+			&l.LastModifiedAt,
+			&l.LastModifiedBy); err != nil {
+			return types.NullListeners, err
+		}
+		l.VirtualHosts = columnToStringSlice(virtualHosts)
+		l.Policies = columnToPolicies(policies)
+		l.Attributes = columnToAttributes(attributes)
+		listeners = append(listeners, l)
+	}
+	return listeners, rows.Err()
+}
+
+// Update UPSERTs a listener
+func (s *ListenerStore) Update(l *types.Listener, precondition db.Precondition) types.Error {
+
+	if err := s.db.upsert("listeners", listenerColumns, "name", precondition,
+		l.Name,
+		l.DisplayName,
+		stringSliceToColumn(l.VirtualHosts),
+		l.Port,
+		l.RouteGroup,
+		policiesToColumn(l.Policies),
+		attributesToColumn(l.Attributes),
+		l.CreatedAt,
+		l.CreatedBy,
+		l.LastModifiedAt,
+		l.LastModifiedBy); err != nil {
+
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeListenerName, l.Name)
+		}
+		s.db.metrics.QueryFailed(listenerMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot update listener '%s' (%s)", l.Name, err))
+	}
+	return nil
+}
+
+// Delete deletes a listener
+func (s *ListenerStore) Delete(listenerToDelete string, precondition db.Precondition) types.Error {
+
+	query := "DELETE FROM listeners WHERE name = ?"
+	if err := s.db.delete(query, precondition, listenerToDelete); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeListenerName, listenerToDelete)
+		}
+		s.db.metrics.QueryFailed(listenerMetricLabel)
+		return types.NewDatabaseError(err)
+	}
+	return nil
+}
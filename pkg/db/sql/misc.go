@@ -0,0 +1,94 @@
+package sql
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+// primaryKey generates primary key of entities stored per organization,
+// we combine organization and name to make them unique per organization
+func primaryKey(organization, name string) string {
+
+	return fmt.Sprintf("%s@@@%s", organization, name)
+}
+
+// Collection types (sets, maps) are not supported by all SQL databases,
+// we store them JSON-encoded in a text column instead.
+
+// stringSliceToColumn converts []string into a JSON-encoded column value
+func stringSliceToColumn(s []string) string {
+
+	if s == nil {
+		s = []string{}
+	}
+	return valueToJSON(s)
+}
+
+// columnToStringSlice converts a JSON-encoded column value into []string
+func columnToStringSlice(columnValue string) []string {
+
+	s := []string{}
+	if columnValue != "" {
+		_ = json.Unmarshal([]byte(columnValue), &s)
+	}
+	return s
+}
+
+// attributesToColumn converts attributes into a JSON-encoded column value
+func attributesToColumn(attributes types.Attributes) string {
+
+	if attributes == nil {
+		attributes = types.NullAttributes
+	}
+	return valueToJSON(attributes)
+}
+
+// columnToAttributes converts a JSON-encoded column value into attributes
+func columnToAttributes(columnValue string) types.Attributes {
+
+	attributes := types.Attributes{}
+	if columnValue != "" {
+		_ = json.Unmarshal([]byte(columnValue), &attributes)
+	}
+	return attributes
+}
+
+// policiesToColumn converts policies into a JSON-encoded column value
+func policiesToColumn(policies types.Policies) string {
+
+	if len(policies) == 0 {
+		return ""
+	}
+	return valueToJSON(policies)
+}
+
+// columnToPolicies converts a JSON-encoded column value into policies
+func columnToPolicies(columnValue string) types.Policies {
+
+	policies, err := types.ParsePolicies(columnValue)
+	if err != nil {
+		return types.NullPolicies
+	}
+	return policies
+}
+
+// columnToMapString converts a JSON-encoded column value into map[string]interface
+func columnToMapString(columnValue string) map[string]interface{} {
+
+	m := map[string]interface{}{}
+	if columnValue != "" {
+		_ = json.Unmarshal([]byte(columnValue), &m)
+	}
+	return m
+}
+
+func valueToJSON(s interface{}) string {
+
+	jsonEncoded, err := json.Marshal(s)
+	if err == nil {
+		return string(jsonEncoded)
+	}
+	return ""
+}
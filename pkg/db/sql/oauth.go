@@ -0,0 +1,204 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// Prometheus label for metrics of db interactions
+	oauthMetricLabel = "oauth"
+	// Default TTL for OAuth token row in seconds, expired rows are removed when a new token is stored
+	defaultOAuthtokenTTL = 86400
+
+	// List of columns we use
+	oauthColumns = `client_id,
+user_id,
+redirect_uri,
+scope,
+code,
+code_created_at,
+code_expires_in,
+access,
+access_created_at,
+access_expires_in,
+refresh,
+refresh_created_at,
+refresh_expires_in,
+code_challenge,
+code_challenge_method`
+)
+
+// OAuthStore holds our database config
+type OAuthStore struct {
+	db *Database
+}
+
+// NewOAuthStore creates oauth instance
+func NewOAuthStore(database *Database) *OAuthStore {
+	return &OAuthStore{
+		db: database,
+	}
+}
+
+// OAuthAccessTokenGetByAccess retrieves an access token
+func (s *OAuthStore) OAuthAccessTokenGetByAccess(accessToken string) (*types.OAuthAccessToken, error) {
+
+	query := "SELECT " + oauthColumns + " FROM oauth_access_token WHERE access = ? AND inserted_at >= ? LIMIT 1"
+	return s.runGetOAuthAccessTokenQuery(query, accessToken)
+}
+
+// OAuthAccessTokenGetByCode retrieves token by code
+func (s *OAuthStore) OAuthAccessTokenGetByCode(code string) (*types.OAuthAccessToken, error) {
+
+	query := "SELECT " + oauthColumns + " FROM oauth_access_token WHERE code = ? AND inserted_at >= ? LIMIT 1"
+	return s.runGetOAuthAccessTokenQuery(query, code)
+}
+
+// OAuthAccessTokenGetByRefresh retrieves token by refreshcode
+func (s *OAuthStore) OAuthAccessTokenGetByRefresh(refresh string) (*types.OAuthAccessToken, error) {
+
+	query := "SELECT " + oauthColumns + " FROM oauth_access_token WHERE refresh = ? AND inserted_at >= ? LIMIT 1"
+	return s.runGetOAuthAccessTokenQuery(query, refresh)
+}
+
+// OAuthAccessTokenGetByClientID retrieves all tokens issued to a client
+func (s *OAuthStore) OAuthAccessTokenGetByClientID(clientID string) (types.OAuthAccessTokens, error) {
+
+	query := "SELECT " + oauthColumns + " FROM oauth_access_token WHERE client_id = ? AND inserted_at >= ?"
+	return s.runGetOAuthAccessTokensQuery(query, clientID)
+}
+
+// runGetOAuthAccessTokenQuery executes SQL query and returns first token of resultset
+func (s *OAuthStore) runGetOAuthAccessTokenQuery(query, queryParameter string) (*types.OAuthAccessToken, error) {
+
+	tokens, err := s.runGetOAuthAccessTokensQuery(query, queryParameter)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("cannot find token")
+	}
+	return &tokens[0], nil
+}
+
+// runGetOAuthAccessTokensQuery executes SQL query and returns resultset,
+// rows which have exceeded their TTL are ignored
+func (s *OAuthStore) runGetOAuthAccessTokensQuery(query, queryParameter string) (types.OAuthAccessTokens, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	rows, err := s.db.query(query, queryParameter, s.oldestValidInsertTime())
+	if err != nil {
+		s.db.metrics.QueryFailed(oauthMetricLabel)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := types.OAuthAccessTokens{}
+	for rows.Next() {
+		var t types.OAuthAccessToken
+		if err := rows.Scan(
+			&t.ClientID,
+			&t.UserID,
+			&t.RedirectURI,
+			&t.Scope,
+			&t.Code,
+			&t.CodeCreatedAt,
+			&t.CodeExpiresIn,
+			&t.Access,
+			&t.AccessCreatedAt,
+			&t.AccessExpiresIn,
+			&t.Refresh,
+			&t.RefreshCreatedAt,
+			&t.RefreshExpiresIn,
+			&t.CodeChallenge,
+			&t.CodeChallengeMethod); err != nil {
+			s.db.metrics.QueryFailed(oauthMetricLabel)
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	if err := rows.Err(); err != nil {
+		s.db.metrics.QueryFailed(oauthMetricLabel)
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		s.db.metrics.QueryNotFound(oauthMetricLabel)
+		return tokens, nil
+	}
+	s.db.metrics.QuerySuccessful(oauthMetricLabel)
+
+	return tokens, nil
+}
+
+// OAuthAccessTokenCreate UPSERTs a token in database
+func (s *OAuthStore) OAuthAccessTokenCreate(t *types.OAuthAccessToken) error {
+
+	// SQL databases do not support a row time-to-live, hence we remove expired rows ourselves.
+	//
+	// OAuth packages will check CreatedAt + ExpiresIn to check validity of a retrieved token,
+	// but does not actively delete from database.
+	if err := s.db.exec("DELETE FROM oauth_access_token WHERE inserted_at < ?",
+		s.oldestValidInsertTime()); err != nil {
+		return fmt.Errorf("cannot remove expired access tokens (%s)", err)
+	}
+
+	query := upsertQuery("oauth_access_token", oauthColumns+",inserted_at", "access")
+	if err := s.db.exec(query,
+		t.ClientID,
+		t.UserID,
+		t.RedirectURI,
+		t.Scope,
+		t.Code,
+		t.CodeCreatedAt,
+		t.CodeExpiresIn,
+		t.Access,
+		t.AccessCreatedAt,
+		t.AccessExpiresIn,
+		t.Refresh,
+		t.RefreshCreatedAt,
+		t.RefreshExpiresIn,
+		t.CodeChallenge,
+		t.CodeChallengeMethod,
+		shared.GetCurrentTimeMilliseconds()); err != nil {
+
+		return fmt.Errorf("cannot update access token '%s' (%s)", t.Access, err)
+	}
+	return nil
+}
+
+// OAuthAccessTokenRemoveByAccess deletes an access token
+func (s *OAuthStore) OAuthAccessTokenRemoveByAccess(accessTokenToDelete string) error {
+
+	query := "DELETE FROM oauth_access_token WHERE access = ?"
+
+	return s.db.exec(query, accessTokenToDelete)
+}
+
+// OAuthAccessTokenRemoveByCode deletes an access token
+func (s *OAuthStore) OAuthAccessTokenRemoveByCode(codeToDelete string) error {
+
+	query := "DELETE FROM oauth_access_token WHERE code = ?"
+
+	return s.db.exec(query, codeToDelete)
+}
+
+// OAuthAccessTokenRemoveByRefresh deletes an access token
+func (s *OAuthStore) OAuthAccessTokenRemoveByRefresh(refreshToDelete string) error {
+
+	query := "DELETE FROM oauth_access_token WHERE refresh = ?"
+
+	return s.db.exec(query, refreshToDelete)
+}
+
+// oldestValidInsertTime returns insert time in milliseconds of oldest token row not yet expired
+func (s *OAuthStore) oldestValidInsertTime() int64 {
+
+	return shared.GetCurrentTimeMilliseconds() - defaultOAuthtokenTTL*1000
+}
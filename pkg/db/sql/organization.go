@@ -0,0 +1,138 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of organization columns we use
+	organizationColumns = `name,
+display_name,
+attributes,
+created_at,
+created_by,
+lastmodified_at,
+lastmodified_by`
+
+	// Prometheus label for metrics of db interactions
+	organizationMetricLabel = "organizations"
+)
+
+// OrganizationStore holds our database config
+type OrganizationStore struct {
+	db *Database
+}
+
+// NewOrganizationStore creates organization instance
+func NewOrganizationStore(database *Database) *OrganizationStore {
+	return &OrganizationStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all organizations
+func (s *OrganizationStore) GetAll() (types.Organizations, types.Error) {
+
+	query := "SELECT " + organizationColumns + " FROM organizations ORDER BY name"
+	organizations, err := s.runGetOrganizationQuery(query)
+	if err != nil {
+		s.db.metrics.QueryFailed(organizationMetricLabel)
+		return types.NullOrganizations, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(organizationMetricLabel)
+	return organizations, nil
+}
+
+// Get retrieves a organization from database
+func (s *OrganizationStore) Get(organizationName string) (*types.Organization, types.Error) {
+
+	query := "SELECT " + organizationColumns + " FROM organizations WHERE name = ? LIMIT 1"
+	organizations, err := s.runGetOrganizationQuery(query, organizationName)
+	if err != nil {
+		s.db.metrics.QueryFailed(organizationMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(organizations) == 0 {
+		s.db.metrics.QueryNotFound(organizationMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find organization '%s'", organizationName))
+	}
+
+	s.db.metrics.QuerySuccessful(organizationMetricLabel)
+	return &organizations[0], nil
+}
+
+// runGetOrganizationQuery executes SQL query and returns resultset
+func (s *OrganizationStore) runGetOrganizationQuery(query string, queryParameters ...interface{}) (types.Organizations, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	rows, err := s.db.query(query, queryParameters...)
+	if err != nil {
+		return types.NullOrganizations, err
+	}
+	defer rows.Close()
+
+	organizations := types.Organizations{}
+	for rows.Next() {
+		var c types.Organization
+		var attributes string
+		if err := rows.Scan(
+			&c.Name,
+			&c.DisplayName,
+			&attributes,
+			&c.CreatedAt,
+			&c.CreatedBy,
+			&c.LastModifiedAt,
+			&c.LastModifiedBy); err != nil {
+			return types.NullOrganizations, err
+		}
+		c.Attributes = columnToAttributes(attributes)
+		organizations = append(organizations, c)
+	}
+	return organizations, rows.Err()
+}
+
+// Update UPSERTs a organization in database
+func (s *OrganizationStore) Update(c *types.Organization, precondition db.Precondition) types.Error {
+
+	if err := s.db.upsert("organizations", organizationColumns, "name", precondition,
+		c.Name,
+		c.DisplayName,
+		attributesToColumn(c.Attributes),
+		c.CreatedAt,
+		c.CreatedBy,
+		c.LastModifiedAt,
+		c.LastModifiedBy); err != nil {
+
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeOrganizationName, c.Name)
+		}
+		s.db.metrics.QueryFailed(organizationMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot update organization '%s' (%s)", c.Name, err))
+	}
+	return nil
+}
+
+// Delete deletes a organization
+func (s *OrganizationStore) Delete(organizationToDelete string, precondition db.Precondition) types.Error {
+
+	query := "DELETE FROM organizations WHERE name = ?"
+	if err := s.db.delete(query, precondition, organizationToDelete); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeOrganizationName, organizationToDelete)
+		}
+		s.db.metrics.QueryFailed(organizationMetricLabel)
+		return types.NewDatabaseError(err)
+	}
+	return nil
+}
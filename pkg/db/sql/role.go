@@ -0,0 +1,139 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/db/cassandra"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of role columns we use
+	roleColumns = `name,
+display_name,
+permissions,
+created_at,
+created_by,
+lastmodified_at,
+lastmodified_by`
+
+	// Prometheus label for metrics of db interactions
+	roleMetricLabel = "roles"
+)
+
+// RoleStore holds our database config
+type RoleStore struct {
+	db *Database
+}
+
+// NewRoleStore creates role instance
+func NewRoleStore(database *Database) *RoleStore {
+	return &RoleStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all roles
+func (s *RoleStore) GetAll() (types.Roles, types.Error) {
+
+	query := "SELECT " + roleColumns + " FROM roles ORDER BY name"
+	roles, err := s.runGetRoleQuery(query)
+	if err != nil {
+		s.db.metrics.QueryFailed(roleMetricLabel)
+		return types.NullRoles, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(roleMetricLabel)
+	return roles, nil
+}
+
+// Get retrieves a role from database
+func (s *RoleStore) Get(roleName string) (*types.Role, types.Error) {
+
+	query := "SELECT " + roleColumns + " FROM roles WHERE name = ? LIMIT 1"
+	roles, err := s.runGetRoleQuery(query, roleName)
+	if err != nil {
+		s.db.metrics.QueryFailed(roleMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(roles) == 0 {
+		s.db.metrics.QueryNotFound(roleMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find role '%s'", roleName))
+	}
+
+	s.db.metrics.QuerySuccessful(roleMetricLabel)
+	return &roles[0], nil
+}
+
+// runGetRoleQuery executes SQL query and returns resultset
+func (s *RoleStore) runGetRoleQuery(query string, queryParameters ...interface{}) (types.Roles, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	rows, err := s.db.query(query, queryParameters...)
+	if err != nil {
+		return types.NullRoles, err
+	}
+	defer rows.Close()
+
+	roles := types.Roles{}
+	for rows.Next() {
+		var r types.Role
+		var permissions string
+		if err := rows.Scan(
+			&r.Name,
+			&r.DisplayName,
+			&permissions,
+			&r.CreatedAt,
+			&r.CreatedBy,
+			&r.LastModifiedAt,
+			&r.LastModifiedBy); err != nil {
+			return types.NullRoles, err
+		}
+		r.Permissions = cassandra.PermissionsUnmarshal(permissions)
+		roles = append(roles, r)
+	}
+	return roles, rows.Err()
+}
+
+// Update UPSERTs an role in database
+func (s *RoleStore) Update(c *types.Role, precondition db.Precondition) types.Error {
+
+	if err := s.db.upsert("roles", roleColumns, "name", precondition,
+		c.Name,
+		c.DisplayName,
+		cassandra.PermissionsMarshal(c.Permissions),
+		c.CreatedAt,
+		c.CreatedBy,
+		c.LastModifiedAt,
+		c.LastModifiedBy); err != nil {
+
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeRoleName, c.Name)
+		}
+		s.db.metrics.QueryFailed(roleMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot update role '%s' (%s)", c.Name, err))
+	}
+	return nil
+}
+
+// Delete deletes a role
+func (s *RoleStore) Delete(roleToDelete string, precondition db.Precondition) types.Error {
+
+	query := "DELETE FROM roles WHERE name = ?"
+	if err := s.db.delete(query, precondition, roleToDelete); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeRoleName, roleToDelete)
+		}
+		s.db.metrics.QueryFailed(roleMetricLabel)
+		return types.NewDatabaseError(err)
+	}
+	return nil
+}
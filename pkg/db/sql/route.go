@@ -0,0 +1,147 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of route columns we use
+	routeColumns = `name,
+display_name,
+route_group,
+path,
+path_type,
+attributes,
+created_at,
+created_by,
+lastmodified_at,
+lastmodified_by`
+
+	// Prometheus label for metrics of db interactions
+	routeMetricLabel = "routes"
+)
+
+// RouteStore holds our route config
+type RouteStore struct {
+	db *Database
+}
+
+// NewRouteStore creates route instance
+func NewRouteStore(database *Database) *RouteStore {
+	return &RouteStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all routes
+func (s *RouteStore) GetAll() (types.Routes, types.Error) {
+
+	query := "SELECT " + routeColumns + " FROM routes ORDER BY name"
+	routes, err := s.runGetRouteQuery(query)
+	if err != nil {
+		s.db.metrics.QueryFailed(routeMetricLabel)
+		return types.NullRoutes, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(routeMetricLabel)
+	return routes, nil
+}
+
+// Get retrieves a route
+func (s *RouteStore) Get(routeName string) (*types.Route, types.Error) {
+
+	query := "SELECT " + routeColumns + " FROM routes WHERE name = ? LIMIT 1"
+	routes, err := s.runGetRouteQuery(query, routeName)
+	if err != nil {
+		s.db.metrics.QueryFailed(routeMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(routes) == 0 {
+		s.db.metrics.QueryNotFound(routeMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find route '%s'", routeName))
+	}
+
+	s.db.metrics.QuerySuccessful(routeMetricLabel)
+	return &routes[0], nil
+}
+
+// runGetRouteQuery executes SQL query and returns resultset
+func (s *RouteStore) runGetRouteQuery(query string, queryParameters ...interface{}) (types.Routes, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	rows, err := s.db.query(query, queryParameters...)
+	if err != nil {
+		return types.NullRoutes, err
+	}
+	defer rows.Close()
+
+	routes := types.Routes{}
+	for rows.Next() {
+		var r types.Route
+		var attributes string
+		if err := rows.Scan(
+			&r.Name,
+			&r.DisplayName,
+			&r.RouteGroup,
+			&r.Path,
+			&r.PathType,
+			&attributes,
+			&r.CreatedAt,
+			&r.CreatedBy,
+			&r.LastModifiedAt,
+			&r.LastModifiedBy); err != nil {
+			return types.NullRoutes, err
+		}
+		r.Attributes = columnToAttributes(attributes)
+		routes = append(routes, r)
+	}
+	return routes, rows.Err()
+}
+
+// Update UPSERTs a route
+func (s *RouteStore) Update(r *types.Route, precondition db.Precondition) types.Error {
+
+	if err := s.db.upsert("routes", routeColumns, "name", precondition,
+		r.Name,
+		r.DisplayName,
+		r.RouteGroup,
+		r.Path,
+		r.PathType,
+		attributesToColumn(r.Attributes),
+		r.CreatedAt,
+		r.CreatedBy,
+		r.LastModifiedAt,
+		r.LastModifiedBy); err != nil {
+
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeRouteName, r.Name)
+		}
+		s.db.metrics.QueryFailed(routeMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot update route '%s' (%s)", r.Name, err))
+	}
+	return nil
+}
+
+// Delete deletes a route
+func (s *RouteStore) Delete(routeToDelete string, precondition db.Precondition) types.Error {
+
+	query := "DELETE FROM routes WHERE name = ?"
+	if err := s.db.delete(query, precondition, routeToDelete); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeRouteName, routeToDelete)
+		}
+		s.db.metrics.QueryFailed(routeMetricLabel)
+		return types.NewDatabaseError(err)
+	}
+	return nil
+}
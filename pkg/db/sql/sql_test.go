@@ -0,0 +1,390 @@
+package sql
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/shared"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+func newTestDatabase(t *testing.T) *db.Database {
+
+	// Prometheus metrics can only be registered once per name, so each test uses its own namespace
+	database := Database{
+		driver:  DriverSQLite,
+		metrics: metricsCollection{},
+	}
+	database.metrics.register(t.Name(), DriverSQLite)
+
+	var err error
+	database.SQLDB, err = connect(DatabaseConfig{
+		Driver: DriverSQLite,
+		DSN:    "file::memory:",
+	}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { database.SQLDB.Close() })
+
+	require.NoError(t, database.createTables(zap.NewNop()))
+
+	return &db.Database{
+		Listener:     NewListenerStore(&database),
+		Route:        NewRouteStore(&database),
+		Developer:    NewDeveloperStore(&database),
+		DeveloperApp: NewDeveloperAppStore(&database),
+		Key:          NewKeyStore(&database),
+		User:         NewUserStore(&database),
+		Role:         NewRoleStore(&database),
+		OAuth:        NewOAuthStore(&database),
+		Audit:        NewAuditStore(&database),
+		ChangeLog:    NewChangeLogStore(&database),
+	}
+}
+
+func Test_rebind(t *testing.T) {
+
+	d := Database{driver: DriverPostgres}
+	require.Equal(t, "SELECT a FROM t WHERE b = $1 AND c = $2",
+		d.rebind("SELECT a FROM t WHERE b = ? AND c = ?"))
+
+	d = Database{driver: DriverSQLite}
+	require.Equal(t, "SELECT a FROM t WHERE b = ?",
+		d.rebind("SELECT a FROM t WHERE b = ?"))
+}
+
+func Test_DefaultEntities(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	user, err := database.User.Get("admin")
+	require.Nil(t, err)
+	require.Equal(t, []string{"admin"}, user.Roles)
+
+	role, err := database.Role.Get("admin")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(role.Permissions))
+}
+
+func Test_DeveloperAppKey_ForeignKeys(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	require.Nil(t, database.Developer.Update("org1", &types.Developer{
+		DeveloperID: "dev1", Email: "joe@example.com"}, db.Precondition{}))
+	require.Nil(t, database.Developer.Update("org2", &types.Developer{
+		DeveloperID: "dev2", Email: "joe@example.com"}, db.Precondition{}))
+
+	// A developer app must belong to an existing developer in the same organization
+	require.NotNil(t, database.DeveloperApp.Update("org2", &types.DeveloperApp{
+		AppID: "app0", DeveloperID: "dev1", Name: "shop"}, db.Precondition{}))
+
+	require.Nil(t, database.DeveloperApp.Update("org1", &types.DeveloperApp{
+		AppID: "app1", DeveloperID: "dev1", Name: "shop"}, db.Precondition{}))
+	require.Nil(t, database.DeveloperApp.Update("org1", &types.DeveloperApp{
+		AppID: "app2", DeveloperID: "dev1", Name: "mobile"}, db.Precondition{}))
+	require.Nil(t, database.DeveloperApp.Update("org2", &types.DeveloperApp{
+		AppID: "app3", DeveloperID: "dev2", Name: "shop"}, db.Precondition{}))
+
+	// A key must belong to an existing developer app
+	require.NotNil(t, database.Key.UpdateByKey("org1", &types.Key{
+		ConsumerKey: "k0", AppID: "unknown"}))
+
+	require.Nil(t, database.Key.UpdateByKey("org1", &types.Key{
+		ConsumerKey: "k1", AppID: "app1", APIProducts: types.KeyAPIProductStatuses{
+			{Apiproduct: "p1"}, {Apiproduct: "p2"}}}))
+	require.Nil(t, database.Key.UpdateByKey("org1", &types.Key{
+		ConsumerKey: "k2", AppID: "app2", APIProducts: types.KeyAPIProductStatuses{
+			{Apiproduct: "p1"}}}))
+
+	app, err := database.DeveloperApp.GetByName("org2", "joe@example.com", "shop")
+	require.Nil(t, err)
+	require.Equal(t, "app3", app.AppID)
+
+	count, err := database.DeveloperApp.GetCountByDeveloperID("org1", "dev1")
+	require.Nil(t, err)
+	require.Equal(t, 2, count)
+
+	count, err = database.Key.GetCountByAPIProductName("org1", "p1")
+	require.Nil(t, err)
+	require.Equal(t, 2, count)
+
+	// Updating an existing developer must not remove its developer apps
+	require.Nil(t, database.Developer.Update("org1", &types.Developer{
+		DeveloperID: "dev1", Email: "joe@example.com", FirstName: "Joe"}, db.Precondition{}))
+	developer, err := database.Developer.GetByEmail("org1", "joe@example.com")
+	require.Nil(t, err)
+	require.Equal(t, "Joe", developer.FirstName)
+	require.Equal(t, "org1", developer.OrganizationName)
+
+	apps, err := database.DeveloperApp.GetAllByDeveloperID("org1", "dev1")
+	require.Nil(t, err)
+	require.Equal(t, 2, len(apps))
+
+	// Deleting a developer removes its developer apps and keys
+	require.Nil(t, database.Developer.DeleteByID("org1", "dev1", db.Precondition{}))
+
+	apps, err = database.DeveloperApp.GetAllByDeveloperID("org1", "dev1")
+	require.Nil(t, err)
+	require.Equal(t, 0, len(apps))
+
+	consumerKey := "k1"
+	_, err = database.Key.GetByKey(nil, &consumerKey)
+	require.Equal(t, http.StatusNotFound, types.HTTPStatusCode(err))
+
+	_, err = database.DeveloperApp.GetByID("org2", "app3")
+	require.Nil(t, err)
+}
+
+func Test_OAuth(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	now := shared.GetCurrentTimeMilliseconds()
+	require.NoError(t, database.OAuth.OAuthAccessTokenCreate(&types.OAuthAccessToken{
+		ClientID: "client1", Access: "access1", AccessCreatedAt: now, AccessExpiresIn: 3600}))
+
+	token, err := database.OAuth.OAuthAccessTokenGetByAccess("access1")
+	require.NoError(t, err)
+	require.Equal(t, "client1", token.ClientID)
+
+	require.NoError(t, database.OAuth.OAuthAccessTokenRemoveByAccess("access1"))
+	_, err = database.OAuth.OAuthAccessTokenGetByAccess("access1")
+	require.Error(t, err)
+
+	require.NoError(t, database.OAuth.OAuthAccessTokenCreate(&types.OAuthAccessToken{
+		ClientID: "client1", Access: "code:code1", Code: "code1", CodeCreatedAt: now,
+		CodeChallenge: "challenge", CodeChallengeMethod: "S256"}))
+	token, err = database.OAuth.OAuthAccessTokenGetByCode("code1")
+	require.NoError(t, err)
+	require.Equal(t, "challenge", token.CodeChallenge)
+	require.Equal(t, "S256", token.CodeChallengeMethod)
+
+	require.NoError(t, database.OAuth.OAuthAccessTokenCreate(&types.OAuthAccessToken{
+		ClientID: "client2", Access: "access2", AccessCreatedAt: now, AccessExpiresIn: 3600}))
+	tokens, err := database.OAuth.OAuthAccessTokenGetByClientID("client1")
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Equal(t, "code:code1", tokens[0].Access)
+	tokens, err = database.OAuth.OAuthAccessTokenGetByClientID("client3")
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+}
+
+func Test_createTables_AddsColumns(t *testing.T) {
+
+	database := Database{
+		driver:  DriverSQLite,
+		metrics: metricsCollection{},
+	}
+	var err error
+	database.SQLDB, err = connect(DatabaseConfig{
+		Driver: DriverSQLite,
+		DSN:    "file::memory:",
+	}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { database.SQLDB.Close() })
+
+	// Table as created by a previous release
+	require.NoError(t, database.exec(`CREATE TABLE oauth_access_token (
+        access text,
+        access_created_at bigint,
+        access_expires_in bigint,
+        client_id text,
+        code text,
+        code_created_at bigint,
+        code_expires_in bigint,
+        redirect_uri text,
+        refresh text,
+        refresh_created_at bigint,
+        refresh_expires_in bigint,
+        scope text,
+        user_id text,
+        inserted_at bigint,
+        PRIMARY KEY (access)
+        )`))
+
+	// Running twice should not fail on columns which already exist
+	require.NoError(t, database.createTables(zap.NewNop()))
+	require.NoError(t, database.createTables(zap.NewNop()))
+
+	rows, err := database.SQLDB.Query("SELECT code_challenge, code_challenge_method FROM oauth_access_token")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+}
+
+func Test_Audit_FilterParams(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	for timestamp := int64(1); timestamp <= 10; timestamp++ {
+		require.Nil(t, database.Audit.Write(&types.Audit{
+			ID:           fmt.Sprintf("audit%d", timestamp),
+			Timestamp:    timestamp,
+			Organization: "org1",
+			User:         "admin",
+			NewValue:     map[string]interface{}{"name": "test"},
+		}))
+	}
+
+	tests := []struct {
+		name     string
+		params   db.AuditFilterParams
+		expected int
+	}{
+		{"all", db.AuditFilterParams{StartTime: 0, EndTime: 100}, 10},
+		{"time range", db.AuditFilterParams{StartTime: 3, EndTime: 5}, 3},
+		{"count", db.AuditFilterParams{StartTime: 0, EndTime: 100, Count: 4}, 4},
+		{"time range and count", db.AuditFilterParams{StartTime: 8, EndTime: 100, Count: 5}, 3},
+		{"no match", db.AuditFilterParams{StartTime: 11, EndTime: 100}, 0},
+	}
+	for _, test := range tests {
+		audits, err := database.Audit.GetOrganization("org1", test.params)
+		require.Nil(t, err)
+		require.Equal(t, test.expected, len(audits), test.name)
+
+		audits, err = database.Audit.GetUser("admin", test.params)
+		require.Nil(t, err)
+		require.Equal(t, test.expected, len(audits), test.name)
+	}
+}
+
+func Test_ChangeLog(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	now := shared.GetCurrentTimeMilliseconds()
+	for i := int64(1); i <= 3; i++ {
+		require.Nil(t, database.ChangeLog.Write(&types.Change{
+			ID:           fmt.Sprintf("change%d", i),
+			Timestamp:    now + i,
+			Operation:    types.ChangeOperationUpdate,
+			EntityType:   types.TypeKeyName,
+			Organization: "org1",
+			EntityID:     fmt.Sprintf("key%d", i),
+		}))
+	}
+
+	changes, err := database.ChangeLog.GetSince(now + 1)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(changes))
+	require.Equal(t, "key2", changes[0].EntityID)
+	require.Equal(t, "key3", changes[1].EntityID)
+}
+
+func Test_Developer_List(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	for i := 1; i <= 5; i++ {
+		status := "active"
+		if i%2 == 0 {
+			status = "inactive"
+		}
+		require.Nil(t, database.Developer.Update("org1", &types.Developer{
+			DeveloperID:      fmt.Sprintf("dev%d", i),
+			Email:            fmt.Sprintf("dev%d@example.com", i),
+			Status:           status,
+			LastModifiedAt:   int64(i),
+			OrganizationName: "org1",
+		}, db.Precondition{}))
+	}
+	require.Nil(t, database.Developer.Update("org1", &types.Developer{
+		DeveloperID: "dev6", Email: "joe@example.org", Status: "active",
+		LastModifiedAt: 6, OrganizationName: "org1"}, db.Precondition{}))
+
+	// Walk all pages
+	var emails []string
+	params := db.ListParams{PageSize: 2}
+	for {
+		developers, nextPageToken, err := database.Developer.List("org1", params)
+		require.Nil(t, err)
+		require.LessOrEqual(t, len(developers), 2)
+		for _, developer := range developers {
+			emails = append(emails, developer.Email)
+		}
+		if nextPageToken == "" {
+			break
+		}
+		params.PageToken = nextPageToken
+	}
+	require.Equal(t, []string{"dev1@example.com", "dev2@example.com", "dev3@example.com",
+		"dev4@example.com", "dev5@example.com", "joe@example.org"}, emails)
+
+	tests := []struct {
+		name     string
+		params   db.ListParams
+		expected int
+	}{
+		{"all", db.ListParams{}, 6},
+		{"status", db.ListParams{Status: "inactive"}, 2},
+		{"email prefix", db.ListParams{EmailPrefix: "dev"}, 5},
+		{"last modified", db.ListParams{LastModifiedFrom: 2, LastModifiedTo: 4}, 3},
+		{"combined", db.ListParams{Status: "active", EmailPrefix: "dev", LastModifiedFrom: 2}, 2},
+	}
+	for _, test := range tests {
+		developers, _, err := database.Developer.List("org1", test.params)
+		require.Nil(t, err, test.name)
+		require.Equal(t, test.expected, len(developers), test.name)
+	}
+
+	_, _, err := database.Developer.List("org1", db.ListParams{PageToken: "%%%"})
+	require.Equal(t, http.StatusBadRequest, types.HTTPStatusCode(err))
+}
+
+func Test_Route_Precondition(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	require.Nil(t, database.Route.Update(&types.Route{
+		Name: "r1", Path: "/", LastModifiedAt: 10}, db.Precondition{}))
+
+	// Update of stored entity with different last modified timestamp fails
+	err := database.Route.Update(&types.Route{
+		Name: "r1", Path: "/new", LastModifiedAt: 20}, db.Precondition{LastModifiedAt: 9})
+	require.Equal(t, http.StatusPreconditionFailed, types.HTTPStatusCode(err))
+
+	require.Nil(t, database.Route.Update(&types.Route{
+		Name: "r1", Path: "/new", LastModifiedAt: 20}, db.Precondition{LastModifiedAt: 10}))
+	route, err := database.Route.Get("r1")
+	require.Nil(t, err)
+	require.Equal(t, "/new", route.Path)
+	require.Equal(t, int64(20), route.LastModifiedAt)
+
+	// Conditional update must not create an entity
+	err = database.Route.Update(&types.Route{
+		Name: "r2", Path: "/", LastModifiedAt: 20}, db.Precondition{LastModifiedAt: 10})
+	require.Equal(t, http.StatusPreconditionFailed, types.HTTPStatusCode(err))
+
+	err = database.Route.Delete("r1", db.Precondition{LastModifiedAt: 10})
+	require.Equal(t, http.StatusPreconditionFailed, types.HTTPStatusCode(err))
+
+	require.Nil(t, database.Route.Delete("r1", db.Precondition{LastModifiedAt: 20}))
+	_, err = database.Route.Get("r1")
+	require.Equal(t, http.StatusNotFound, types.HTTPStatusCode(err))
+}
+
+func Test_Listener_Policies(t *testing.T) {
+
+	database := newTestDatabase(t)
+
+	policies := types.Policies{
+		{Name: "checkAPIKey", Parameters: types.Attributes{{Name: "header", Value: "x-api-key"}}},
+		{Name: "lookupGeoIP", Condition: "POST /admin/**"},
+	}
+	require.Nil(t, database.Listener.Update(&types.Listener{
+		Name: "l1", Port: 80, Policies: policies}, db.Precondition{}))
+	listener, err := database.Listener.Get("l1")
+	require.Nil(t, err)
+	require.Equal(t, policies, listener.Policies)
+
+	require.Nil(t, database.Listener.Update(&types.Listener{
+		Name: "l2", Port: 80}, db.Precondition{}))
+	listener, err = database.Listener.Get("l2")
+	require.Nil(t, err)
+	require.Empty(t, listener.Policies)
+}
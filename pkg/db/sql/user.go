@@ -0,0 +1,144 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erikbos/gatekeeper/pkg/db"
+	"github.com/erikbos/gatekeeper/pkg/types"
+)
+
+const (
+	// List of user columns we use
+	userColumns = `name,
+display_name,
+password,
+status,
+roles,
+created_at,
+created_by,
+lastmodified_at,
+lastmodified_by`
+
+	// Prometheus label for metrics of db interactions
+	userMetricLabel = "users"
+)
+
+// UserStore holds our database config
+type UserStore struct {
+	db *Database
+}
+
+// NewUserStore creates user instance
+func NewUserStore(database *Database) *UserStore {
+	return &UserStore{
+		db: database,
+	}
+}
+
+// GetAll retrieves all users
+func (s *UserStore) GetAll() (types.Users, types.Error) {
+
+	query := "SELECT " + userColumns + " FROM users ORDER BY name"
+	users, err := s.runGetUserQuery(query)
+	if err != nil {
+		s.db.metrics.QueryFailed(userMetricLabel)
+		return types.NullUsers, types.NewDatabaseError(err)
+	}
+
+	s.db.metrics.QuerySuccessful(userMetricLabel)
+	return users, nil
+}
+
+// Get retrieves a user from database
+func (s *UserStore) Get(userName string) (*types.User, types.Error) {
+
+	query := "SELECT " + userColumns + " FROM users WHERE name = ? LIMIT 1"
+	users, err := s.runGetUserQuery(query, userName)
+	if err != nil {
+		s.db.metrics.QueryFailed(userMetricLabel)
+		return nil, types.NewDatabaseError(err)
+	}
+
+	if len(users) == 0 {
+		s.db.metrics.QueryNotFound(userMetricLabel)
+		return nil, types.NewItemNotFoundError(
+			fmt.Errorf("cannot find user '%s'", userName))
+	}
+
+	s.db.metrics.QuerySuccessful(userMetricLabel)
+	return &users[0], nil
+}
+
+// runGetUserQuery executes SQL query and returns resultset
+func (s *UserStore) runGetUserQuery(query string, queryParameters ...interface{}) (types.Users, error) {
+
+	timer := prometheus.NewTimer(s.db.metrics.queryHistogram)
+	defer timer.ObserveDuration()
+
+	rows, err := s.db.query(query, queryParameters...)
+	if err != nil {
+		return types.NullUsers, err
+	}
+	defer rows.Close()
+
+	users := types.Users{}
+	for rows.Next() {
+		var u types.User
+		var roles string
+		if err := rows.Scan(
+			&u.Name,
+			&u.DisplayName,
+			&u.Password,
+			&u.Status,
+			&roles,
+			&u.CreatedAt,
+			&u.CreatedBy,
+			&u.LastModifiedAt,
+			&u.LastModifiedBy); err != nil {
+			return types.NullUsers, err
+		}
+		u.Roles = columnToStringSlice(roles)
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// Update UPSERTs an user in database
+func (s *UserStore) Update(c *types.User, precondition db.Precondition) types.Error {
+
+	if err := s.db.upsert("users", userColumns, "name", precondition,
+		c.Name,
+		c.DisplayName,
+		c.Password,
+		c.Status,
+		stringSliceToColumn(c.Roles),
+		c.CreatedAt,
+		c.CreatedBy,
+		c.LastModifiedAt,
+		c.LastModifiedBy); err != nil {
+
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeUserName, c.Name)
+		}
+		s.db.metrics.QueryFailed(userMetricLabel)
+		return types.NewDatabaseError(
+			fmt.Errorf("cannot update user '%s' (%s)", c.Name, err))
+	}
+	return nil
+}
+
+// Delete deletes a user
+func (s *UserStore) Delete(userToDelete string, precondition db.Precondition) types.Error {
+
+	query := "DELETE FROM users WHERE name = ?"
+	if err := s.db.delete(query, precondition, userToDelete); err != nil {
+		if err == errPreconditionFailed {
+			return db.NewPreconditionFailedError(types.TypeUserName, userToDelete)
+		}
+		s.db.metrics.QueryFailed(userMetricLabel)
+		return types.NewDatabaseError(err)
+	}
+	return nil
+}
@@ -1,6 +1,13 @@
 package types
 
-import "github.com/go-playground/validator/v10"
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/go-playground/validator/v10"
+)
 
 // APIProduct type contains everything about an API product
 //
@@ -13,7 +20,8 @@ type (
 		// Routegroup this apiproduct should match to
 		RouteGroup string
 
-		// List of paths this apiproduct applies to
+		// List of paths this apiproduct applies to, optionally prefixed with
+		// allowed HTTP methods (e.g. "GET,HEAD /orders/**")
 		APIResources []string `binding:"required,min=1"`
 
 		// List of scopes that apply to this product
@@ -31,8 +39,8 @@ type (
 		// Full description of this api product
 		Description string
 
-		// Comma separated list of policynames, to apply to requests
-		Policies string
+		// Policies to apply to requests, in order of evaluation
+		Policies Policies
 
 		// Created at timestamp in epoch milliseconds
 		CreatedAt int64
@@ -49,6 +57,15 @@ type (
 
 	// APIProducts holds one or more apiproducts
 	APIProducts []APIProduct
+
+	// APIResource is a path pattern of an apiproduct, optionally restricted to HTTP methods
+	APIResource struct {
+		// Allowed HTTP methods, empty means all methods are allowed
+		Methods []string
+
+		// Doublestar path pattern (e.g. "/orders/**")
+		Path string
+	}
 )
 
 var (
@@ -63,5 +80,62 @@ var (
 func (a *APIProduct) Validate() error {
 
 	validate := validator.New()
-	return validate.Struct(a)
+	if err := validate.Struct(a); err != nil {
+		return err
+	}
+	for _, resource := range a.APIResources {
+		if _, err := ParseAPIResource(resource); err != nil {
+			return err
+		}
+	}
+	return a.Policies.Validate(PolicyScopeAPIProduct)
+}
+
+// ParseAPIResource parses an apiproduct resource, which is a path pattern
+// optionally prefixed with a comma separated list of HTTP methods
+func ParseAPIResource(resource string) (APIResource, error) {
+
+	fields := strings.Fields(resource)
+	var r APIResource
+	switch len(fields) {
+	case 1:
+		r.Path = fields[0]
+	case 2:
+		for _, method := range strings.Split(strings.ToUpper(fields[0]), ",") {
+			if !validHTTPMethods[method] {
+				return APIResource{}, fmt.Errorf("unknown HTTP method '%s' in apiresource '%s'", method, resource)
+			}
+			r.Methods = append(r.Methods, method)
+		}
+		r.Path = fields[1]
+	default:
+		return APIResource{}, fmt.Errorf("cannot parse apiresource '%s'", resource)
+	}
+	if !doublestar.ValidatePattern(r.Path) {
+		return APIResource{}, fmt.Errorf("invalid path pattern in apiresource '%s'", resource)
+	}
+	return r, nil
+}
+
+// IsRequestAllowed checks whether method and path of a request match resource
+func (r APIResource) IsRequestAllowed(requestMethod, requestPath string) bool {
+
+	// No methods means all methods are allowed
+	if len(r.Methods) != 0 && !methodMatch(r.Methods, requestMethod) {
+		return false
+	}
+	return pathMatch([]string{r.Path}, requestPath)
+}
+
+// validHTTPMethods contains all HTTP methods an apiresource can be restricted to
+var validHTTPMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodConnect: true,
 }
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseAPIResource(t *testing.T) {
+
+	tests := []struct {
+		resource string
+		expected APIResource
+		valid    bool
+	}{
+		{"/orders/**", APIResource{Path: "/orders/**"}, true},
+		{"GET,HEAD /orders/**", APIResource{Methods: []string{"GET", "HEAD"}, Path: "/orders/**"}, true},
+		{"get /orders", APIResource{Methods: []string{"GET"}, Path: "/orders"}, true},
+		{"FETCH /orders", APIResource{}, false},
+		{"GET /orders /customers", APIResource{}, false},
+		{"GET /orders/[", APIResource{}, false},
+		{"", APIResource{}, false},
+	}
+	for _, test := range tests {
+		resource, err := ParseAPIResource(test.resource)
+		if !test.valid {
+			require.Error(t, err, test.resource)
+			continue
+		}
+		require.NoError(t, err, test.resource)
+		require.Equal(t, test.expected, resource, test.resource)
+	}
+}
+
+func Test_APIResourceIsRequestAllowed(t *testing.T) {
+
+	readOnly, err := ParseAPIResource("GET,HEAD /orders/**")
+	require.NoError(t, err)
+	require.True(t, readOnly.IsRequestAllowed("GET", "/orders/42"))
+	require.True(t, readOnly.IsRequestAllowed("HEAD", "/orders/42"))
+	require.False(t, readOnly.IsRequestAllowed("POST", "/orders/42"))
+	require.False(t, readOnly.IsRequestAllowed("GET", "/customers/42"))
+
+	all, err := ParseAPIResource("/orders/**")
+	require.NoError(t, err)
+	require.True(t, all.IsRequestAllowed("DELETE", "/orders/42"))
+}
+
+func Test_APIProductValidate(t *testing.T) {
+
+	product := APIProduct{
+		Name:         "orders",
+		APIResources: []string{"/orders/**", "POST /payments"},
+	}
+	require.NoError(t, product.Validate())
+
+	product.APIResources = append(product.APIResources, "FETCH /orders")
+	require.Error(t, product.Validate())
+}
@@ -0,0 +1,42 @@
+package types
+
+// Change holds a change made to an entity, used to notify other instances
+type (
+	Change struct {
+		// Unique id
+		ID string
+
+		// Change timestamp in epoch milliseconds
+		Timestamp int64
+
+		// Operation performed on entity: "create", "update" or "delete"
+		Operation string
+
+		// Type of entity that has been changed, e.g. "apiproduct"
+		EntityType string
+
+		// Organization of changed entity, empty for entities without organization
+		Organization string
+
+		// Name or id of changed entity
+		EntityID string
+	}
+
+	// Changes holds one or more changes
+	Changes []Change
+)
+
+var (
+	// NullChange is an empty change type
+	NullChange = Change{}
+
+	// NullChanges is an empty change slice
+	NullChanges = Changes{}
+)
+
+// Operations that can be performed on an entity
+const (
+	ChangeOperationCreate = "create"
+	ChangeOperationUpdate = "update"
+	ChangeOperationDelete = "delete"
+)
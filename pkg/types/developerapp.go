@@ -83,3 +83,9 @@ func (d *DeveloperApp) IsActive() bool {
 
 	return d.Status == applicationStatusApproved
 }
+
+// IsRevoked returns true in case developer app's status is revoked
+func (d *DeveloperApp) IsRevoked() bool {
+
+	return d.Status == applicationStatusRevoked
+}
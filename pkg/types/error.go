@@ -25,6 +25,12 @@ var (
 	// errNotAcceptable indicates the request can not be processed (406)
 	errNotAcceptable = errors.New("not acceptable")
 
+	// errConflict indicates the request conflicts with an existing item (409)
+	errConflict = errors.New("conflict")
+
+	// errPreconditionFailed indicates the item does not match the requested state (412)
+	errPreconditionFailed = errors.New("precondition failed")
+
 	// errDatabaseIssue indicates a database error
 	errDatabaseIssue = errors.New("database issue")
 )
@@ -103,11 +109,26 @@ func NewNotAcceptable(details error) Error {
 	return newError(errNotAcceptable, details)
 }
 
+// NewConflictError returns a conflict error
+func NewConflictError(details error) Error {
+	return newError(errConflict, details)
+}
+
+// NewPreconditionFailedError returns a precondition failed error
+func NewPreconditionFailedError(details error) Error {
+	return newError(errPreconditionFailed, details)
+}
+
 // NewDatabaseError returns a database error
 func NewDatabaseError(details error) Error {
 	return newError(errDatabaseIssue, details)
 }
 
+// IsItemNotFoundError returns true if error indicates an item cannot be found
+func IsItemNotFoundError(e Error) bool {
+	return e != nil && e.Type() == errItemNotFound
+}
+
 // HTTPStatusCode returns HTTP status code for Error type
 func HTTPStatusCode(e Error) int {
 
@@ -133,6 +154,12 @@ func HTTPStatusCode(e Error) int {
 	case errNotAcceptable:
 		return http.StatusNotAcceptable
 
+	case errConflict:
+		return http.StatusConflict
+
+	case errPreconditionFailed:
+		return http.StatusPreconditionFailed
+
 	case errDatabaseIssue:
 		return http.StatusServiceUnavailable
 
@@ -1,6 +1,10 @@
 package types
 
-import "github.com/go-playground/validator/v10"
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
 
 // Key contains an apikey entitlement
 //
@@ -39,6 +43,15 @@ type (
 	Keys []Key
 )
 
+// Prefixes of consumer keys of keys that authenticate clients by their TLS certificate
+const (
+	// KeyClientCertificateFingerprintPrefix is followed by hex encoded SHA-256 fingerprint of certificate
+	KeyClientCertificateFingerprintPrefix = "cert-sha256:"
+
+	// KeyClientCertificateNamePrefix is followed by a subject alternative name or subject of certificate
+	KeyClientCertificateNamePrefix = "cert-name:"
+)
+
 var (
 	// NullDeveloperAppKey is an empty key type
 	NullDeveloperAppKey = Key{}
@@ -84,6 +97,20 @@ func (k *Key) IsApproved() bool {
 	return k.Status == "approved"
 }
 
+// IsRevoked returns true in case key's status is revoked
+func (k *Key) IsRevoked() bool {
+
+	return k.Status == "revoked"
+}
+
+// IsClientCertificateConsumerKey returns true in case consumer key identifies a client
+// certificate, such a key cannot be used as apikey
+func IsClientCertificateConsumerKey(consumerKey string) bool {
+
+	return strings.HasPrefix(consumerKey, KeyClientCertificateFingerprintPrefix) ||
+		strings.HasPrefix(consumerKey, KeyClientCertificateNamePrefix)
+}
+
 // IsExpired returns true in case key is expired
 func (k *Key) IsExpired(now int64) bool {
 
@@ -26,8 +26,8 @@ type (
 		// Routegroup to forward traffic to
 		RouteGroup string `validate:"required"`
 
-		// Comma separated list of policynames, to apply to requests
-		Policies string
+		// Policies to apply to requests, in order of evaluation
+		Policies Policies
 
 		// Attributes of this listener
 		Attributes Attributes
@@ -97,9 +97,18 @@ const (
 	// Number of bytes of POST request to include in authentication request
 	AttributeExtAuthzRequestBodySize = "ExtAuthzRequestBodySize"
 
+	// PEM encoded CA certificate(s) to validate client certificates with
+	AttributeTLSClientCertificateCA = "TLSClientCertificateCA"
+
+	// Are connections without valid client certificate rejected
+	AttributeTLSClientCertificateRequired = "TLSClientCertificateRequired"
+
 	// Organization to be used for lookups by envoyauth when authentication requests
 	AttributeOrganization = "Organization"
 
+	// Name of request header holding apikey
+	AttributeAPIKeyHeader = "APIKeyHeader"
+
 	// Ratelimiting
 	AttributeRateLimitingCluster = "RateLimitingCluster"
 
@@ -177,6 +186,9 @@ func (l *Listener) Validate() error {
 			return fmt.Errorf("unknown attribute '%s'", attribute.Name)
 		}
 	}
+	if err := l.Policies.Validate(PolicyScopeListener); err != nil {
+		return err
+	}
 	// scan for duplicate vhosts
 	hostsSeen := make(map[string]bool, len(l.VirtualHosts))
 	for _, host := range l.VirtualHosts {
@@ -192,6 +204,7 @@ func (l *Listener) Validate() error {
 
 // validListenerAttributes contains all valid attribute names for a listener
 var validListenerAttributes = map[string]bool{
+	AttributeAPIKeyHeader:                 true,
 	AttributeAccessLogCluster:             true,
 	AttributeAccessLogClusterBufferSize:   true,
 	AttributeAccessLogFile:                true,
@@ -199,6 +212,8 @@ var validListenerAttributes = map[string]bool{
 	AttributeExtAuthzCluster:              true,
 	AttributeExtAuthzFailureModeAllow:     true,
 	AttributeExtAuthzRequestBodySize:      true,
+	AttributeTLSClientCertificateCA:       true,
+	AttributeTLSClientCertificateRequired: true,
 	AttributeExtAuthzTimeout:              true,
 	AttributeHTTPProtocol:                 true,
 	AttributeIdleTimeout:                  true,
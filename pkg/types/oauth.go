@@ -1,5 +1,11 @@
 package types
 
+import "strings"
+
+// An authorization code is stored before any access token has been issued, as access
+// token is the primary key of a token we store it using code with this prefix instead
+const OAuthAuthorizationCodePrefix = "code:"
+
 // OAuthAccessToken holds details of an issued OAuth token
 type OAuthAccessToken struct {
 	ClientID         string `json:"client_id"`
@@ -15,4 +21,17 @@ type OAuthAccessToken struct {
 	Refresh          string `json:"refresh"`
 	RefreshCreatedAt int64  `json:"refresh_created_at"`
 	RefreshExpiresIn int64  `json:"refresh_expires_in"`
+	// PKCE code challenge of authorization code (RFC 7636)
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+}
+
+// IsAuthorizationCode returns true in case token is an authorization code
+// which has not been exchanged for an access token yet
+func (t *OAuthAccessToken) IsAuthorizationCode() bool {
+
+	return strings.HasPrefix(t.Access, OAuthAuthorizationCodePrefix)
 }
+
+// OAuthAccessTokens holds one or more issued OAuth tokens
+type OAuthAccessTokens []OAuthAccessToken
@@ -0,0 +1,462 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Policy is a policy which is evaluated as part of policy chain of a listener or apiproduct
+//
+// Field validation settings (binding) are validated with
+// https://godoc.org/github.com/go-playground/validator
+type (
+	Policy struct {
+		// Name of policy
+		Name string `validate:"required,min=1"`
+
+		// Parameters of policy, see policy definition for supported parameters
+		Parameters Attributes `json:",omitempty"`
+
+		// Only evaluate policy for requests matching this apiresource (e.g. "POST /admin/**")
+		Condition string `json:",omitempty"`
+
+		// Evaluate policy without its decision changing outcome of policy chain
+		Shadow bool `json:",omitempty"`
+	}
+
+	// Policies holds one or more policies, in order of evaluation
+	Policies []Policy
+
+	// PolicyDefinition describes a policy and the parameters it supports
+	PolicyDefinition struct {
+		// Name of policy
+		Name string
+
+		// Description of policy
+		Description string
+
+		// Policy chain(s) policy can be used in: listener, apiproduct or both
+		Scope string
+
+		// Parameters supported by policy
+		Parameters []PolicyParameter
+	}
+
+	// PolicyParameter describes a parameter of a policy
+	PolicyParameter struct {
+		// Name of parameter
+		Name string
+
+		// Type of parameter value
+		Type string
+
+		// Is parameter required or not
+		Required bool
+
+		// Description of parameter
+		Description string
+
+		// Allowed values of parameter, any value in case empty
+		Values []string
+
+		// Allowed range of integer parameter, only checked in case Maximum is not 0
+		Minimum int
+		Maximum int
+	}
+)
+
+// Policy chains a policy can be used in
+const (
+	// Policy chain of listener
+	PolicyScopeListener = "listener"
+
+	// Policy chain of apiproduct
+	PolicyScopeAPIProduct = "apiproduct"
+
+	// Policy chains of both listener and apiproduct
+	PolicyScopeBoth = "both"
+)
+
+// Types of policy parameter values
+const (
+	// Any string
+	PolicyParameterTypeString = "string"
+
+	// Integer number, e.g. "10"
+	PolicyParameterTypeInteger = "integer"
+
+	// Boolean, e.g. "true"
+	PolicyParameterTypeBoolean = "boolean"
+
+	// Duration, e.g. "1m30s"
+	PolicyParameterTypeDuration = "duration"
+
+	// Comma separated list of strings, e.g. "NL,BE"
+	PolicyParameterTypeList = "list"
+
+	// CEL expression evaluating to boolean, e.g. `request.method == "POST"`
+	PolicyParameterTypeExpression = "expression"
+)
+
+var (
+	// NullPolicies is an empty policy slice
+	NullPolicies = Policies{}
+)
+
+// ParsePolicies parses policies, which are either JSON encoded or
+// a comma separated list of policy names
+func ParsePolicies(value string) (Policies, error) {
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Policies{}, nil
+	}
+	if strings.HasPrefix(value, "[") {
+		var policies Policies
+		if err := json.Unmarshal([]byte(value), &policies); err != nil {
+			return Policies{}, fmt.Errorf("cannot parse policies (%s)", err)
+		}
+		return policies, nil
+	}
+	// Policies without parameters, stored before policies were structured
+	var policies Policies
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			policies = append(policies, Policy{Name: name})
+		}
+	}
+	return policies, nil
+}
+
+// String returns comma separated list of policy names
+func (policies Policies) String() string {
+
+	names := make([]string, len(policies))
+	for i := range policies {
+		names[i] = policies[i].Name
+	}
+	return strings.Join(names, ",")
+}
+
+// Validate checks if all policies are known, can be used in policy chain
+// of scope and have valid parameters and condition
+func (policies Policies) Validate(scope string) error {
+
+	for i := range policies {
+		if err := policies[i].Validate(scope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks if policy is known, can be used in policy chain of scope
+// and has valid parameters and condition
+func (p *Policy) Validate(scope string) error {
+
+	validate := validator.New()
+	if err := validate.Struct(p); err != nil {
+		return err
+	}
+	definition, found := GetPolicyDefinition(p.Name)
+	if !found {
+		return fmt.Errorf("unknown policy '%s'", p.Name)
+	}
+	if !definition.IsAllowedInScope(scope) {
+		return fmt.Errorf("policy '%s' cannot be used in %s policies", p.Name, scope)
+	}
+	if err := definition.validateParameters(p.Parameters); err != nil {
+		return fmt.Errorf("policy '%s': %s", p.Name, err)
+	}
+	if p.Condition != "" {
+		if _, err := ParseAPIResource(p.Condition); err != nil {
+			return fmt.Errorf("policy '%s': invalid condition (%s)", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// IsAllowedInScope returns true in case policy can be used in policy chain of scope
+func (d *PolicyDefinition) IsAllowedInScope(scope string) bool {
+
+	return d.Scope == PolicyScopeBoth || d.Scope == scope
+}
+
+// validateParameters checks whether all parameters are supported, have a value
+// of the right type and whether all required parameters are present
+func (d *PolicyDefinition) validateParameters(parameters Attributes) error {
+
+	seen := make(map[string]bool, len(parameters))
+	for _, parameter := range parameters {
+		if seen[parameter.Name] {
+			return fmt.Errorf("duplicate parameter '%s'", parameter.Name)
+		}
+		seen[parameter.Name] = true
+
+		definition := d.parameter(parameter.Name)
+		if definition == nil {
+			return fmt.Errorf("unknown parameter '%s'", parameter.Name)
+		}
+		if err := definition.validateValue(parameter.Value); err != nil {
+			return err
+		}
+	}
+	for _, definition := range d.Parameters {
+		if definition.Required && !seen[definition.Name] {
+			return fmt.Errorf("missing required parameter '%s'", definition.Name)
+		}
+	}
+	return nil
+}
+
+// parameter returns definition of named parameter
+func (d *PolicyDefinition) parameter(name string) *PolicyParameter {
+
+	for i := range d.Parameters {
+		if d.Parameters[i].Name == name {
+			return &d.Parameters[i]
+		}
+	}
+	return nil
+}
+
+// validateValue checks whether value can be parsed as type of parameter
+func (p *PolicyParameter) validateValue(value string) error {
+
+	var err error
+	switch p.Type {
+	case PolicyParameterTypeString:
+		if value == "" {
+			err = fmt.Errorf("no value")
+		}
+	case PolicyParameterTypeInteger:
+		var number int
+		if number, err = strconv.Atoi(value); err == nil && p.Maximum != 0 &&
+			(number < p.Minimum || number > p.Maximum) {
+			err = fmt.Errorf("not between %d and %d", p.Minimum, p.Maximum)
+		}
+	case PolicyParameterTypeBoolean:
+		_, err = strconv.ParseBool(value)
+	case PolicyParameterTypeDuration:
+		_, err = time.ParseDuration(value)
+	case PolicyParameterTypeList:
+		for _, item := range strings.Split(value, ",") {
+			if strings.TrimSpace(item) == "" {
+				err = fmt.Errorf("empty list item")
+			}
+		}
+	case PolicyParameterTypeExpression:
+		_, err = CompilePolicyExpression(value)
+	default:
+		err = fmt.Errorf("unknown type '%s'", p.Type)
+	}
+	if err == nil && len(p.Values) != 0 && !p.isAllowedValue(value) {
+		err = fmt.Errorf("not one of %s", strings.Join(p.Values, ", "))
+	}
+	if err != nil {
+		return fmt.Errorf("parameter '%s' requires %s value (%s)", p.Name, p.Type, err)
+	}
+	return nil
+}
+
+// isAllowedValue returns true in case value is one of the allowed values of parameter
+func (p *PolicyParameter) isAllowedValue(value string) bool {
+
+	for _, allowed := range p.Values {
+		if value == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterPolicyDefinition adds a policy so it can be used in policy chains
+func RegisterPolicyDefinition(definition PolicyDefinition) error {
+
+	if definition.Name == "" {
+		return errors.New("policy definition requires name")
+	}
+	switch definition.Scope {
+	case PolicyScopeListener, PolicyScopeAPIProduct, PolicyScopeBoth:
+	default:
+		return fmt.Errorf("policy '%s' has unknown scope '%s'", definition.Name, definition.Scope)
+	}
+	for _, parameter := range definition.Parameters {
+		switch parameter.Type {
+		case PolicyParameterTypeString, PolicyParameterTypeInteger, PolicyParameterTypeBoolean,
+			PolicyParameterTypeDuration, PolicyParameterTypeList, PolicyParameterTypeExpression:
+		default:
+			return fmt.Errorf("policy '%s' parameter '%s' has unknown type '%s'",
+				definition.Name, parameter.Name, parameter.Type)
+		}
+	}
+
+	policyDefinitionsMutex.Lock()
+	defer policyDefinitionsMutex.Unlock()
+
+	if _, found := policyDefinitions[definition.Name]; found {
+		return fmt.Errorf("policy '%s' already registered", definition.Name)
+	}
+	policyDefinitions[definition.Name] = definition
+	return nil
+}
+
+// GetPolicyDefinition returns definition of named policy
+func GetPolicyDefinition(name string) (PolicyDefinition, bool) {
+
+	policyDefinitionsMutex.RLock()
+	defer policyDefinitionsMutex.RUnlock()
+
+	definition, found := policyDefinitions[name]
+	return definition, found
+}
+
+// GetPolicyDefinitions returns definitions of all policies, sorted by name
+func GetPolicyDefinitions() []PolicyDefinition {
+
+	policyDefinitionsMutex.RLock()
+	defer policyDefinitionsMutex.RUnlock()
+
+	definitions := make([]PolicyDefinition, 0, len(policyDefinitions))
+	for _, definition := range policyDefinitions {
+		definitions = append(definitions, definition)
+	}
+	sort.SliceStable(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+	return definitions
+}
+
+// policyDefinitionsMutex guards policyDefinitions against concurrent registration
+var policyDefinitionsMutex sync.RWMutex
+
+// policyDefinitions contains all policies which can be used in a policy chain
+var policyDefinitions = map[string]PolicyDefinition{
+	"checkAPIKey": {
+		Name:        "checkAPIKey",
+		Description: "Authenticate request using apikey",
+		Scope:       PolicyScopeBoth,
+		Parameters: []PolicyParameter{
+			{Name: "header", Type: PolicyParameterTypeString,
+				Description: "Request header holding apikey, overrides listener attribute APIKeyHeader"},
+		},
+	},
+	"checkOAuth2": {
+		Name:        "checkOAuth2",
+		Description: "Authenticate request using OAuth2 accesstoken",
+		Scope:       PolicyScopeBoth,
+	},
+	"checkJWT": {
+		Name:        "checkJWT",
+		Description: "Authenticate request using JWT accesstoken",
+		Scope:       PolicyScopeBoth,
+	},
+	"checkHMACSignature": {
+		Name:        "checkHMACSignature",
+		Description: "Authenticate request using HMAC request signature, nonces are only checked per authserver instance",
+		Scope:       PolicyScopeBoth,
+	},
+	"checkClientCertificate": {
+		Name:        "checkClientCertificate",
+		Description: "Authenticate request using TLS client certificate",
+		Scope:       PolicyScopeBoth,
+	},
+	"removeAPIKeyFromQP": {
+		Name:        "removeAPIKeyFromQP",
+		Description: "Remove apikey from query parameters",
+		Scope:       PolicyScopeBoth,
+	},
+	"removeAPIKeyFromHeader": {
+		Name:        "removeAPIKeyFromHeader",
+		Description: "Remove apikey request header",
+		Scope:       PolicyScopeBoth,
+		Parameters: []PolicyParameter{
+			{Name: "header", Type: PolicyParameterTypeString,
+				Description: "Request header holding apikey, overrides listener attribute APIKeyHeader"},
+		},
+	},
+	"lookupGeoIP": {
+		Name:        "lookupGeoIP",
+		Description: "Set country and state of connecting ip address as metadata",
+		Scope:       PolicyScopeBoth,
+		Parameters: []PolicyParameter{
+			{Name: "allowedCountries", Type: PolicyParameterTypeList,
+				Description: "Country codes allowed to connect, requests from other countries are rejected"},
+		},
+	},
+	"qps": {
+		Name:        "qps",
+		Description: "Set requests per second ratelimit as metadata",
+		Scope:       PolicyScopeBoth,
+		Parameters: []PolicyParameter{
+			{Name: "limit", Type: PolicyParameterTypeInteger,
+				Description: "Requests per second, overrides developer app and apiproduct attribute <apiproduct>_quotaPerSecond"},
+		},
+	},
+	"sendAPIKey": {
+		Name:        "sendAPIKey",
+		Description: "Add apikey as upstream header",
+		Scope:       PolicyScopeBoth,
+	},
+	"sendDeveloperEmail": {
+		Name:        "sendDeveloperEmail",
+		Description: "Add developer email address as upstream header",
+		Scope:       PolicyScopeBoth,
+	},
+	"sendDeveloperID": {
+		Name:        "sendDeveloperID",
+		Description: "Add developer id as upstream header",
+		Scope:       PolicyScopeBoth,
+	},
+	"sendDeveloperAppName": {
+		Name:        "sendDeveloperAppName",
+		Description: "Add developer app name as upstream header",
+		Scope:       PolicyScopeBoth,
+	},
+	"sendDeveloperAppID": {
+		Name:        "sendDeveloperAppID",
+		Description: "Add developer app id as upstream header",
+		Scope:       PolicyScopeBoth,
+	},
+	"checkIPAccessList": {
+		Name:        "checkIPAccessList",
+		Description: "Check source ip address against access list",
+		Scope:       PolicyScopeBoth,
+		Parameters: []PolicyParameter{
+			{Name: "accessList", Type: PolicyParameterTypeList,
+				Description: "Allowed networks, overrides developer app attribute IPAccessList"},
+		},
+	},
+	"checkReferer": {
+		Name:        "checkReferer",
+		Description: "Check host of request against access list",
+		Scope:       PolicyScopeBoth,
+		Parameters: []PolicyParameter{
+			{Name: "accessList", Type: PolicyParameterTypeList,
+				Description: "Allowed host patterns, overrides developer app attribute Referer"},
+		},
+	},
+	"checkExpression": {
+		Name:        "checkExpression",
+		Description: "Check request against CEL expression",
+		Scope:       PolicyScopeBoth,
+		Parameters: []PolicyParameter{
+			{Name: "expression", Type: PolicyParameterTypeExpression, Required: true,
+				Description: "CEL expression, e.g. request.method == \"POST\" && developer.attributes[\"tier\"] != \"gold\""},
+			{Name: "action", Type: PolicyParameterTypeString, Values: []string{"deny", "allow"},
+				Description: "deny (default) rejects requests matching expression, allow rejects requests not matching expression"},
+			{Name: "statusCode", Type: PolicyParameterTypeInteger, Minimum: 400, Maximum: 599,
+				Description: "Status code of rejected requests, default 403"},
+			{Name: "message", Type: PolicyParameterTypeString,
+				Description: "Message of rejected requests, default \"Blocked by expression\""},
+		},
+	},
+}
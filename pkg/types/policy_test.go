@@ -0,0 +1,216 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParsePolicies(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		value    string
+		expected Policies
+		err      bool
+	}{
+		{
+			name:     "empty",
+			value:    "",
+			expected: Policies{},
+		},
+		{
+			name:  "comma separated names",
+			value: "checkAPIKey, lookupGeoIP,",
+			expected: Policies{
+				{Name: "checkAPIKey"},
+				{Name: "lookupGeoIP"},
+			},
+		},
+		{
+			name:  "json",
+			value: `[{"Name":"qps","Parameters":[{"Name":"limit","Value":"10"}],"Condition":"GET /orders/**"}]`,
+			expected: Policies{
+				{Name: "qps", Parameters: Attributes{{Name: "limit", Value: "10"}}, Condition: "GET /orders/**"},
+			},
+		},
+		{
+			name:  "json with shadow policy",
+			value: `[{"Name":"checkIPAccessList","Shadow":true}]`,
+			expected: Policies{
+				{Name: "checkIPAccessList", Shadow: true},
+			},
+		},
+		{
+			name:  "malformed json",
+			value: `[{"Name":`,
+			err:   true,
+		},
+	}
+	for _, test := range tests {
+		policies, err := ParsePolicies(test.value)
+		if test.err {
+			require.Error(t, err, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		require.Equal(t, test.expected, policies, test.name)
+	}
+}
+
+func Test_PoliciesString(t *testing.T) {
+
+	policies := Policies{{Name: "checkAPIKey"}, {Name: "qps", Parameters: Attributes{{Name: "limit", Value: "10"}}}}
+	require.Equal(t, "checkAPIKey,qps", policies.String())
+}
+
+func Test_PoliciesValidate(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		policies Policies
+		valid    bool
+	}{
+		{
+			name:     "no policies",
+			policies: Policies{},
+			valid:    true,
+		},
+		{
+			name: "valid",
+			policies: Policies{
+				{Name: "checkAPIKey", Parameters: Attributes{{Name: "header", Value: "x-api-key"}}},
+				{Name: "qps", Parameters: Attributes{{Name: "limit", Value: "10"}}, Condition: "POST,PUT /orders/**"},
+				{Name: "lookupGeoIP", Parameters: Attributes{{Name: "allowedCountries", Value: "NL,BE"}}},
+			},
+			valid: true,
+		},
+		{
+			name:     "unknown policy",
+			policies: Policies{{Name: "checkPassword"}},
+		},
+		{
+			name:     "no name",
+			policies: Policies{{}},
+		},
+		{
+			name:     "unknown parameter",
+			policies: Policies{{Name: "checkOAuth2", Parameters: Attributes{{Name: "header", Value: "x"}}}},
+		},
+		{
+			name:     "duplicate parameter",
+			policies: Policies{{Name: "qps", Parameters: Attributes{{Name: "limit", Value: "1"}, {Name: "limit", Value: "2"}}}},
+		},
+		{
+			name:     "wrong parameter type",
+			policies: Policies{{Name: "qps", Parameters: Attributes{{Name: "limit", Value: "many"}}}},
+		},
+		{
+			name:     "empty list item",
+			policies: Policies{{Name: "lookupGeoIP", Parameters: Attributes{{Name: "allowedCountries", Value: "NL,,BE"}}}},
+		},
+		{
+			name:     "invalid condition",
+			policies: Policies{{Name: "checkAPIKey", Condition: "FETCH /orders"}},
+		},
+		{
+			name: "expression",
+			policies: Policies{{Name: "checkExpression", Parameters: Attributes{
+				{Name: "expression", Value: `request.method == "POST" && developer.attributes["tier"] != "gold"`}}}},
+			valid: true,
+		},
+		{
+			name: "expression with syntax error",
+			policies: Policies{{Name: "checkExpression", Parameters: Attributes{
+				{Name: "expression", Value: `request.method ==`}}}},
+		},
+		{
+			name: "expression with unknown variable",
+			policies: Policies{{Name: "checkExpression", Parameters: Attributes{
+				{Name: "expression", Value: `request.user == "joe"`}}}},
+		},
+		{
+			name: "expression not evaluating to boolean",
+			policies: Policies{{Name: "checkExpression", Parameters: Attributes{
+				{Name: "expression", Value: `request.path`}}}},
+		},
+		{
+			name: "expression with action and status code",
+			policies: Policies{{Name: "checkExpression", Parameters: Attributes{
+				{Name: "expression", Value: `request.method == "GET"`},
+				{Name: "action", Value: "allow"},
+				{Name: "statusCode", Value: "429"}}}},
+			valid: true,
+		},
+		{
+			name: "expression with unknown action",
+			policies: Policies{{Name: "checkExpression", Parameters: Attributes{
+				{Name: "expression", Value: `request.method == "GET"`},
+				{Name: "action", Value: "Allow"}}}},
+		},
+		{
+			name: "expression with status code below 400",
+			policies: Policies{{Name: "checkExpression", Parameters: Attributes{
+				{Name: "expression", Value: `request.method == "GET"`},
+				{Name: "statusCode", Value: "0"}}}},
+		},
+		{
+			name: "expression with status code above 599",
+			policies: Policies{{Name: "checkExpression", Parameters: Attributes{
+				{Name: "expression", Value: `request.method == "GET"`},
+				{Name: "statusCode", Value: "99999"}}}},
+		},
+	}
+	for _, test := range tests {
+		err := test.policies.Validate(PolicyScopeListener)
+		if test.valid {
+			require.NoError(t, err, test.name)
+		} else {
+			require.Error(t, err, test.name)
+		}
+	}
+}
+
+func Test_PolicyDefinitionRequiredParameter(t *testing.T) {
+
+	definition := PolicyDefinition{
+		Name: "test",
+		Parameters: []PolicyParameter{
+			{Name: "timeout", Type: PolicyParameterTypeDuration, Required: true},
+			{Name: "enabled", Type: PolicyParameterTypeBoolean},
+		},
+	}
+	require.NoError(t, definition.validateParameters(Attributes{{Name: "timeout", Value: "1m"}}))
+	require.Error(t, definition.validateParameters(Attributes{{Name: "enabled", Value: "true"}}))
+	require.Error(t, definition.validateParameters(Attributes{
+		{Name: "timeout", Value: "1m"}, {Name: "enabled", Value: "yes"}}))
+}
+
+func Test_RegisterPolicyDefinition(t *testing.T) {
+
+	require.NoError(t, RegisterPolicyDefinition(PolicyDefinition{
+		Name:  "testPolicy",
+		Scope: PolicyScopeListener,
+		Parameters: []PolicyParameter{
+			{Name: "limit", Type: PolicyParameterTypeInteger},
+		},
+	}))
+	definition, found := GetPolicyDefinition("testPolicy")
+	require.True(t, found)
+	require.Equal(t, PolicyScopeListener, definition.Scope)
+
+	require.Error(t, RegisterPolicyDefinition(PolicyDefinition{Name: "testPolicy", Scope: PolicyScopeBoth}))
+	require.Error(t, RegisterPolicyDefinition(PolicyDefinition{Scope: PolicyScopeBoth}))
+	require.Error(t, RegisterPolicyDefinition(PolicyDefinition{Name: "testScope", Scope: "route"}))
+	require.Error(t, RegisterPolicyDefinition(PolicyDefinition{Name: "testParameter", Scope: PolicyScopeBoth,
+		Parameters: []PolicyParameter{{Name: "limit", Type: "float"}}}))
+
+	policies := Policies{{Name: "testPolicy", Parameters: Attributes{{Name: "limit", Value: "5"}}}}
+	require.NoError(t, policies.Validate(PolicyScopeListener))
+	require.Error(t, policies.Validate(PolicyScopeAPIProduct))
+
+	definitions := GetPolicyDefinitions()
+	for i := 1; i < len(definitions); i++ {
+		require.Less(t, definitions[i-1].Name, definitions[i].Name)
+	}
+}
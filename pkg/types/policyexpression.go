@@ -0,0 +1,91 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Variables which can be used in a policy expression, e.g.
+// request.method == "POST" && developer.attributes["tier"] != "gold"
+const (
+	PolicyExpressionRequestMethod        = "request.method"
+	PolicyExpressionRequestHost          = "request.host"
+	PolicyExpressionRequestPath          = "request.path"
+	PolicyExpressionRequestHeaders       = "request.headers"
+	PolicyExpressionRequestQuery         = "request.query"
+	PolicyExpressionRequestIP            = "request.ip"
+	PolicyExpressionRequestCountry       = "request.country"
+	PolicyExpressionDeveloperID          = "developer.id"
+	PolicyExpressionDeveloperEmail       = "developer.email"
+	PolicyExpressionDeveloperStatus      = "developer.status"
+	PolicyExpressionDeveloperAttributes  = "developer.attributes"
+	PolicyExpressionAppID                = "app.id"
+	PolicyExpressionAppName              = "app.name"
+	PolicyExpressionAppStatus            = "app.status"
+	PolicyExpressionAppAttributes        = "app.attributes"
+	PolicyExpressionKeyStatus            = "key.status"
+	PolicyExpressionKeyAttributes        = "key.attributes"
+	PolicyExpressionAPIProductName       = "apiproduct.name"
+	PolicyExpressionAPIProductAttributes = "apiproduct.attributes"
+)
+
+// policyExpressionEnv holds declarations of all variables of a policy expression
+var policyExpressionEnv *cel.Env
+
+func init() {
+
+	stringMap := decls.NewMapType(decls.String, decls.String)
+
+	var err error
+	policyExpressionEnv, err = cel.NewEnv(cel.Declarations(
+		decls.NewVar(PolicyExpressionRequestMethod, decls.String),
+		decls.NewVar(PolicyExpressionRequestHost, decls.String),
+		decls.NewVar(PolicyExpressionRequestPath, decls.String),
+		decls.NewVar(PolicyExpressionRequestHeaders, stringMap),
+		decls.NewVar(PolicyExpressionRequestQuery, stringMap),
+		decls.NewVar(PolicyExpressionRequestIP, decls.String),
+		decls.NewVar(PolicyExpressionRequestCountry, decls.String),
+		decls.NewVar(PolicyExpressionDeveloperID, decls.String),
+		decls.NewVar(PolicyExpressionDeveloperEmail, decls.String),
+		decls.NewVar(PolicyExpressionDeveloperStatus, decls.String),
+		decls.NewVar(PolicyExpressionDeveloperAttributes, stringMap),
+		decls.NewVar(PolicyExpressionAppID, decls.String),
+		decls.NewVar(PolicyExpressionAppName, decls.String),
+		decls.NewVar(PolicyExpressionAppStatus, decls.String),
+		decls.NewVar(PolicyExpressionAppAttributes, stringMap),
+		decls.NewVar(PolicyExpressionKeyStatus, decls.String),
+		decls.NewVar(PolicyExpressionKeyAttributes, stringMap),
+		decls.NewVar(PolicyExpressionAPIProductName, decls.String),
+		decls.NewVar(PolicyExpressionAPIProductAttributes, stringMap),
+	))
+	if err != nil {
+		panic(fmt.Sprintf("cannot create policy expression environment (%s)", err))
+	}
+}
+
+// CompilePolicyExpression parses and type checks a policy expression,
+// it returns a program which can be evaluated against a request
+func CompilePolicyExpression(expression string) (cel.Program, error) {
+
+	if expression == "" {
+		return nil, errors.New("no expression")
+	}
+	ast, issues := policyExpressionEnv.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if !isBoolType(ast.ResultType()) {
+		return nil, errors.New("expression does not evaluate to boolean")
+	}
+	return policyExpressionEnv.Program(ast)
+}
+
+// isBoolType returns true in case type is boolean
+func isBoolType(t *exprpb.Type) bool {
+
+	return t.GetPrimitive() == exprpb.Type_BOOL
+}
@@ -65,6 +65,11 @@ func NameOf(entity interface{}) string {
 	case *APIProduct:
 		return TypeAPIProductName
 
+	case OAuthAccessToken:
+		return TypeOAuthName
+	case *OAuthAccessToken:
+		return TypeOAuthName
+
 	case User:
 		return TypeUserName
 	case *User:
@@ -129,6 +134,12 @@ func IDOf(entity interface{}) string {
 	case *APIProduct:
 		return v.Name
 
+	// Token values are secret, so a token is identified by the client it was issued to
+	case OAuthAccessToken:
+		return v.ClientID
+	case *OAuthAccessToken:
+		return v.ClientID
+
 	case User:
 		return v.Name
 	case *User: